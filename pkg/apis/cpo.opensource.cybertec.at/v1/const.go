@@ -1,5 +1,7 @@
 package v1
 
+import "time"
+
 // ClusterStatusUnknown etc : status of a Postgres cluster known to the operator
 const (
 	ClusterStatusUnknown      = ""
@@ -11,8 +13,62 @@ const (
 	ClusterStatusRunning      = "Running"
 	ClusterStatusInvalid      = "Invalid"
 	ClusterStatusRestoring    = "Restoring"
+	ClusterStatusPaused       = "Paused"
+)
+
+// StorageMigrationRunning etc : phase of the storage class migration of a Postgres cluster
+const (
+	StorageMigrationRunning   = "Running"
+	StorageMigrationCompleted = "Completed"
+	StorageMigrationFailed    = "Failed"
+)
+
+// SynchronousReplicationOff etc : replication modes of the synchronous_replication setting
+const (
+	SynchronousReplicationOff    = "off"
+	SynchronousReplicationOn     = "on"
+	SynchronousReplicationStrict = "strict"
+	SynchronousReplicationQuorum = "quorum"
+)
+
+// TuningProfileOLTP etc : workloads the Postgres parameters can be tuned for
+const (
+	TuningProfileOLTP  = "oltp"
+	TuningProfileOLAP  = "olap"
+	TuningProfileMixed = "mixed"
+)
+
+// StorageTypeSSD etc : storage types considered by the tuning profiles
+const (
+	StorageTypeSSD = "ssd"
+	StorageTypeHDD = "hdd"
+)
+
+// ExtensionStatePresent etc : desired states of a database extension
+const (
+	ExtensionStatePresent = "present"
+	ExtensionStateAbsent  = "absent"
+)
+
+// DeletionPolicyRetain etc : handling of databases and roles removed from the manifest
+const (
+	DeletionPolicyRetain = "retain"
+	DeletionPolicyRename = "rename"
+	DeletionPolicyDrop   = "drop"
+
+	// DefaultDeletionDelay is the time between the removal from the manifest and the deletion
+	DefaultDeletionDelay = 24 * time.Hour
+)
+
+// PendingDeletionDatabase etc : kinds of objects waiting for their deletion
+const (
+	PendingDeletionDatabase = "database"
+	PendingDeletionRole     = "role"
 )
 
+// ValidUntilInfinity lets the password of a role never expire
+const ValidUntilInfinity = "infinity"
+
 const (
 	serviceNameMaxLength   = 63
 	clusterNameMaxLength   = serviceNameMaxLength - len("-repl")
@@ -114,6 +114,106 @@ var minDisable = -1.0
 var mapString = "map"
 var min1int64 = int64(1)
 
+// pgHbaRulesSchema is shared by the Postgres manifest and the operator configuration
+var pgHbaRulesSchema = apiextv1.JSONSchemaProps{
+	Type: "array",
+	Items: &apiextv1.JSONSchemaPropsOrArray{
+		Schema: &apiextv1.JSONSchemaProps{
+			Type:     "object",
+			Required: []string{"type", "database", "user", "method"},
+			Properties: map[string]apiextv1.JSONSchemaProps{
+				"type": {
+					Type: "string",
+					Enum: []apiextv1.JSON{
+						{
+							Raw: []byte(`"local"`),
+						},
+						{
+							Raw: []byte(`"host"`),
+						},
+						{
+							Raw: []byte(`"hostssl"`),
+						},
+						{
+							Raw: []byte(`"hostnossl"`),
+						},
+						{
+							Raw: []byte(`"hostgssenc"`),
+						},
+						{
+							Raw: []byte(`"hostnogssenc"`),
+						},
+					},
+				},
+				"database": {
+					Type: "string",
+				},
+				"user": {
+					Type: "string",
+				},
+				"address": {
+					Type: "string",
+				},
+				"method": {
+					Type: "string",
+					Enum: []apiextv1.JSON{
+						{
+							Raw: []byte(`"trust"`),
+						},
+						{
+							Raw: []byte(`"reject"`),
+						},
+						{
+							Raw: []byte(`"scram-sha-256"`),
+						},
+						{
+							Raw: []byte(`"md5"`),
+						},
+						{
+							Raw: []byte(`"password"`),
+						},
+						{
+							Raw: []byte(`"gss"`),
+						},
+						{
+							Raw: []byte(`"sspi"`),
+						},
+						{
+							Raw: []byte(`"ident"`),
+						},
+						{
+							Raw: []byte(`"peer"`),
+						},
+						{
+							Raw: []byte(`"pam"`),
+						},
+						{
+							Raw: []byte(`"ldap"`),
+						},
+						{
+							Raw: []byte(`"radius"`),
+						},
+						{
+							Raw: []byte(`"cert"`),
+						},
+						{
+							Raw: []byte(`"oauth"`),
+						},
+					},
+				},
+				"options": {
+					Type: "object",
+					AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+						Schema: &apiextv1.JSONSchemaProps{
+							Type: "string",
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
 // PostgresCRDResourceValidation to check applied manifest parameters
 var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 	OpenAPIV3Schema: &apiextv1.JSONSchemaProps{
@@ -360,12 +460,83 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 							},
 						},
 					},
+					"databaseExtensions": {
+						Type: "object",
+						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "object",
+								AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type: "object",
+										Properties: map[string]apiextv1.JSONSchemaProps{
+											"schema": {
+												Type: "string",
+											},
+											"state": {
+												Type: "string",
+												Enum: []apiextv1.JSON{
+													{
+														Raw: []byte(`"present"`),
+													},
+													{
+														Raw: []byte(`"absent"`),
+													},
+												},
+											},
+											"version": {
+												Type: "string",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"databases": {
 						Type: "object",
 						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
 							Schema: &apiextv1.JSONSchemaProps{
+								XPreserveUnknownFields: util.True(),
+							},
+						},
+					},
+					"deletionPolicy": {
+						Type: "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"databases": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"retain"`),
+									},
+									{
+										Raw: []byte(`"rename"`),
+									},
+									{
+										Raw: []byte(`"drop"`),
+									},
+								},
+							},
+							"delay": {
 								Type: "string",
 							},
+							"force": {
+								Type: "boolean",
+							},
+							"roles": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"retain"`),
+									},
+									{
+										Raw: []byte(`"rename"`),
+									},
+									{
+										Raw: []byte(`"drop"`),
+									},
+								},
+							},
 						},
 					},
 					"dockerImage": {
@@ -426,6 +597,117 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 							},
 						},
 					},
+					"grants": {
+						Type: "object",
+						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"privileges": {
+										Type: "array",
+										Items: &apiextv1.JSONSchemaPropsOrArray{
+											Schema: &apiextv1.JSONSchemaProps{
+												Type:     "object",
+												Required: []string{"role", "objectType", "privileges"},
+												Properties: map[string]apiextv1.JSONSchemaProps{
+													"role": {
+														Type: "string",
+													},
+													"objectType": {
+														Type: "string",
+														Enum: []apiextv1.JSON{
+															{
+																Raw: []byte(`"database"`),
+															},
+															{
+																Raw: []byte(`"schema"`),
+															},
+															{
+																Raw: []byte(`"table"`),
+															},
+															{
+																Raw: []byte(`"sequence"`),
+															},
+															{
+																Raw: []byte(`"function"`),
+															},
+														},
+													},
+													"schema": {
+														Type: "string",
+													},
+													"objects": {
+														Type: "array",
+														Items: &apiextv1.JSONSchemaPropsOrArray{
+															Schema: &apiextv1.JSONSchemaProps{
+																Type: "string",
+															},
+														},
+													},
+													"privileges": {
+														Type: "array",
+														Items: &apiextv1.JSONSchemaPropsOrArray{
+															Schema: &apiextv1.JSONSchemaProps{
+																Type: "string",
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+									"defaultPrivileges": {
+										Type: "array",
+										Items: &apiextv1.JSONSchemaPropsOrArray{
+											Schema: &apiextv1.JSONSchemaProps{
+												Type:     "object",
+												Required: []string{"role", "owner", "objectType", "privileges"},
+												Properties: map[string]apiextv1.JSONSchemaProps{
+													"role": {
+														Type: "string",
+													},
+													"owner": {
+														Type: "string",
+													},
+													"objectType": {
+														Type: "string",
+														Enum: []apiextv1.JSON{
+															{
+																Raw: []byte(`"schema"`),
+															},
+															{
+																Raw: []byte(`"table"`),
+															},
+															{
+																Raw: []byte(`"sequence"`),
+															},
+															{
+																Raw: []byte(`"function"`),
+															},
+															{
+																Raw: []byte(`"type"`),
+															},
+														},
+													},
+													"schema": {
+														Type: "string",
+													},
+													"privileges": {
+														Type: "array",
+														Items: &apiextv1.JSONSchemaPropsOrArray{
+															Schema: &apiextv1.JSONSchemaProps{
+																Type: "string",
+															},
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"initContainers": {
 						Type:     "array",
 						Nullable: true,
@@ -482,6 +764,70 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 						Type:    "string",
 						Pattern: "^(\\d+|\\*)(/\\d+)?(\\s+(\\d+|\\*)(/\\d+)?){4}$",
 					},
+					"logicalReplication": {
+						Type: "object",
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"publications": {
+								Type: "array",
+								Items: &apiextv1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type:     "object",
+										Required: []string{"name", "database"},
+										Properties: map[string]apiextv1.JSONSchemaProps{
+											"name": {
+												Type: "string",
+											},
+											"database": {
+												Type: "string",
+											},
+											"tables": {
+												Type: "array",
+												Items: &apiextv1.JSONSchemaPropsOrArray{
+													Schema: &apiextv1.JSONSchemaProps{
+														Type: "string",
+													},
+												},
+											},
+											"allTables": {
+												Type: "boolean",
+											},
+										},
+									},
+								},
+							},
+							"subscriptions": {
+								Type: "array",
+								Items: &apiextv1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type:     "object",
+										Required: []string{"name", "database", "sourceCluster", "publications"},
+										Properties: map[string]apiextv1.JSONSchemaProps{
+											"name": {
+												Type: "string",
+											},
+											"database": {
+												Type: "string",
+											},
+											"sourceCluster": {
+												Type: "string",
+											},
+											"sourceDatabase": {
+												Type: "string",
+											},
+											"publications": {
+												Type: "array",
+												Items: &apiextv1.JSONSchemaPropsOrArray{
+													Schema: &apiextv1.JSONSchemaProps{
+														Type: "string",
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
 					"maintenanceWindows": {
 						Type: "array",
 						Items: &apiextv1.JSONSchemaPropsOrArray{
@@ -664,6 +1010,89 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 							"maximum_lag_on_failover": {
 								Type: "integer",
 							},
+							"member_tags": {
+								Type: "array",
+								Items: &apiextv1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type: "object",
+										Properties: map[string]apiextv1.JSONSchemaProps{
+											"clonefrom": {
+												Type: "boolean",
+											},
+											"failover_priority": {
+												Type:    "integer",
+												Minimum: &min0,
+											},
+											"nofailover": {
+												Type: "boolean",
+											},
+											"noloadbalance": {
+												Type: "boolean",
+											},
+											"nosync": {
+												Type: "boolean",
+											},
+											"ordinals": {
+												Type: "array",
+												Items: &apiextv1.JSONSchemaPropsOrArray{
+													Schema: &apiextv1.JSONSchemaProps{
+														Type:    "integer",
+														Minimum: &min0,
+													},
+												},
+											},
+											"selector": {
+												Type: "object",
+												Properties: map[string]apiextv1.JSONSchemaProps{
+													"matchExpressions": {
+														Type: "array",
+														Items: &apiextv1.JSONSchemaPropsOrArray{
+															Schema: &apiextv1.JSONSchemaProps{
+																Type:     "object",
+																Required: []string{"key", "operator"},
+																Properties: map[string]apiextv1.JSONSchemaProps{
+																	"key": {
+																		Type: "string",
+																	},
+																	"operator": {
+																		Type: "string",
+																		Enum: []apiextv1.JSON{
+																			{
+																				Raw: []byte(`"DoesNotExist"`),
+																			},
+																			{
+																				Raw: []byte(`"Exists"`),
+																			},
+																			{
+																				Raw: []byte(`"In"`),
+																			},
+																			{
+																				Raw: []byte(`"NotIn"`),
+																			},
+																		},
+																	},
+																	"values": {
+																		Type: "array",
+																		Items: &apiextv1.JSONSchemaPropsOrArray{
+																			Schema: &apiextv1.JSONSchemaProps{
+																				Type: "string",
+																			},
+																		},
+																	},
+																},
+															},
+														},
+													},
+													"matchLabels": {
+														Type:                   "object",
+														XPreserveUnknownFields: util.True(),
+													},
+												},
+											},
+										},
+									},
+								},
+							},
 							"multisite": {
 								Type: "object",
 								Properties: map[string]apiextv1.JSONSchemaProps{
@@ -701,6 +1130,9 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 									},
 								},
 							},
+							"pause": {
+								Type: "boolean",
+							},
 							"pg_hba": {
 								Type: "array",
 								Items: &apiextv1.JSONSchemaPropsOrArray{
@@ -709,6 +1141,7 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 									},
 								},
 							},
+							"pg_hba_rules": pgHbaRulesSchema,
 							"retry_timeout": {
 								Type: "integer",
 							},
@@ -734,6 +1167,23 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 							"synchronous_node_count": {
 								Type: "integer",
 							},
+							"synchronous_replication": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"off"`),
+									},
+									{
+										Raw: []byte(`"on"`),
+									},
+									{
+										Raw: []byte(`"strict"`),
+									},
+									{
+										Raw: []byte(`"quorum"`),
+									},
+								},
+							},
 							"ttl": {
 								Type: "integer",
 							},
@@ -832,6 +1282,37 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 									},
 								},
 							},
+							"tuning": {
+								Type:     "object",
+								Required: []string{"profile"},
+								Properties: map[string]apiextv1.JSONSchemaProps{
+									"profile": {
+										Type: "string",
+										Enum: []apiextv1.JSON{
+											{
+												Raw: []byte(`"oltp"`),
+											},
+											{
+												Raw: []byte(`"olap"`),
+											},
+											{
+												Raw: []byte(`"mixed"`),
+											},
+										},
+									},
+									"storageType": {
+										Type: "string",
+										Enum: []apiextv1.JSON{
+											{
+												Raw: []byte(`"ssd"`),
+											},
+											{
+												Raw: []byte(`"hdd"`),
+											},
+										},
+									},
+								},
+							},
 							"env": {
 								Type:     "array",
 								Nullable: true,
@@ -887,6 +1368,14 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 											},
 										},
 									},
+									"parameters": {
+										Type: "object",
+										AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
+											Schema: &apiextv1.JSONSchemaProps{
+												Type: "string",
+											},
+										},
+									},
 									"secretNamespace": {
 										Type: "string",
 									},
@@ -894,6 +1383,29 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 							},
 						},
 					},
+					"reinitialize": {
+						Type:     "object",
+						Nullable: true,
+						Properties: map[string]apiextv1.JSONSchemaProps{
+							"auto": {
+								Type: "boolean",
+							},
+							"id": {
+								Type: "string",
+							},
+							"members": {
+								Type: "array",
+								Items: &apiextv1.JSONSchemaPropsOrArray{
+									Schema: &apiextv1.JSONSchemaProps{
+										Type: "string",
+									},
+								},
+							},
+							"recreatePVC": {
+								Type: "boolean",
+							},
+						},
+					},
 					"replicaLoadBalancer": {
 						Type:        "boolean",
 						Description: "deprecated",
@@ -940,6 +1452,15 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 					"schedulerName": {
 						Type: "string",
 					},
+					"selfServiceNamespaces": {
+						Type:     "array",
+						Nullable: true,
+						Items: &apiextv1.JSONSchemaPropsOrArray{
+							Schema: &apiextv1.JSONSchemaProps{
+								Type: "string",
+							},
+						},
+					},
 					"serviceAnnotations": {
 						Type: "object",
 						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
@@ -1122,99 +1643,8 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 						Type: "object",
 						AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
 							Schema: &apiextv1.JSONSchemaProps{
-								Type:     "array",
-								Nullable: true,
-								Items: &apiextv1.JSONSchemaPropsOrArray{
-									Schema: &apiextv1.JSONSchemaProps{
-										Type: "string",
-										Enum: []apiextv1.JSON{
-											{
-												Raw: []byte(`"bypassrls"`),
-											},
-											{
-												Raw: []byte(`"BYPASSRLS"`),
-											},
-											{
-												Raw: []byte(`"nobypassrls"`),
-											},
-											{
-												Raw: []byte(`"NOBYPASSRLS"`),
-											},
-											{
-												Raw: []byte(`"createdb"`),
-											},
-											{
-												Raw: []byte(`"CREATEDB"`),
-											},
-											{
-												Raw: []byte(`"nocreatedb"`),
-											},
-											{
-												Raw: []byte(`"NOCREATEDB"`),
-											},
-											{
-												Raw: []byte(`"createrole"`),
-											},
-											{
-												Raw: []byte(`"CREATEROLE"`),
-											},
-											{
-												Raw: []byte(`"nocreaterole"`),
-											},
-											{
-												Raw: []byte(`"NOCREATEROLE"`),
-											},
-											{
-												Raw: []byte(`"inherit"`),
-											},
-											{
-												Raw: []byte(`"INHERIT"`),
-											},
-											{
-												Raw: []byte(`"noinherit"`),
-											},
-											{
-												Raw: []byte(`"NOINHERIT"`),
-											},
-											{
-												Raw: []byte(`"login"`),
-											},
-											{
-												Raw: []byte(`"LOGIN"`),
-											},
-											{
-												Raw: []byte(`"nologin"`),
-											},
-											{
-												Raw: []byte(`"NOLOGIN"`),
-											},
-											{
-												Raw: []byte(`"replication"`),
-											},
-											{
-												Raw: []byte(`"REPLICATION"`),
-											},
-											{
-												Raw: []byte(`"noreplication"`),
-											},
-											{
-												Raw: []byte(`"NOREPLICATION"`),
-											},
-											{
-												Raw: []byte(`"superuser"`),
-											},
-											{
-												Raw: []byte(`"SUPERUSER"`),
-											},
-											{
-												Raw: []byte(`"nosuperuser"`),
-											},
-											{
-												Raw: []byte(`"NOSUPERUSER"`),
-											},
-										},
-									},
-								},
+								Nullable:               true,
+								XPreserveUnknownFields: util.True(),
 							},
 						},
 					},
@@ -1599,12 +2029,8 @@ var PostgresCRDResourceValidation = apiextv1.CustomResourceValidation{
 				},
 			},
 			"status": {
-				Type: "object",
-				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
-					Schema: &apiextv1.JSONSchemaProps{
-						Type: "string",
-					},
-				},
+				Type:                   "object",
+				XPreserveUnknownFields: util.True(),
 			},
 		},
 	},
@@ -1728,9 +2154,26 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 									},
 								},
 							},
+							"credential_store": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"kubernetes"`),
+									},
+									{
+										Raw: []byte(`"vault"`),
+									},
+								},
+							},
 							"enable_password_rotation": {
 								Type: "boolean",
 							},
+							"enable_self_service_crds": {
+								Type: "boolean",
+							},
+							"mirror_credential_secrets": {
+								Type: "boolean",
+							},
 							"password_rotation_interval": {
 								Type: "integer",
 							},
@@ -1743,6 +2186,18 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 							"super_username": {
 								Type: "string",
 							},
+							"vault_address": {
+								Type: "string",
+							},
+							"vault_kv_mount": {
+								Type: "string",
+							},
+							"vault_path_prefix": {
+								Type: "string",
+							},
+							"vault_token_file": {
+								Type: "string",
+							},
 						},
 					},
 					"major_version_upgrade": {
@@ -1829,6 +2284,9 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 							"enable_readiness_probe": {
 								Type: "boolean",
 							},
+							"enable_postgres_tls_certificates": {
+								Type: "boolean",
+							},
 							"enable_sidecars": {
 								Type: "boolean",
 							},
@@ -1991,6 +2449,23 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 							"pod_terminate_grace_period": {
 								Type: "string",
 							},
+							"postgres_tls_ca_secret_name": {
+								Type: "string",
+							},
+							"postgres_tls_certificate_authority": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"operator"`),
+									},
+									{
+										Raw: []byte(`"cluster"`),
+									},
+								},
+							},
+							"postgres_tls_renew_before": {
+								Type: "string",
+							},
 							"secret_name_template": {
 								Type: "string",
 							},
@@ -2029,6 +2504,20 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 									},
 								},
 							},
+							"storage_resize_provider": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"aws"`),
+									},
+									{
+										Raw: []byte(`"gcp"`),
+									},
+									{
+										Raw: []byte(`"azure"`),
+									},
+								},
+							},
 							"toleration": {
 								Type: "object",
 								AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{
@@ -2045,9 +2534,24 @@ var OperatorConfigCRDResourceValidation = apiextv1.CustomResourceValidation{
 					"patroni": {
 						Type: "object",
 						Properties: map[string]apiextv1.JSONSchemaProps{
+							"enable_patroni_api_tls": {
+								Type: "boolean",
+							},
 							"enable_patroni_failsafe_mode": {
 								Type: "boolean",
 							},
+							"patroni_api_auth_method": {
+								Type: "string",
+								Enum: []apiextv1.JSON{
+									{
+										Raw: []byte(`"basic"`),
+									},
+									{
+										Raw: []byte(`"certificate"`),
+									},
+								},
+							},
+							"pg_hba_rules": pgHbaRulesSchema,
 						},
 					},
 					"postgres_pod_resources": {
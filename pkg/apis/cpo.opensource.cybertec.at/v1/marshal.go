@@ -9,6 +9,8 @@ import (
 
 type postgresqlCopy Postgresql
 type postgresStatusCopy PostgresStatus
+type userDefinitionCopy UserDefinition
+type databaseDefinitionCopy DatabaseDefinition
 
 // MarshalJSON converts a maintenance window definition to JSON.
 func (m *MaintenanceWindow) MarshalJSON() ([]byte, error) {
@@ -90,6 +92,81 @@ func (ps *PostgresStatus) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON converts a user definition to JSON. Definitions with flags only are written as a
+// plain list of flags, so manifests using the short form stay unchanged.
+func (u UserDefinition) MarshalJSON() ([]byte, error) {
+	if u.ConnectionLimit == nil && u.ValidUntil == "" && len(u.MemberOf) == 0 && len(u.AdminOf) == 0 && len(u.ConsumerNamespaces) == 0 && len(u.Parameters) == 0 {
+		if u.Flags == nil {
+			return []byte("[]"), nil
+		}
+		return json.Marshal([]string(u.Flags))
+	}
+	return json.Marshal(userDefinitionCopy(u))
+}
+
+// UnmarshalJSON converts either a list of flags or an object with flags and role attributes to
+// the user definition.
+func (u *UserDefinition) UnmarshalJSON(data []byte) error {
+	var flags UserFlags
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		*u = UserDefinition{}
+		return nil
+	}
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &flags); err != nil {
+			return fmt.Errorf("could not parse user flags: %v", err)
+		}
+		*u = UserDefinition{Flags: flags}
+		return nil
+	}
+
+	var tmp userDefinitionCopy
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return fmt.Errorf("could not parse user definition: %v", err)
+	}
+	*u = UserDefinition(tmp)
+
+	return nil
+}
+
+// MarshalJSON converts a database definition to JSON. Definitions without parameters are written
+// as the name of the owner, so manifests using the short form stay unchanged.
+func (d DatabaseDefinition) MarshalJSON() ([]byte, error) {
+	if len(d.Parameters) == 0 {
+		return json.Marshal(d.Owner)
+	}
+	return json.Marshal(databaseDefinitionCopy(d))
+}
+
+// UnmarshalJSON converts either the name of the owner or an object with the owner and parameters
+// to the database definition.
+func (d *DatabaseDefinition) UnmarshalJSON(data []byte) error {
+	var owner string
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		*d = DatabaseDefinition{}
+		return nil
+	}
+	if strings.HasPrefix(trimmed, "\"") {
+		if err := json.Unmarshal(data, &owner); err != nil {
+			return fmt.Errorf("could not parse database owner: %v", err)
+		}
+		*d = DatabaseDefinition{Owner: owner}
+		return nil
+	}
+
+	var tmp databaseDefinitionCopy
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return fmt.Errorf("could not parse database definition: %v", err)
+	}
+	*d = DatabaseDefinition(tmp)
+
+	return nil
+}
+
 // UnmarshalJSON converts a JSON into the PostgreSQL object.
 func (p *Postgresql) UnmarshalJSON(data []byte) error {
 	var tmp postgresqlCopy
@@ -113,6 +190,30 @@ func (p *Postgresql) UnmarshalJSON(data []byte) error {
 	if err := validateCloneClusterDescription(tmp2.Spec.Clone); err != nil {
 		tmp2.Error = err.Error()
 		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateSynchronousReplication(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validatePgHbaRules(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateUsers(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateObjectParameters(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateDatabaseExtensions(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateGrants(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateLogicalReplication(&tmp2.Spec); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
+	} else if err := validateDeletionPolicy(tmp2.Spec.DeletionPolicy); err != nil {
+		tmp2.Error = err.Error()
+		tmp2.Status.PostgresClusterStatus = ClusterStatusInvalid
 	}
 
 	*p = tmp2
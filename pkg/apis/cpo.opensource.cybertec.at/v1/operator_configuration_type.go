@@ -43,6 +43,13 @@ type PostgresUsersConfiguration struct {
 	EnablePasswordRotation        bool     `json:"enable_password_rotation,omitempty"`
 	PasswordRotationInterval      uint32   `json:"password_rotation_interval,omitempty"`
 	PasswordRotationUserRetention uint32   `json:"password_rotation_user_retention,omitempty"`
+	EnableSelfServiceCRDs         bool     `json:"enable_self_service_crds,omitempty"`
+	CredentialStore               string   `json:"credential_store,omitempty"`
+	MirrorCredentialSecrets       *bool    `json:"mirror_credential_secrets,omitempty"`
+	VaultAddress                  string   `json:"vault_address,omitempty"`
+	VaultKVMount                  string   `json:"vault_kv_mount,omitempty"`
+	VaultPathPrefix               string   `json:"vault_path_prefix,omitempty"`
+	VaultTokenFile                string   `json:"vault_token_file,omitempty"`
 }
 
 // MajorVersionUpgradeConfiguration defines how to execute major version upgrades of Postgres.
@@ -71,6 +78,7 @@ type KubernetesMetaConfiguration struct {
 	PDBNameFormat                          config.StringTemplate        `json:"pdb_name_format,omitempty"`
 	EnablePodDisruptionBudget              *bool                        `json:"enable_pod_disruption_budget,omitempty"`
 	StorageResizeMode                      string                       `json:"storage_resize_mode,omitempty"`
+	StorageResizeProvider                  string                       `json:"storage_resize_provider,omitempty"`
 	EnableInitContainers                   *bool                        `json:"enable_init_containers,omitempty"`
 	EnableSidecars                         *bool                        `json:"enable_sidecars,omitempty"`
 	SharePgSocketWithSidecars              *bool                        `json:"share_pgsocket_with_sidecars,omitempty"`
@@ -105,6 +113,10 @@ type KubernetesMetaConfiguration struct {
 	EnableReadinessProbe                     bool                `json:"enable_readiness_probe,omitempty"`
 	EnableLivenessProbe                      bool                `json:"enable_liveness_probe,omitempty"`
 	EnableCrossNamespaceSecret               bool                `json:"enable_cross_namespace_secret,omitempty"`
+	EnablePostgresTLSCertificates            bool                `json:"enable_postgres_tls_certificates,omitempty"`
+	PostgresTLSCertificateAuthority          string              `json:"postgres_tls_certificate_authority,omitempty"`
+	PostgresTLSCASecretName                  string              `json:"postgres_tls_ca_secret_name,omitempty"`
+	PostgresTLSRenewBefore                   Duration            `json:"postgres_tls_renew_before,omitempty"`
 }
 
 // PostgresPodResourcesDefaults defines the spec of default resources
@@ -243,7 +255,10 @@ type OperatorLogicalBackupConfiguration struct {
 
 // PatroniConfiguration defines configuration for Patroni
 type PatroniConfiguration struct {
-	FailsafeMode *bool `json:"enable_patroni_failsafe_mode,omitempty"`
+	FailsafeMode  *bool              `json:"enable_patroni_failsafe_mode,omitempty"`
+	EnableAPITLS  bool               `json:"enable_patroni_api_tls,omitempty"`
+	APIAuthMethod string             `json:"patroni_api_auth_method,omitempty"`
+	PgHbaRules    []config.PgHbaRule `json:"pg_hba_rules,omitempty"`
 }
 
 // OperatorConfigurationData defines the operation config
@@ -0,0 +1,35 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresDatabase defines Custom Resource Definition Object for self-service databases.
+type PostgresDatabase struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresDatabaseSpec `json:"spec"`
+	Status SelfServiceStatus    `json:"status,omitempty"`
+}
+
+// PostgresDatabaseSpec defines the specification for the PostgresDatabase CRD.
+type PostgresDatabaseSpec struct {
+	ClusterName      string `json:"clusterName"`
+	ClusterNamespace string `json:"clusterNamespace,omitempty"`
+	DatabaseName     string `json:"databaseName,omitempty"`
+	Owner            string `json:"owner"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresDatabaseList defines a list of PostgresDatabase definitions.
+type PostgresDatabaseList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PostgresDatabase `json:"items"`
+}
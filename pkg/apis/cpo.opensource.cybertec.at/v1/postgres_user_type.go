@@ -0,0 +1,42 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresUser defines Custom Resource Definition Object for self-service roles.
+type PostgresUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresUserSpec  `json:"spec"`
+	Status SelfServiceStatus `json:"status,omitempty"`
+}
+
+// PostgresUserSpec defines the specification for the PostgresUser CRD.
+type PostgresUserSpec struct {
+	ClusterName      string            `json:"clusterName"`
+	ClusterNamespace string            `json:"clusterNamespace,omitempty"`
+	UserName         string            `json:"userName,omitempty"`
+	Flags            UserFlags         `json:"flags,omitempty"`
+	Parameters       map[string]string `json:"parameters,omitempty"`
+}
+
+// SelfServiceStatus describes whether a PostgresUser or PostgresDatabase was accepted by the
+// cluster it refers to.
+type SelfServiceStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresUserList defines a list of PostgresUser definitions.
+type PostgresUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []PostgresUser `json:"items"`
+}
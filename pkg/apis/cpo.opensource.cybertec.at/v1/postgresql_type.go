@@ -5,6 +5,8 @@ package v1
 import (
 	"time"
 
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -58,15 +60,20 @@ type PostgresSpec struct {
 	// load balancers' source ranges are the same for master and replica services
 	AllowedSourceRanges []string `json:"allowedSourceRanges"`
 
-	Users                          map[string]UserFlags `json:"users,omitempty"`
-	UsersWithSecretRotation        []string             `json:"usersWithSecretRotation,omitempty"`
-	UsersWithInPlaceSecretRotation []string             `json:"usersWithInPlaceSecretRotation,omitempty"`
+	Users                          map[string]UserDefinition `json:"users,omitempty"`
+	UsersWithSecretRotation        []string                  `json:"usersWithSecretRotation,omitempty"`
+	UsersWithInPlaceSecretRotation []string                  `json:"usersWithInPlaceSecretRotation,omitempty"`
 
 	NumberOfInstances         int32                         `json:"numberOfInstances"`
 	MaintenanceWindows        []MaintenanceWindow           `json:"maintenanceWindows,omitempty"`
 	Clone                     *CloneDescription             `json:"clone,omitempty"`
-	Databases                 map[string]string             `json:"databases,omitempty"`
+	Databases                 map[string]DatabaseDefinition `json:"databases,omitempty"`
 	PreparedDatabases         map[string]PreparedDatabase   `json:"preparedDatabases,omitempty"`
+	DatabaseExtensions        map[string]Extensions         `json:"databaseExtensions,omitempty"`
+	Grants                    map[string]DatabaseGrants     `json:"grants,omitempty"`
+	LogicalReplication        *LogicalReplication           `json:"logicalReplication,omitempty"`
+	SelfServiceNamespaces     []string                      `json:"selfServiceNamespaces,omitempty"`
+	DeletionPolicy            *DeletionPolicy               `json:"deletionPolicy,omitempty"`
 	SchedulerName             *string                       `json:"schedulerName,omitempty"`
 	NodeAffinity              *v1.NodeAffinity              `json:"nodeAffinity,omitempty"`
 	Tolerations               []v1.Toleration               `json:"tolerations,omitempty"`
@@ -93,6 +100,7 @@ type PostgresSpec struct {
 	Backup                        *Backup            `json:"backup,omitempty"`
 	TDE                           *TDE               `json:"tde,omitempty"`
 	Monitoring                    *Monitoring        `json:"monitor,omitempty"`
+	Reinitialize                  *Reinitialize      `json:"reinitialize,omitempty"`
 
 	// deprecated json tags
 	InitContainersOld       []v1.Container `json:"init_containers,omitempty"`
@@ -115,6 +123,54 @@ type PreparedDatabase struct {
 	DefaultUsers    bool                      `json:"defaultUsers,omitempty" defaults:"false"`
 	Extensions      map[string]string         `json:"extensions,omitempty"`
 	SecretNamespace string                    `json:"secretNamespace,omitempty"`
+	Parameters      map[string]string         `json:"parameters,omitempty"`
+}
+
+// DeletionPolicy defines what happens to databases and roles which are removed from the manifest.
+// Dropping or renaming waits for the delay and is refused while there are active connections,
+// unless force is set.
+type DeletionPolicy struct {
+	Databases string `json:"databases,omitempty"`
+	Roles     string `json:"roles,omitempty"`
+	Delay     string `json:"delay,omitempty"`
+	Force     bool   `json:"force,omitempty"`
+}
+
+// Extensions maps the names of extensions to their desired state in a database
+type Extensions map[string]DatabaseExtension
+
+// DatabaseExtension describes the desired state of an extension. Without a version the extension is
+// kept at the default version of the image.
+type DatabaseExtension struct {
+	Schema  string `json:"schema,omitempty"`
+	Version string `json:"version,omitempty"`
+	State   string `json:"state,omitempty"`
+}
+
+// DatabaseGrants describes the privileges of roles in a database. The privileges of every role
+// listed here are reconciled, privileges which are not declared are revoked.
+type DatabaseGrants struct {
+	Privileges        []Grant            `json:"privileges,omitempty"`
+	DefaultPrivileges []DefaultPrivilege `json:"defaultPrivileges,omitempty"`
+}
+
+// Grant describes privileges of a role on a database, on schemas or on objects of a schema.
+// Without objects, the privileges apply to all tables, sequences or functions of the schema.
+type Grant struct {
+	Role       string   `json:"role"`
+	ObjectType string   `json:"objectType"`
+	Schema     string   `json:"schema,omitempty"`
+	Objects    []string `json:"objects,omitempty"`
+	Privileges []string `json:"privileges"`
+}
+
+// DefaultPrivilege describes privileges of a role on objects created by the owner in the future
+type DefaultPrivilege struct {
+	Role       string   `json:"role"`
+	Owner      string   `json:"owner"`
+	ObjectType string   `json:"objectType"`
+	Schema     string   `json:"schema,omitempty"`
+	Privileges []string `json:"privileges"`
 }
 
 // PreparedSchema describes elements to be bootstrapped per schema
@@ -155,10 +211,17 @@ type AdditionalVolume struct {
 type PostgresqlParam struct {
 	PgVersion  string            `json:"version"`
 	Parameters map[string]string `json:"parameters,omitempty"`
+	Tuning     *PostgresTuning   `json:"tuning,omitempty"`
 	Env        []v1.EnvVar       `json:"env,omitempty"`
 	Labels     map[string]string `json:"labels,omitempty" name:"labels" default:""`
 }
 
+// PostgresTuning derives Postgres parameters from the resources of the Postgres container
+type PostgresTuning struct {
+	Profile     string `json:"profile"`
+	StorageType string `json:"storageType,omitempty"`
+}
+
 // ResourceDescription describes CPU and memory resources defined for a cluster.
 type ResourceDescription struct {
 	CPU    string `json:"cpu"`
@@ -175,6 +238,7 @@ type Resources struct {
 type Patroni struct {
 	InitDB                map[string]string            `json:"initdb,omitempty"`
 	PgHba                 []string                     `json:"pg_hba,omitempty"`
+	PgHbaRules            []config.PgHbaRule           `json:"pg_hba_rules,omitempty"`
 	TTL                   uint32                       `json:"ttl,omitempty"`
 	LoopWait              uint32                       `json:"loop_wait,omitempty"`
 	RetryTimeout          uint32                       `json:"retry_timeout,omitempty"`
@@ -183,9 +247,25 @@ type Patroni struct {
 	SynchronousMode       bool                         `json:"synchronous_mode,omitempty"`
 	SynchronousModeStrict bool                         `json:"synchronous_mode_strict,omitempty"`
 	SynchronousNodeCount  uint32                       `json:"synchronous_node_count,omitempty" defaults:"1"`
-	Multisite             *Multisite                   `json:"multisite,omitempty"`
-	FailsafeMode          *bool                        `json:"failsafe_mode,omitempty"`
-	Log                   *PatroniLog                  `json:"log,omitempty"`
+	// SynchronousReplication takes precedence over SynchronousMode and SynchronousModeStrict
+	SynchronousReplication string              `json:"synchronous_replication,omitempty"`
+	Multisite              *Multisite          `json:"multisite,omitempty"`
+	FailsafeMode           *bool               `json:"failsafe_mode,omitempty"`
+	Log                    *PatroniLog         `json:"log,omitempty"`
+	Pause                  bool                `json:"pause,omitempty"`
+	MemberTags             []PatroniMemberTags `json:"member_tags,omitempty"`
+}
+
+// PatroniMemberTags sets Patroni tags on the members selected by ordinal or by pod labels.
+// Entries later in the list take precedence over earlier ones.
+type PatroniMemberTags struct {
+	Ordinals         []int32               `json:"ordinals,omitempty"`
+	Selector         *metav1.LabelSelector `json:"selector,omitempty"`
+	NoFailover       *bool                 `json:"nofailover,omitempty"`
+	NoLoadBalance    *bool                 `json:"noloadbalance,omitempty"`
+	CloneFrom        *bool                 `json:"clonefrom,omitempty"`
+	NoSync           *bool                 `json:"nosync,omitempty"`
+	FailoverPriority *int32                `json:"failover_priority,omitempty"`
 }
 
 // StandbyDescription contains remote primary config or s3/gs wal path
@@ -234,10 +314,114 @@ type Sidecar struct {
 // UserFlags defines flags (such as superuser, nologin) that could be assigned to individual users
 type UserFlags []string
 
+// UserDefinition defines a role of the users map. It is given either as a plain list of flags or as
+// an object with the flags and further attributes of the role. Unset attributes are not managed.
+type UserDefinition struct {
+	Flags           UserFlags `json:"flags,omitempty"`
+	ConnectionLimit *int32    `json:"connectionLimit,omitempty"`
+	ValidUntil      string    `json:"validUntil,omitempty"`
+	MemberOf        []string  `json:"memberOf,omitempty"`
+	AdminOf         []string  `json:"adminOf,omitempty"`
+	// ConsumerNamespaces get a copy of the credential secret of the role
+	ConsumerNamespaces []string `json:"consumerNamespaces,omitempty"`
+	// Parameters are set for the role, parameters set before are reset once removed
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// DatabaseDefinition defines a database of the databases map. It is given either as the name of
+// its owner or as an object with the owner and the parameters of the database.
+type DatabaseDefinition struct {
+	Owner      string            `json:"owner"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
 // PostgresStatus contains status of the PostgreSQL cluster (running, creation failed etc.)
 type PostgresStatus struct {
-	PostgresClusterStatus string `json:"PostgresClusterStatus"`
-	RestoreID             string `json:"RestoreID"`
+	PostgresClusterStatus string                  `json:"PostgresClusterStatus"`
+	RestoreID             string                  `json:"RestoreID"`
+	ReinitializeID        string                  `json:"ReinitializeID,omitempty"`
+	ReinitializeMembers   []string                `json:"ReinitializeMembers,omitempty"`
+	ReinitializeStarted   *metav1.Time            `json:"ReinitializeStarted,omitempty"`
+	FailedMembers         []FailedMember          `json:"FailedMembers,omitempty"`
+	StorageMigration      *StorageMigrationStatus `json:"StorageMigration,omitempty"`
+	SynchronousStandbys   []string                `json:"SynchronousStandbys,omitempty"`
+	TimelineHistory       []TimelineHistoryEntry  `json:"TimelineHistory,omitempty"`
+	MissingFailoverSlots  map[string][]string     `json:"MissingFailoverSlots,omitempty"`
+	Extensions            []ExtensionStatus       `json:"Extensions,omitempty"`
+	Subscriptions         []SubscriptionStatus    `json:"Subscriptions,omitempty"`
+	PendingDeletions      []PendingDeletion       `json:"PendingDeletions,omitempty"`
+	ManagedDatabases      []string                `json:"ManagedDatabases,omitempty"`
+	ManagedRoles          []string                `json:"ManagedRoles,omitempty"`
+	// databases and roles whose parameters were set from the manifest, reset once they disappear
+	ParameterDatabases []string `json:"ParameterDatabases,omitempty"`
+	ParameterRoles     []string `json:"ParameterRoles,omitempty"`
+	// grants per database as they were last applied, privileges which disappear from them are revoked
+	Grants []AppliedGrants `json:"Grants,omitempty"`
+}
+
+// AppliedGrants are the grants of a database the operator applied last. Comparing them with the
+// manifest tells which privileges were removed from it and have to be revoked.
+type AppliedGrants struct {
+	Database          string             `json:"Database"`
+	Privileges        []Grant            `json:"Privileges,omitempty"`
+	DefaultPrivileges []DefaultPrivilege `json:"DefaultPrivileges,omitempty"`
+}
+
+// FailedMember is a replica which Patroni reports failed since the given time. Failed members are
+// reinitialized automatically after a grace period, which is kept across operator restarts this way.
+type FailedMember struct {
+	Name  string      `json:"Name"`
+	Since metav1.Time `json:"Since"`
+}
+
+// PendingDeletion is a database or role which was removed from the manifest and waits to be
+// dropped or renamed according to the deletion policy
+type PendingDeletion struct {
+	Kind      string      `json:"Kind"`
+	Name      string      `json:"Name"`
+	RemovedAt metav1.Time `json:"RemovedAt"`
+	Blocked   bool        `json:"Blocked,omitempty"`
+	BackupJob string      `json:"BackupJob,omitempty"`
+	Message   string      `json:"Message,omitempty"`
+}
+
+// SubscriptionStatus reports the state of a subscription, the time since the last confirmed apply
+// and the errors of the apply and table synchronization workers
+type SubscriptionStatus struct {
+	Name        string `json:"Name"`
+	Database    string `json:"Database"`
+	Enabled     bool   `json:"Enabled"`
+	ApplyLag    string `json:"ApplyLag,omitempty"`
+	ApplyErrors int64  `json:"ApplyErrors,omitempty"`
+	SyncErrors  int64  `json:"SyncErrors,omitempty"`
+	Error       string `json:"Error,omitempty"`
+}
+
+// ExtensionStatus reports the installed version of an extension next to the default version
+// available in the image
+type ExtensionStatus struct {
+	Database         string `json:"Database"`
+	Name             string `json:"Name"`
+	InstalledVersion string `json:"InstalledVersion,omitempty"`
+	AvailableVersion string `json:"AvailableVersion,omitempty"`
+}
+
+// TimelineHistoryEntry describes how a timeline of the cluster ended, e.g. by a failover
+type TimelineHistoryEntry struct {
+	Timeline  int    `json:"Timeline"`
+	SwitchLSN string `json:"SwitchLSN"`
+	Reason    string `json:"Reason"`
+	Timestamp string `json:"Timestamp,omitempty"`
+	NewLeader string `json:"NewLeader,omitempty"`
+}
+
+// StorageMigrationStatus tracks the online move of the data volumes to a new storage class
+type StorageMigrationStatus struct {
+	TargetStorageClass string   `json:"TargetStorageClass"`
+	Phase              string   `json:"Phase"`
+	CurrentMember      string   `json:"CurrentMember"`
+	MigratedMembers    []string `json:"MigratedMembers"`
+	Message            string   `json:"Message"`
 }
 
 // ConnectionPooler Options for connection pooler
@@ -281,6 +465,31 @@ type StreamTable struct {
 	PayloadColumn     *string `json:"payloadColumn,omitempty"`
 }
 
+// LogicalReplication defines the publications of the cluster and its subscriptions to publications
+// of other clusters in the same namespace
+type LogicalReplication struct {
+	Publications  []Publication  `json:"publications,omitempty"`
+	Subscriptions []Subscription `json:"subscriptions,omitempty"`
+}
+
+// Publication publishes the listed tables or all tables of a database
+type Publication struct {
+	Name      string   `json:"name"`
+	Database  string   `json:"database"`
+	Tables    []string `json:"tables,omitempty"`
+	AllTables bool     `json:"allTables,omitempty"`
+}
+
+// Subscription subscribes a database to publications of the source cluster. The source database
+// defaults to the name of the local database.
+type Subscription struct {
+	Name           string   `json:"name"`
+	Database       string   `json:"database"`
+	SourceCluster  string   `json:"sourceCluster"`
+	SourceDatabase string   `json:"sourceDatabase,omitempty"`
+	Publications   []string `json:"publications"`
+}
+
 type Backup struct {
 	Pgbackrest *Pgbackrest `json:"pgbackrest"`
 }
@@ -321,6 +530,15 @@ type Restore struct {
 	Options map[string]string `json:"options"`
 }
 
+// Reinitialize lists members to be rebuilt from the primary, a request is executed once per id.
+// Auto enables the reinitialization of members Patroni reports as failed to start or to rewind.
+type Reinitialize struct {
+	ID          string   `json:"id,omitempty"`
+	Members     []string `json:"members,omitempty"`
+	RecreatePVC bool     `json:"recreatePVC,omitempty"`
+	Auto        bool     `json:"auto,omitempty"`
+}
+
 type Configuration struct {
 	Secret string `json:"secret"`
 }
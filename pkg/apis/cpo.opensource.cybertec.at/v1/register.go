@@ -45,6 +45,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("postgresqlList"), &PostgresqlList{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresTeam"), &PostgresTeam{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresTeamList"), &PostgresTeamList{})
+	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresDatabase"), &PostgresDatabase{})
+	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresDatabaseList"), &PostgresDatabaseList{})
+	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresUser"), &PostgresUser{})
+	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("PostgresUserList"), &PostgresUserList{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("OperatorConfiguration"),
 		&OperatorConfiguration{})
 	scheme.AddKnownTypeWithName(SchemeGroupVersion.WithKind("OperatorConfigurationList"),
@@ -3,16 +3,31 @@ package v1
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/pgparameters"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/privileges"
 )
 
 var (
 	weekdays         = map[string]int{"Sun": 0, "Mon": 1, "Tue": 2, "Wed": 3, "Thu": 4, "Fri": 5, "Sat": 6}
 	serviceNameRegex = regexp.MustCompile(serviceNameRegexString)
 	nullBackup       = Backup{Pgbackrest: nil}
+
+	// parameter names, including the placeholders of extensions, e.g. pg_stat_statements.track
+	parameterNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+	// functions are identified by their name and argument types, e.g. add(integer, integer)
+	functionSignatureRegex = regexp.MustCompile(`^[^"()]+\([^"();]*\)$`)
+	// subscription names are used as slot names on the publisher
+	replicationNameRegex = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
 )
 
 // Clone convenience wrapper around DeepCopy
@@ -85,6 +100,334 @@ func validateCloneClusterDescription(clone *CloneDescription) error {
 	return nil
 }
 
+// SynchronousReplicationMode returns the replication mode of the cluster, either taken from
+// synchronous_replication or derived from the synchronous_mode flags.
+func (p Patroni) SynchronousReplicationMode() string {
+	if p.SynchronousReplication != "" {
+		return p.SynchronousReplication
+	}
+	if !p.SynchronousMode {
+		return SynchronousReplicationOff
+	}
+	if p.SynchronousModeStrict {
+		return SynchronousReplicationStrict
+	}
+	return SynchronousReplicationOn
+}
+
+func validateSynchronousReplication(spec *PostgresSpec) error {
+	mode := spec.Patroni.SynchronousReplication
+	switch mode {
+	case "", SynchronousReplicationOff:
+		return nil
+	case SynchronousReplicationOn, SynchronousReplicationStrict, SynchronousReplicationQuorum:
+	default:
+		return fmt.Errorf("synchronous_replication must be one of %q, %q, %q or %q",
+			SynchronousReplicationOff, SynchronousReplicationOn, SynchronousReplicationStrict, SynchronousReplicationQuorum)
+	}
+
+	if _, ok := spec.PostgresqlParam.Parameters["synchronous_standby_names"]; ok {
+		return fmt.Errorf("synchronous_standby_names is managed by Patroni when synchronous_replication is %q", mode)
+	}
+	nodeCount := spec.Patroni.SynchronousNodeCount
+	if nodeCount == 0 {
+		nodeCount = 1
+	}
+	// a stopped cluster has no instances at all
+	if spec.NumberOfInstances > 0 && uint32(spec.NumberOfInstances) <= nodeCount {
+		return fmt.Errorf("synchronous_replication %q with synchronous_node_count %d requires more than %d instances",
+			mode, nodeCount, nodeCount)
+	}
+	return nil
+}
+
+// ValidatePostgresParameters checks the PostgreSQL parameters against the catalog of the requested
+// major version. An unknown version is validated against the parameters of all versions. It is not
+// part of unmarshalling, since existing clusters must not become invalid with a newer catalog.
+func ValidatePostgresParameters(spec *PostgresSpec) error {
+	majorVersion, _ := strconv.Atoi(spec.PostgresqlParam.PgVersion)
+	if err := pgparameters.ValidateAll(spec.PostgresqlParam.Parameters, majorVersion); err != nil {
+		return fmt.Errorf("invalid PostgreSQL parameters: %v", err)
+	}
+	return nil
+}
+
+// validateUsers checks the flags and role attributes of the users
+func validateUsers(spec *PostgresSpec) error {
+	for _, user := range sortedKeys(spec.Users) {
+		definition := spec.Users[user]
+		for _, flag := range definition.Flags {
+			if !isRoleFlag(flag) {
+				return fmt.Errorf("flag %q of user %q is not valid", flag, user)
+			}
+		}
+		if definition.ConnectionLimit != nil && *definition.ConnectionLimit < -1 {
+			return fmt.Errorf("connectionLimit of user %q must be -1 or greater", user)
+		}
+		if definition.ValidUntil != "" && definition.ValidUntil != ValidUntilInfinity {
+			if _, err := time.Parse(time.RFC3339, definition.ValidUntil); err != nil {
+				return fmt.Errorf("validUntil of user %q must be an RFC 3339 timestamp or %q", user, ValidUntilInfinity)
+			}
+		}
+		for _, role := range append(append([]string{}, definition.MemberOf...), definition.AdminOf...) {
+			if role == "" || strings.Contains(role, `"`) {
+				return fmt.Errorf("role %q of user %q must not be empty or contain double quotes", role, user)
+			}
+			if role == user {
+				return fmt.Errorf("user %q cannot be a member of itself", user)
+			}
+		}
+		for _, namespace := range definition.ConsumerNamespaces {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return fmt.Errorf("consumer namespace %q of user %q is not valid: %s", namespace, user, strings.Join(errs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// isRoleFlag reports whether the flag or its NO-prefixed inversion is a known role flag
+func isRoleFlag(flag string) bool {
+	flag = strings.TrimPrefix(strings.ToUpper(flag), "NO")
+	for _, roleFlag := range []string{constants.RoleFlagByPassRLS, constants.RoleFlagCreateDB, constants.RoleFlagCreateRole,
+		constants.RoleFlagInherit, constants.RoleFlagLogin, constants.RoleFlagReplication, constants.RoleFlagSuperuser} {
+		if flag == roleFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// validateObjectParameters checks the settings of users and databases, which can only use
+// parameters that are settable for a session.
+func validateObjectParameters(spec *PostgresSpec) error {
+	majorVersion, _ := strconv.Atoi(spec.PostgresqlParam.PgVersion)
+
+	userParameters := spec.GetUserParameters()
+	for _, user := range sortedKeys(userParameters) {
+		if err := ValidateSessionParameters(userParameters[user], majorVersion); err != nil {
+			return fmt.Errorf("invalid parameters of user %q: %v", user, err)
+		}
+	}
+	databaseParameters := spec.GetDatabaseParameters()
+	for _, database := range sortedKeys(databaseParameters) {
+		if err := ValidateSessionParameters(databaseParameters[database], majorVersion); err != nil {
+			return fmt.Errorf("invalid parameters of database %q: %v", database, err)
+		}
+	}
+	return nil
+}
+
+func ValidateSessionParameters(parameters map[string]string, majorVersion int) error {
+	for _, name := range sortedKeys(parameters) {
+		if !parameterNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid parameter name %q", name)
+		}
+		// values are passed as SQL literals, an enclosing pair of single quotes is kept as it is
+		value := parameters[name]
+		if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		if strings.Contains(value, "'") {
+			return fmt.Errorf("value of parameter %q must not contain single quotes", name)
+		}
+	}
+	return pgparameters.ValidateSessionAll(parameters, majorVersion)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateDatabaseExtensions checks the desired state of extensions, which must not be managed by
+// preparedDatabases at the same time.
+func validateDatabaseExtensions(spec *PostgresSpec) error {
+	for _, database := range sortedKeys(spec.DatabaseExtensions) {
+		extensions := spec.DatabaseExtensions[database]
+		for _, name := range sortedKeys(extensions) {
+			extension := extensions[name]
+			if strings.Contains(name, `"`) || strings.Contains(extension.Schema, `"`) {
+				return fmt.Errorf("extension %q of database %q: names must not contain double quotes", name, database)
+			}
+			if strings.Contains(extension.Version, `'`) {
+				return fmt.Errorf("extension %q of database %q: version must not contain single quotes", name, database)
+			}
+			switch extension.State {
+			case "", ExtensionStatePresent:
+			case ExtensionStateAbsent:
+				if extension.Version != "" {
+					return fmt.Errorf("extension %q of database %q: version cannot be set for state %q", name, database, ExtensionStateAbsent)
+				}
+			default:
+				return fmt.Errorf("extension %q of database %q: state must be %q or %q", name, database, ExtensionStatePresent, ExtensionStateAbsent)
+			}
+			if _, ok := spec.PreparedDatabases[database].Extensions[name]; ok {
+				return fmt.Errorf("extension %q of database %q is already defined in preparedDatabases", name, database)
+			}
+		}
+	}
+	return nil
+}
+
+// validateGrants checks the object types, objects and privileges of the grants
+func validateGrants(spec *PostgresSpec) error {
+	for _, database := range sortedKeys(spec.Grants) {
+		grants := spec.Grants[database]
+		for i, grant := range grants.Privileges {
+			if err := validateGrant(grant); err != nil {
+				return fmt.Errorf("privileges[%d] of database %q: %v", i, database, err)
+			}
+		}
+		for i, defaultPrivilege := range grants.DefaultPrivileges {
+			if err := validateDefaultPrivilege(defaultPrivilege); err != nil {
+				return fmt.Errorf("defaultPrivileges[%d] of database %q: %v", i, database, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateGrant(grant Grant) error {
+	if err := validateGrantee(grant.Role); err != nil {
+		return err
+	}
+	if !privileges.IsGrantable(grant.ObjectType) {
+		return fmt.Errorf("object type %q is not supported", grant.ObjectType)
+	}
+
+	switch grant.ObjectType {
+	case privileges.ObjectDatabase:
+		if grant.Schema != "" || len(grant.Objects) > 0 {
+			return fmt.Errorf("schema and objects cannot be set for object type %q", grant.ObjectType)
+		}
+	case privileges.ObjectSchema:
+		if grant.Schema != "" {
+			return fmt.Errorf("schema cannot be set for object type %q, list the schemas in objects", grant.ObjectType)
+		}
+		if len(grant.Objects) == 0 {
+			return fmt.Errorf("objects must list the schemas")
+		}
+	default:
+		if grant.Schema == "" {
+			return fmt.Errorf("schema must be set for object type %q", grant.ObjectType)
+		}
+	}
+	if strings.Contains(grant.Schema, `"`) {
+		return fmt.Errorf("schema %q must not contain double quotes", grant.Schema)
+	}
+	for _, object := range grant.Objects {
+		if grant.ObjectType == privileges.ObjectFunction {
+			if !functionSignatureRegex.MatchString(object) {
+				return fmt.Errorf("function %q must be given as name(argument types)", object)
+			}
+		} else if object == "" || strings.Contains(object, `"`) {
+			return fmt.Errorf("object %q must not be empty or contain double quotes", object)
+		}
+	}
+
+	_, err := privileges.Normalize(grant.ObjectType, grant.Privileges, 0)
+	return err
+}
+
+func validateDefaultPrivilege(defaultPrivilege DefaultPrivilege) error {
+	if err := validateGrantee(defaultPrivilege.Role); err != nil {
+		return err
+	}
+	if defaultPrivilege.Owner == "" || strings.Contains(defaultPrivilege.Owner, `"`) {
+		return fmt.Errorf("owner %q must not be empty or contain double quotes", defaultPrivilege.Owner)
+	}
+	if !privileges.IsDefaultable(defaultPrivilege.ObjectType) {
+		return fmt.Errorf("object type %q is not supported", defaultPrivilege.ObjectType)
+	}
+	if defaultPrivilege.ObjectType == privileges.ObjectSchema && defaultPrivilege.Schema != "" {
+		return fmt.Errorf("schema cannot be set for object type %q", defaultPrivilege.ObjectType)
+	}
+	if strings.Contains(defaultPrivilege.Schema, `"`) {
+		return fmt.Errorf("schema %q must not contain double quotes", defaultPrivilege.Schema)
+	}
+
+	_, err := privileges.Normalize(defaultPrivilege.ObjectType, defaultPrivilege.Privileges, 0)
+	return err
+}
+
+func validateGrantee(role string) error {
+	if role == "" || strings.Contains(role, `"`) {
+		return fmt.Errorf("role %q must not be empty or contain double quotes", role)
+	}
+	if strings.EqualFold(role, "public") {
+		return fmt.Errorf("privileges of PUBLIC are not managed")
+	}
+	return nil
+}
+
+// validateLogicalReplication checks the names of publications and subscriptions, which are unique
+// per database, and their tables and sources
+func validateLogicalReplication(spec *PostgresSpec) error {
+	if spec.LogicalReplication == nil {
+		return nil
+	}
+
+	publications := make(map[string]bool)
+	for _, publication := range spec.LogicalReplication.Publications {
+		if !replicationNameRegex.MatchString(publication.Name) {
+			return fmt.Errorf("publication name %q must consist of lower case letters, digits and underscores", publication.Name)
+		}
+		if publication.Database == "" {
+			return fmt.Errorf("publication %q: database must be set", publication.Name)
+		}
+		key := publication.Database + "/" + publication.Name
+		if publications[key] {
+			return fmt.Errorf("publication %q is defined twice in database %q", publication.Name, publication.Database)
+		}
+		publications[key] = true
+
+		if publication.AllTables == (len(publication.Tables) > 0) {
+			return fmt.Errorf("publication %q: either tables or allTables must be set", publication.Name)
+		}
+		for _, table := range publication.Tables {
+			if table == "" || strings.Contains(table, `"`) || strings.Count(table, ".") > 1 {
+				return fmt.Errorf("publication %q: table %q must be given as table or schema.table", publication.Name, table)
+			}
+		}
+	}
+
+	subscriptions := make(map[string]bool)
+	for _, subscription := range spec.LogicalReplication.Subscriptions {
+		if !replicationNameRegex.MatchString(subscription.Name) {
+			return fmt.Errorf("subscription name %q must consist of lower case letters, digits and underscores", subscription.Name)
+		}
+		if subscriptions[subscription.Name] {
+			return fmt.Errorf("subscription %q is defined twice", subscription.Name)
+		}
+		subscriptions[subscription.Name] = true
+
+		if subscription.Database == "" || subscription.SourceCluster == "" {
+			return fmt.Errorf("subscription %q: database and sourceCluster must be set", subscription.Name)
+		}
+		if strings.Contains(subscription.SourceDatabase, "'") {
+			return fmt.Errorf("subscription %q: sourceDatabase must not contain single quotes", subscription.Name)
+		}
+		if len(subscription.Publications) == 0 {
+			return fmt.Errorf("subscription %q: publications must not be empty", subscription.Name)
+		}
+		for _, publication := range subscription.Publications {
+			if !replicationNameRegex.MatchString(publication) {
+				return fmt.Errorf("subscription %q: publication name %q must consist of lower case letters, digits and underscores", subscription.Name, publication)
+			}
+		}
+	}
+	return nil
+}
+
+func validatePgHbaRules(spec *PostgresSpec) error {
+	return config.ValidatePgHbaRules(spec.Patroni.PgHbaRules)
+}
+
 // Success of the current Status
 func (postgresStatus PostgresStatus) Success() bool {
 	return postgresStatus.PostgresClusterStatus != ClusterStatusAddFailed &&
@@ -113,6 +456,34 @@ func (s *PostgresSpec) GetBackup() *Backup {
 	return s.Backup
 }
 
+// GetUserParameters returns the parameters of the users which define any, keyed by user name.
+func (s *PostgresSpec) GetUserParameters() map[string]map[string]string {
+	parameters := make(map[string]map[string]string)
+	for user, definition := range s.Users {
+		if len(definition.Parameters) > 0 {
+			parameters[user] = definition.Parameters
+		}
+	}
+	return parameters
+}
+
+// GetDatabaseParameters returns the parameters of the databases and prepared databases which define
+// any, keyed by database name. Entries of databases take precedence over prepared databases.
+func (s *PostgresSpec) GetDatabaseParameters() map[string]map[string]string {
+	parameters := make(map[string]map[string]string)
+	for database, preparedDatabase := range s.PreparedDatabases {
+		if len(preparedDatabase.Parameters) > 0 {
+			parameters[database] = preparedDatabase.Parameters
+		}
+	}
+	for database, definition := range s.Databases {
+		if len(definition.Parameters) > 0 {
+			parameters[database] = definition.Parameters
+		}
+	}
+	return parameters
+}
+
 // Returns currently specified restore ID or empty string if restore is not specified.
 func (b *Backup) GetRestoreID() string {
 	if b.Pgbackrest == nil {
@@ -120,3 +491,34 @@ func (b *Backup) GetRestoreID() string {
 	}
 	return b.Pgbackrest.Restore.ID
 }
+
+// validateDeletionPolicy checks the policies for removed databases and roles and the delay
+func validateDeletionPolicy(policy *DeletionPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for _, value := range []string{policy.Databases, policy.Roles} {
+		switch value {
+		case "", DeletionPolicyRetain, DeletionPolicyRename, DeletionPolicyDrop:
+		default:
+			return fmt.Errorf("deletion policy %q must be %q, %q or %q", value, DeletionPolicyRetain, DeletionPolicyRename, DeletionPolicyDrop)
+		}
+	}
+	if _, err := policy.DelayDuration(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DelayDuration returns the time between the removal of a database or role from the manifest
+// and its deletion
+func (p *DeletionPolicy) DelayDuration() (time.Duration, error) {
+	if p.Delay == "" {
+		return DefaultDeletionDelay, nil
+	}
+	delay, err := time.ParseDuration(p.Delay)
+	if err != nil || delay < 0 {
+		return 0, fmt.Errorf("delay of the deletion policy must be a positive duration, e.g. 24h")
+	}
+	return delay, nil
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -74,6 +75,272 @@ var cloneClusterDescriptions = []struct {
 	{"common cluster name", &CloneDescription{"foobar", "", "", "", "", "", "", nil, nil}, nil},
 }
 
+var synchronousReplicationSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"legacy synchronous mode is not validated", PostgresSpec{NumberOfInstances: 1, Patroni: Patroni{SynchronousMode: true}}, nil},
+	{"quorum with enough instances", PostgresSpec{NumberOfInstances: 3, Patroni: Patroni{SynchronousReplication: "quorum", SynchronousNodeCount: 2}}, nil},
+	{"stopped cluster", PostgresSpec{NumberOfInstances: 0, Patroni: Patroni{SynchronousReplication: "strict"}}, nil},
+	{"expect error as mode is unknown", PostgresSpec{NumberOfInstances: 2, Patroni: Patroni{SynchronousReplication: "any"}},
+		errors.New(`synchronous_replication must be one of "off", "on", "strict" or "quorum"`)},
+	{"expect error as there are not enough instances", PostgresSpec{NumberOfInstances: 2, Patroni: Patroni{SynchronousReplication: "quorum", SynchronousNodeCount: 2}},
+		errors.New(`synchronous_replication "quorum" with synchronous_node_count 2 requires more than 2 instances`)},
+	{"expect error as synchronous_standby_names is set", PostgresSpec{NumberOfInstances: 2,
+		PostgresqlParam: PostgresqlParam{Parameters: map[string]string{"synchronous_standby_names": "*"}},
+		Patroni:         Patroni{SynchronousReplication: "on"}},
+		errors.New(`synchronous_standby_names is managed by Patroni when synchronous_replication is "on"`)},
+}
+
+var postgresParameterSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid parameters", PostgresSpec{PostgresqlParam: PostgresqlParam{PgVersion: "17",
+		Parameters: map[string]string{"shared_buffers": "1GB", "log_statement": "ddl", "pg_stat_statements.max": "1000"}}}, nil},
+	{"unknown version", PostgresSpec{PostgresqlParam: PostgresqlParam{
+		Parameters: map[string]string{"old_snapshot_threshold": "60"}}}, nil},
+	{"expect error as parameters are invalid", PostgresSpec{PostgresqlParam: PostgresqlParam{PgVersion: "17",
+		Parameters: map[string]string{"max_connections": "0", "wal_levl": "logical"}}},
+		errors.New(`invalid PostgreSQL parameters: invalid value "0" for parameter "max_connections": out of range [1, 262143], unknown parameter "wal_levl", did you mean "wal_level"?`)},
+	{"expect error as parameter was removed", PostgresSpec{PostgresqlParam: PostgresqlParam{PgVersion: "17",
+		Parameters: map[string]string{"old_snapshot_threshold": "60"}}},
+		errors.New(`invalid PostgreSQL parameters: parameter "old_snapshot_threshold" is not available in PostgreSQL 17`)},
+}
+
+var pgHbaRuleSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid rules", PostgresSpec{Patroni: Patroni{PgHbaRules: []config.PgHbaRule{
+		{Type: "local", Database: "all", User: "all", Method: "trust"},
+		{Type: "hostssl", Database: "all", User: "+app", Address: "10.0.0.0/8", Method: "scram-sha-256",
+			Options: map[string]string{"clientcert": "verify-full"}},
+		{Type: "host", Database: "all", User: "all", Address: "all", Method: "ldap",
+			Options: map[string]string{"ldapserver": "ldap.example.com", "ldapprefix": "cn=", "ldapsuffix": ", dc=example, dc=com"}},
+	}}}, nil},
+	{"expect error as address is missing", PostgresSpec{Patroni: Patroni{PgHbaRules: []config.PgHbaRule{
+		{Type: "local", Database: "all", User: "all", Method: "trust"},
+		{Type: "host", Database: "all", User: "all", Method: "md5"},
+	}}}, errors.New("invalid pg_hba rule 1: address must not be empty for host entries")},
+	{"expect error as clientcert requires hostssl", PostgresSpec{Patroni: Patroni{PgHbaRules: []config.PgHbaRule{
+		{Type: "host", Database: "all", User: "all", Address: "0.0.0.0/0", Method: "md5",
+			Options: map[string]string{"clientcert": "verify-ca"}},
+	}}}, errors.New("invalid pg_hba rule 0: option clientcert requires type hostssl")},
+}
+
+var connectionLimit, invalidConnectionLimit = int32(10), int32(-2)
+
+var userSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid role attributes", PostgresSpec{Users: map[string]UserDefinition{
+		"app":    {Flags: UserFlags{"createdb"}, ConnectionLimit: &connectionLimit, ValidUntil: "2030-01-01T00:00:00+02:00", MemberOf: []string{"reader"}},
+		"reader": {ValidUntil: ValidUntilInfinity, AdminOf: []string{"app"}, ConsumerNamespaces: []string{"team-a"}}}}, nil},
+	{"expect error as flag is unknown", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {Flags: UserFlags{"login", "superuser1"}}}},
+		errors.New(`flag "superuser1" of user "app" is not valid`)},
+	{"expect error as connection limit is too low", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {ConnectionLimit: &invalidConnectionLimit}}},
+		errors.New(`connectionLimit of user "app" must be -1 or greater`)},
+	{"expect error as expiry is not a timestamp", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {ValidUntil: "tomorrow"}}},
+		errors.New(`validUntil of user "app" must be an RFC 3339 timestamp or "infinity"`)},
+	{"expect error as role name contains a quote", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {AdminOf: []string{`re"ader`}}}},
+		errors.New(`role "re\"ader" of user "app" must not be empty or contain double quotes`)},
+	{"expect error as user is a member of itself", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {MemberOf: []string{"app"}}}},
+		errors.New(`user "app" cannot be a member of itself`)},
+	{"expect error as consumer namespace is invalid", PostgresSpec{Users: map[string]UserDefinition{
+		"app": {ConsumerNamespaces: []string{"Team_A"}}}},
+		errors.New(`consumer namespace "Team_A" of user "app" is not valid: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`)},
+}
+
+var userDefinitions = []struct {
+	about   string
+	in      []byte
+	out     UserDefinition
+	marshal []byte
+}{
+	{"list of flags", []byte(`["superuser", "createdb"]`),
+		UserDefinition{Flags: UserFlags{"superuser", "createdb"}}, []byte(`["superuser","createdb"]`)},
+	{"empty list of flags", []byte(`[]`), UserDefinition{Flags: UserFlags{}}, []byte(`[]`)},
+	{"null", []byte(`null`), UserDefinition{}, []byte(`[]`)},
+	{"object with flags only", []byte(`{"flags": ["login"]}`),
+		UserDefinition{Flags: UserFlags{"login"}}, []byte(`["login"]`)},
+	{"object with role attributes", []byte(`{"flags": ["login"], "connectionLimit": 10, "validUntil": "infinity", "memberOf": ["reader"], "adminOf": ["app"]}`),
+		UserDefinition{Flags: UserFlags{"login"}, ConnectionLimit: &connectionLimit, ValidUntil: ValidUntilInfinity, MemberOf: []string{"reader"}, AdminOf: []string{"app"}},
+		[]byte(`{"flags":["login"],"connectionLimit":10,"validUntil":"infinity","memberOf":["reader"],"adminOf":["app"]}`)},
+	{"object with consumer namespaces", []byte(`{"consumerNamespaces": ["team-a"]}`),
+		UserDefinition{ConsumerNamespaces: []string{"team-a"}}, []byte(`{"consumerNamespaces":["team-a"]}`)},
+	{"object with parameters", []byte(`{"parameters": {"statement_timeout": "30s"}}`),
+		UserDefinition{Parameters: map[string]string{"statement_timeout": "30s"}}, []byte(`{"parameters":{"statement_timeout":"30s"}}`)},
+}
+
+var databaseDefinitions = []struct {
+	about   string
+	in      []byte
+	out     DatabaseDefinition
+	marshal []byte
+}{
+	{"owner", []byte(`"app"`), DatabaseDefinition{Owner: "app"}, []byte(`"app"`)},
+	{"null", []byte(`null`), DatabaseDefinition{}, []byte(`""`)},
+	{"object with owner only", []byte(`{"owner": "app"}`), DatabaseDefinition{Owner: "app"}, []byte(`"app"`)},
+	{"object with parameters", []byte(`{"owner": "app", "parameters": {"work_mem": "64MB"}}`),
+		DatabaseDefinition{Owner: "app", Parameters: map[string]string{"work_mem": "64MB"}},
+		[]byte(`{"owner":"app","parameters":{"work_mem":"64MB"}}`)},
+}
+
+var objectParameterSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid parameters", PostgresSpec{PostgresqlParam: PostgresqlParam{PgVersion: "17"},
+		Users:             map[string]UserDefinition{"app": {Parameters: map[string]string{"statement_timeout": "30s", "search_path": `'"$user", public'`}}},
+		Databases:         map[string]DatabaseDefinition{"app": {Owner: "app", Parameters: map[string]string{"work_mem": "64MB"}}},
+		PreparedDatabases: map[string]PreparedDatabase{"shop": {Parameters: map[string]string{"pg_stat_statements.track": "all"}}}}, nil},
+	{"expect error as parameter cannot be set per role", PostgresSpec{PostgresqlParam: PostgresqlParam{PgVersion: "17"},
+		Users: map[string]UserDefinition{"app": {Parameters: map[string]string{"shared_buffers": "1GB"}}}},
+		errors.New(`invalid parameters of user "app": parameter "shared_buffers" cannot be set per role or database`)},
+	{"expect error as value contains a quote", PostgresSpec{
+		Databases: map[string]DatabaseDefinition{"app": {Owner: "app", Parameters: map[string]string{"application_name": "it's"}}}},
+		errors.New(`invalid parameters of database "app": value of parameter "application_name" must not contain single quotes`)},
+	{"expect error as name of a prepared database parameter is invalid", PostgresSpec{
+		PreparedDatabases: map[string]PreparedDatabase{"app": {Parameters: map[string]string{"work_mem; DROP": "1"}}}},
+		errors.New(`invalid parameters of database "app": invalid parameter name "work_mem; DROP"`)},
+}
+
+var databaseExtensionSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid extensions", PostgresSpec{DatabaseExtensions: map[string]Extensions{
+		"postgres": {"pg_stat_statements": {}},
+		"app":      {"postgis": {Schema: "gis", Version: "3.5.0"}, "uuid-ossp": {State: ExtensionStateAbsent}},
+	}}, nil},
+	{"expect error as state is invalid", PostgresSpec{DatabaseExtensions: map[string]Extensions{
+		"app": {"postgis": {State: "installed"}},
+	}}, errors.New(`extension "postgis" of database "app": state must be "present" or "absent"`)},
+	{"expect error as absent extension is pinned", PostgresSpec{DatabaseExtensions: map[string]Extensions{
+		"app": {"postgis": {State: ExtensionStateAbsent, Version: "3.5.0"}},
+	}}, errors.New(`extension "postgis" of database "app": version cannot be set for state "absent"`)},
+	{"expect error as version contains a quote", PostgresSpec{DatabaseExtensions: map[string]Extensions{
+		"app": {"postgis": {Version: "3.5'"}},
+	}}, errors.New(`extension "postgis" of database "app": version must not contain single quotes`)},
+	{"expect error as extension is defined twice", PostgresSpec{
+		PreparedDatabases:  map[string]PreparedDatabase{"app": {Extensions: map[string]string{"postgis": "public"}}},
+		DatabaseExtensions: map[string]Extensions{"app": {"postgis": {}}},
+	}, errors.New(`extension "postgis" of database "app" is already defined in preparedDatabases`)},
+}
+
+var grantSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"valid grants", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {
+			Privileges: []Grant{
+				{Role: "reporting", ObjectType: "database", Privileges: []string{"connect"}},
+				{Role: "reporting", ObjectType: "schema", Objects: []string{"sales"}, Privileges: []string{"USAGE"}},
+				{Role: "reporting", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT"}},
+				{Role: "reporting", ObjectType: "function", Schema: "sales", Objects: []string{"revenue(date, date)"},
+					Privileges: []string{"EXECUTE"}},
+				{Role: "auditor", ObjectType: "table", Schema: "sales", Privileges: []string{}},
+			},
+			DefaultPrivileges: []DefaultPrivilege{
+				{Role: "reporting", Owner: "app_owner", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT"}},
+			},
+		},
+	}}, nil},
+	{"expect error as object type is unknown", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {Privileges: []Grant{{Role: "reporting", ObjectType: "view", Schema: "sales", Privileges: []string{"SELECT"}}}},
+	}}, errors.New(`privileges[0] of database "app": object type "view" is not supported`)},
+	{"expect error as privilege does not exist for schemas", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {Privileges: []Grant{{Role: "reporting", ObjectType: "schema", Objects: []string{"sales"}, Privileges: []string{"SELECT"}}}},
+	}}, errors.New(`privileges[0] of database "app": privilege "SELECT" cannot be granted on schema, valid privileges: CREATE, USAGE`)},
+	{"expect error as tables need a schema", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {Privileges: []Grant{{Role: "reporting", ObjectType: "table", Objects: []string{"orders"}, Privileges: []string{"SELECT"}}}},
+	}}, errors.New(`privileges[0] of database "app": schema must be set for object type "table"`)},
+	{"expect error as function has no signature", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {Privileges: []Grant{{Role: "reporting", ObjectType: "function", Schema: "sales", Objects: []string{"revenue"},
+			Privileges: []string{"EXECUTE"}}}},
+	}}, errors.New(`privileges[0] of database "app": function "revenue" must be given as name(argument types)`)},
+	{"expect error as public is not managed", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {Privileges: []Grant{{Role: "PUBLIC", ObjectType: "database", Privileges: []string{"CONNECT"}}}},
+	}}, errors.New(`privileges[0] of database "app": privileges of PUBLIC are not managed`)},
+	{"expect error as default privileges need an owner", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {DefaultPrivileges: []DefaultPrivilege{{Role: "reporting", ObjectType: "table", Privileges: []string{"SELECT"}}}},
+	}}, errors.New(`defaultPrivileges[0] of database "app": owner "" must not be empty or contain double quotes`)},
+	{"expect error as default privileges of schemas are global", PostgresSpec{Grants: map[string]DatabaseGrants{
+		"app": {DefaultPrivileges: []DefaultPrivilege{{Role: "reporting", Owner: "app_owner", ObjectType: "schema", Schema: "sales",
+			Privileges: []string{"USAGE"}}}},
+	}}, errors.New(`defaultPrivileges[0] of database "app": schema cannot be set for object type "schema"`)},
+}
+
+var logicalReplicationSpecs = []struct {
+	about string
+	in    PostgresSpec
+	err   error
+}{
+	{"no logical replication", PostgresSpec{}, nil},
+	{"valid publications and subscriptions", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Publications: []Publication{
+			{Name: "orders", Database: "shop", Tables: []string{"orders", "sales.invoices"}},
+			{Name: "everything", Database: "shop", AllTables: true},
+			{Name: "everything", Database: "crm", AllTables: true},
+		},
+		Subscriptions: []Subscription{
+			{Name: "shop_orders", Database: "reporting", SourceCluster: "shop-cluster", SourceDatabase: "shop", Publications: []string{"orders"}},
+		},
+	}}, nil},
+	{"expect error as publication name is invalid", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Publications: []Publication{{Name: "Orders", Database: "shop", AllTables: true}},
+	}}, errors.New(`publication name "Orders" must consist of lower case letters, digits and underscores`)},
+	{"expect error as publication has tables and all tables", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Publications: []Publication{{Name: "orders", Database: "shop", Tables: []string{"orders"}, AllTables: true}},
+	}}, errors.New(`publication "orders": either tables or allTables must be set`)},
+	{"expect error as publication is defined twice", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Publications: []Publication{
+			{Name: "orders", Database: "shop", AllTables: true},
+			{Name: "orders", Database: "shop", Tables: []string{"orders"}},
+		},
+	}}, errors.New(`publication "orders" is defined twice in database "shop"`)},
+	{"expect error as table name is invalid", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Publications: []Publication{{Name: "orders", Database: "shop", Tables: []string{"a.b.c"}}},
+	}}, errors.New(`publication "orders": table "a.b.c" must be given as table or schema.table`)},
+	{"expect error as subscription has no source", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Subscriptions: []Subscription{{Name: "shop_orders", Database: "reporting", Publications: []string{"orders"}}},
+	}}, errors.New(`subscription "shop_orders": database and sourceCluster must be set`)},
+	{"expect error as subscription has no publications", PostgresSpec{LogicalReplication: &LogicalReplication{
+		Subscriptions: []Subscription{{Name: "shop_orders", Database: "reporting", SourceCluster: "shop-cluster"}},
+	}}, errors.New(`subscription "shop_orders": publications must not be empty`)},
+}
+
+var deletionPolicies = []struct {
+	about string
+	in    *DeletionPolicy
+	err   error
+}{
+	{"no deletion policy", nil, nil},
+	{"valid deletion policy", &DeletionPolicy{Databases: "drop", Roles: "rename", Delay: "1h30m", Force: true}, nil},
+	{"valid deletion policy without delay", &DeletionPolicy{Databases: "retain"}, nil},
+	{"expect error as policy is unknown", &DeletionPolicy{Roles: "delete"},
+		errors.New(`deletion policy "delete" must be "retain", "rename" or "drop"`)},
+	{"expect error as delay is invalid", &DeletionPolicy{Databases: "drop", Delay: "one day"},
+		errors.New(`delay of the deletion policy must be a positive duration, e.g. 24h`)},
+	{"expect error as delay is negative", &DeletionPolicy{Databases: "drop", Delay: "-1h"},
+		errors.New(`delay of the deletion policy must be a positive duration, e.g. 24h`)},
+}
+
 var maintenanceWindows = []struct {
 	about string
 	in    []byte
@@ -308,7 +575,7 @@ var unmarshalCluster = []struct {
 				TeamID:              "acid",
 				AllowedSourceRanges: []string{"127.0.0.1/32"},
 				NumberOfInstances:   2,
-				Users:               map[string]UserFlags{"zalando": {"superuser", "createdb"}},
+				Users:               map[string]UserDefinition{"zalando": {Flags: UserFlags{"superuser", "createdb"}}},
 				MaintenanceWindows: []MaintenanceWindow{{
 					Everyday:  false,
 					Weekday:   time.Monday,
@@ -638,6 +905,174 @@ func TestCloneClusterDescription(t *testing.T) {
 	}
 }
 
+func TestSynchronousReplication(t *testing.T) {
+	for _, tt := range synchronousReplicationSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateSynchronousReplication(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateSynchronousReplication expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestPostgresParameters(t *testing.T) {
+	for _, tt := range postgresParameterSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := ValidatePostgresParameters(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("ValidatePostgresParameters expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestPgHbaRules(t *testing.T) {
+	for _, tt := range pgHbaRuleSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validatePgHbaRules(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validatePgHbaRules expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestUsers(t *testing.T) {
+	for _, tt := range userSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateUsers(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateUsers expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestUserDefinition(t *testing.T) {
+	for _, tt := range userDefinitions {
+		t.Run(tt.about, func(t *testing.T) {
+			var definition UserDefinition
+			if err := json.Unmarshal(tt.in, &definition); err != nil {
+				t.Fatalf("could not unmarshal user definition: %v", err)
+			}
+			if !reflect.DeepEqual(definition, tt.out) {
+				t.Errorf("expected user definition %#v, got %#v", tt.out, definition)
+			}
+			m, err := json.Marshal(definition)
+			if err != nil {
+				t.Fatalf("could not marshal user definition: %v", err)
+			}
+			if !bytes.Equal(m, tt.marshal) {
+				t.Errorf("expected %s, got %s", tt.marshal, m)
+			}
+		})
+	}
+}
+
+func TestDatabaseDefinition(t *testing.T) {
+	for _, tt := range databaseDefinitions {
+		t.Run(tt.about, func(t *testing.T) {
+			var definition DatabaseDefinition
+			if err := json.Unmarshal(tt.in, &definition); err != nil {
+				t.Fatalf("could not unmarshal database definition: %v", err)
+			}
+			if !reflect.DeepEqual(definition, tt.out) {
+				t.Errorf("expected database definition %#v, got %#v", tt.out, definition)
+			}
+			m, err := json.Marshal(definition)
+			if err != nil {
+				t.Fatalf("could not marshal database definition: %v", err)
+			}
+			if !bytes.Equal(m, tt.marshal) {
+				t.Errorf("expected %s, got %s", tt.marshal, m)
+			}
+		})
+	}
+}
+
+func TestObjectParameters(t *testing.T) {
+	for _, tt := range objectParameterSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateObjectParameters(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateObjectParameters expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestDatabaseExtensions(t *testing.T) {
+	for _, tt := range databaseExtensionSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateDatabaseExtensions(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateDatabaseExtensions expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestGrants(t *testing.T) {
+	for _, tt := range grantSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateGrants(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateGrants expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestLogicalReplication(t *testing.T) {
+	for _, tt := range logicalReplicationSpecs {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateLogicalReplication(&tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateLogicalReplication expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestDeletionPolicy(t *testing.T) {
+	for _, tt := range deletionPolicies {
+		t.Run(tt.about, func(t *testing.T) {
+			if err := validateDeletionPolicy(tt.in); err != nil {
+				if tt.err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("validateDeletionPolicy expected error: %v, got: %v", tt.err, err)
+				}
+			} else if tt.err != nil {
+				t.Errorf("Expected error: %v", tt.err)
+			}
+		})
+	}
+}
+
 func TestUnmarshalMaintenanceWindow(t *testing.T) {
 	for _, tt := range maintenanceWindows {
 		t.Run(tt.about, func(t *testing.T) {
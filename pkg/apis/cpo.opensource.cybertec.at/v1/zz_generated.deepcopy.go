@@ -72,6 +72,36 @@ func (in *AdditionalVolume) DeepCopy() *AdditionalVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedGrants) DeepCopyInto(out *AppliedGrants) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]Grant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultPrivileges != nil {
+		in, out := &in.DefaultPrivileges, &out.DefaultPrivileges
+		*out = make([]DefaultPrivilege, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedGrants.
+func (in *AppliedGrants) DeepCopy() *AppliedGrants {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedGrants)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
@@ -192,6 +222,112 @@ func (in *ConnectionPoolerConfiguration) DeepCopy() *ConnectionPoolerConfigurati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseDefinition) DeepCopyInto(out *DatabaseDefinition) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseDefinition.
+func (in *DatabaseDefinition) DeepCopy() *DatabaseDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseExtension) DeepCopyInto(out *DatabaseExtension) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseExtension.
+func (in *DatabaseExtension) DeepCopy() *DatabaseExtension {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseExtension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseGrants) DeepCopyInto(out *DatabaseGrants) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]Grant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultPrivileges != nil {
+		in, out := &in.DefaultPrivileges, &out.DefaultPrivileges
+		*out = make([]DefaultPrivilege, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseGrants.
+func (in *DatabaseGrants) DeepCopy() *DatabaseGrants {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseGrants)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultPrivilege) DeepCopyInto(out *DefaultPrivilege) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultPrivilege.
+func (in *DefaultPrivilege) DeepCopy() *DefaultPrivilege {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultPrivilege)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionPolicy) DeepCopyInto(out *DeletionPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionPolicy.
+func (in *DeletionPolicy) DeepCopy() *DeletionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdConfig) DeepCopyInto(out *EtcdConfig) {
 	*out = *in
@@ -233,6 +369,87 @@ func (in *EtcdConfig) DeepCopy() *EtcdConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtensionStatus) DeepCopyInto(out *ExtensionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtensionStatus.
+func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtensionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in Extensions) DeepCopyInto(out *Extensions) {
+	{
+		in := &in
+		*out = make(Extensions, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Extensions.
+func (in Extensions) DeepCopy() Extensions {
+	if in == nil {
+		return nil
+	}
+	out := new(Extensions)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedMember) DeepCopyInto(out *FailedMember) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedMember.
+func (in *FailedMember) DeepCopy() *FailedMember {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Grant) DeepCopyInto(out *Grant) {
+	*out = *in
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Grant.
+func (in *Grant) DeepCopy() *Grant {
+	if in == nil {
+		return nil
+	}
+	out := new(Grant)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesMetaConfiguration) DeepCopyInto(out *KubernetesMetaConfiguration) {
 	*out = *in
@@ -407,6 +624,36 @@ func (in *LoggingRESTAPIConfiguration) DeepCopy() *LoggingRESTAPIConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalReplication) DeepCopyInto(out *LogicalReplication) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]Publication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]Subscription, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalReplication.
+func (in *LogicalReplication) DeepCopy() *LogicalReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
@@ -687,6 +934,13 @@ func (in *Patroni) DeepCopyInto(out *Patroni) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PgHbaRules != nil {
+		in, out := &in.PgHbaRules, &out.PgHbaRules
+		*out = make([]config.PgHbaRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Slots != nil {
 		in, out := &in.Slots, &out.Slots
 		*out = make(map[string]map[string]string, len(*in))
@@ -714,6 +968,13 @@ func (in *Patroni) DeepCopyInto(out *Patroni) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MemberTags != nil {
+		in, out := &in.MemberTags, &out.MemberTags
+		*out = make([]PatroniMemberTags, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -735,6 +996,13 @@ func (in *PatroniConfiguration) DeepCopyInto(out *PatroniConfiguration) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PgHbaRules != nil {
+		in, out := &in.PgHbaRules, &out.PgHbaRules
+		*out = make([]config.PgHbaRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -748,6 +1016,74 @@ func (in *PatroniConfiguration) DeepCopy() *PatroniConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniMemberTags) DeepCopyInto(out *PatroniMemberTags) {
+	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NoFailover != nil {
+		in, out := &in.NoFailover, &out.NoFailover
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoLoadBalance != nil {
+		in, out := &in.NoLoadBalance, &out.NoLoadBalance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoSync != nil {
+		in, out := &in.NoSync, &out.NoSync
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailoverPriority != nil {
+		in, out := &in.FailoverPriority, &out.FailoverPriority
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniMemberTags.
+func (in *PatroniMemberTags) DeepCopy() *PatroniMemberTags {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniMemberTags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingDeletion) DeepCopyInto(out *PendingDeletion) {
+	*out = *in
+	in.RemovedAt.DeepCopyInto(&out.RemovedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingDeletion.
+func (in *PendingDeletion) DeepCopy() *PendingDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Pgbackrest) DeepCopyInto(out *Pgbackrest) {
 	*out = *in
@@ -811,31 +1147,108 @@ func (in *PgbackrestClone) DeepCopy() *PgbackrestClone {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresPodResourcesDefaults) DeepCopyInto(out *PostgresPodResourcesDefaults) {
+func (in *PostgresDatabase) DeepCopyInto(out *PostgresDatabase) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPodResourcesDefaults.
-func (in *PostgresPodResourcesDefaults) DeepCopy() *PostgresPodResourcesDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabase.
+func (in *PostgresDatabase) DeepCopy() *PostgresDatabase {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresPodResourcesDefaults)
+	out := new(PostgresDatabase)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresDatabase) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
+func (in *PostgresDatabaseList) DeepCopyInto(out *PostgresDatabaseList) {
 	*out = *in
-	in.PostgresqlParam.DeepCopyInto(&out.PostgresqlParam)
-	in.Volume.DeepCopyInto(&out.Volume)
-	in.Patroni.DeepCopyInto(&out.Patroni)
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(Resources)
-		**out = **in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresDatabase, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseList.
+func (in *PostgresDatabaseList) DeepCopy() *PostgresDatabaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresDatabaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseSpec) DeepCopyInto(out *PostgresDatabaseSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseSpec.
+func (in *PostgresDatabaseSpec) DeepCopy() *PostgresDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPodResourcesDefaults) DeepCopyInto(out *PostgresPodResourcesDefaults) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPodResourcesDefaults.
+func (in *PostgresPodResourcesDefaults) DeepCopy() *PostgresPodResourcesDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPodResourcesDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
+	*out = *in
+	in.PostgresqlParam.DeepCopyInto(&out.PostgresqlParam)
+	in.Volume.DeepCopyInto(&out.Volume)
+	in.Patroni.DeepCopyInto(&out.Patroni)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(Resources)
+		**out = **in
 	}
 	if in.EnableConnectionPooler != nil {
 		in, out := &in.EnableConnectionPooler, &out.EnableConnectionPooler
@@ -904,17 +1317,9 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 	}
 	if in.Users != nil {
 		in, out := &in.Users, &out.Users
-		*out = make(map[string]UserFlags, len(*in))
+		*out = make(map[string]UserDefinition, len(*in))
 		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				in, out := &val, &outVal
-				*out = make(UserFlags, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.UsersWithSecretRotation != nil {
@@ -941,11 +1346,50 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 	}
 	if in.Databases != nil {
 		in, out := &in.Databases, &out.Databases
-		*out = make(map[string]string, len(*in))
+		*out = make(map[string]DatabaseDefinition, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.DatabaseExtensions != nil {
+		in, out := &in.DatabaseExtensions, &out.DatabaseExtensions
+		*out = make(map[string]Extensions, len(*in))
+		for key, val := range *in {
+			var outVal map[string]DatabaseExtension
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(Extensions, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make(map[string]DatabaseGrants, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.LogicalReplication != nil {
+		in, out := &in.LogicalReplication, &out.LogicalReplication
+		*out = new(LogicalReplication)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SelfServiceNamespaces != nil {
+		in, out := &in.SelfServiceNamespaces, &out.SelfServiceNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(DeletionPolicy)
+		**out = **in
+	}
 	if in.PreparedDatabases != nil {
 		in, out := &in.PreparedDatabases, &out.PreparedDatabases
 		*out = make(map[string]PreparedDatabase, len(*in))
@@ -1084,6 +1528,11 @@ func (in *PostgresSpec) DeepCopyInto(out *PostgresSpec) {
 		*out = new(Monitoring)
 		**out = **in
 	}
+	if in.Reinitialize != nil {
+		in, out := &in.Reinitialize, &out.Reinitialize
+		*out = new(Reinitialize)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1100,6 +1549,97 @@ func (in *PostgresSpec) DeepCopy() *PostgresSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresStatus) DeepCopyInto(out *PostgresStatus) {
 	*out = *in
+	if in.ReinitializeMembers != nil {
+		in, out := &in.ReinitializeMembers, &out.ReinitializeMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReinitializeStarted != nil {
+		in, out := &in.ReinitializeStarted, &out.ReinitializeStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.FailedMembers != nil {
+		in, out := &in.FailedMembers, &out.FailedMembers
+		*out = make([]FailedMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageMigration != nil {
+		in, out := &in.StorageMigration, &out.StorageMigration
+		*out = new(StorageMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SynchronousStandbys != nil {
+		in, out := &in.SynchronousStandbys, &out.SynchronousStandbys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimelineHistory != nil {
+		in, out := &in.TimelineHistory, &out.TimelineHistory
+		*out = make([]TimelineHistoryEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.MissingFailoverSlots != nil {
+		in, out := &in.MissingFailoverSlots, &out.MissingFailoverSlots
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]ExtensionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]SubscriptionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingDeletions != nil {
+		in, out := &in.PendingDeletions, &out.PendingDeletions
+		*out = make([]PendingDeletion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedDatabases != nil {
+		in, out := &in.ManagedDatabases, &out.ManagedDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedRoles != nil {
+		in, out := &in.ManagedRoles, &out.ManagedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ParameterDatabases != nil {
+		in, out := &in.ParameterDatabases, &out.ParameterDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ParameterRoles != nil {
+		in, out := &in.ParameterRoles, &out.ParameterRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]AppliedGrants, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+
 	return
 }
 
@@ -1234,6 +1774,95 @@ func (in *PostgresTeamSpec) DeepCopy() *PostgresTeamSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUser) DeepCopyInto(out *PostgresUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUser.
+func (in *PostgresUser) DeepCopy() *PostgresUser {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserList) DeepCopyInto(out *PostgresUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserList.
+func (in *PostgresUserList) DeepCopy() *PostgresUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
+	*out = *in
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make(UserFlags, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserSpec.
+func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUsersConfiguration) DeepCopyInto(out *PostgresUsersConfiguration) {
 	*out = *in
@@ -1242,6 +1871,11 @@ func (in *PostgresUsersConfiguration) DeepCopyInto(out *PostgresUsersConfigurati
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.MirrorCredentialSecrets != nil {
+		in, out := &in.MirrorCredentialSecrets, &out.MirrorCredentialSecrets
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -1261,7 +1895,7 @@ func (in *Postgresql) DeepCopyInto(out *Postgresql) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1326,6 +1960,11 @@ func (in *PostgresqlParam) DeepCopyInto(out *PostgresqlParam) {
 			(*out)[key] = val
 		}
 	}
+	if in.Tuning != nil {
+		in, out := &in.Tuning, &out.Tuning
+		*out = new(PostgresTuning)
+		**out = **in
+	}
 	return
 }
 
@@ -1339,6 +1978,22 @@ func (in *PostgresqlParam) DeepCopy() *PostgresqlParam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresTuning) DeepCopyInto(out *PostgresTuning) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresTuning.
+func (in *PostgresTuning) DeepCopy() *PostgresTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreparedDatabase) DeepCopyInto(out *PreparedDatabase) {
 	*out = *in
@@ -1356,6 +2011,13 @@ func (in *PreparedDatabase) DeepCopyInto(out *PreparedDatabase) {
 			(*out)[key] = val
 		}
 	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1390,6 +2052,27 @@ func (in *PreparedSchema) DeepCopy() *PreparedSchema {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Publication) DeepCopyInto(out *Publication) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Publication.
+func (in *Publication) DeepCopy() *Publication {
+	if in == nil {
+		return nil
+	}
+	out := new(Publication)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Repo) DeepCopyInto(out *Repo) {
 	*out = *in
@@ -1430,6 +2113,27 @@ func (in *ResourceDescription) DeepCopy() *ResourceDescription {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Reinitialize) DeepCopyInto(out *Reinitialize) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Reinitialize.
+func (in *Reinitialize) DeepCopy() *Reinitialize {
+	if in == nil {
+		return nil
+	}
+	out := new(Reinitialize)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resources) DeepCopyInto(out *Resources) {
 	*out = *in
@@ -1487,6 +2191,29 @@ func (in *ScalyrConfiguration) DeepCopy() *ScalyrConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelfServiceStatus) DeepCopyInto(out *SelfServiceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelfServiceStatus.
+func (in *SelfServiceStatus) DeepCopy() *SelfServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SelfServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Sidecar) DeepCopyInto(out *Sidecar) {
 	*out = *in
@@ -1553,6 +2280,27 @@ func (in *StandbyDescription) DeepCopy() *StandbyDescription {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageMigrationStatus) DeepCopyInto(out *StorageMigrationStatus) {
+	*out = *in
+	if in.MigratedMembers != nil {
+		in, out := &in.MigratedMembers, &out.MigratedMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageMigrationStatus.
+func (in *StorageMigrationStatus) DeepCopy() *StorageMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Stream) DeepCopyInto(out *Stream) {
 	*out = *in
@@ -1627,6 +2375,43 @@ func (in *StreamTable) DeepCopy() *StreamTable {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Subscription) DeepCopyInto(out *Subscription) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subscription.
+func (in *Subscription) DeepCopy() *Subscription {
+	if in == nil {
+		return nil
+	}
+	out := new(Subscription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionStatus) DeepCopyInto(out *SubscriptionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionStatus.
+func (in *SubscriptionStatus) DeepCopy() *SubscriptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TDE) DeepCopyInto(out *TDE) {
 	*out = *in
@@ -1697,6 +2482,54 @@ func (in *TeamsAPIConfiguration) DeepCopy() *TeamsAPIConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDefinition) DeepCopyInto(out *UserDefinition) {
+	*out = *in
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make(UserFlags, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConnectionLimit != nil {
+		in, out := &in.ConnectionLimit, &out.ConnectionLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MemberOf != nil {
+		in, out := &in.MemberOf, &out.MemberOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminOf != nil {
+		in, out := &in.AdminOf, &out.AdminOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConsumerNamespaces != nil {
+		in, out := &in.ConsumerNamespaces, &out.ConsumerNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDefinition.
+func (in *UserDefinition) DeepCopy() *UserDefinition {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDefinition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in UserFlags) DeepCopyInto(out *UserFlags) {
 	{
@@ -1717,6 +2550,22 @@ func (in UserFlags) DeepCopy() UserFlags {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimelineHistoryEntry) DeepCopyInto(out *TimelineHistoryEntry) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimelineHistoryEntry.
+func (in *TimelineHistoryEntry) DeepCopy() *TimelineHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(TimelineHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Volume) DeepCopyInto(out *Volume) {
 	*out = *in
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -19,11 +20,13 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/clientset/versioned/scheme"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/selfservice"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
 	pgteams "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/teams"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/credentials"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/teams"
@@ -59,9 +62,14 @@ type Config struct {
 	OpConfig                     config.Config
 	RestConfig                   *rest.Config
 	PgTeamMap                    *pgteams.PostgresTeamMap
+	SelfService                  *selfservice.Registry
+	CredentialStore              credentials.Store      // nil when credentials are kept in Kubernetes secrets only
 	InfrastructureRoles          map[string]spec.PgUser // inherited from the controller
 	PodServiceAccount            *v1.ServiceAccount
 	PodServiceAccountRoleBinding *rbacv1.RoleBinding
+
+	// QueueSync schedules another sync of the cluster, nil when the cluster is not run by a controller
+	QueueSync func(clusterName spec.NamespacedName, after time.Duration)
 }
 
 type kubeResources struct {
@@ -87,6 +95,7 @@ type Cluster struct {
 	pgUsers          map[string]spec.PgUser
 	pgUsersCache     map[string]spec.PgUser
 	systemUsers      map[string]spec.PgUser
+	selfServiceDbs   map[string]string
 	podSubscribers   map[spec.NamespacedName]chan PodEvent
 	podSubscribersMu sync.RWMutex
 	pgDb             *sql.DB
@@ -163,7 +172,12 @@ func New(cfg Config, kubeClient k8sutil.KubernetesClient, pgSpec cpov1.Postgresq
 
 	cluster.EBSVolumes = make(map[string]volumes.VolumeProperties)
 	if cfg.OpConfig.StorageResizeMode != "pvc" || cfg.OpConfig.EnableEBSGp3Migration {
-		cluster.VolumeResizer = &volumes.EBSVolumeResizer{AWSRegion: cfg.OpConfig.AWSRegion}
+		resizer, err := volumes.NewVolumeResizer(cfg.OpConfig.StorageResizeProvider, cfg.OpConfig.AWSRegion)
+		if err != nil {
+			cluster.logger.Errorf("could not set up volume resizing: %v", err)
+		} else {
+			cluster.VolumeResizer = resizer
+		}
 	}
 
 	//Check if monitoring user is added in manifest
@@ -262,6 +276,8 @@ func (c *Cluster) initUsers() error {
 		return fmt.Errorf("could not init robot users: %v", err)
 	}
 
+	c.initSelfServiceUsers()
+
 	if err := c.initHumanUsers(); err != nil {
 		// remember all cached users in c.pgUsers
 		for cachedUserName, cachedUser := range c.pgUsersCache {
@@ -288,7 +304,7 @@ func (c *Cluster) Create() (err error) {
 
 	defer func() {
 		if err == nil {
-			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusRunning) //TODO: are you sure it's running?
+			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), c.runningStatus()) //TODO: are you sure it's running?
 		} else {
 			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusAddFailed)
 		}
@@ -338,6 +354,14 @@ func (c *Cluster) Create() (err error) {
 	c.logger.Infof("secrets have been successfully created")
 	c.eventRecorder.Event(c.GetReference(), v1.EventTypeNormal, "Secrets", "The secrets have been successfully created")
 
+	if err = c.syncPatroniAPI(); err != nil {
+		return fmt.Errorf("could not set up Patroni API credentials: %v", err)
+	}
+
+	if err = c.syncPostgresTLSCertificates(); err != nil {
+		return fmt.Errorf("could not set up TLS certificates: %v", err)
+	}
+
 	if c.PodDisruptionBudget != nil {
 		return fmt.Errorf("pod disruption budget already exists in the cluster")
 	}
@@ -385,6 +409,12 @@ func (c *Cluster) Create() (err error) {
 		c.waitForPrimaryLoadBalancerIp()
 	}
 
+	if hasMemberTags(&c.Postgresql.Spec) {
+		if err := c.syncMemberTagsConfigMap(nil); err != nil {
+			return fmt.Errorf("could not create member tags config map: %v", err)
+		}
+	}
+
 	if c.Statefulset != nil {
 		return fmt.Errorf("statefulset already exists in the cluster")
 	}
@@ -420,6 +450,17 @@ func (c *Cluster) Create() (err error) {
 			return fmt.Errorf("could not sync prepared databases: %v", err)
 		}
 		c.logger.Infof("databases have been successfully created")
+
+		// a missing extension must not fail the creation of the cluster, it is retried on sync
+		if err = c.syncDatabaseExtensions(); err != nil {
+			c.logger.Errorf("could not sync database extensions: %v", err)
+		}
+		if err = c.syncGrants(false); err != nil {
+			c.logger.Errorf("could not sync grants: %v", err)
+		}
+		if err = c.syncLogicalReplication(); err != nil {
+			c.logger.Errorf("could not sync logical replication: %v", err)
+		}
 	}
 
 	if c.Postgresql.Spec.EnableLogicalBackup {
@@ -940,7 +981,7 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 		if updateFailed {
 			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusUpdateFailed)
 		} else {
-			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusRunning)
+			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), c.runningStatus())
 		}
 	}()
 
@@ -965,6 +1006,12 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 			updateFailed = true
 		}
 	}
+
+	// TLS certificates cover the service names of the cluster and its poolers
+	if err := c.syncPostgresTLSCertificates(); err != nil {
+		c.logger.Errorf("could not sync TLS certificates: %v", err)
+		updateFailed = true
+	}
 	//Check if monitoring user is added in manifest
 	if _, ok := newSpec.Spec.Users["cpo-exporter"]; ok {
 		c.logger.Error("creating user of name cpo-exporter is not allowed as it is reserved for monitoring")
@@ -975,7 +1022,8 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 	func() {
 		// check if users need to be synced during update
 		sameUsers := reflect.DeepEqual(oldSpec.Spec.Users, newSpec.Spec.Users) &&
-			reflect.DeepEqual(oldSpec.Spec.PreparedDatabases, newSpec.Spec.PreparedDatabases)
+			reflect.DeepEqual(oldSpec.Spec.PreparedDatabases, newSpec.Spec.PreparedDatabases) &&
+			reflect.DeepEqual(oldSpec.Spec.SelfServiceNamespaces, newSpec.Spec.SelfServiceNamespaces)
 		sameRotatedUsers := reflect.DeepEqual(oldSpec.Spec.UsersWithSecretRotation, newSpec.Spec.UsersWithSecretRotation) &&
 			reflect.DeepEqual(oldSpec.Spec.UsersWithInPlaceSecretRotation, newSpec.Spec.UsersWithInPlaceSecretRotation)
 		// connection pooler needs one system user created who is initialized in initUsers
@@ -989,7 +1037,13 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 		// only when streams were not specified in oldSpec but in newSpec
 		needStreamUser := len(oldSpec.Spec.Streams) == 0 && len(newSpec.Spec.Streams) > 0
 
-		if !sameUsers || !sameRotatedUsers || needPoolerUser || needMonitoring || needStreamUser {
+		// the replication user of logical replication is created with the first publication
+		needLogicalReplicationUser := !hasPublications(&oldSpec.Spec) && hasPublications(&newSpec.Spec)
+
+		// passwords are rotated on demand when roles are listed in the rotation annotation
+		rotationRequested := len(c.requestedPasswordRotations()) > 0
+
+		if !sameUsers || !sameRotatedUsers || needPoolerUser || needMonitoring || needStreamUser || needLogicalReplicationUser || rotationRequested {
 			c.logger.Debugf("initialize users")
 			if err := c.initUsers(); err != nil {
 				c.logger.Errorf("could not init users - skipping sync of secrets and databases: %v", err)
@@ -997,11 +1051,19 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 				updateFailed = true
 				return
 			}
+			c.checkSelfServiceRoles()
 
 			c.logger.Debugf("syncing secrets")
 			//TODO: mind the secrets of the deleted/new users
 			if err := c.syncSecrets(); err != nil {
-				c.logger.Errorf("could not sync secrets: %v", err)
+				var storeErr *credentialStoreError
+				if errors.As(err, &storeErr) {
+					// roles must not be synced with passwords which are not stored
+					c.logger.Errorf("could not sync secrets - skipping sync of roles and databases: %v", err)
+					userInitFailed = true
+				} else {
+					c.logger.Errorf("could not sync secrets: %v", err)
+				}
 				updateFailed = true
 			}
 		}
@@ -1052,6 +1114,11 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 		syncStatefulSet = true
 	}
 
+	// maintenance mode is applied through the Patroni config during the statefulset sync
+	if oldSpec.Spec.Patroni.Pause != newSpec.Spec.Patroni.Pause {
+		syncStatefulSet = true
+	}
+
 	//sync sts if there is a change in the monitor-section
 	if !reflect.DeepEqual(oldSpec.Spec.Monitoring, newSpec.Spec.Monitoring) {
 		c.logger.Infof("monitoring configuration changed, triggering statefulset sync")
@@ -1158,6 +1225,30 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 		}
 	}()
 
+	// Patroni member tags
+	if !reflect.DeepEqual(oldSpec.Spec.Patroni.MemberTags, newSpec.Spec.Patroni.MemberTags) {
+		if err := c.syncPatroniMemberTags(); err != nil {
+			c.logger.Errorf("could not sync Patroni member tags: %v", err)
+			updateFailed = true
+		}
+	}
+
+	// reinitialization of members
+	if !reflect.DeepEqual(oldSpec.Spec.Reinitialize, newSpec.Spec.Reinitialize) {
+		if err := c.syncReinitialize(); err != nil {
+			c.logger.Errorf("could not reinitialize members: %v", err)
+			updateFailed = true
+		}
+	}
+
+	// storage class migration, further members are migrated on the following syncs
+	if oldSpec.Spec.Volume.StorageClass != newSpec.Spec.Volume.StorageClass {
+		if err := c.syncStorageClassMigration(); err != nil {
+			c.logger.Errorf("could not migrate volumes to storage class %q: %v", newSpec.Spec.Volume.StorageClass, err)
+			updateFailed = true
+		}
+	}
+
 	// add or remove standby_cluster section from Patroni config depending on changes in standby section
 	if !reflect.DeepEqual(oldSpec.Spec.StandbyCluster, newSpec.Spec.StandbyCluster) {
 		if err := c.syncStandbyClusterConfiguration(); err != nil {
@@ -1211,6 +1302,12 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 
 	}()
 
+	// remember databases and roles removed from the manifest for the deletion policy
+	if err := c.registerRemovedObjects(&oldSpec.Spec, &newSpec.Spec); err != nil {
+		c.logger.Errorf("could not register removed databases and roles: %v", err)
+		updateFailed = true
+	}
+
 	// Ensure the cluster has a leader
 	leaderNotReady := false
 	if err := c.waitForLeader(60 * time.Second); err != nil {
@@ -1226,8 +1323,13 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 			c.logger.Errorf("could not sync roles: %v", err)
 			updateFailed = true
 		}
+		if err := c.syncSystemUserRotation(); err != nil {
+			c.logger.Errorf("could not rotate passwords of system users: %v", err)
+			updateFailed = true
+		}
 		if !reflect.DeepEqual(oldSpec.Spec.Databases, newSpec.Spec.Databases) ||
-			!reflect.DeepEqual(oldSpec.Spec.PreparedDatabases, newSpec.Spec.PreparedDatabases) {
+			!reflect.DeepEqual(oldSpec.Spec.PreparedDatabases, newSpec.Spec.PreparedDatabases) ||
+			!reflect.DeepEqual(oldSpec.Spec.SelfServiceNamespaces, newSpec.Spec.SelfServiceNamespaces) {
 			c.logger.Infof("syncing databases")
 			if err := c.syncDatabases(); err != nil {
 				c.logger.Errorf("could not sync databases: %v", err)
@@ -1241,6 +1343,32 @@ func (c *Cluster) Update(oldSpec, newSpec *cpov1.Postgresql) error {
 				updateFailed = true
 			}
 		}
+		if !reflect.DeepEqual(oldSpec.Spec.DatabaseExtensions, newSpec.Spec.DatabaseExtensions) {
+			c.logger.Infof("syncing database extensions")
+			if err := c.syncDatabaseExtensions(); err != nil {
+				c.logger.Errorf("could not sync database extensions: %v", err)
+				updateFailed = true
+			}
+		}
+		if !reflect.DeepEqual(oldSpec.Spec.Grants, newSpec.Spec.Grants) {
+			c.logger.Infof("syncing grants")
+			if err := c.syncGrants(false); err != nil {
+				c.logger.Errorf("could not sync grants: %v", err)
+				updateFailed = true
+			}
+		}
+		if !reflect.DeepEqual(oldSpec.Spec.LogicalReplication, newSpec.Spec.LogicalReplication) {
+			c.logger.Infof("syncing logical replication")
+			if err := c.syncLogicalReplication(); err != nil {
+				c.logger.Errorf("could not sync logical replication: %v", err)
+				updateFailed = true
+			}
+		}
+		c.logger.Debugf("syncing pending deletions")
+		if err := c.syncPendingDeletions(); err != nil {
+			c.logger.Errorf("could not sync pending deletions: %v", err)
+			updateFailed = true
+		}
 	}
 
 	// Sync connection pooler. Before actually doing sync reset lookup
@@ -1411,6 +1539,14 @@ func (c *Cluster) Delete() {
 		c.logger.Warningf("could not delete pgbackrest restore config: %v", err)
 	}
 
+	if err := c.deletePostgresTLSCABundle(); err != nil {
+		c.logger.Warningf("could not delete CA bundle: %v", err)
+	}
+
+	if err := c.deleteMemberTagsConfigMap(); err != nil {
+		c.logger.Warningf("could not delete member tags config map: %v", err)
+	}
+
 	if err := c.deleteSecrets(); err != nil {
 		c.logger.Warningf("could not delete secrets: %v", err)
 	}
@@ -1589,6 +1725,26 @@ func (c *Cluster) initSystemUsers() error {
 		}
 	}
 
+	// subscribers of other clusters connect with one replication user for all publications,
+	// which can read the published tables for the initial copy
+	if hasPublications(&c.Spec) {
+		logicalReplicationUser := spec.PgUser{
+			Origin:    spec.RoleOriginLogicalReplication,
+			Name:      constants.LogicalReplicationUserName,
+			Namespace: c.Namespace,
+			Flags:     []string{constants.RoleFlagLogin, constants.RoleFlagReplication},
+			Password:  util.RandomPassword(constants.PasswordLength),
+		}
+		// pg_read_all_data exists since PostgreSQL 14, before the published tables are granted
+		if c.GetDesiredMajorVersionAsInt() >= VersionMap["14"] {
+			logicalReplicationUser.MemberOf = []string{constants.ReadAllDataRoleName}
+		}
+
+		if _, exists := c.systemUsers[constants.LogicalReplicationUserName]; !exists {
+			c.systemUsers[constants.LogicalReplicationUserName] = logicalReplicationUser
+		}
+	}
+
 	return nil
 }
 
@@ -1736,7 +1892,7 @@ func (c *Cluster) initDefaultRoles(defaultRoles map[string]string, admin, prefix
 }
 
 func (c *Cluster) initRobotUsers() error {
-	for username, userFlags := range c.Spec.Users {
+	for username, userDefinition := range c.Spec.Users {
 		if !isValidUsername(username) {
 			return fmt.Errorf("invalid username: %q", username)
 		}
@@ -1748,8 +1904,8 @@ func (c *Cluster) initRobotUsers() error {
 
 		// check if role is specified as database owner
 		isOwner := false
-		for _, owner := range c.Spec.Databases {
-			if username == owner {
+		for _, database := range c.Spec.Databases {
+			if username == database.Owner {
 				isOwner = true
 			}
 		}
@@ -1763,22 +1919,38 @@ func (c *Cluster) initRobotUsers() error {
 			}
 		}
 
-		flags, err := normalizeUserFlags(userFlags)
+		flags, err := normalizeUserFlags(userDefinition.Flags)
 		if err != nil {
 			return fmt.Errorf("invalid flags for user %q: %v", username, err)
 		}
+		validUntil, err := normalizeValidUntil(userDefinition.ValidUntil)
+		if err != nil {
+			return fmt.Errorf("invalid validUntil for user %q: %v", username, err)
+		}
+		// copy the memberships, as additional owner roles are appended later on
+		var memberOf []string
+		memberOf = append(memberOf, userDefinition.MemberOf...)
 		adminRole := ""
 		if c.OpConfig.EnableAdminRoleForUsers {
 			adminRole = c.OpConfig.TeamAdminRole
 		}
 		newRole := spec.PgUser{
-			Origin:    spec.RoleOriginManifest,
-			Name:      username,
-			Namespace: namespace,
-			Password:  util.RandomPassword(constants.PasswordLength),
-			Flags:     flags,
-			AdminRole: adminRole,
-			IsDbOwner: isOwner,
+			Origin:     spec.RoleOriginManifest,
+			Name:       username,
+			Namespace:  namespace,
+			Password:   util.RandomPassword(constants.PasswordLength),
+			Flags:      flags,
+			MemberOf:   memberOf,
+			AdminOf:    userDefinition.AdminOf,
+			ConnLimit:  userDefinition.ConnectionLimit,
+			ValidUntil: validUntil,
+			AdminRole:  adminRole,
+			IsDbOwner:  isOwner,
+			Parameters: userDefinition.Parameters,
+		}
+		// parameters set from the manifest before are reset once the entry is removed
+		if newRole.Parameters == nil && util.SliceContains(c.Status.ParameterRoles, username) {
+			newRole.Parameters = map[string]string{}
 		}
 		if currentRole, present := c.pgUsers[username]; present {
 			c.pgUsers[username] = c.resolveNameConflict(&currentRole, &newRole)
@@ -1980,6 +2152,9 @@ func (c *Cluster) GetStatus() *ClusterStatus {
 func (c *Cluster) Switchover(curMaster *v1.Pod, candidate spec.NamespacedName) error {
 
 	var err error
+	if c.patroniPaused() {
+		return fmt.Errorf("cluster management is paused, not switching over from %q to %q", curMaster.Name, candidate)
+	}
 	c.logger.Debugf("switching over from %q to %q", curMaster.Name, candidate)
 	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "Switchover", "Switching over from %q to %q", curMaster.Name, candidate)
 	stopCh := make(chan struct{})
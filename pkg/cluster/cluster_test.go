@@ -147,65 +147,65 @@ func TestStatefulSetUpdateWithEnv(t *testing.T) {
 func TestInitRobotUsers(t *testing.T) {
 	tests := []struct {
 		testCase      string
-		manifestUsers map[string]cpov1.UserFlags
+		manifestUsers map[string]cpov1.UserDefinition
 		infraRoles    map[string]spec.PgUser
 		result        map[string]spec.PgUser
 		err           error
 	}{
 		{
 			testCase:      "manifest user called like infrastructure role - latter should take percedence",
-			manifestUsers: map[string]cpov1.UserFlags{"foo": {"superuser", "createdb"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"foo": {Flags: cpov1.UserFlags{"superuser", "createdb"}}},
 			infraRoles:    map[string]spec.PgUser{"foo": {Origin: spec.RoleOriginInfrastructure, Name: "foo", Namespace: cl.Namespace, Password: "bar"}},
 			result:        map[string]spec.PgUser{"foo": {Origin: spec.RoleOriginInfrastructure, Name: "foo", Namespace: cl.Namespace, Password: "bar"}},
 			err:           nil,
 		},
 		{
 			testCase:      "manifest user with forbidden characters",
-			manifestUsers: map[string]cpov1.UserFlags{"!fooBar": {"superuser", "createdb"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"!fooBar": {Flags: cpov1.UserFlags{"superuser", "createdb"}}},
 			err:           fmt.Errorf(`invalid username: "!fooBar"`),
 		},
 		{
 			testCase:      "manifest user with unknown privileges (should be catched by CRD, too)",
-			manifestUsers: map[string]cpov1.UserFlags{"foobar": {"!superuser", "createdb"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"foobar": {Flags: cpov1.UserFlags{"!superuser", "createdb"}}},
 			err: fmt.Errorf(`invalid flags for user "foobar": ` +
 				`user flag "!superuser" is not alphanumeric`),
 		},
 		{
 			testCase:      "manifest user with unknown privileges - part 2 (should be catched by CRD, too)",
-			manifestUsers: map[string]cpov1.UserFlags{"foobar": {"superuser1", "createdb"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"foobar": {Flags: cpov1.UserFlags{"superuser1", "createdb"}}},
 			err: fmt.Errorf(`invalid flags for user "foobar": ` +
 				`user flag "SUPERUSER1" is not valid`),
 		},
 		{
 			testCase:      "manifest user with conflicting flags",
-			manifestUsers: map[string]cpov1.UserFlags{"foobar": {"inherit", "noinherit"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"foobar": {Flags: cpov1.UserFlags{"inherit", "noinherit"}}},
 			err: fmt.Errorf(`invalid flags for user "foobar": ` +
 				`conflicting user flags: "NOINHERIT" and "INHERIT"`),
 		},
 		{
 			testCase:      "manifest user called like Spilo system users",
-			manifestUsers: map[string]cpov1.UserFlags{superUserName: {"createdb"}, replicationUserName: {"replication"}},
+			manifestUsers: map[string]cpov1.UserDefinition{superUserName: {Flags: cpov1.UserFlags{"createdb"}}, replicationUserName: {Flags: cpov1.UserFlags{"replication"}}},
 			infraRoles:    map[string]spec.PgUser{},
 			result:        map[string]spec.PgUser{},
 			err:           nil,
 		},
 		{
 			testCase:      "manifest user called like protected user name",
-			manifestUsers: map[string]cpov1.UserFlags{adminUserName: {"superuser"}},
+			manifestUsers: map[string]cpov1.UserDefinition{adminUserName: {Flags: cpov1.UserFlags{"superuser"}}},
 			infraRoles:    map[string]spec.PgUser{},
 			result:        map[string]spec.PgUser{},
 			err:           nil,
 		},
 		{
 			testCase:      "manifest user called like pooler system user",
-			manifestUsers: map[string]cpov1.UserFlags{poolerUserName: {}},
+			manifestUsers: map[string]cpov1.UserDefinition{poolerUserName: {}},
 			infraRoles:    map[string]spec.PgUser{},
 			result:        map[string]spec.PgUser{},
 			err:           nil,
 		},
 		{
 			testCase:      "manifest user called like stream system user",
-			manifestUsers: map[string]cpov1.UserFlags{"fes_user": {"replication"}},
+			manifestUsers: map[string]cpov1.UserDefinition{"fes_user": {Flags: cpov1.UserFlags{"replication"}}},
 			infraRoles:    map[string]spec.PgUser{},
 			result:        map[string]spec.PgUser{},
 			err:           nil,
@@ -230,15 +230,45 @@ func TestInitRobotUsers(t *testing.T) {
 	}
 }
 
+func TestInitRobotUsersParameters(t *testing.T) {
+	defer func(users map[string]cpov1.UserDefinition, status cpov1.PostgresStatus) {
+		cl.Spec.Users = users
+		cl.Status = status
+	}(cl.Spec.Users, cl.Status)
+
+	cl.Spec.Users = map[string]cpov1.UserDefinition{
+		"app":       {Parameters: map[string]string{"statement_timeout": "30s"}},
+		"reporting": {},
+		"legacy":    {},
+	}
+	cl.Status.ParameterRoles = []string{"app", "reporting"}
+	cl.pgUsers = map[string]spec.PgUser{}
+
+	if err := cl.initRobotUsers(); err != nil {
+		t.Fatalf("%s: could not init manifest users: %v", t.Name(), err)
+	}
+
+	expected := map[string]map[string]string{
+		"app":       {"statement_timeout": "30s"},
+		"reporting": {},
+		"legacy":    nil,
+	}
+	for username, parameters := range expected {
+		if got := cl.pgUsers[username].Parameters; !reflect.DeepEqual(got, parameters) {
+			t.Errorf("%s: expected parameters %#v of role %q, got %#v", t.Name(), parameters, username, got)
+		}
+	}
+}
+
 func TestInitAdditionalOwnerRoles(t *testing.T) {
-	manifestUsers := map[string]cpov1.UserFlags{"foo_owner": {}, "bar_owner": {}, "app_user": {}}
+	manifestUsers := map[string]cpov1.UserDefinition{"foo_owner": {}, "bar_owner": {}, "app_user": {}}
 	expectedUsers := map[string]spec.PgUser{
 		"foo_owner": {Origin: spec.RoleOriginManifest, Name: "foo_owner", Namespace: cl.Namespace, Password: "f123", Flags: []string{"LOGIN"}, IsDbOwner: true, MemberOf: []string{"cron_admin", "part_man"}},
 		"bar_owner": {Origin: spec.RoleOriginManifest, Name: "bar_owner", Namespace: cl.Namespace, Password: "b123", Flags: []string{"LOGIN"}, IsDbOwner: true, MemberOf: []string{"cron_admin", "part_man"}},
 		"app_user":  {Origin: spec.RoleOriginManifest, Name: "app_user", Namespace: cl.Namespace, Password: "a123", Flags: []string{"LOGIN"}, IsDbOwner: false},
 	}
 
-	cl.Spec.Databases = map[string]string{"foo_db": "foo_owner", "bar_db": "bar_owner"}
+	cl.Spec.Databases = map[string]cpov1.DatabaseDefinition{"foo_db": {Owner: "foo_owner"}, "bar_db": {Owner: "bar_owner"}}
 	cl.Spec.Users = manifestUsers
 
 	// this should set IsDbOwner field for manifest users
@@ -259,6 +289,34 @@ func TestInitAdditionalOwnerRoles(t *testing.T) {
 	}
 }
 
+func TestInitRobotUserAttributes(t *testing.T) {
+	connectionLimit := int32(5)
+	cl.Spec.Users = map[string]cpov1.UserDefinition{
+		"app_user": {Flags: cpov1.UserFlags{"createdb"}, ConnectionLimit: &connectionLimit,
+			ValidUntil: "2030-01-01T02:00:00+02:00", MemberOf: []string{"reader"}, AdminOf: []string{"app_reader"}},
+		"app_reader": {ValidUntil: cpov1.ValidUntilInfinity},
+	}
+	cl.pgUsers = map[string]spec.PgUser{}
+
+	if err := cl.initRobotUsers(); err != nil {
+		t.Fatalf("%s could not init manifest users: %v", t.Name(), err)
+	}
+
+	appUser := cl.pgUsers["app_user"]
+	if appUser.ConnLimit == nil || *appUser.ConnLimit != connectionLimit {
+		t.Errorf("%s expected connection limit %d, got %v", t.Name(), connectionLimit, appUser.ConnLimit)
+	}
+	if appUser.ValidUntil != "2030-01-01T00:00:00Z" {
+		t.Errorf("%s expected expiry to be converted to UTC, got %q", t.Name(), appUser.ValidUntil)
+	}
+	if !reflect.DeepEqual(appUser.MemberOf, []string{"reader"}) || !reflect.DeepEqual(appUser.AdminOf, []string{"app_reader"}) {
+		t.Errorf("%s unexpected memberships: member of %#v, admin of %#v", t.Name(), appUser.MemberOf, appUser.AdminOf)
+	}
+	if appReader := cl.pgUsers["app_reader"]; appReader.ConnLimit != nil || appReader.ValidUntil != cpov1.ValidUntilInfinity {
+		t.Errorf("%s unexpected attributes of app_reader: %#v", t.Name(), appReader)
+	}
+}
+
 type mockOAuthTokenGetter struct {
 }
 
@@ -297,7 +355,7 @@ func TestInitHumanUsers(t *testing.T) {
 	cl.OpConfig.EnableTeamMemberDeprecation = true
 	cl.OpConfig.PamRoleName = "humans"
 	cl.Spec.TeamID = "test"
-	cl.Spec.Users = map[string]cpov1.UserFlags{"bar": []string{}}
+	cl.Spec.Users = map[string]cpov1.UserDefinition{"bar": {}}
 
 	tests := []struct {
 		existingRoles map[string]spec.PgUser
@@ -1005,7 +1063,7 @@ func TestInitSystemUsers(t *testing.T) {
 
 	// using stream user in manifest but no streams defined should be treated like normal robot user
 	streamUser := fmt.Sprintf("%s%s", constants.EventStreamSourceSlotPrefix, constants.UserRoleNameSuffix)
-	cl.Spec.Users = map[string]cpov1.UserFlags{streamUser: []string{}}
+	cl.Spec.Users = map[string]cpov1.UserDefinition{streamUser: {}}
 	cl.initSystemUsers()
 	if _, exist := cl.systemUsers[constants.EventStreamUserKeyName]; exist {
 		t.Errorf("%s, stream user is present", t.Name())
@@ -1617,7 +1675,7 @@ func TestCrossNamespacedSecrets(t *testing.T) {
 			Volume: cpov1.Volume{
 				Size: "1Gi",
 			},
-			Users: map[string]cpov1.UserFlags{
+			Users: map[string]cpov1.UserDefinition{
 				"appspace.db_user": {},
 				"db_user":          {},
 			},
@@ -359,7 +359,7 @@ func (c *Cluster) generateConnectionPoolerPodTemplate(role PostgresRole) (
 	//  3. Mount the volume to the container at /tls
 	var poolerVolumes []v1.Volume
 	var volumeMounts []v1.VolumeMount
-	if spec.TLS != nil && spec.TLS.SecretName != "" {
+	if tls := c.postgresTLS(spec); tls != nil {
 		getPoolerTLSEnv := func(k string) string {
 			keyName := ""
 			switch k {
@@ -375,7 +375,7 @@ func (c *Cluster) generateConnectionPoolerPodTemplate(role PostgresRole) (
 
 			return keyName
 		}
-		tlsEnv, tlsVolumes := c.generateTlsMounts(spec, getPoolerTLSEnv)
+		tlsEnv, tlsVolumes := c.generateTlsMounts(tls, getPoolerTLSEnv)
 		envVars = append(envVars, tlsEnv...)
 		for _, vol := range tlsVolumes {
 			poolerVolumes = append(poolerVolumes, v1.Volume{
@@ -409,11 +409,21 @@ func (c *Cluster) generateConnectionPoolerPodTemplate(role PostgresRole) (
 
 	topologySpreadConstraintsSpec := topologySpreadConstraints(&spec.TopologySpreadConstraints)
 
+	// pgBouncer only reads its certificate on start, so a renewed certificate
+	// changes the pod template and rolls the pooler
+	podAnnotations := c.generatePodAnnotations(spec)
+	if serial := c.postgresTLSCertificateSerial(spec); serial != "" {
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string)
+		}
+		podAnnotations[constants.TLSCertificateSerialAnnotation] = serial
+	}
+
 	podTemplate := &v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:      c.connectionPoolerLabels(true, role, true).MatchLabels,
 			Namespace:   c.Namespace,
-			Annotations: c.annotationsSet(c.generatePodAnnotations(spec)),
+			Annotations: c.annotationsSet(podAnnotations),
 		},
 		Spec: v1.PodSpec{
 			TerminationGracePeriodSeconds: &gracePeriod,
@@ -1069,7 +1079,13 @@ func (c *Cluster) syncConnectionPoolerWorker(oldSpec, newSpec *cpov1.Postgresql,
 			syncReason = append(syncReason, "pod template labels changed")
 		}
 
-		if labelsSync || specSync || defaultsSync || templateSync {
+		certificateSync := deployment.Spec.Template.Annotations[constants.TLSCertificateSerialAnnotation] !=
+			desired.Spec.Template.Annotations[constants.TLSCertificateSerialAnnotation]
+		if certificateSync {
+			syncReason = append(syncReason, "TLS certificate renewed")
+		}
+
+		if labelsSync || specSync || defaultsSync || templateSync || certificateSync {
 			c.logger.Infof("update connection pooler deployment %s, reason: %+v", c.connectionPoolerName(role), syncReason)
 
 			deployment, err = updateConnectionPoolerDeployment(c.KubeClient, desired)
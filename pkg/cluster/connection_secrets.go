@@ -0,0 +1,276 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+const (
+	// serviceBindingType and serviceBindingProvider identify the secret as a workload
+	// projection following the servicebinding.io specification
+	serviceBindingType     = "postgresql"
+	serviceBindingProvider = "cybertec-pg-operator"
+)
+
+// connectionSecretKeys are the keys of user secrets managed next to username and password
+var connectionSecretKeys = []string{
+	"type", "provider", "host", "port", "database", "uri", "jdbc-uri",
+	"pooler-host", "pooler-port", "pooler-uri",
+}
+
+// connectionSecretData returns the connection details of a role for its secret. The database is
+// the first database owned by the role or postgres.
+func (c *Cluster) connectionSecretData(role, username, password string) map[string][]byte {
+	host := fmt.Sprintf("%s.%s.svc.%s", c.serviceName(Master), c.Namespace, c.OpConfig.ClusterDomain)
+	port := strconv.Itoa(pgPort)
+	database := c.ownedDatabase(role)
+
+	data := map[string][]byte{
+		"type":     []byte(serviceBindingType),
+		"provider": []byte(serviceBindingProvider),
+		"host":     []byte(host),
+		"port":     []byte(port),
+		"database": []byte(database),
+		"uri":      []byte(postgresURI(host, port, database, username, password)),
+		"jdbc-uri": []byte(jdbcURI(host, port, database, username, password)),
+	}
+	if needMasterConnectionPooler(&c.Spec) {
+		poolerHost := fmt.Sprintf("%s.%s.svc.%s", c.connectionPoolerName(Master), c.Namespace, c.OpConfig.ClusterDomain)
+		data["pooler-host"] = []byte(poolerHost)
+		data["pooler-port"] = []byte(port)
+		data["pooler-uri"] = []byte(postgresURI(poolerHost, port, database, username, password))
+	}
+	return data
+}
+
+// ownedDatabase returns the first database owned by the role in alphabetical order
+func (c *Cluster) ownedDatabase(role string) string {
+	databases := make([]string, 0)
+	for database, definition := range c.Spec.Databases {
+		if definition.Owner == role {
+			databases = append(databases, database)
+		}
+	}
+	for database, owner := range c.selfServiceDbs {
+		if owner == role {
+			databases = append(databases, database)
+		}
+	}
+	if len(databases) == 0 {
+		return "postgres"
+	}
+	sort.Strings(databases)
+	return databases[0]
+}
+
+func postgresURI(host, port, database, username, password string) string {
+	uri := url.URL{
+		Scheme: "postgresql",
+		User:   url.UserPassword(username, password),
+		Host:   host + ":" + port,
+		Path:   "/" + database,
+	}
+	return uri.String()
+}
+
+func jdbcURI(host, port, database, username, password string) string {
+	query := url.Values{}
+	query.Set("user", username)
+	query.Set("password", password)
+	return fmt.Sprintf("jdbc:postgresql://%s:%s/%s?%s", host, port, url.PathEscape(database), query.Encode())
+}
+
+// updateConnectionSecretData refreshes the connection details in the secret from its username and
+// password and reports whether the secret has changed
+func (c *Cluster) updateConnectionSecretData(secret *v1.Secret, role string) bool {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	desired := c.connectionSecretData(role, string(secret.Data["username"]), string(secret.Data["password"]))
+	changed := false
+	for _, key := range connectionSecretKeys {
+		value, wanted := desired[key]
+		current, exists := secret.Data[key]
+		switch {
+		case wanted && (!exists || string(current) != string(value)):
+			secret.Data[key] = value
+			changed = true
+		case !wanted && exists:
+			delete(secret.Data, key)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// syncConsumerSecrets copies the secrets of roles into their consumer namespaces. The namespaces
+// that received a copy are recorded in an annotation of the original secret, so that copies are
+// removed again when a namespace is no longer listed.
+func (c *Cluster) syncConsumerSecrets(generatedSecrets map[string]*v1.Secret) error {
+	currentSecrets := make(map[string]*v1.Secret, len(c.Secrets))
+	for _, secret := range c.Secrets {
+		currentSecrets[util.NameFromMeta(secret.ObjectMeta).String()] = secret
+	}
+
+	errors := make([]string, 0)
+	for username, generatedSecret := range generatedSecrets {
+		desired := make([]string, 0)
+		for _, namespace := range c.Spec.Users[username].ConsumerNamespaces {
+			if namespace != generatedSecret.Namespace && !util.SliceContains(desired, namespace) {
+				desired = append(desired, namespace)
+			}
+		}
+		currentSecret, exists := currentSecrets[util.NameFromMeta(generatedSecret.ObjectMeta).String()]
+		if len(desired) == 0 && (!exists || len(consumerNamespacesOf(currentSecret)) == 0) {
+			continue
+		}
+
+		// copy the latest version of the secret
+		secret, err := c.KubeClient.Secrets(generatedSecret.Namespace).Get(context.TODO(), generatedSecret.Name, metav1.GetOptions{})
+		if k8sutil.ResourceNotFound(err) {
+			continue
+		} else if err != nil {
+			errors = append(errors, fmt.Sprintf("could not get secret %s: %v", util.NameFromMeta(generatedSecret.ObjectMeta), err))
+			continue
+		}
+		previous := consumerNamespacesOf(secret)
+
+		synced := make([]string, 0, len(desired))
+		for _, namespace := range desired {
+			if err := c.syncConsumerSecret(secret, namespace); err != nil {
+				errors = append(errors, err.Error())
+				continue
+			}
+			synced = append(synced, namespace)
+		}
+		for _, namespace := range previous {
+			if util.SliceContains(desired, namespace) {
+				continue
+			}
+			if err := c.deleteConsumerSecret(secret, namespace); err != nil {
+				errors = append(errors, err.Error())
+				synced = append(synced, namespace)
+			}
+		}
+
+		if err := c.setConsumerNamespaces(secret, synced); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("could not sync consumer secrets: %v", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+func (c *Cluster) syncConsumerSecret(source *v1.Secret, namespace string) error {
+	secrets := c.KubeClient.Secrets(namespace)
+	secretName := fmt.Sprintf("%s/%s", namespace, source.Name)
+	consumerSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+			Labels:    c.labelsSet(true),
+			Annotations: c.annotationsSet(map[string]string{
+				constants.ConsumerSecretSourceAnnotation: util.NameFromMeta(source.ObjectMeta).String(),
+			}),
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+
+	currentSecret, err := secrets.Get(context.TODO(), source.Name, metav1.GetOptions{})
+	if k8sutil.ResourceNotFound(err) {
+		createdSecret, err := secrets.Create(context.TODO(), consumerSecret, metav1.CreateOptions{})
+		if k8sutil.ResourceNotFound(err) {
+			c.logger.Warningf("could not copy secret %s: namespace %q does not exist", util.NameFromMeta(source.ObjectMeta), namespace)
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not create secret %s: %v", secretName, err)
+		}
+		c.Secrets[createdSecret.UID] = createdSecret
+		c.logger.Infof("copied secret %s to consumer namespace %s", util.NameFromMeta(source.ObjectMeta), namespace)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not get secret %s: %v", secretName, err)
+	}
+
+	if currentSecret.Annotations[constants.ConsumerSecretSourceAnnotation] != util.NameFromMeta(source.ObjectMeta).String() {
+		return fmt.Errorf("secret %s already exists and is not a copy of %s", secretName, util.NameFromMeta(source.ObjectMeta))
+	}
+	if secretDataEqual(currentSecret.Data, source.Data) {
+		c.Secrets[currentSecret.UID] = currentSecret
+		return nil
+	}
+	currentSecret.Data = source.Data
+	updatedSecret, err := secrets.Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update secret %s: %v", secretName, err)
+	}
+	c.Secrets[updatedSecret.UID] = updatedSecret
+	c.logger.Debugf("updated secret %s in consumer namespace %s", util.NameFromMeta(source.ObjectMeta), namespace)
+	return nil
+}
+
+func (c *Cluster) deleteConsumerSecret(source *v1.Secret, namespace string) error {
+	secrets := c.KubeClient.Secrets(namespace)
+	currentSecret, err := secrets.Get(context.TODO(), source.Name, metav1.GetOptions{})
+	if k8sutil.ResourceNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not get secret %s/%s: %v", namespace, source.Name, err)
+	}
+	if currentSecret.Annotations[constants.ConsumerSecretSourceAnnotation] != util.NameFromMeta(source.ObjectMeta).String() {
+		return nil
+	}
+	if err = secrets.Delete(context.TODO(), source.Name, c.deleteOptions); err != nil && !k8sutil.ResourceNotFound(err) {
+		return fmt.Errorf("could not delete secret %s/%s: %v", namespace, source.Name, err)
+	}
+	delete(c.Secrets, currentSecret.UID)
+	c.logger.Infof("removed copy of secret %s from namespace %s", util.NameFromMeta(source.ObjectMeta), namespace)
+	return nil
+}
+
+// setConsumerNamespaces records the namespaces with a copy of the secret
+func (c *Cluster) setConsumerNamespaces(source *v1.Secret, namespaces []string) error {
+	sort.Strings(namespaces)
+	value := strings.Join(namespaces, ",")
+	if source.Annotations[constants.ConsumerNamespacesAnnotation] == value {
+		return nil
+	}
+
+	if source.Annotations == nil {
+		source.Annotations = map[string]string{}
+	}
+	if value == "" {
+		delete(source.Annotations, constants.ConsumerNamespacesAnnotation)
+	} else {
+		source.Annotations[constants.ConsumerNamespacesAnnotation] = value
+	}
+	updatedSecret, err := c.KubeClient.Secrets(source.Namespace).Update(context.TODO(), source, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update secret %s: %v", util.NameFromMeta(source.ObjectMeta), err)
+	}
+	c.Secrets[updatedSecret.UID] = updatedSecret
+	return nil
+}
+
+func consumerNamespacesOf(secret *v1.Secret) []string {
+	value := secret.Annotations[constants.ConsumerNamespacesAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
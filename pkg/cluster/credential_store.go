@@ -0,0 +1,173 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/credentials"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+// credentialStoreError is returned when the credential store cannot be read or written, in which case
+// the roles must not be synced with passwords that have not been stored.
+type credentialStoreError struct {
+	err error
+}
+
+func (e *credentialStoreError) Error() string {
+	return e.err.Error()
+}
+
+func (e *credentialStoreError) Unwrap() error {
+	return e.err
+}
+
+// syncStoredCredentials reconciles the credentials of a role with the credential store, which
+// takes precedence over the Kubernetes secret. Credentials of existing secrets are copied to the
+// store first, so that roles of running clusters keep their passwords.
+func (c *Cluster) syncStoredCredentials(
+	secretUsername string,
+	generatedSecret *v1.Secret,
+	retentionUsers *[]string,
+	currentTime time.Time) error {
+	storePath := credentialStorePath(generatedSecret)
+	secretName := util.NameFromMeta(generatedSecret.ObjectMeta)
+
+	data, err := c.CredentialStore.Get(storePath)
+	if errors.Is(err, credentials.ErrNotFound) {
+		data = secretDataToCredentials(generatedSecret.Data)
+		existingSecret, err := c.KubeClient.Secrets(generatedSecret.Namespace).Get(context.TODO(), generatedSecret.Name, metav1.GetOptions{})
+		if err == nil {
+			data = secretDataToCredentials(existingSecret.Data)
+		} else if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get secret %s: %v", secretName, err)
+		}
+		if err = c.CredentialStore.Put(storePath, data); err != nil {
+			return &credentialStoreError{fmt.Errorf("could not store credentials: %v", err)}
+		}
+		c.logger.Infof("stored credentials of secret %s in the credential store at %s", secretName, storePath)
+	} else if err != nil {
+		return &credentialStoreError{fmt.Errorf("could not get credentials from the credential store: %v", err)}
+	}
+
+	secret := generatedSecret.DeepCopy()
+	secret.Data = credentialsToSecretData(data)
+	// an immediate rotation can be requested by annotating the mirrored secret
+	if c.mirrorCredentialSecret(secretUsername) {
+		mirroredSecret, err := c.KubeClient.Secrets(generatedSecret.Namespace).Get(context.TODO(), generatedSecret.Name, metav1.GetOptions{})
+		if err == nil {
+			if rotationRequest, exists := mirroredSecret.Annotations[constants.RotatePasswordAnnotation]; exists {
+				if secret.Annotations == nil {
+					secret.Annotations = map[string]string{}
+				}
+				secret.Annotations[constants.RotatePasswordAnnotation] = rotationRequest
+			}
+		} else if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get secret %s: %v", secretName, err)
+		}
+	}
+	secret, updateSecretMsg := c.reconcileUserSecret(secretUsername, generatedSecret, secret, retentionUsers, currentTime)
+	if updateSecretMsg != "" {
+		c.logger.Debugf("%s", updateSecretMsg)
+		if err = c.CredentialStore.Put(storePath, secretDataToCredentials(secret.Data)); err != nil {
+			return &credentialStoreError{fmt.Errorf("could not store credentials: %v", err)}
+		}
+	}
+
+	if !c.mirrorCredentialSecret(secretUsername) {
+		return nil
+	}
+	return c.mirrorSecret(secret)
+}
+
+// mirrorCredentialSecret reports whether the credentials of a role are kept in a Kubernetes secret
+// as well. Secrets of system users are always kept, as they are referenced by the pods, and so
+// are secrets that are copied to consumer namespaces.
+func (c *Cluster) mirrorCredentialSecret(username string) bool {
+	if c.OpConfig.MirrorCredentialSecrets == nil || *c.OpConfig.MirrorCredentialSecrets {
+		return true
+	}
+	if len(c.Spec.Users[username].ConsumerNamespaces) > 0 {
+		return true
+	}
+	for _, systemUser := range c.systemUsers {
+		if systemUser.Name == username {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorSecret creates the secret or updates its data to match the credential store
+func (c *Cluster) mirrorSecret(secret *v1.Secret) error {
+	secrets := c.KubeClient.Secrets(secret.Namespace)
+	currentSecret, err := secrets.Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	if k8sutil.ResourceNotFound(err) {
+		secret.ResourceVersion = ""
+		createdSecret, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("could not create secret %s: %v", util.NameFromMeta(secret.ObjectMeta), err)
+		}
+		c.Secrets[createdSecret.UID] = createdSecret
+		c.logger.Debugf("created new secret %s, namespace: %s, uid: %s", util.NameFromMeta(createdSecret.ObjectMeta), createdSecret.Namespace, createdSecret.UID)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not get secret %s: %v", util.NameFromMeta(secret.ObjectMeta), err)
+	}
+
+	annotationsChanged := syncRotationAnnotations(secret, currentSecret)
+	if secretDataEqual(currentSecret.Data, secret.Data) && !annotationsChanged {
+		c.Secrets[currentSecret.UID] = currentSecret
+		return nil
+	}
+	currentSecret.Data = secret.Data
+	updatedSecret, err := secrets.Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update secret %s: %v", util.NameFromMeta(currentSecret.ObjectMeta), err)
+	}
+	c.Secrets[updatedSecret.UID] = updatedSecret
+	c.logger.Debugf("updated secret %s from the credential store", util.NameFromMeta(updatedSecret.ObjectMeta))
+	return nil
+}
+
+// credentialStorePath returns the path of the credentials of a secret in the credential store
+func credentialStorePath(secret *v1.Secret) string {
+	return path.Join(secret.Namespace, secret.Name)
+}
+
+func secretDataToCredentials(data map[string][]byte) map[string]string {
+	result := make(map[string]string, len(data))
+	for key, value := range data {
+		result[key] = string(value)
+	}
+	return result
+}
+
+func credentialsToSecretData(data map[string]string) map[string][]byte {
+	result := make(map[string][]byte, len(data))
+	for key, value := range data {
+		result[key] = []byte(value)
+	}
+	return result
+}
+
+func secretDataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, exists := b[key]; !exists || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/credentials"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+type memoryCredentialStore struct {
+	credentials map[string]map[string]string
+	err         error
+}
+
+func (m *memoryCredentialStore) Get(path string) (map[string]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	data, exists := m.credentials[path]
+	if !exists {
+		return nil, credentials.ErrNotFound
+	}
+	result := make(map[string]string, len(data))
+	for key, value := range data {
+		result[key] = value
+	}
+	return result, nil
+}
+
+func (m *memoryCredentialStore) Put(path string, data map[string]string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.credentials[path] = data
+	return nil
+}
+
+func credentialStoreTestSpec() cpov1.PostgresSpec {
+	return cpov1.PostgresSpec{
+		Users:                          map[string]cpov1.UserDefinition{"foo": {}, "bar": {}},
+		UsersWithInPlaceSecretRotation: []string{"foo"},
+	}
+}
+
+func credentialStoreTestConfig(store credentials.Store, mirror *bool) Config {
+	return Config{
+		OpConfig: config.Config{
+			Auth: config.Auth{
+				SuperUsername:            superUserName,
+				ReplicationUsername:      replicationUserName,
+				SecretNameTemplate:       "{username}.{cluster}.credentials",
+				PasswordRotationInterval: 1,
+				MirrorCredentialSecrets:  mirror,
+			},
+		},
+		CredentialStore: store,
+	}
+}
+
+func TestSyncStoredCredentials(t *testing.T) {
+	store := &memoryCredentialStore{credentials: map[string]map[string]string{}}
+	cluster := newTestCluster(t, credentialStoreTestSpec(), credentialStoreTestConfig(store, util.False()))
+	secrets := cluster.KubeClient.Secrets("default")
+
+	// the secret of a running cluster is copied to the store
+	fooSecretName := cluster.credentialSecretName("foo")
+	fooSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: fooSecretName, Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("foo"), "password": []byte("existing")},
+	}
+	if _, err := secrets.Create(context.TODO(), fooSecret, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("could not create secret: %v", err)
+	}
+
+	if err := cluster.initUsers(); err != nil {
+		t.Fatalf("could not init users: %v", err)
+	}
+	if err := cluster.syncSecrets(); err != nil {
+		t.Fatalf("could not sync secrets: %v", err)
+	}
+
+	if password := store.credentials["default/"+fooSecretName]["password"]; password != "existing" {
+		t.Errorf("%s: expected password of the existing secret in the store, got %q", t.Name(), password)
+	}
+	if password := cluster.pgUsers["foo"].Password; password != "existing" {
+		t.Errorf("%s: expected role foo to use the stored password, got %q", t.Name(), password)
+	}
+	if _, exists := store.credentials["default/"+cluster.credentialSecretName("bar")]; !exists {
+		t.Errorf("%s: expected credentials of role bar in the store", t.Name())
+	}
+
+	// without mirroring only the secrets of system users are created
+	if _, err := secrets.Get(context.TODO(), cluster.credentialSecretName("bar"), metav1.GetOptions{}); !k8sutil.ResourceNotFound(err) {
+		t.Errorf("%s: expected no secret for role bar, got %v", t.Name(), err)
+	}
+	superuserSecretName := cluster.credentialSecretName(superUserName)
+	superuserSecret, err := secrets.Get(context.TODO(), superuserSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: expected secret of the superuser: %v", t.Name(), err)
+	}
+	if string(superuserSecret.Data["password"]) != store.credentials["default/"+superuserSecretName]["password"] {
+		t.Errorf("%s: expected secret of the superuser to mirror the store", t.Name())
+	}
+
+	// the store takes precedence over the secret
+	store.credentials["default/"+superuserSecretName]["password"] = "changed"
+	if err := cluster.syncSecrets(); err != nil {
+		t.Fatalf("could not sync secrets: %v", err)
+	}
+	if password := cluster.systemUsers["superuser"].Password; password != "changed" {
+		t.Errorf("%s: expected superuser to use the stored password, got %q", t.Name(), password)
+	}
+	superuserSecret, _ = secrets.Get(context.TODO(), superuserSecretName, metav1.GetOptions{})
+	if password := string(superuserSecret.Data["password"]); password != "changed" {
+		t.Errorf("%s: expected mirrored password to be updated, got %q", t.Name(), password)
+	}
+
+	// rotation writes to the store
+	retentionUsers := make([]string, 0)
+	generatedSecret := cluster.generateUserSecrets()["foo"]
+	if err := cluster.syncStoredCredentials("foo", generatedSecret, &retentionUsers, time.Now().AddDate(0, 0, 2)); err != nil {
+		t.Fatalf("could not sync credentials: %v", err)
+	}
+	stored := store.credentials["default/"+fooSecretName]
+	if stored["password"] == "existing" || stored["nextRotation"] == "" {
+		t.Errorf("%s: expected rotated password in the store, got %v", t.Name(), stored)
+	}
+	if cluster.pgUsers["foo"].Password != stored["password"] {
+		t.Errorf("%s: expected role foo to use the rotated password", t.Name())
+	}
+
+	// an unavailable store fails the sync instead of changing passwords
+	store.err = fmt.Errorf("vault is sealed")
+	var storeErr *credentialStoreError
+	if err := cluster.syncSecrets(); !errors.As(err, &storeErr) {
+		t.Errorf("%s: expected a credential store error when the store is unavailable, got %v", t.Name(), err)
+	}
+}
+
+func TestMirrorCredentialSecrets(t *testing.T) {
+	store := &memoryCredentialStore{credentials: map[string]map[string]string{}}
+	cluster := newTestCluster(t, credentialStoreTestSpec(), credentialStoreTestConfig(store, util.True()))
+	if err := cluster.initUsers(); err != nil {
+		t.Fatalf("could not init users: %v", err)
+	}
+	if err := cluster.syncSecrets(); err != nil {
+		t.Fatalf("could not sync secrets: %v", err)
+	}
+
+	for _, username := range []string{"foo", "bar", superUserName, replicationUserName} {
+		secretName := cluster.credentialSecretName(username)
+		secret, err := cluster.KubeClient.Secrets("default").Get(context.TODO(), secretName, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("%s: expected secret for %s: %v", t.Name(), username, err)
+			continue
+		}
+		if string(secret.Data["password"]) != store.credentials["default/"+secretName]["password"] {
+			t.Errorf("%s: expected secret of %s to mirror the store", t.Name(), username)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"net"
+	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -23,7 +25,15 @@ const (
 	       ARRAY(SELECT b.rolname
 	             FROM pg_catalog.pg_auth_members m
 	             JOIN pg_catalog.pg_authid b ON (m.roleid = b.oid)
-	            WHERE m.member = a.oid) as memberof
+	            WHERE m.member = a.oid) as memberof,
+	       a.rolconnlimit,
+	       CASE WHEN a.rolvaliduntil IS NULL THEN ''
+	            WHEN a.rolvaliduntil = 'infinity' THEN 'infinity'
+	            ELSE to_char(a.rolvaliduntil AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"') END as rolvaliduntil,
+	       ARRAY(SELECT b.rolname
+	             FROM pg_catalog.pg_auth_members m
+	             JOIN pg_catalog.pg_authid b ON (m.roleid = b.oid)
+	            WHERE m.member = a.oid AND m.admin_option) as adminof
 	FROM pg_catalog.pg_authid a LEFT JOIN pg_db_role_setting s ON (a.oid = s.setrole AND s.setdatabase = 0::oid)
 	WHERE a.rolname = ANY($1)
 	ORDER BY 1;`
@@ -39,10 +49,17 @@ const (
 			WHERE n.nspname !~ '^pg_' AND n.nspname <> 'information_schema' ORDER BY 1`
 	getExtensionsSQL = `SELECT e.extname, n.nspname FROM pg_catalog.pg_extension e
 	        LEFT JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace ORDER BY 1;`
+	getDatabaseSettingsSQL = `SELECT d.datname, s.setconfig FROM pg_catalog.pg_db_role_setting s
+			JOIN pg_catalog.pg_database d ON d.oid = s.setdatabase WHERE s.setrole = 0::oid;`
+	getRoleCommentsSQL = `SELECT rolname, COALESCE(pg_catalog.shobj_description(oid, 'pg_authid'), '')
+			FROM pg_catalog.pg_roles WHERE rolname = ANY($1);`
+	commentOnRoleSQL = `COMMENT ON ROLE "%s" IS %s;`
 
 	createDatabaseSQL       = `CREATE DATABASE "%s" OWNER "%s";`
 	createDatabaseSchemaSQL = `SET ROLE TO "%s"; CREATE SCHEMA IF NOT EXISTS "%s" AUTHORIZATION "%s"`
 	alterDatabaseOwnerSQL   = `ALTER DATABASE "%s" OWNER TO "%s";`
+	alterDatabaseSetSQL     = `ALTER DATABASE "%s" SET %s TO %s;`
+	alterDatabaseResetSQL   = `ALTER DATABASE "%s" RESET %s;`
 	createExtensionSQL      = `CREATE EXTENSION IF NOT EXISTS "%s" SCHEMA "%s"`
 	alterExtensionSQL       = `ALTER EXTENSION "%s" SET SCHEMA "%s"`
 
@@ -145,8 +162,11 @@ const (
 )
 
 func (c *Cluster) pgConnectionString(dbname string) string {
-	password := c.systemUsers[constants.SuperuserKeyName].Password
+	superuser := c.systemUsers[constants.SuperuserKeyName]
+	return c.pgConnectionStringForUser(dbname, superuser.Name, superuser.Password)
+}
 
+func (c *Cluster) pgConnectionStringForUser(dbname, username, password string) string {
 	if dbname == "" {
 		dbname = "postgres"
 	}
@@ -154,7 +174,7 @@ func (c *Cluster) pgConnectionString(dbname string) string {
 	return fmt.Sprintf("host='%s' dbname='%s' sslmode=require user='%s' password='%s' connect_timeout='%d'",
 		fmt.Sprintf("%s.%s.svc.%s", c.Name, c.Namespace, c.OpConfig.ClusterDomain),
 		dbname,
-		c.systemUsers[constants.SuperuserKeyName].Name,
+		username,
 		strings.Replace(password, "$", "\\$", -1),
 		constants.PostgresConnectTimeout/time.Second)
 }
@@ -264,13 +284,15 @@ func (c *Cluster) readPgUsersFromDatabase(userNames []string) (users spec.PgUser
 
 	for rows.Next() {
 		var (
-			rolname, rolpassword                                          string
+			rolname, rolpassword, rolvaliduntil                           string
 			rolsuper, rolinherit, rolcreaterole, rolcreatedb, rolcanlogin bool
-			roloptions, memberof                                          []string
+			roloptions, memberof, adminof                                 []string
+			rolconnlimit                                                  int32
 			roldeleted                                                    bool
 		)
 		err := rows.Scan(&rolname, &rolpassword, &rolsuper, &rolinherit,
-			&rolcreaterole, &rolcreatedb, &rolcanlogin, pq.Array(&roloptions), pq.Array(&memberof))
+			&rolcreaterole, &rolcreatedb, &rolcanlogin, pq.Array(&roloptions), pq.Array(&memberof),
+			&rolconnlimit, &rolvaliduntil, pq.Array(&adminof))
 		if err != nil {
 			return nil, fmt.Errorf("error when processing user rows: %v", err)
 		}
@@ -291,7 +313,8 @@ func (c *Cluster) readPgUsersFromDatabase(userNames []string) (users spec.PgUser
 			roldeleted = true
 		}
 
-		users[rolname] = spec.PgUser{Name: rolname, Password: rolpassword, Flags: flags, MemberOf: memberof, Parameters: parameters, Deleted: roldeleted}
+		users[rolname] = spec.PgUser{Name: rolname, Password: rolpassword, Flags: flags, MemberOf: memberof, AdminOf: adminof,
+			ConnLimit: &rolconnlimit, ValidUntil: rolvaliduntil, Parameters: parameters, Deleted: roldeleted}
 	}
 
 	return users, nil
@@ -391,6 +414,100 @@ func (c *Cluster) getDatabases() (dbs map[string]string, err error) {
 	return dbs, err
 }
 
+// getDatabaseParameters returns the settings of all databases which do not depend on a role
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getDatabaseParameters() (parameters map[string]map[string]string, err error) {
+	var (
+		rows *sql.Rows
+	)
+
+	if rows, err = c.pgDb.Query(getDatabaseSettingsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database settings: %v", err)
+	}
+
+	defer func() {
+		if err2 := rows.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("error when closing query cursor: %v, previous error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("error when closing query cursor: %v", err2)
+			}
+		}
+	}()
+
+	parameters = make(map[string]map[string]string)
+
+	for rows.Next() {
+		var (
+			datname   string
+			setconfig []string
+		)
+
+		if err = rows.Scan(&datname, pq.Array(&setconfig)); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		parameters[datname] = make(map[string]string)
+		for _, option := range setconfig {
+			fields := strings.SplitN(option, "=", 2)
+			if len(fields) != 2 {
+				c.logger.Warningf("skipping malformed setting of database %q: %q", datname, option)
+				continue
+			}
+			parameters[datname][fields[0]] = fields[1]
+		}
+	}
+
+	return parameters, err
+}
+
+// executeAlterDatabaseParameters sets the desired parameters of a database and resets the ones
+// which are no longer desired.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) executeAlterDatabaseParameters(databaseName string, current, desired map[string]string) error {
+	statements := make([]string, 0)
+	for name := range current {
+		if _, ok := desired[name]; !ok {
+			statements = append(statements, fmt.Sprintf(alterDatabaseResetSQL, databaseName, name))
+		}
+	}
+	for name, value := range desired {
+		if currentValue, ok := current[name]; !ok || currentValue != strings.Trim(value, "'") {
+			statements = append(statements, fmt.Sprintf(alterDatabaseSetSQL, databaseName, name, users.QuoteParameterValue(name, value)))
+		}
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+	sort.Strings(statements)
+
+	c.logger.Infof("altering parameters of database %q", databaseName)
+	for _, statement := range statements {
+		if _, err := c.pgDb.Exec(statement); err != nil {
+			return fmt.Errorf("could not alter parameters of database %q: %v", databaseName, err)
+		}
+	}
+	return nil
+}
+
+// setParametersStatus records the databases and roles whose parameters are set from the manifest
+func (c *Cluster) setParametersStatus(databases, roles []string) error {
+	if len(databases) == 0 {
+		databases = nil
+	}
+	if len(roles) == 0 {
+		roles = nil
+	}
+	if reflect.DeepEqual(databases, c.Status.ParameterDatabases) && reflect.DeepEqual(roles, c.Status.ParameterRoles) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDParametersStatus(c.clusterName(), databases, roles); err != nil {
+		return err
+	}
+	c.Status.ParameterDatabases = databases
+	c.Status.ParameterRoles = roles
+	return nil
+}
+
 // executeCreateDatabase creates new database with the given owner.
 // The caller is responsible for opening and closing the database connection.
 func (c *Cluster) executeCreateDatabase(databaseName, owner string) error {
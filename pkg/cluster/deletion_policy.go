@@ -0,0 +1,428 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+)
+
+const (
+	countDatabaseConnectionsSQL     = `SELECT count(*) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid();`
+	countRoleConnectionsSQL         = `SELECT count(*) FROM pg_stat_activity WHERE usename = $1 AND pid <> pg_backend_pid();`
+	terminateDatabaseConnectionsSQL = `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = $1 AND pid <> pg_backend_pid();`
+	terminateRoleConnectionsSQL     = `SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1 AND pid <> pg_backend_pid();`
+	databaseExistsSQL               = `SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1);`
+	roleExistsSQL                   = `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1);`
+
+	// a connection limit does not apply to superusers, hence the final dump can still connect
+	blockDatabaseSQL   = `ALTER DATABASE "%s" CONNECTION LIMIT 0;`
+	unblockDatabaseSQL = `ALTER DATABASE "%s" CONNECTION LIMIT -1;`
+	blockRoleSQL       = `ALTER ROLE "%s" NOLOGIN;`
+
+	dropDatabaseSQL   = `DROP DATABASE "%s";`
+	renameDatabaseSQL = `ALTER DATABASE "%s" RENAME TO "%s";`
+	dropRoleSQL       = `DROP ROLE "%s";`
+	renameRoleSQL     = `ALTER ROLE "%s" NOLOGIN; ALTER ROLE "%s" RENAME TO "%s";`
+
+	// env var of the logical backup image to dump a single database
+	finalDumpDatabaseEnvVar = "LOGICAL_BACKUP_DATABASE"
+)
+
+// deletionPolicyOf returns the policy for databases or roles removed from the manifest
+func (c *Cluster) deletionPolicyOf(kind string) string {
+	policy := ""
+	if c.Spec.DeletionPolicy != nil {
+		if kind == cpov1.PendingDeletionDatabase {
+			policy = c.Spec.DeletionPolicy.Databases
+		} else {
+			policy = c.Spec.DeletionPolicy.Roles
+		}
+	}
+	if policy == "" {
+		return cpov1.DeletionPolicyRetain
+	}
+	return policy
+}
+
+// registerRemovedObjects remembers the databases and roles which were removed from the manifest
+// in the status of the cluster, unless the deletion policy retains them. Without an old spec, e.g.
+// during a sync, the removals are found with the databases and roles recorded in the status.
+func (c *Cluster) registerRemovedObjects(oldSpec, newSpec *cpov1.PostgresSpec) error {
+	pending := append([]cpov1.PendingDeletion{}, c.Status.PendingDeletions...)
+	removedAt := metav1.Now().Rfc3339Copy()
+
+	register := func(kind string, known []string, defined map[string]bool) []string {
+		if c.deletionPolicyOf(kind) == cpov1.DeletionPolicyRetain {
+			return nil
+		}
+		for _, name := range removedNames(known, defined) {
+			if findPendingDeletion(pending, kind, name) >= 0 {
+				continue
+			}
+			c.logger.Infof("%s %q was removed from the manifest and will be deleted according to the deletion policy", kind, name)
+			pending = append(pending, cpov1.PendingDeletion{Kind: kind, Name: name, RemovedAt: removedAt})
+		}
+		return sortedNames(defined)
+	}
+
+	knownDatabases, knownRoles := c.Status.ManagedDatabases, c.Status.ManagedRoles
+	if oldSpec != nil {
+		knownDatabases = append(append([]string{}, knownDatabases...), sortedNames(keySet(oldSpec.Databases))...)
+		knownRoles = append(append([]string{}, knownRoles...), sortedNames(keySet(oldSpec.Users))...)
+	}
+	databases := register(cpov1.PendingDeletionDatabase, knownDatabases, keySet(newSpec.Databases))
+	roles := register(cpov1.PendingDeletionRole, knownRoles, keySet(newSpec.Users))
+
+	return c.setDeletionStatus(pending, databases, roles)
+}
+
+// syncPendingDeletions drops or renames the databases and roles removed from the manifest once
+// the delay of the deletion policy has passed. Databases are handled first, as they can be owned
+// by the roles waiting for their deletion.
+func (c *Cluster) syncPendingDeletions() error {
+	if len(c.Status.PendingDeletions) == 0 {
+		return nil
+	}
+	c.setProcessName("syncing pending deletions")
+	errors := make([]string, 0)
+
+	delay := cpov1.DefaultDeletionDelay
+	if c.Spec.DeletionPolicy != nil {
+		var err error
+		if delay, err = c.Spec.DeletionPolicy.DelayDuration(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+
+	deletions := append([]cpov1.PendingDeletion{}, c.Status.PendingDeletions...)
+	sort.SliceStable(deletions, func(i, j int) bool {
+		return deletions[i].Kind == cpov1.PendingDeletionDatabase && deletions[j].Kind != cpov1.PendingDeletionDatabase
+	})
+
+	pending := make([]cpov1.PendingDeletion, 0)
+	for _, deletion := range deletions {
+		done, err := c.processPendingDeletion(&deletion, delay)
+		if err != nil {
+			deletion.Message = err.Error()
+			errors = append(errors, fmt.Sprintf("could not delete %s %q: %v", deletion.Kind, deletion.Name, err))
+		}
+		if !done {
+			pending = append(pending, deletion)
+		}
+	}
+
+	if err := c.setPendingDeletionsStatus(pending); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("error(s) while syncing pending deletions: %v", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// processPendingDeletion drops or renames a database or role when it is due. New connections are
+// blocked first, then the open sessions are awaited or terminated, a dropped database is dumped
+// and only then deleted. It reports whether the deletion is finished, i.e. it can be removed from
+// the status.
+func (c *Cluster) processPendingDeletion(deletion *cpov1.PendingDeletion, delay time.Duration) (bool, error) {
+	policy := c.deletionPolicyOf(deletion.Kind)
+	if policy == cpov1.DeletionPolicyRetain || c.stillDefined(deletion.Kind, deletion.Name) {
+		c.logger.Infof("cancelling deletion of %s %q", deletion.Kind, deletion.Name)
+		return true, c.cancelPendingDeletion(deletion)
+	}
+
+	dueAt := deletion.RemovedAt.Add(delay)
+	if time.Now().Before(dueAt) {
+		deletion.Message = fmt.Sprintf("%s at %s", policy, dueAt.UTC().Format(time.RFC3339))
+		return false, nil
+	}
+
+	existsSQL, blockSQL := databaseExistsSQL, blockDatabaseSQL
+	if deletion.Kind == cpov1.PendingDeletionRole {
+		existsSQL, blockSQL = roleExistsSQL, blockRoleSQL
+	}
+	var exists bool
+	if err := c.pgDb.QueryRow(existsSQL, deletion.Name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("could not check if %s exists: %v", deletion.Kind, err)
+	}
+	if !exists {
+		c.logger.Infof("%s %q does not exist anymore", deletion.Kind, deletion.Name)
+		return true, c.deleteFinalDumpJob(deletion)
+	}
+
+	if !deletion.Blocked {
+		if _, err := c.pgDb.Exec(fmt.Sprintf(blockSQL, deletion.Name)); err != nil {
+			return false, fmt.Errorf("could not block new connections: %v", err)
+		}
+		c.logger.Infof("blocked new connections of %s %q", deletion.Kind, deletion.Name)
+		deletion.Blocked = true
+	}
+
+	// a dropped database is dumped when the cluster has logical backups. The sessions are closed
+	// before the dump job starts, the job itself connects as superuser.
+	dump := deletion.Kind == cpov1.PendingDeletionDatabase && policy == cpov1.DeletionPolicyDrop && c.Spec.EnableLogicalBackup
+	if !dump || deletion.BackupJob == "" {
+		if closed, err := c.closeConnections(deletion); err != nil || !closed {
+			return false, err
+		}
+	}
+	if dump {
+		if dumped, err := c.finalDump(deletion); err != nil || !dumped {
+			return false, err
+		}
+		if closed, err := c.closeConnections(deletion); err != nil || !closed {
+			return false, err
+		}
+	}
+
+	newName := deletion.Name + c.OpConfig.RoleDeletionSuffix
+	var statement string
+	switch {
+	case deletion.Kind == cpov1.PendingDeletionDatabase && policy == cpov1.DeletionPolicyDrop:
+		statement = fmt.Sprintf(dropDatabaseSQL, deletion.Name)
+	case deletion.Kind == cpov1.PendingDeletionDatabase:
+		statement = fmt.Sprintf(renameDatabaseSQL, deletion.Name, newName)
+	case policy == cpov1.DeletionPolicyDrop:
+		statement = fmt.Sprintf(dropRoleSQL, deletion.Name)
+	default:
+		statement = fmt.Sprintf(renameRoleSQL, deletion.Name, deletion.Name, newName)
+	}
+	if _, err := c.pgDb.Exec(statement); err != nil {
+		return false, err
+	}
+
+	if policy == cpov1.DeletionPolicyDrop {
+		c.logger.Infof("dropped %s %q", deletion.Kind, deletion.Name)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "Deleted", "Dropped %s %q removed from the manifest", deletion.Kind, deletion.Name)
+	} else {
+		c.logger.Infof("renamed %s %q to %q", deletion.Kind, deletion.Name, newName)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "Deleted", "Renamed %s %q removed from the manifest to %q", deletion.Kind, deletion.Name, newName)
+	}
+	return true, c.deleteFinalDumpJob(deletion)
+}
+
+// closeConnections waits for the sessions of a database or role to end, or terminates them with
+// the force option of the deletion policy. It reports whether no session is left.
+func (c *Cluster) closeConnections(deletion *cpov1.PendingDeletion) (bool, error) {
+	countSQL, terminateSQL := countDatabaseConnectionsSQL, terminateDatabaseConnectionsSQL
+	if deletion.Kind == cpov1.PendingDeletionRole {
+		countSQL, terminateSQL = countRoleConnectionsSQL, terminateRoleConnectionsSQL
+	}
+
+	var connections int
+	if err := c.pgDb.QueryRow(countSQL, deletion.Name).Scan(&connections); err != nil {
+		return false, fmt.Errorf("could not count connections: %v", err)
+	}
+	if connections == 0 {
+		return true, nil
+	}
+	if c.Spec.DeletionPolicy == nil || !c.Spec.DeletionPolicy.Force {
+		deletion.Message = fmt.Sprintf("waiting for %d active connection(s) to close", connections)
+		return false, nil
+	}
+	c.logger.Warningf("terminating %d connection(s) of %s %q", connections, deletion.Kind, deletion.Name)
+	if _, err := c.pgDb.Exec(terminateSQL, deletion.Name); err != nil {
+		return false, fmt.Errorf("could not terminate connections: %v", err)
+	}
+	return true, nil
+}
+
+// cancelPendingDeletion allows connections again to a database or role, which is wanted again
+// after its connections were already blocked. The login of a role is restored by the role sync.
+func (c *Cluster) cancelPendingDeletion(deletion *cpov1.PendingDeletion) error {
+	if deletion.Blocked && deletion.Kind == cpov1.PendingDeletionDatabase {
+		if _, err := c.pgDb.Exec(fmt.Sprintf(unblockDatabaseSQL, deletion.Name)); err != nil {
+			return fmt.Errorf("could not allow connections again: %v", err)
+		}
+		c.logger.Infof("allowed connections of %s %q again", deletion.Kind, deletion.Name)
+	}
+	return c.deleteFinalDumpJob(deletion)
+}
+
+// stillDefined reports whether a database or role is wanted again, e.g. after it was added back
+// to the manifest or is defined by other means
+func (c *Cluster) stillDefined(kind, name string) bool {
+	if kind == cpov1.PendingDeletionDatabase {
+		_, isDatabase := c.Spec.Databases[name]
+		_, isPreparedDatabase := c.Spec.PreparedDatabases[name]
+		_, isSelfServiceDatabase := c.selfServiceDbs[name]
+		return isDatabase || isPreparedDatabase || isSelfServiceDatabase || util.SliceContains(reservedDatabaseNames, name)
+	}
+	_, isUser := c.Spec.Users[name]
+	_, isPgUser := c.pgUsers[name]
+	return isUser || isPgUser || c.isProtectedUsername(name) || c.isSystemUsername(name)
+}
+
+// finalDump runs a one-off job of the logical backup image, which dumps the database before it is
+// dropped. It reports whether the dump has finished successfully. The job is kept until the
+// database is dropped, so a later sync does not start the dump over.
+func (c *Cluster) finalDump(deletion *cpov1.PendingDeletion) (bool, error) {
+	jobs := c.KubeClient.Jobs(c.Namespace)
+	if deletion.BackupJob == "" {
+		job, err := c.generateFinalDumpJob(deletion.Name)
+		if err != nil {
+			return false, err
+		}
+		if _, err := jobs.Create(context.TODO(), job, metav1.CreateOptions{}); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("could not create final dump job: %v", err)
+		}
+		c.logger.Infof("started job %q for the final dump of database %q", job.Name, deletion.Name)
+		deletion.BackupJob = job.Name
+		deletion.Message = fmt.Sprintf("waiting for final dump job %q", job.Name)
+		return false, nil
+	}
+
+	job, err := jobs.Get(context.TODO(), deletion.BackupJob, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		// the job was removed, e.g. after a failure, start over with a new one
+		deletion.BackupJob = ""
+		deletion.Message = ""
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not get final dump job %q: %v", deletion.BackupJob, err)
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == v1.ConditionTrue {
+			return false, fmt.Errorf("final dump job %q failed, delete the job to retry", job.Name)
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		deletion.Message = fmt.Sprintf("waiting for final dump job %q", job.Name)
+		return false, nil
+	}
+	return true, nil
+}
+
+// deleteFinalDumpJob removes the final dump job of a finished or cancelled deletion
+func (c *Cluster) deleteFinalDumpJob(deletion *cpov1.PendingDeletion) error {
+	if deletion.BackupJob == "" {
+		return nil
+	}
+	propagationPolicy := metav1.DeletePropagationBackground
+	err := c.KubeClient.Jobs(c.Namespace).Delete(context.TODO(), deletion.BackupJob, metav1.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		c.logger.Warningf("could not delete final dump job %q: %v", deletion.BackupJob, err)
+	}
+	deletion.BackupJob = ""
+	return nil
+}
+
+// generateFinalDumpJob derives a job from the logical backup cron job, which dumps a single database
+func (c *Cluster) generateFinalDumpJob(database string) (*batchv1.Job, error) {
+	cronJob, err := c.generateLogicalBackupJob()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate final dump job: %v", err)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        c.getFinalDumpJobName(database),
+			Namespace:   c.Namespace,
+			Labels:      cronJob.Labels,
+			Annotations: cronJob.Annotations,
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+	containers := job.Spec.Template.Spec.Containers
+	containers[0].Env = append(containers[0].Env, v1.EnvVar{Name: finalDumpDatabaseEnvVar, Value: database})
+
+	return job, nil
+}
+
+// getFinalDumpJobName returns a valid job name for any database name
+func (c *Cluster) getFinalDumpJobName(database string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(database))
+	return fmt.Sprintf("%s-%08x", trimCronjobName(c.getLogicalBackupJobName()+"-final"), hash.Sum32())
+}
+
+func (c *Cluster) setPendingDeletionsStatus(pending []cpov1.PendingDeletion) error {
+	return c.setDeletionStatus(pending, c.Status.ManagedDatabases, c.Status.ManagedRoles)
+}
+
+// setDeletionStatus records the pending deletions and the databases and roles of the manifest,
+// which are subject to the deletion policy
+func (c *Cluster) setDeletionStatus(pending []cpov1.PendingDeletion, databases, roles []string) error {
+	if len(pending) == 0 {
+		pending = nil
+	}
+	if len(databases) == 0 {
+		databases = nil
+	}
+	if len(roles) == 0 {
+		roles = nil
+	}
+	if reflect.DeepEqual(pending, c.Status.PendingDeletions) &&
+		reflect.DeepEqual(databases, c.Status.ManagedDatabases) &&
+		reflect.DeepEqual(roles, c.Status.ManagedRoles) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDPendingDeletionsStatus(c.clusterName(), pending, databases, roles); err != nil {
+		return err
+	}
+	c.Status.PendingDeletions = pending
+	c.Status.ManagedDatabases = databases
+	c.Status.ManagedRoles = roles
+	return nil
+}
+
+func findPendingDeletion(pending []cpov1.PendingDeletion, kind, name string) int {
+	for i, deletion := range pending {
+		if deletion.Kind == kind && deletion.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// keySet returns the keys of a map as a set
+func keySet[T any](m map[string]T) map[string]bool {
+	keys := make(map[string]bool, len(m))
+	for key := range m {
+		keys[key] = true
+	}
+	return keys
+}
+
+// sortedNames returns the sorted names of a set
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// removedNames returns the sorted and distinct names which are known but not defined anymore
+func removedNames(known []string, defined map[string]bool) []string {
+	removed := make(map[string]bool)
+	for _, name := range known {
+		if !defined[name] {
+			removed[name] = true
+		}
+	}
+	return sortedNames(removed)
+}
@@ -0,0 +1,246 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+)
+
+func deletionPolicyTestSpec(policy *cpov1.DeletionPolicy) cpov1.PostgresSpec {
+	return cpov1.PostgresSpec{
+		Users:          map[string]cpov1.UserDefinition{"app": {}},
+		Databases:      map[string]cpov1.DatabaseDefinition{"shop": {Owner: "app"}},
+		DeletionPolicy: policy,
+	}
+}
+
+var deletionPolicyTestConfig = Config{
+	OpConfig: config.Config{
+		ProtectedRoles: []string{"admin"},
+		Auth: config.Auth{
+			SuperUsername:       superUserName,
+			ReplicationUsername: replicationUserName,
+		},
+		LogicalBackup: config.LogicalBackup{
+			LogicalBackupJobPrefix: "logical-backup-",
+			LogicalBackupSchedule:  "30 00 * * *",
+		},
+		Resources: config.Resources{
+			DefaultCPURequest:    "100m",
+			DefaultCPULimit:      "1",
+			DefaultMemoryRequest: "100Mi",
+			DefaultMemoryLimit:   "500Mi",
+		},
+	},
+}
+
+func TestRegisterRemovedObjects(t *testing.T) {
+	removedAt := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	oldSpec := cpov1.PostgresSpec{
+		Users:     map[string]cpov1.UserDefinition{"app": {}, "legacy": {}, "reporting": {}},
+		Databases: map[string]cpov1.DatabaseDefinition{"shop": {Owner: "app"}, "archive": {Owner: "legacy"}, "crm": {Owner: "app"}},
+	}
+	newSpec := cpov1.PostgresSpec{
+		Users:     map[string]cpov1.UserDefinition{"app": {}},
+		Databases: map[string]cpov1.DatabaseDefinition{"shop": {Owner: "app"}},
+	}
+
+	tests := []struct {
+		subTest  string
+		policy   *cpov1.DeletionPolicy
+		pending  []cpov1.PendingDeletion
+		expected []string
+	}{
+		{
+			subTest:  "no deletion policy retains everything",
+			policy:   nil,
+			expected: nil,
+		},
+		{
+			subTest:  "only databases are deleted",
+			policy:   &cpov1.DeletionPolicy{Databases: cpov1.DeletionPolicyDrop},
+			expected: []string{"database/archive", "database/crm"},
+		},
+		{
+			subTest:  "databases and roles are deleted",
+			policy:   &cpov1.DeletionPolicy{Databases: cpov1.DeletionPolicyDrop, Roles: cpov1.DeletionPolicyRename},
+			expected: []string{"database/archive", "database/crm", "role/legacy", "role/reporting"},
+		},
+		{
+			subTest: "already pending deletions are kept",
+			policy:  &cpov1.DeletionPolicy{Roles: cpov1.DeletionPolicyDrop},
+			pending: []cpov1.PendingDeletion{
+				{Kind: cpov1.PendingDeletionRole, Name: "legacy", RemovedAt: removedAt},
+			},
+			expected: []string{"role/legacy", "role/reporting"},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, deletionPolicyTestSpec(tt.policy), deletionPolicyTestConfig)
+		cluster.Status.PendingDeletions = tt.pending
+		if err := cluster.registerRemovedObjects(&oldSpec, &newSpec); err != nil {
+			t.Fatalf("%s [%s]: could not register removed objects: %v", t.Name(), tt.subTest, err)
+		}
+
+		var registered []string
+		for _, deletion := range cluster.Status.PendingDeletions {
+			registered = append(registered, deletion.Kind+"/"+deletion.Name)
+		}
+		if !reflect.DeepEqual(registered, tt.expected) {
+			t.Errorf("%s [%s]: expected pending deletions %v, got %v", t.Name(), tt.subTest, tt.expected, registered)
+		}
+		if len(tt.pending) > 0 && !cluster.Status.PendingDeletions[0].RemovedAt.Equal(&removedAt) {
+			t.Errorf("%s [%s]: expected removal time of a pending deletion to be kept", t.Name(), tt.subTest)
+		}
+
+		pg, err := cluster.KubeClient.Postgresqls("default").Get(context.TODO(), cluster.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s [%s]: could not get postgresql: %v", t.Name(), tt.subTest, err)
+		}
+		inSync := len(pg.Status.PendingDeletions) == len(cluster.Status.PendingDeletions)
+		for i := 0; inSync && i < len(pg.Status.PendingDeletions); i++ {
+			stored, registered := pg.Status.PendingDeletions[i], cluster.Status.PendingDeletions[i]
+			inSync = stored.Kind == registered.Kind && stored.Name == registered.Name && stored.RemovedAt.Equal(&registered.RemovedAt)
+		}
+		if !inSync {
+			t.Errorf("%s [%s]: status of the resource %v not in sync with the cluster %v", t.Name(), tt.subTest, pg.Status.PendingDeletions, cluster.Status.PendingDeletions)
+		}
+	}
+}
+
+func TestRegisterRemovedObjectsDuringSync(t *testing.T) {
+	cluster := newTestCluster(t, deletionPolicyTestSpec(&cpov1.DeletionPolicy{Databases: cpov1.DeletionPolicyDrop, Roles: cpov1.DeletionPolicyDrop}), deletionPolicyTestConfig)
+
+	// the first sync only records the databases and roles of the manifest
+	if err := cluster.registerRemovedObjects(nil, &cluster.Spec); err != nil {
+		t.Fatalf("%s: could not register removed objects: %v", t.Name(), err)
+	}
+	if len(cluster.Status.PendingDeletions) > 0 {
+		t.Errorf("%s: expected no pending deletions, got %v", t.Name(), cluster.Status.PendingDeletions)
+	}
+	if !reflect.DeepEqual(cluster.Status.ManagedDatabases, []string{"shop"}) || !reflect.DeepEqual(cluster.Status.ManagedRoles, []string{"app"}) {
+		t.Errorf("%s: expected managed database shop and role app, got %v and %v", t.Name(), cluster.Status.ManagedDatabases, cluster.Status.ManagedRoles)
+	}
+
+	// a removal missed by the update is found by the next sync
+	newSpec := cpov1.PostgresSpec{DeletionPolicy: cluster.Spec.DeletionPolicy}
+	if err := cluster.registerRemovedObjects(nil, &newSpec); err != nil {
+		t.Fatalf("%s: could not register removed objects: %v", t.Name(), err)
+	}
+	var registered []string
+	for _, deletion := range cluster.Status.PendingDeletions {
+		registered = append(registered, deletion.Kind+"/"+deletion.Name)
+	}
+	if expected := []string{"database/shop", "role/app"}; !reflect.DeepEqual(registered, expected) {
+		t.Errorf("%s: expected pending deletions %v, got %v", t.Name(), expected, registered)
+	}
+	if cluster.Status.ManagedDatabases != nil || cluster.Status.ManagedRoles != nil {
+		t.Errorf("%s: expected no managed databases and roles, got %v and %v", t.Name(), cluster.Status.ManagedDatabases, cluster.Status.ManagedRoles)
+	}
+
+	pg, err := cluster.KubeClient.Postgresqls("default").Get(context.TODO(), cluster.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get postgresql: %v", t.Name(), err)
+	}
+	if len(pg.Status.PendingDeletions) != 2 || pg.Status.ManagedDatabases != nil || pg.Status.ManagedRoles != nil {
+		t.Errorf("%s: status of the resource not in sync with the cluster: %#v", t.Name(), pg.Status)
+	}
+}
+
+func TestStillDefined(t *testing.T) {
+	cluster := newTestCluster(t, deletionPolicyTestSpec(&cpov1.DeletionPolicy{Databases: cpov1.DeletionPolicyDrop, Roles: cpov1.DeletionPolicyDrop}), deletionPolicyTestConfig)
+	if err := cluster.initUsers(); err != nil {
+		t.Fatalf("could not init users: %v", err)
+	}
+
+	tests := []struct {
+		kind     string
+		name     string
+		expected bool
+	}{
+		{cpov1.PendingDeletionDatabase, "shop", true},
+		{cpov1.PendingDeletionDatabase, "postgres", true},
+		{cpov1.PendingDeletionDatabase, "archive", false},
+		{cpov1.PendingDeletionRole, "app", true},
+		{cpov1.PendingDeletionRole, "admin", true},
+		{cpov1.PendingDeletionRole, superUserName, true},
+		{cpov1.PendingDeletionRole, "legacy", false},
+	}
+	for _, tt := range tests {
+		if defined := cluster.stillDefined(tt.kind, tt.name); defined != tt.expected {
+			t.Errorf("%s: expected %s %q to be defined: %v, got %v", t.Name(), tt.kind, tt.name, tt.expected, defined)
+		}
+	}
+}
+
+func TestFinalDump(t *testing.T) {
+	cluster := newTestCluster(t, deletionPolicyTestSpec(&cpov1.DeletionPolicy{Databases: cpov1.DeletionPolicyDrop}), deletionPolicyTestConfig)
+	jobs := cluster.KubeClient.Jobs("default")
+	deletion := cpov1.PendingDeletion{Kind: cpov1.PendingDeletionDatabase, Name: "archive"}
+
+	// first run starts the job
+	if done, err := cluster.finalDump(&deletion); done || err != nil {
+		t.Fatalf("%s: expected the final dump to be started, got done: %v, err: %v", t.Name(), done, err)
+	}
+	job, err := jobs.Get(context.TODO(), deletion.BackupJob, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get final dump job: %v", t.Name(), err)
+	}
+	if len(job.Name) > 63 || job.Name != cluster.getFinalDumpJobName("archive") {
+		t.Errorf("%s: unexpected job name %q", t.Name(), job.Name)
+	}
+	env := job.Spec.Template.Spec.Containers[0].Env
+	if env[len(env)-1] != (v1.EnvVar{Name: finalDumpDatabaseEnvVar, Value: "archive"}) {
+		t.Errorf("%s: expected database of the final dump in the environment, got %v", t.Name(), env[len(env)-1])
+	}
+
+	// job still running
+	if done, err := cluster.finalDump(&deletion); done || err != nil {
+		t.Errorf("%s: expected to wait for the final dump, got done: %v, err: %v", t.Name(), done, err)
+	}
+
+	// job failed
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: v1.ConditionTrue}}
+	if _, err := jobs.UpdateStatus(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("%s: could not update job: %v", t.Name(), err)
+	}
+	if done, err := cluster.finalDump(&deletion); done || err == nil {
+		t.Errorf("%s: expected an error for the failed final dump, got done: %v, err: %v", t.Name(), done, err)
+	}
+
+	// job succeeded
+	job.Status.Conditions = nil
+	job.Status.Succeeded = 1
+	if _, err := jobs.UpdateStatus(context.TODO(), job, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("%s: could not update job: %v", t.Name(), err)
+	}
+	if done, err := cluster.finalDump(&deletion); !done || err != nil {
+		t.Errorf("%s: expected the final dump to be done, got done: %v, err: %v", t.Name(), done, err)
+	}
+
+	// the finished job is kept until the database is dropped
+	if done, err := cluster.finalDump(&deletion); !done || err != nil {
+		t.Errorf("%s: expected the final dump to stay done, got done: %v, err: %v", t.Name(), done, err)
+	}
+	if err := cluster.deleteFinalDumpJob(&deletion); err != nil {
+		t.Fatalf("%s: could not delete final dump job: %v", t.Name(), err)
+	}
+	if _, err := jobs.Get(context.TODO(), job.Name, metav1.GetOptions{}); err == nil {
+		t.Errorf("%s: expected the final dump job to be deleted", t.Name())
+	}
+	deletion.BackupJob = job.Name
+
+	// a removed job is started again
+	if done, err := cluster.finalDump(&deletion); done || err != nil || deletion.BackupJob != "" {
+		t.Errorf("%s: expected the final dump to start over, got done: %v, err: %v, job: %q", t.Name(), done, err, deletion.BackupJob)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -17,6 +18,12 @@ import (
 
 //ExecCommand executes arbitrary command inside the pod
 func (c *Cluster) ExecCommand(podName *spec.NamespacedName, command ...string) (string, error) {
+	return c.ExecCommandWithStdin(podName, nil, command...)
+}
+
+// ExecCommandWithStdin executes a command inside the pod and passes stdin to it, which keeps
+// secrets out of the command line of the process
+func (c *Cluster) ExecCommandWithStdin(podName *spec.NamespacedName, stdin io.Reader, command ...string) (string, error) {
 	c.setProcessName("executing command %q", strings.Join(command, " "))
 
 	var (
@@ -50,6 +57,7 @@ func (c *Cluster) ExecCommand(podName *spec.NamespacedName, command ...string) (
 	req.VersionedParams(&v1.PodExecOptions{
 		Container: pod.Spec.Containers[targetContainer].Name,
 		Command:   command,
+		Stdin:     stdin != nil,
 		Stdout:    true,
 		Stderr:    true,
 	}, scheme.ParameterCodec)
@@ -60,6 +68,7 @@ func (c *Cluster) ExecCommand(podName *spec.NamespacedName, command ...string) (
 	}
 
 	err = exec.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
 		Stdout: &execOut,
 		Stderr: &execErr,
 		Tty:    false,
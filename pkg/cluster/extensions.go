@@ -0,0 +1,248 @@
+package cluster
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+)
+
+const (
+	getAvailableExtensionsSQL = `SELECT a.name, COALESCE(a.default_version, ''), COALESCE(e.extversion, ''), COALESCE(n.nspname, ''),
+			ARRAY(SELECT v.version FROM pg_catalog.pg_available_extension_versions v WHERE v.name = a.name ORDER BY 1)
+		FROM pg_catalog.pg_available_extensions a
+		LEFT JOIN pg_catalog.pg_extension e ON e.extname = a.name
+		LEFT JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace;`
+
+	createExtensionIfNotExistsSQL = `CREATE EXTENSION IF NOT EXISTS "%s"`
+	updateExtensionSQL            = `ALTER EXTENSION "%s" UPDATE`
+	dropExtensionSQL              = `DROP EXTENSION IF EXISTS "%s"`
+)
+
+// availableExtension describes an extension shipped with the image and its installation in a database
+type availableExtension struct {
+	defaultVersion   string
+	installedVersion string
+	schema           string
+	versions         []string
+}
+
+// getAvailableExtensions returns the extensions available in the image, including the installed ones
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getAvailableExtensions() (extensions map[string]availableExtension, err error) {
+	var (
+		rows *sql.Rows
+	)
+
+	if rows, err = c.pgDb.Query(getAvailableExtensionsSQL); err != nil {
+		return nil, fmt.Errorf("could not query available extensions: %v", err)
+	}
+
+	defer func() {
+		if err2 := rows.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("error when closing query cursor: %v, previous error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("error when closing query cursor: %v", err2)
+			}
+		}
+	}()
+
+	extensions = make(map[string]availableExtension)
+
+	for rows.Next() {
+		var (
+			name      string
+			extension availableExtension
+		)
+
+		if err = rows.Scan(&name, &extension.defaultVersion, &extension.installedVersion, &extension.schema,
+			pq.Array(&extension.versions)); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		extensions[name] = extension
+	}
+
+	return extensions, err
+}
+
+// extensionStatements returns the statements which bring an extension into the desired state. An
+// extension without a pinned version is updated to the default version of the image.
+func extensionStatements(name string, desired cpov1.DatabaseExtension, available map[string]availableExtension) ([]string, error) {
+	current, isAvailable := available[name]
+	installed := isAvailable && current.installedVersion != ""
+
+	if desired.State == cpov1.ExtensionStateAbsent {
+		if installed {
+			return []string{fmt.Sprintf(dropExtensionSQL, name)}, nil
+		}
+		return nil, nil
+	}
+
+	if !isAvailable {
+		return nil, fmt.Errorf("extension %q is not available in the image", name)
+	}
+	if desired.Version != "" && !containsVersion(current.versions, desired.Version) {
+		return nil, fmt.Errorf("version %q of extension %q is not available in the image, available versions: %s",
+			desired.Version, name, strings.Join(current.versions, ", "))
+	}
+
+	if !installed {
+		statement := fmt.Sprintf(createExtensionIfNotExistsSQL, name)
+		if desired.Schema != "" {
+			statement += fmt.Sprintf(` SCHEMA "%s"`, desired.Schema)
+		}
+		if desired.Version != "" {
+			statement += fmt.Sprintf(` VERSION '%s'`, desired.Version)
+		}
+		return []string{statement}, nil
+	}
+
+	statements := make([]string, 0)
+	if desired.Schema != "" && desired.Schema != current.schema {
+		statements = append(statements, fmt.Sprintf(alterExtensionSQL, name, desired.Schema))
+	}
+	if desired.Version != "" && desired.Version != current.installedVersion {
+		statements = append(statements, fmt.Sprintf(updateExtensionSQL+` TO '%s'`, name, desired.Version))
+	} else if desired.Version == "" && current.defaultVersion != "" && current.defaultVersion != current.installedVersion {
+		statements = append(statements, fmt.Sprintf(updateExtensionSQL, name))
+	}
+	return statements, nil
+}
+
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDatabaseExtensions installs, updates and drops the extensions listed in databaseExtensions and
+// reports their versions in the status
+func (c *Cluster) syncDatabaseExtensions() error {
+	c.setProcessName("syncing database extensions")
+	if len(c.Spec.DatabaseExtensions) == 0 {
+		return c.setExtensionsStatus(nil)
+	}
+	errors := make([]string, 0)
+
+	if err := c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	currentDatabases, err := c.getDatabases()
+	if err := c.closeDbConn(); err != nil {
+		c.logger.Errorf("could not close database connection: %v", err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get current databases: %v", err)
+	}
+
+	databases := make([]string, 0, len(c.Spec.DatabaseExtensions))
+	for database := range c.Spec.DatabaseExtensions {
+		databases = append(databases, database)
+	}
+	sort.Strings(databases)
+
+	status := make([]cpov1.ExtensionStatus, 0)
+	for _, database := range databases {
+		if _, exists := currentDatabases[database]; !exists {
+			c.logger.Warningf("skipping extensions of database %q, which does not exist", database)
+			continue
+		}
+		if err := c.initDbConnWithName(database); err != nil {
+			errors = append(errors, fmt.Sprintf("could not init connection to database %s: %v", database, err))
+			continue
+		}
+		databaseStatus, err := c.syncExtensionsOfDatabase(database, c.Spec.DatabaseExtensions[database])
+		if err != nil {
+			errors = append(errors, err.Error())
+		}
+		status = append(status, databaseStatus...)
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}
+
+	if err := c.setExtensionsStatus(status); err != nil {
+		errors = append(errors, fmt.Sprintf("could not update status of extensions: %v", err))
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("error(s) while syncing database extensions: %v", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// syncExtensionsOfDatabase applies the desired state of the extensions to the connected database
+// and returns their versions afterwards
+func (c *Cluster) syncExtensionsOfDatabase(database string, extensions cpov1.Extensions) ([]cpov1.ExtensionStatus, error) {
+	errors := make([]string, 0)
+
+	available, err := c.getAvailableExtensions()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changed := false
+	for _, name := range names {
+		statements, err := extensionStatements(name, extensions[name], available)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("database %s: %v", database, err))
+			continue
+		}
+		for _, statement := range statements {
+			c.logger.Infof("executing %q in database %q", statement, database)
+			if _, err := c.pgDb.Exec(statement); err != nil {
+				errors = append(errors, fmt.Sprintf("could not execute %q in database %s: %v", statement, database, err))
+				break
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		if available, err = c.getAvailableExtensions(); err != nil {
+			return nil, err
+		}
+	}
+	status := make([]cpov1.ExtensionStatus, 0, len(names))
+	for _, name := range names {
+		status = append(status, cpov1.ExtensionStatus{
+			Database:         database,
+			Name:             name,
+			InstalledVersion: available[name].installedVersion,
+			AvailableVersion: available[name].defaultVersion,
+		})
+	}
+
+	if len(errors) > 0 {
+		return status, fmt.Errorf("%s", strings.Join(errors, `', '`))
+	}
+	return status, nil
+}
+
+func (c *Cluster) setExtensionsStatus(status []cpov1.ExtensionStatus) error {
+	if len(status) == 0 {
+		status = nil
+	}
+	if reflect.DeepEqual(status, c.Status.Extensions) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDExtensionsStatus(c.clusterName(), status); err != nil {
+		return err
+	}
+	c.Status.Extensions = status
+	return nil
+}
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+)
+
+func TestExtensionStatements(t *testing.T) {
+	available := map[string]availableExtension{
+		"pg_partman": {defaultVersion: "5.1.0", versions: []string{"4.7.4", "5.0.1", "5.1.0"}},
+		"postgis": {defaultVersion: "3.4.2", installedVersion: "3.4.0", schema: "public",
+			versions: []string{"3.4.0", "3.4.2"}},
+		"pg_stat_statements": {defaultVersion: "1.10", installedVersion: "1.10", schema: "public",
+			versions: []string{"1.9", "1.10"}},
+	}
+
+	tests := []struct {
+		subTest            string
+		name               string
+		desired            cpov1.DatabaseExtension
+		expectedStatements []string
+		expectedError      string
+	}{
+		{
+			subTest:            "create with default version",
+			name:               "pg_partman",
+			desired:            cpov1.DatabaseExtension{},
+			expectedStatements: []string{`CREATE EXTENSION IF NOT EXISTS "pg_partman"`},
+		},
+		{
+			subTest: "create with schema and pinned version",
+			name:    "pg_partman",
+			desired: cpov1.DatabaseExtension{Schema: "partman", Version: "5.0.1", State: cpov1.ExtensionStatePresent},
+			expectedStatements: []string{
+				`CREATE EXTENSION IF NOT EXISTS "pg_partman" SCHEMA "partman" VERSION '5.0.1'`,
+			},
+		},
+		{
+			subTest:            "update to the default version",
+			name:               "postgis",
+			desired:            cpov1.DatabaseExtension{},
+			expectedStatements: []string{`ALTER EXTENSION "postgis" UPDATE`},
+		},
+		{
+			subTest:            "pinned version is already installed",
+			name:               "postgis",
+			desired:            cpov1.DatabaseExtension{Version: "3.4.0"},
+			expectedStatements: []string{},
+		},
+		{
+			subTest: "change schema and downgrade",
+			name:    "pg_stat_statements",
+			desired: cpov1.DatabaseExtension{Schema: "monitoring", Version: "1.9"},
+			expectedStatements: []string{
+				`ALTER EXTENSION "pg_stat_statements" SET SCHEMA "monitoring"`,
+				`ALTER EXTENSION "pg_stat_statements" UPDATE TO '1.9'`,
+			},
+		},
+		{
+			subTest:            "drop installed extension",
+			name:               "postgis",
+			desired:            cpov1.DatabaseExtension{State: cpov1.ExtensionStateAbsent},
+			expectedStatements: []string{`DROP EXTENSION IF EXISTS "postgis"`},
+		},
+		{
+			subTest:            "absent extension is not installed",
+			name:               "pg_partman",
+			desired:            cpov1.DatabaseExtension{State: cpov1.ExtensionStateAbsent},
+			expectedStatements: nil,
+		},
+		{
+			subTest:       "extension is not available",
+			name:          "timescaledb",
+			desired:       cpov1.DatabaseExtension{},
+			expectedError: `extension "timescaledb" is not available in the image`,
+		},
+		{
+			subTest:       "version is not available",
+			name:          "postgis",
+			desired:       cpov1.DatabaseExtension{Version: "3.5.0"},
+			expectedError: `version "3.5.0" of extension "postgis" is not available in the image, available versions: 3.4.0, 3.4.2`,
+		},
+	}
+
+	for _, tt := range tests {
+		statements, err := extensionStatements(tt.name, tt.desired, available)
+		if tt.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("%s: expected error %q, got %v", tt.subTest, tt.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.subTest, err)
+			continue
+		}
+		if !reflect.DeepEqual(statements, tt.expectedStatements) {
+			t.Errorf("%s: expected statements %#v, got %#v", tt.subTest, tt.expectedStatements, statements)
+		}
+	}
+}
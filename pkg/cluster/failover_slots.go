@@ -0,0 +1,267 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+const (
+	// failoverSlotKey marks a logical slot in the Patroni slots section as failover slot
+	failoverSlotKey = "failover"
+	// synchronizedStandbySlotsParameter lists the physical slots logical decoding waits for
+	synchronizedStandbySlotsParameter = "synchronized_standby_slots"
+)
+
+var slotNameRegexp = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// failoverSlotsSupported reports whether logical slots can be synchronized to the standbys, which
+// is available since PostgreSQL 17.
+func (c *Cluster) failoverSlotsSupported() bool {
+	return c.GetDesiredMajorVersionAsInt() >= VersionMap["17"]
+}
+
+// failoverSlots returns the database of every logical slot that has to survive a failover: the
+// slots marked with failover: "true" and the slots of the event streams.
+func (c *Cluster) failoverSlots() map[string]string {
+	slots := make(map[string]string)
+	for name, slot := range c.Spec.Patroni.Slots {
+		if slot["type"] == "logical" && slot[failoverSlotKey] == "true" {
+			slots[name] = slot["database"]
+		}
+	}
+	for _, stream := range c.Spec.Streams {
+		slots[getSlotName(stream.Database, stream.ApplicationId)] = stream.Database
+	}
+	return slots
+}
+
+// addFailoverSlotParameters enables the slot synchronization on the standbys, unless the
+// parameters are set in the manifest.
+func (c *Cluster) addFailoverSlotParameters(parameters map[string]string) {
+	if !c.failoverSlotsSupported() || len(c.failoverSlots()) == 0 {
+		return
+	}
+	for _, parameter := range []string{"sync_replication_slots", "hot_standby_feedback"} {
+		if _, exists := parameters[parameter]; !exists {
+			parameters[parameter] = "on"
+		}
+	}
+}
+
+// physicalSlotName returns the name of the physical slot Patroni creates for a member
+func physicalSlotName(memberName string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(memberName) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case r == '-', r == '.':
+			b.WriteRune('_')
+		default:
+			fmt.Fprintf(&b, "u%04d", r)
+		}
+	}
+	name := b.String()
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
+
+// syncFailoverSlots marks the failover slots on the primary for synchronization, lets logical
+// decoding wait for the standbys and reports the slots which have not been synchronized yet.
+func (c *Cluster) syncFailoverSlots() error {
+	slots := c.failoverSlots()
+	if !c.failoverSlotsSupported() {
+		return c.setMissingFailoverSlots(nil)
+	}
+
+	masterPods, err := c.getRolePods(Master)
+	if err != nil {
+		return err
+	}
+	if len(masterPods) == 0 {
+		c.logger.Debug("no primary found, skipping failover slots")
+		return nil
+	}
+	master := &masterPods[0]
+
+	if len(slots) == 0 {
+		if err := c.setSynchronizedStandbySlots(master, nil); err != nil {
+			return err
+		}
+		return c.setMissingFailoverSlots(nil)
+	}
+
+	members, err := c.patroni.GetClusterMembers(master)
+	if err != nil {
+		return fmt.Errorf("could not get Patroni cluster members: %v", err)
+	}
+
+	primarySlots, err := c.primaryFailoverSlots(master)
+	if err != nil {
+		return err
+	}
+	missing := make(map[string][]string)
+	for _, name := range sortedSlotNames(slots) {
+		failover, exists := primarySlots[name]
+		if !exists {
+			// Patroni creates the slot, it is marked on the next sync
+			missing[master.Name] = append(missing[master.Name], name)
+			continue
+		}
+		if !failover {
+			if err := c.enableSlotFailover(master, name, slots[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	var standbySlots []string
+	for _, member := range members {
+		role := PostgresRole(member.Role)
+		if role == Leader || role == StandbyLeader {
+			continue
+		}
+		if member.State != "streaming" {
+			continue
+		}
+		standbySlots = append(standbySlots, physicalSlotName(member.Name))
+		pod := &v1.Pod{}
+		pod.Name, pod.Namespace = member.Name, master.Namespace
+		synced, err := c.syncedSlots(pod)
+		if err != nil {
+			c.logger.Warningf("could not get synchronized slots of member %q: %v", member.Name, err)
+			continue
+		}
+		for _, name := range sortedSlotNames(slots) {
+			if !synced[name] {
+				missing[member.Name] = append(missing[member.Name], name)
+			}
+		}
+	}
+	sort.Strings(standbySlots)
+	if err := c.setSynchronizedStandbySlots(master, standbySlots); err != nil {
+		return err
+	}
+
+	if len(missing) == 0 {
+		missing = nil
+	}
+	return c.setMissingFailoverSlots(missing)
+}
+
+func sortedSlotNames(slots map[string]string) []string {
+	names := make([]string, 0, len(slots))
+	for name := range slots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// execPsql runs a query in the Postgres container, connecting through the local socket
+func (c *Cluster) execPsql(pod *v1.Pod, user, dbname, query string) (string, error) {
+	podName := util.NameFromMeta(pod.ObjectMeta)
+	return c.ExecCommand(&podName, "psql", "-U", user, "-d", dbname, "-tAX", "-c", query)
+}
+
+func (c *Cluster) primaryFailoverSlots(master *v1.Pod) (map[string]bool, error) {
+	out, err := c.execPsql(master, c.systemUsers[constants.SuperuserKeyName].Name, "postgres",
+		"SELECT slot_name, failover FROM pg_replication_slots WHERE slot_type = 'logical'")
+	if err != nil {
+		return nil, fmt.Errorf("could not get logical slots of the primary: %v", err)
+	}
+	slots := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) == 2 {
+			slots[fields[0]] = fields[1] == "t"
+		}
+	}
+	return slots, nil
+}
+
+// enableSlotFailover marks an existing slot for synchronization, which is only possible through a
+// replication connection.
+func (c *Cluster) enableSlotFailover(master *v1.Pod, name, database string) error {
+	if !slotNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid slot name %q", name)
+	}
+	c.logger.Infof("enabling failover for logical slot %q", name)
+	conninfo := fmt.Sprintf("dbname=%s replication=database", conninfoValue(database))
+	if _, err := c.execPsql(master, c.systemUsers[constants.ReplicationUserKeyName].Name, conninfo,
+		fmt.Sprintf("ALTER_REPLICATION_SLOT %s (FAILOVER true)", name)); err != nil {
+		return fmt.Errorf("could not enable failover for slot %q: %v", name, err)
+	}
+	return nil
+}
+
+func (c *Cluster) syncedSlots(pod *v1.Pod) (map[string]bool, error) {
+	out, err := c.execPsql(pod, c.systemUsers[constants.SuperuserKeyName].Name, "postgres",
+		"SELECT slot_name FROM pg_replication_slots WHERE synced")
+	if err != nil {
+		return nil, err
+	}
+	synced := make(map[string]bool)
+	for _, name := range strings.Fields(out) {
+		synced[name] = true
+	}
+	return synced, nil
+}
+
+// setSynchronizedStandbySlots lets logical decoding wait for the physical slots of the streaming
+// standbys, so that no consumer gets ahead of a standby it may fail over to. The parameter is set
+// in the Patroni dynamic configuration, which survives restarts and is in place on a promoted
+// standby. Members which do not stream are left out, as logical decoding would wait for them.
+// Without standby slots the parameter is removed, unless it is set in the manifest.
+func (c *Cluster) setSynchronizedStandbySlots(master *v1.Pod, standbySlots []string) error {
+	if _, exists := c.Spec.Parameters[synchronizedStandbySlotsParameter]; exists {
+		return nil
+	}
+	_, effectivePgParameters, err := c.patroni.GetConfig(master)
+	if err != nil {
+		return fmt.Errorf("could not get Patroni configuration: %v", err)
+	}
+
+	var desired interface{}
+	if len(standbySlots) > 0 {
+		desired = strings.Join(standbySlots, ",")
+	}
+	current, exists := effectivePgParameters[synchronizedStandbySlotsParameter]
+	if (desired == nil && !exists) || (exists && current == desired) {
+		return nil
+	}
+
+	c.logger.Infof("setting %s to %v", synchronizedStandbySlotsParameter, desired)
+	if err := c.patroni.SetConfig(master, map[string]interface{}{
+		"postgresql": map[string]interface{}{
+			constants.PatroniPGParametersParameterName: map[string]interface{}{synchronizedStandbySlotsParameter: desired},
+		},
+	}); err != nil {
+		return fmt.Errorf("could not set %s: %v", synchronizedStandbySlotsParameter, err)
+	}
+	return nil
+}
+
+func (c *Cluster) setMissingFailoverSlots(missing map[string][]string) error {
+	if reflect.DeepEqual(missing, c.Status.MissingFailoverSlots) {
+		return nil
+	}
+	for member, slots := range missing {
+		c.logger.Warningf("failover slots %v are not synchronized to member %q", slots, member)
+	}
+	if _, err := c.KubeClient.SetCRDMissingFailoverSlotsStatus(c.clusterName(), c.Status.MissingFailoverSlots, missing); err != nil {
+		return err
+	}
+	c.Status.MissingFailoverSlots = missing
+	return nil
+}
@@ -0,0 +1,199 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/mocks"
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+func TestFailoverSlotParameters(t *testing.T) {
+	slots := map[string]map[string]string{
+		"cdc":       {"type": "logical", "database": "foo", "plugin": "pgoutput", "failover": "true"},
+		"analytics": {"type": "logical", "database": "bar", "plugin": "pgoutput"},
+		"physical":  {"type": "physical", "failover": "true"},
+	}
+
+	tests := []struct {
+		subTest            string
+		pgVersion          string
+		slots              map[string]map[string]string
+		streams            []cpov1.Stream
+		parameters         map[string]string
+		expectedSlots      map[string]string
+		expectedParameters map[string]string
+	}{
+		{
+			subTest:            "marked logical slots on PostgreSQL 17",
+			pgVersion:          "17",
+			slots:              slots,
+			parameters:         map[string]string{},
+			expectedSlots:      map[string]string{"cdc": "foo"},
+			expectedParameters: map[string]string{"sync_replication_slots": "on", "hot_standby_feedback": "on"},
+		},
+		{
+			subTest:            "stream slots are failover slots",
+			pgVersion:          "17",
+			streams:            []cpov1.Stream{{ApplicationId: "test-app", Database: "foo"}},
+			parameters:         map[string]string{"hot_standby_feedback": "off"},
+			expectedSlots:      map[string]string{"fes_foo_test_app": "foo"},
+			expectedParameters: map[string]string{"sync_replication_slots": "on", "hot_standby_feedback": "off"},
+		},
+		{
+			subTest:            "not supported before PostgreSQL 17",
+			pgVersion:          "16",
+			slots:              slots,
+			parameters:         map[string]string{},
+			expectedSlots:      map[string]string{"cdc": "foo"},
+			expectedParameters: map[string]string{},
+		},
+		{
+			subTest:            "no failover slots",
+			pgVersion:          "17",
+			slots:              map[string]map[string]string{"analytics": slots["analytics"]},
+			parameters:         map[string]string{},
+			expectedSlots:      map[string]string{},
+			expectedParameters: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(Config{}, k8sutil.KubernetesClient{}, cpov1.Postgresql{
+			Spec: cpov1.PostgresSpec{
+				PostgresqlParam: cpov1.PostgresqlParam{PgVersion: tt.pgVersion},
+				Patroni:         cpov1.Patroni{Slots: tt.slots},
+				Streams:         tt.streams,
+			},
+		}, logger, eventRecorder)
+
+		if failoverSlots := cluster.failoverSlots(); !reflect.DeepEqual(failoverSlots, tt.expectedSlots) {
+			t.Errorf("%s [%s]: expected failover slots %v, got %v", t.Name(), tt.subTest, tt.expectedSlots, failoverSlots)
+		}
+		cluster.addFailoverSlotParameters(tt.parameters)
+		if !reflect.DeepEqual(tt.parameters, tt.expectedParameters) {
+			t.Errorf("%s [%s]: expected parameters %v, got %v", t.Name(), tt.subTest, tt.expectedParameters, tt.parameters)
+		}
+	}
+}
+
+func TestPhysicalSlotName(t *testing.T) {
+	tests := map[string]string{
+		"acid-test-cluster-1": "acid_test_cluster_1",
+		"Acid.Test":           "acid_test",
+		"acid+test":           "acidu0043test",
+	}
+	for member, expected := range tests {
+		if name := physicalSlotName(member); name != expected {
+			t.Errorf("%s: expected slot name %q for member %q, got %q", t.Name(), expected, member, name)
+		}
+	}
+}
+
+func TestSetSynchronizedStandbySlots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		subTest       string
+		effective     string
+		manifest      map[string]string
+		standbySlots  []string
+		expectedPatch string
+	}{
+		{
+			subTest:       "add streaming standbys",
+			effective:     `{"postgresql": {"parameters": {"synchronized_standby_slots": "acid_test_cluster_1"}}}`,
+			standbySlots:  []string{"acid_test_cluster_1", "acid_test_cluster_2"},
+			expectedPatch: "{\"postgresql\":{\"parameters\":{\"synchronized_standby_slots\":\"acid_test_cluster_1,acid_test_cluster_2\"}}}\n",
+		},
+		{
+			subTest:      "standbys unchanged",
+			effective:    `{"postgresql": {"parameters": {"synchronized_standby_slots": "acid_test_cluster_1"}}}`,
+			standbySlots: []string{"acid_test_cluster_1"},
+		},
+		{
+			subTest:       "no streaming standby left",
+			effective:     `{"postgresql": {"parameters": {"synchronized_standby_slots": "acid_test_cluster_1"}}}`,
+			expectedPatch: "{\"postgresql\":{\"parameters\":{\"synchronized_standby_slots\":null}}}\n",
+		},
+		{
+			subTest:   "parameter not set",
+			effective: `{"postgresql": {"parameters": {}}}`,
+		},
+		{
+			subTest:      "parameter set in the manifest",
+			effective:    `{"postgresql": {"parameters": {}}}`,
+			manifest:     map[string]string{"synchronized_standby_slots": "external"},
+			standbySlots: []string{"acid_test_cluster_1"},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(Config{}, k8sutil.KubernetesClient{}, cpov1.Postgresql{
+			Spec: cpov1.PostgresSpec{PostgresqlParam: cpov1.PostgresqlParam{PgVersion: "17", Parameters: tt.manifest}},
+		}, logger, eventRecorder)
+
+		patch := ""
+		effective := tt.effective
+		mockClient := mocks.NewMockHTTPClient(ctrl)
+		mockClient.EXPECT().Get(gomock.Any()).DoAndReturn(func(url string) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(effective)))}, nil
+		}).AnyTimes()
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			patch = string(body)
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte("{}")))}, nil
+		}).AnyTimes()
+		cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+		if err := cluster.setSynchronizedStandbySlots(newMockPod("192.168.100.1"), tt.standbySlots); err != nil {
+			t.Fatalf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+		}
+		if patch != tt.expectedPatch {
+			t.Errorf("%s [%s]: expected patch %q, got %q", t.Name(), tt.subTest, tt.expectedPatch, patch)
+		}
+	}
+}
+
+func TestSetMissingFailoverSlots(t *testing.T) {
+	cluster := newTestCluster(t, cpov1.PostgresSpec{}, Config{})
+
+	missing := map[string][]string{"acid-test-cluster-1": {"cdc"}, "acid-test-cluster-2": {"cdc"}}
+	if err := cluster.setMissingFailoverSlots(missing); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+
+	// members which caught up disappear from the status
+	missing = map[string][]string{"acid-test-cluster-2": {"cdc"}}
+	if err := cluster.setMissingFailoverSlots(missing); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	pg, err := cluster.KubeClient.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get postgresql: %v", t.Name(), err)
+	}
+	if !reflect.DeepEqual(pg.Status.MissingFailoverSlots, missing) {
+		t.Errorf("%s: expected missing failover slots %v, got %v", t.Name(), missing, pg.Status.MissingFailoverSlots)
+	}
+
+	if err := cluster.setMissingFailoverSlots(nil); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	pg, err = cluster.KubeClient.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get postgresql: %v", t.Name(), err)
+	}
+	if len(pg.Status.MissingFailoverSlots) != 0 {
+		t.Errorf("%s: expected no missing failover slots, got %v", t.Name(), pg.Status.MissingFailoverSlots)
+	}
+}
@@ -9,31 +9,48 @@ import (
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/filesystems"
 )
 
-func (c *Cluster) getPostgresFilesystemInfo(podName *spec.NamespacedName) (device, fstype string, err error) {
-	out, err := c.ExecCommand(podName, "bash", "-c", fmt.Sprintf("df -T %s|tail -1", constants.PostgresDataMount))
+// postgresFilesystemResizers lists the filesystem resizers tried for the Postgres data volume.
+var postgresFilesystemResizers = []filesystems.FilesystemResizer{
+	&filesystems.Ext234Resize{},
+	&filesystems.XfsResize{},
+}
+
+func (c *Cluster) getPostgresFilesystemInfo(podName *spec.NamespacedName) (device, fstype, mountPoint string, err error) {
+	out, err := c.ExecCommand(podName, "bash", "-c", fmt.Sprintf("df -PT %s|tail -1", constants.PostgresDataMount))
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
+	return parseDfOutput(out)
+}
+
+// parseDfOutput extracts the device, filesystem type and mount point from the last line of `df -PT`.
+func parseDfOutput(out string) (device, fstype, mountPoint string, err error) {
 	fields := strings.Fields(out)
 	if len(fields) < 2 {
-		return "", "", fmt.Errorf("too few fields in the df output")
+		return "", "", "", fmt.Errorf("too few fields in the df output")
+	}
+	if len(fields) >= 7 {
+		mountPoint = fields[len(fields)-1]
 	}
 
-	return fields[0], fields[1], nil
+	return fields[0], fields[1], mountPoint, nil
 }
 
 func (c *Cluster) resizePostgresFilesystem(podName *spec.NamespacedName, resizers []filesystems.FilesystemResizer) error {
 	// resize2fs always writes to stderr, and ExecCommand considers a non-empty stderr an error
 	// first, determine the device and the filesystem
-	deviceName, fsType, err := c.getPostgresFilesystemInfo(podName)
+	deviceName, fsType, mountPoint, err := c.getPostgresFilesystemInfo(podName)
 	if err != nil {
 		return fmt.Errorf("could not get device and type for the postgres filesystem: %v", err)
 	}
+	if mountPoint == "" {
+		mountPoint = constants.PostgresDataMount
+	}
 	for _, resizer := range resizers {
 		if !resizer.CanResizeFilesystem(fsType) {
 			continue
 		}
-		err := resizer.ResizeFilesystem(deviceName, func(cmd string) (out string, err error) {
+		err := resizer.ResizeFilesystem(deviceName, mountPoint, func(cmd string) (out string, err error) {
 			return c.ExecCommand(podName, "bash", "-c", cmd)
 		})
 
@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"testing"
+)
+
+func TestParseDfOutput(t *testing.T) {
+	var tests = []struct {
+		subtest    string
+		output     string
+		device     string
+		fstype     string
+		mountPoint string
+		err        bool
+	}{
+		{
+			subtest:    "ext4",
+			output:     "/dev/nvme1n1   ext4  10255636 102404 10136848  2% /home/postgres/pgdata\n",
+			device:     "/dev/nvme1n1",
+			fstype:     "ext4",
+			mountPoint: "/home/postgres/pgdata",
+		},
+		{
+			subtest:    "xfs",
+			output:     "/dev/sdb  xfs  10475520 106088 10369432  2% /home/postgres/pgdata",
+			device:     "/dev/sdb",
+			fstype:     "xfs",
+			mountPoint: "/home/postgres/pgdata",
+		},
+		{
+			subtest: "truncated",
+			output:  "/dev/sdb",
+			err:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		device, fstype, mountPoint, err := parseDfOutput(tt.output)
+		if (err != nil) != tt.err {
+			t.Errorf("%s [%s]: expected error %t, got %v", t.Name(), tt.subtest, tt.err, err)
+		}
+		if device != tt.device || fstype != tt.fstype || mountPoint != tt.mountPoint {
+			t.Errorf("%s [%s]: expected %q %q %q, got %q %q %q", t.Name(), tt.subtest,
+				tt.device, tt.fstype, tt.mountPoint, device, fstype, mountPoint)
+		}
+	}
+}
@@ -0,0 +1,530 @@
+package cluster
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/privileges"
+)
+
+const (
+	// getObjectPrivilegesSQL returns the current database, the schemas, relations and routines outside
+	// of the system schemas with their owner and one row per privilege granted to another role
+	getObjectPrivilegesSQL = `SELECT o.objtype, o.objschema, o.objname, o.objowner,
+			COALESCE(pg_get_userbyid(a.grantee), ''), COALESCE(a.privilege_type, '')
+		FROM (
+			SELECT 'database' AS objtype, '' AS objschema, d.datname::text AS objname, d.datdba AS ownerid,
+					pg_get_userbyid(d.datdba) AS objowner, d.datacl AS objacl
+				FROM pg_catalog.pg_database d WHERE d.datname = current_database()
+			UNION ALL
+			SELECT 'schema', '', n.nspname::text, n.nspowner, pg_get_userbyid(n.nspowner), n.nspacl
+				FROM pg_catalog.pg_namespace n
+				WHERE n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
+			UNION ALL
+			SELECT CASE WHEN c.relkind = 'S' THEN 'sequence' ELSE 'table' END, n.nspname::text, c.relname::text,
+					c.relowner, pg_get_userbyid(c.relowner), c.relacl
+				FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+				WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f', 'S')
+					AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
+			UNION ALL
+			SELECT 'function', n.nspname::text, p.proname || '(' || pg_catalog.oidvectortypes(p.proargtypes) || ')',
+					p.proowner, pg_get_userbyid(p.proowner), p.proacl
+				FROM pg_catalog.pg_proc p JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+				WHERE p.prokind IN ('f', 'p')
+					AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
+		) o
+		LEFT JOIN LATERAL aclexplode(o.objacl) a ON a.grantee <> o.ownerid AND a.grantee <> 0;`
+	getDefaultPrivilegesSQL = `SELECT pg_get_userbyid(d.defaclrole), COALESCE(n.nspname, ''), d.defaclobjtype::text,
+			pg_get_userbyid(a.grantee), a.privilege_type
+		FROM pg_catalog.pg_default_acl d
+		LEFT JOIN pg_catalog.pg_namespace n ON n.oid = d.defaclnamespace
+		CROSS JOIN LATERAL aclexplode(d.defaclacl) a
+		WHERE a.grantee <> d.defaclrole AND a.grantee <> 0;`
+
+	grantSQL           = `GRANT %s ON %s TO "%s"`
+	revokeSQL          = `REVOKE %s ON %s FROM "%s"`
+	alterDefaultSQL    = `ALTER DEFAULT PRIVILEGES FOR ROLE "%s"`
+	defaultGrantSQL    = ` GRANT %s ON %s TO "%s"`
+	defaultRevokeSQL   = ` REVOKE %s ON %s FROM "%s"`
+	defaultInSchemaSQL = ` IN SCHEMA "%s"`
+)
+
+// databaseObject identifies a database, schema, relation or routine
+type databaseObject struct {
+	objectType string
+	schema     string
+	name       string
+}
+
+// objectPrivilege is a privilege of a role on an object
+type objectPrivilege struct {
+	role   string
+	object databaseObject
+}
+
+// defaultPrivilegeKey identifies the default privileges of a role on objects created by the owner
+type defaultPrivilegeKey struct {
+	role       string
+	owner      string
+	objectType string
+	schema     string
+}
+
+// databasePrivileges holds the objects of a database with their owners and the granted privileges
+type databasePrivileges struct {
+	serverVersion     int
+	owners            map[databaseObject]string
+	privileges        map[objectPrivilege][]string
+	defaultPrivileges map[defaultPrivilegeKey][]string
+}
+
+// getDatabasePrivileges returns the objects and privileges of the connected database
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getDatabasePrivileges() (current databasePrivileges, err error) {
+	var (
+		rows *sql.Rows
+	)
+
+	current = databasePrivileges{
+		owners:            make(map[databaseObject]string),
+		privileges:        make(map[objectPrivilege][]string),
+		defaultPrivileges: make(map[defaultPrivilegeKey][]string),
+	}
+
+	var serverVersionNum int
+	if err = c.pgDb.QueryRow(serverVersionNumSQL).Scan(&serverVersionNum); err != nil {
+		return current, fmt.Errorf("could not query server version: %v", err)
+	}
+	current.serverVersion = serverVersionNum / 10000
+
+	if rows, err = c.pgDb.Query(getObjectPrivilegesSQL); err != nil {
+		return current, fmt.Errorf("could not query privileges: %v", err)
+	}
+	for rows.Next() {
+		var object databaseObject
+		var owner, grantee, privilege string
+
+		if err = rows.Scan(&object.objectType, &object.schema, &object.name, &owner, &grantee, &privilege); err != nil {
+			rows.Close()
+			return current, fmt.Errorf("error when processing row: %v", err)
+		}
+		current.owners[object] = owner
+		if grantee != "" {
+			key := objectPrivilege{role: grantee, object: object}
+			current.privileges[key] = append(current.privileges[key], privilege)
+		}
+	}
+	if err = rows.Close(); err != nil {
+		return current, fmt.Errorf("error when closing query cursor: %v", err)
+	}
+
+	if rows, err = c.pgDb.Query(getDefaultPrivilegesSQL); err != nil {
+		return current, fmt.Errorf("could not query default privileges: %v", err)
+	}
+	for rows.Next() {
+		var key defaultPrivilegeKey
+		var objectType, privilege string
+
+		if err = rows.Scan(&key.owner, &key.schema, &objectType, &key.role, &privilege); err != nil {
+			rows.Close()
+			return current, fmt.Errorf("error when processing row: %v", err)
+		}
+		key.objectType = privileges.DefaultACLType(objectType)
+		current.defaultPrivileges[key] = append(current.defaultPrivileges[key], privilege)
+	}
+	if err = rows.Close(); err != nil {
+		return current, fmt.Errorf("error when closing query cursor: %v", err)
+	}
+
+	return current, nil
+}
+
+// grantStatements returns the GRANT, REVOKE and ALTER DEFAULT PRIVILEGES statements which bring the
+// privileges declared in the grants of the database into the desired state. Only privileges which were
+// applied before and have been removed from the grants are revoked, privileges granted outside of the
+// manifest are kept. Objects owned by a role are skipped, since the owner holds all privileges anyway.
+// Grants on objects which do not exist are reported as errors and nothing is revoked from their role.
+func grantStatements(database string, grants, applied cpov1.DatabaseGrants, current databasePrivileges) ([]string, []error) {
+	desired, desiredDefaults, failed, errors := expandGrants(database, grants, current)
+	// objects which are gone have no privileges left to revoke
+	previous, previousDefaults, _, _ := expandGrants(database, applied, current)
+
+	statements := make([]string, 0)
+
+	objectKeys := make(map[objectPrivilege]bool)
+	for key := range desired {
+		objectKeys[key] = true
+	}
+	for key := range previous {
+		objectKeys[key] = true
+	}
+	for _, key := range sortedObjectPrivileges(objectKeys) {
+		toGrant, toRevoke := privilegeDiff(desired[key], previous[key], current.privileges[key])
+		target := grantTarget(key.object)
+		if len(toGrant) > 0 {
+			statements = append(statements, fmt.Sprintf(grantSQL, strings.Join(toGrant, ", "), target, key.role))
+		}
+		if len(toRevoke) > 0 && !failed[key.role] {
+			statements = append(statements, fmt.Sprintf(revokeSQL, strings.Join(toRevoke, ", "), target, key.role))
+		}
+	}
+
+	defaultKeys := make(map[defaultPrivilegeKey]bool)
+	for key := range desiredDefaults {
+		defaultKeys[key] = true
+	}
+	for key := range previousDefaults {
+		defaultKeys[key] = true
+	}
+	for _, key := range sortedDefaultPrivileges(defaultKeys) {
+		toGrant, toRevoke := privilegeDiff(desiredDefaults[key], previousDefaults[key], current.defaultPrivileges[key])
+		prefix := fmt.Sprintf(alterDefaultSQL, key.owner)
+		if key.schema != "" {
+			prefix += fmt.Sprintf(defaultInSchemaSQL, key.schema)
+		}
+		objects := privileges.DefaultKeyword(key.objectType)
+		if len(toGrant) > 0 {
+			statements = append(statements, prefix+fmt.Sprintf(defaultGrantSQL, strings.Join(toGrant, ", "), objects, key.role))
+		}
+		if len(toRevoke) > 0 && !failed[key.role] {
+			statements = append(statements, prefix+fmt.Sprintf(defaultRevokeSQL, strings.Join(toRevoke, ", "), objects, key.role))
+		}
+	}
+
+	return statements, errors
+}
+
+// expandGrants returns the privileges of every role on the existing objects the grants apply to.
+// Roles with a grant that could not be expanded completely are returned as failed.
+func expandGrants(database string, grants cpov1.DatabaseGrants, current databasePrivileges) (map[objectPrivilege]map[string]bool, map[defaultPrivilegeKey]map[string]bool, map[string]bool, []error) {
+	var errors []error
+	objectPrivileges := make(map[objectPrivilege]map[string]bool)
+	defaultPrivileges := make(map[defaultPrivilegeKey]map[string]bool)
+	failed := make(map[string]bool)
+
+	for _, grant := range grants.Privileges {
+		privilegeList, err := privileges.Normalize(grant.ObjectType, grant.Privileges, current.serverVersion)
+		if err != nil {
+			errors = append(errors, err)
+			failed[grant.Role] = true
+			continue
+		}
+
+		objects, err := grantObjects(database, grant, current.owners)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("grant for role %q: %v", grant.Role, err))
+			failed[grant.Role] = true
+		}
+		for _, object := range objects {
+			if current.owners[object] == grant.Role {
+				continue
+			}
+			key := objectPrivilege{role: grant.Role, object: object}
+			if objectPrivileges[key] == nil {
+				objectPrivileges[key] = make(map[string]bool)
+			}
+			for _, privilege := range privilegeList {
+				objectPrivileges[key][privilege] = true
+			}
+		}
+	}
+
+	for _, defaultPrivilege := range grants.DefaultPrivileges {
+		privilegeList, err := privileges.Normalize(defaultPrivilege.ObjectType, defaultPrivilege.Privileges, current.serverVersion)
+		if err != nil {
+			errors = append(errors, err)
+			failed[defaultPrivilege.Role] = true
+			continue
+		}
+		if defaultPrivilege.Owner == defaultPrivilege.Role {
+			continue
+		}
+		key := defaultPrivilegeKey{
+			role:       defaultPrivilege.Role,
+			owner:      defaultPrivilege.Owner,
+			objectType: defaultPrivilege.ObjectType,
+			schema:     defaultPrivilege.Schema,
+		}
+		if defaultPrivileges[key] == nil {
+			defaultPrivileges[key] = make(map[string]bool)
+		}
+		for _, privilege := range privilegeList {
+			defaultPrivileges[key][privilege] = true
+		}
+	}
+
+	return objectPrivileges, defaultPrivileges, failed, errors
+}
+
+// grantObjects returns the existing objects a grant applies to. Objects which do not exist are
+// reported as an error next to the ones that do.
+func grantObjects(database string, grant cpov1.Grant, owners map[databaseObject]string) ([]databaseObject, error) {
+	var missing []string
+	objects := make([]databaseObject, 0, len(grant.Objects))
+
+	switch grant.ObjectType {
+	case privileges.ObjectDatabase:
+		return []databaseObject{{objectType: grant.ObjectType, name: database}}, nil
+	case privileges.ObjectSchema:
+		for _, name := range grant.Objects {
+			object := databaseObject{objectType: grant.ObjectType, name: name}
+			if _, exists := owners[object]; !exists {
+				missing = append(missing, fmt.Sprintf("schema %q does not exist", name))
+				continue
+			}
+			objects = append(objects, object)
+		}
+	default:
+		if _, exists := owners[databaseObject{objectType: privileges.ObjectSchema, name: grant.Schema}]; !exists {
+			return nil, fmt.Errorf("schema %q does not exist", grant.Schema)
+		}
+		if len(grant.Objects) == 0 {
+			for object := range owners {
+				if object.objectType == grant.ObjectType && object.schema == grant.Schema {
+					objects = append(objects, object)
+				}
+			}
+			return objects, nil
+		}
+		for _, name := range grant.Objects {
+			object := databaseObject{objectType: grant.ObjectType, schema: grant.Schema, name: name}
+			if _, exists := owners[object]; !exists {
+				missing = append(missing, fmt.Sprintf("%s %q.%q does not exist", grant.ObjectType, grant.Schema, name))
+				continue
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	if len(missing) > 0 {
+		return objects, fmt.Errorf("%s", strings.Join(missing, ", "))
+	}
+	return objects, nil
+}
+
+// grantTarget returns the object of a GRANT or REVOKE statement
+func grantTarget(object databaseObject) string {
+	keyword := privileges.GrantKeyword(object.objectType)
+	switch object.objectType {
+	case privileges.ObjectDatabase, privileges.ObjectSchema:
+		return fmt.Sprintf(`%s "%s"`, keyword, object.name)
+	case privileges.ObjectFunction:
+		// the argument types follow the quoted name, e.g. ROUTINE "public"."add"(integer, integer)
+		i := strings.Index(object.name, "(")
+		return fmt.Sprintf(`%s "%s"."%s"%s`, keyword, object.schema, object.name[:i], object.name[i:])
+	}
+	return fmt.Sprintf(`%s "%s"."%s"`, keyword, object.schema, object.name)
+}
+
+// privilegeDiff returns the sorted privileges to grant and to revoke. Only privileges which were
+// applied before and are not desired anymore are revoked.
+func privilegeDiff(desired, applied map[string]bool, current []string) (toGrant, toRevoke []string) {
+	granted := make(map[string]bool, len(current))
+	for _, privilege := range current {
+		granted[privilege] = true
+		if applied[privilege] && !desired[privilege] {
+			toRevoke = append(toRevoke, privilege)
+		}
+	}
+	for privilege := range desired {
+		if !granted[privilege] {
+			toGrant = append(toGrant, privilege)
+		}
+	}
+	sort.Strings(toGrant)
+	sort.Strings(toRevoke)
+	return toGrant, toRevoke
+}
+
+func sortedObjectPrivileges(keys map[objectPrivilege]bool) []objectPrivilege {
+	result := make([]objectPrivilege, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.role != b.role {
+			return a.role < b.role
+		}
+		if a.object.objectType != b.object.objectType {
+			return a.object.objectType < b.object.objectType
+		}
+		if a.object.schema != b.object.schema {
+			return a.object.schema < b.object.schema
+		}
+		return a.object.name < b.object.name
+	})
+	return result
+}
+
+func sortedDefaultPrivileges(keys map[defaultPrivilegeKey]bool) []defaultPrivilegeKey {
+	result := make([]defaultPrivilegeKey, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.role != b.role {
+			return a.role < b.role
+		}
+		if a.owner != b.owner {
+			return a.owner < b.owner
+		}
+		if a.objectType != b.objectType {
+			return a.objectType < b.objectType
+		}
+		return a.schema < b.schema
+	})
+	return result
+}
+
+// syncGrants reconciles the privileges declared in grants. Privileges which were removed from the
+// grants of a database since they were applied are revoked. With reportDrift, changes which were not
+// caused by a change of the manifest are reported as a warning event.
+func (c *Cluster) syncGrants(reportDrift bool) error {
+	c.setProcessName("syncing grants")
+	if len(c.Spec.Grants) == 0 && len(c.Status.Grants) == 0 {
+		return nil
+	}
+	errors := make([]string, 0)
+
+	if err := c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init database connection: %v", err)
+	}
+	currentDatabases, err := c.getDatabases()
+	if err := c.closeDbConn(); err != nil {
+		c.logger.Errorf("could not close database connection: %v", err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not get current databases: %v", err)
+	}
+
+	applied := make(map[string]cpov1.DatabaseGrants)
+	for _, grants := range c.Status.Grants {
+		applied[grants.Database] = cpov1.DatabaseGrants{Privileges: grants.Privileges, DefaultPrivileges: grants.DefaultPrivileges}
+	}
+	databases := keySet(c.Spec.Grants)
+	for database := range applied {
+		databases[database] = true
+	}
+
+	appliedGrants := make([]cpov1.AppliedGrants, 0)
+	for _, database := range sortedNames(databases) {
+		grants := c.Spec.Grants[database]
+		if _, exists := currentDatabases[database]; !exists {
+			if len(grants.Privileges) > 0 || len(grants.DefaultPrivileges) > 0 {
+				c.logger.Warningf("skipping grants of database %q, which does not exist", database)
+			}
+			continue
+		}
+		if err := c.syncGrantsOfDatabase(database, grants, applied[database], reportDrift); err != nil {
+			errors = append(errors, err.Error())
+			// keep the previously applied grants to revoke the removed privileges on the next sync
+			grants = mergeGrants(applied[database], grants)
+		}
+		if len(grants.Privileges) > 0 || len(grants.DefaultPrivileges) > 0 {
+			appliedGrants = append(appliedGrants, cpov1.AppliedGrants{
+				Database:          database,
+				Privileges:        grants.Privileges,
+				DefaultPrivileges: grants.DefaultPrivileges,
+			})
+		}
+	}
+
+	if err := c.setGrantsStatus(appliedGrants); err != nil {
+		errors = append(errors, fmt.Sprintf("could not update status of grants: %v", err))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("error(s) while syncing grants: %v", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// mergeGrants returns the grants together with the previously applied ones which are not part of them
+func mergeGrants(applied, grants cpov1.DatabaseGrants) cpov1.DatabaseGrants {
+	merged := cpov1.DatabaseGrants{
+		Privileges:        append([]cpov1.Grant{}, grants.Privileges...),
+		DefaultPrivileges: append([]cpov1.DefaultPrivilege{}, grants.DefaultPrivileges...),
+	}
+	for _, grant := range applied.Privileges {
+		if !containsGrant(merged.Privileges, grant) {
+			merged.Privileges = append(merged.Privileges, grant)
+		}
+	}
+	for _, defaultPrivilege := range applied.DefaultPrivileges {
+		if !containsGrant(merged.DefaultPrivileges, defaultPrivilege) {
+			merged.DefaultPrivileges = append(merged.DefaultPrivileges, defaultPrivilege)
+		}
+	}
+	return merged
+}
+
+func containsGrant[T any](list []T, item T) bool {
+	for _, element := range list {
+		if reflect.DeepEqual(element, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// setGrantsStatus records the grants applied to every database
+func (c *Cluster) setGrantsStatus(grants []cpov1.AppliedGrants) error {
+	if len(grants) == 0 {
+		grants = nil
+	}
+	if reflect.DeepEqual(grants, c.Status.Grants) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDGrantsStatus(c.clusterName(), grants); err != nil {
+		return err
+	}
+	c.Status.Grants = grants
+	return nil
+}
+
+// syncGrantsOfDatabase connects to the database and applies the grants to it
+func (c *Cluster) syncGrantsOfDatabase(database string, grants, applied cpov1.DatabaseGrants, reportDrift bool) error {
+	if err := c.initDbConnWithName(database); err != nil {
+		return fmt.Errorf("could not init connection to database %s: %v", database, err)
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+	errors := make([]string, 0)
+
+	current, err := c.getDatabasePrivileges()
+	if err != nil {
+		return fmt.Errorf("database %s: %v", database, err)
+	}
+
+	statements, grantErrors := grantStatements(database, grants, applied, current)
+	for _, err := range grantErrors {
+		errors = append(errors, fmt.Sprintf("database %s: %v", database, err))
+	}
+	if reportDrift && len(statements) > 0 {
+		c.logger.Warningf("privileges in database %q drifted from the manifest", database)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "Grants",
+			"Privileges in database %q drifted from the manifest, correcting them with: %s", database, strings.Join(statements, "; "))
+	}
+	for _, statement := range statements {
+		c.logger.Infof("executing %q in database %q", statement, database)
+		if _, err := c.pgDb.Exec(statement); err != nil {
+			errors = append(errors, fmt.Sprintf("could not execute %q in database %s: %v", statement, database, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, `', '`))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+)
+
+func TestGrantStatements(t *testing.T) {
+	current := databasePrivileges{
+		serverVersion: 16,
+		owners: map[databaseObject]string{
+			{objectType: "database", name: "app"}:                                  "app_owner",
+			{objectType: "schema", name: "sales"}:                                  "app_owner",
+			{objectType: "table", schema: "sales", name: "orders"}:                 "app_owner",
+			{objectType: "table", schema: "sales", name: "customers"}:              "app_owner",
+			{objectType: "table", schema: "sales", name: "notes"}:                  "reporting",
+			{objectType: "sequence", schema: "sales", name: "orders_id_seq"}:       "app_owner",
+			{objectType: "function", schema: "sales", name: "revenue(date, date)"}: "app_owner",
+		},
+		privileges: map[objectPrivilege][]string{
+			{role: "reporting", object: databaseObject{objectType: "table", schema: "sales", name: "orders"}}:           {"SELECT", "DELETE"},
+			{role: "reporting", object: databaseObject{objectType: "sequence", schema: "sales", name: "orders_id_seq"}}: {"USAGE"},
+			{role: "unmanaged", object: databaseObject{objectType: "table", schema: "sales", name: "orders"}}:           {"SELECT"},
+		},
+		defaultPrivileges: map[defaultPrivilegeKey][]string{
+			{role: "reporting", owner: "app_owner", objectType: "function"}: {"EXECUTE"},
+		},
+	}
+
+	tests := []struct {
+		subTest            string
+		grants             cpov1.DatabaseGrants
+		applied            cpov1.DatabaseGrants
+		expectedStatements []string
+		expectedErrors     int
+	}{
+		{
+			subTest: "grant missing privileges and keep undeclared ones",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "database", Privileges: []string{"CONNECT"}},
+					{Role: "reporting", ObjectType: "schema", Objects: []string{"sales"}, Privileges: []string{"usage"}},
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT"}},
+					{Role: "reporting", ObjectType: "function", Schema: "sales", Objects: []string{"revenue(date, date)"},
+						Privileges: []string{"EXECUTE"}},
+				},
+				DefaultPrivileges: []cpov1.DefaultPrivilege{
+					{Role: "reporting", Owner: "app_owner", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT"}},
+				},
+			},
+			expectedStatements: []string{
+				`GRANT CONNECT ON DATABASE "app" TO "reporting"`,
+				`GRANT EXECUTE ON ROUTINE "sales"."revenue"(date, date) TO "reporting"`,
+				`GRANT USAGE ON SCHEMA "sales" TO "reporting"`,
+				`GRANT SELECT ON TABLE "sales"."customers" TO "reporting"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app_owner" IN SCHEMA "sales" GRANT SELECT ON TABLES TO "reporting"`,
+			},
+		},
+		{
+			subTest: "revoke privileges removed from the grants",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT"}},
+				},
+			},
+			applied: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Privileges: []string{"SELECT", "DELETE"}},
+					{Role: "reporting", ObjectType: "sequence", Schema: "sales", Privileges: []string{"USAGE"}},
+				},
+				DefaultPrivileges: []cpov1.DefaultPrivilege{
+					{Role: "reporting", Owner: "app_owner", ObjectType: "function", Privileges: []string{"EXECUTE"}},
+				},
+			},
+			expectedStatements: []string{
+				`REVOKE USAGE ON SEQUENCE "sales"."orders_id_seq" FROM "reporting"`,
+				`GRANT SELECT ON TABLE "sales"."customers" TO "reporting"`,
+				`REVOKE DELETE ON TABLE "sales"."orders" FROM "reporting"`,
+				`ALTER DEFAULT PRIVILEGES FOR ROLE "app_owner" REVOKE EXECUTE ON FUNCTIONS FROM "reporting"`,
+			},
+		},
+		{
+			subTest: "empty privileges revoke the applied ones",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Privileges: []string{}},
+				},
+			},
+			applied: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"SELECT"}},
+				},
+			},
+			expectedStatements: []string{
+				`REVOKE SELECT ON TABLE "sales"."orders" FROM "reporting"`,
+			},
+		},
+		{
+			subTest: "missing objects prevent revoking",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"invoices", "customers"}, Privileges: []string{"SELECT"}},
+				},
+			},
+			applied: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"DELETE"}},
+				},
+			},
+			expectedStatements: []string{
+				`GRANT SELECT ON TABLE "sales"."customers" TO "reporting"`,
+			},
+			expectedErrors: 1,
+		},
+		{
+			subTest: "removed roles lose the applied privileges",
+			grants:  cpov1.DatabaseGrants{},
+			applied: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders", "invoices"}, Privileges: []string{"ALL"}},
+					{Role: "unmanaged", ObjectType: "database", Privileges: []string{"CONNECT"}},
+				},
+			},
+			expectedStatements: []string{
+				`REVOKE DELETE, SELECT ON TABLE "sales"."orders" FROM "reporting"`,
+			},
+		},
+		{
+			subTest: "maintain requires Postgres 17",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"MAINTAIN"}},
+				},
+			},
+			expectedStatements: []string{},
+			expectedErrors:     1,
+		},
+		{
+			subTest: "privileges in sync",
+			grants: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"SELECT", "DELETE"}},
+					{Role: "reporting", ObjectType: "sequence", Schema: "sales", Privileges: []string{"USAGE"}},
+				},
+				DefaultPrivileges: []cpov1.DefaultPrivilege{
+					{Role: "reporting", Owner: "app_owner", ObjectType: "function", Privileges: []string{"ALL"}},
+				},
+			},
+			applied: cpov1.DatabaseGrants{
+				Privileges: []cpov1.Grant{
+					{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"SELECT", "DELETE"}},
+					{Role: "reporting", ObjectType: "sequence", Schema: "sales", Privileges: []string{"USAGE"}},
+				},
+			},
+			expectedStatements: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		statements, errors := grantStatements("app", tt.grants, tt.applied, current)
+		if len(errors) != tt.expectedErrors {
+			t.Errorf("%s: expected %d errors, got %v", tt.subTest, tt.expectedErrors, errors)
+		}
+		if !reflect.DeepEqual(statements, tt.expectedStatements) {
+			t.Errorf("%s: expected statements %#v, got %#v", tt.subTest, tt.expectedStatements, statements)
+		}
+	}
+}
+
+func TestMergeGrants(t *testing.T) {
+	selectOrders := cpov1.Grant{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"SELECT"}}
+	deleteOrders := cpov1.Grant{Role: "reporting", ObjectType: "table", Schema: "sales", Objects: []string{"orders"}, Privileges: []string{"DELETE"}}
+	executeFunctions := cpov1.DefaultPrivilege{Role: "reporting", Owner: "app_owner", ObjectType: "function", Privileges: []string{"EXECUTE"}}
+
+	merged := mergeGrants(
+		cpov1.DatabaseGrants{Privileges: []cpov1.Grant{selectOrders, deleteOrders}, DefaultPrivileges: []cpov1.DefaultPrivilege{executeFunctions}},
+		cpov1.DatabaseGrants{Privileges: []cpov1.Grant{selectOrders}})
+	expected := cpov1.DatabaseGrants{
+		Privileges:        []cpov1.Grant{selectOrders, deleteOrders},
+		DefaultPrivileges: []cpov1.DefaultPrivilege{executeFunctions},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected merged grants %#v, got %#v", expected, merged)
+	}
+}
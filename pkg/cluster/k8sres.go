@@ -64,7 +64,7 @@ type patroniDCS struct {
 	LoopWait                 uint32                       `json:"loop_wait,omitempty"`
 	RetryTimeout             uint32                       `json:"retry_timeout,omitempty"`
 	MaximumLagOnFailover     float32                      `json:"maximum_lag_on_failover,omitempty"`
-	SynchronousMode          bool                         `json:"synchronous_mode,omitempty"`
+	SynchronousMode          interface{}                  `json:"synchronous_mode,omitempty"`
 	SynchronousModeStrict    bool                         `json:"synchronous_mode_strict,omitempty"`
 	SynchronousNodeCount     uint32                       `json:"synchronous_node_count,omitempty"`
 	PGBootstrapConfiguration map[string]interface{}       `json:"postgresql,omitempty"`
@@ -341,6 +341,24 @@ func (c *Cluster) generateResourceRequirements(
 	return &result, nil
 }
 
+// patroniSynchronousMode returns the value of the Patroni synchronous_mode setting, which is a
+// boolean unless quorum commit is used.
+func patroniSynchronousMode(mode string) interface{} {
+	switch mode {
+	case cpov1.SynchronousReplicationOn, cpov1.SynchronousReplicationStrict:
+		return true
+	case cpov1.SynchronousReplicationQuorum:
+		return cpov1.SynchronousReplicationQuorum
+	}
+	return false
+}
+
+// pgHbaEntries returns the pg_hba.conf lines of the operator-wide rules, followed by the rules and
+// plain entries of the cluster. Spilo adds its default entries after them.
+func pgHbaEntries(opConfig *config.Config, patroni *cpov1.Patroni) []string {
+	return config.PgHbaEntries(opConfig.PatroniPgHbaRules, patroni.PgHbaRules, patroni.PgHba)
+}
+
 func generateSpiloJSONConfiguration(pg *cpov1.PostgresqlParam, patroni *cpov1.Patroni, opConfig *config.Config, tdeOptions TDEConfig, logger *logrus.Entry) (string, error) {
 	config := spiloConfiguration{}
 
@@ -435,11 +453,11 @@ PatroniInitDBParams:
 	if patroni.Slots != nil {
 		config.Bootstrap.DCS.Slots = patroni.Slots
 	}
-	if patroni.SynchronousMode {
-		config.Bootstrap.DCS.SynchronousMode = patroni.SynchronousMode
+	if synchronousMode := patroniSynchronousMode(patroni.SynchronousReplicationMode()); synchronousMode != false {
+		config.Bootstrap.DCS.SynchronousMode = synchronousMode
 	}
-	if patroni.SynchronousModeStrict {
-		config.Bootstrap.DCS.SynchronousModeStrict = patroni.SynchronousModeStrict
+	if patroni.SynchronousReplicationMode() == cpov1.SynchronousReplicationStrict {
+		config.Bootstrap.DCS.SynchronousModeStrict = true
 	}
 	if patroni.SynchronousNodeCount >= 1 {
 		config.Bootstrap.DCS.SynchronousNodeCount = patroni.SynchronousNodeCount
@@ -473,8 +491,8 @@ PatroniInitDBParams:
 	// Patroni gives us a choice of writing pg_hba.conf to either the bootstrap section or to the local postgresql one.
 	// We choose the local one, because we need Patroni to change pg_hba.conf in PostgreSQL after the user changes the
 	// relevant section in the manifest.
-	if len(patroni.PgHba) > 0 {
-		config.PgLocalConfiguration[patroniPGHBAConfParameterName] = patroni.PgHba
+	if pgHba := pgHbaEntries(opConfig, patroni); len(pgHba) > 0 {
+		config.PgLocalConfiguration[patroniPGHBAConfParameterName] = pgHba
 	}
 
 	if patroni.Log != nil {
@@ -1364,14 +1382,14 @@ func extractPgVersionFromBinPath(binPath string, template string) (string, error
 	return fmt.Sprintf("%v", pgVersion), nil
 }
 
-func generatePatroniReadinessProbe() *v1.Probe {
+func generatePatroniReadinessProbe(scheme v1.URIScheme) *v1.Probe {
 	return &v1.Probe{
 		FailureThreshold: 3,
 		ProbeHandler: v1.ProbeHandler{
 			HTTPGet: &v1.HTTPGetAction{
 				Path:   "/readiness",
 				Port:   intstr.IntOrString{IntVal: patroni.ApiPort},
-				Scheme: v1.URISchemeHTTP,
+				Scheme: scheme,
 			},
 		},
 		InitialDelaySeconds: 6,
@@ -1398,14 +1416,14 @@ func generateExporterReadinessProbe() *v1.Probe {
 	}
 }
 
-func generatePatroniLivenessProbe() *v1.Probe {
+func generatePatroniLivenessProbe(scheme v1.URIScheme) *v1.Probe {
 	return &v1.Probe{
 		FailureThreshold: 6,
 		ProbeHandler: v1.ProbeHandler{
 			HTTPGet: &v1.HTTPGetAction{
 				Path:   "/liveness",
 				Port:   intstr.IntOrString{IntVal: patroni.ApiPort},
-				Scheme: v1.URISchemeHTTP,
+				Scheme: scheme,
 			},
 		},
 		InitialDelaySeconds: 30,
@@ -1475,7 +1493,12 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 			}
 		}
 	}
-	spiloConfiguration, err := generateSpiloJSONConfiguration(&spec.PostgresqlParam, &spec.Patroni, &c.OpConfig, tdeOptions, c.logger)
+	// pass the parameters derived from the tuning profile on to Spilo
+	pgParam := spec.PostgresqlParam
+	if pgParam.Parameters, err = c.desiredPostgresParameters(spec); err != nil {
+		return nil, fmt.Errorf("could not generate Postgres parameters: %v", err)
+	}
+	spiloConfiguration, err := generateSpiloJSONConfiguration(&pgParam, &spec.Patroni, &c.OpConfig, tdeOptions, c.logger)
 	if err != nil {
 		return nil, fmt.Errorf("could not generate Spilo JSON configuration: %v", err)
 	}
@@ -1507,8 +1530,8 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 
 	volumeMounts := generateVolumeMounts(spec.Volume)
 
-	// configure TLS with a custom secret volume
-	if spec.TLS != nil && spec.TLS.SecretName != "" {
+	// configure TLS with a custom or generated secret volume
+	if tls := c.postgresTLS(spec); tls != nil {
 		getSpiloTLSEnv := func(k string) string {
 			keyName := ""
 			switch k {
@@ -1527,19 +1550,19 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 			defaultMode := int32(0600)
 			mountPath := "/tls"
 			additionalVolumes = append(additionalVolumes, cpov1.AdditionalVolume{
-				Name:      spec.TLS.SecretName,
+				Name:      tls.SecretName,
 				MountPath: mountPath,
 				VolumeSource: v1.VolumeSource{
 					Secret: &v1.SecretVolumeSource{
-						SecretName:  spec.TLS.SecretName,
+						SecretName:  tls.SecretName,
 						DefaultMode: &defaultMode,
 					},
 				},
 			})
 
 			// use the same filenames as Secret resources by default
-			certFile := ensurePath(spec.TLS.CertificateFile, mountPath, "tls.crt")
-			privateKeyFile := ensurePath(spec.TLS.PrivateKeyFile, mountPath, "tls.key")
+			certFile := ensurePath(tls.CertificateFile, mountPath, "tls.crt")
+			privateKeyFile := ensurePath(tls.PrivateKeyFile, mountPath, "tls.key")
 			spiloEnvVars = appendEnvVars(
 				spiloEnvVars,
 				v1.EnvVar{Name: "SSL_CERTIFICATE_FILE", Value: certFile},
@@ -1548,7 +1571,7 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 			return keyName
 		}
 
-		tlsEnv, tlsVolumes := c.generateTlsMounts(spec, getSpiloTLSEnv)
+		tlsEnv, tlsVolumes := c.generateTlsMounts(tls, getSpiloTLSEnv)
 		for _, env := range tlsEnv {
 			spiloEnvVars = appendEnvVars(spiloEnvVars, env)
 		}
@@ -1565,6 +1588,12 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 		})
 	}
 
+	if c.OpConfig.EnablePatroniAPITLS {
+		patroniAPIEnvVars, patroniAPIVolumes := c.generatePatroniAPIEnvVars()
+		spiloEnvVars = appendEnvVars(spiloEnvVars, patroniAPIEnvVars...)
+		additionalVolumes = append(additionalVolumes, patroniAPIVolumes...)
+	}
+
 	if c.multisiteEnabled() {
 		multisiteEnvVars, multisiteVolumes := c.generateMultisiteEnvVars()
 		spiloEnvVars = appendEnvVars(spiloEnvVars, multisiteEnvVars...)
@@ -1583,13 +1612,18 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 		generateCapabilities(c.OpConfig.AdditionalPodCapabilities),
 	)
 
+	if hasMemberTags(spec) {
+		spiloContainer.Lifecycle = generateMemberTagsLifecycle()
+		additionalVolumes = append(additionalVolumes, c.generateMemberTagsVolume())
+	}
+
 	// Patroni responds 200 to probe only if it either owns the leader lock or postgres is running and DCS is accessible
 	if c.OpConfig.EnableReadinessProbe {
-		spiloContainer.ReadinessProbe = generatePatroniReadinessProbe()
+		spiloContainer.ReadinessProbe = generatePatroniReadinessProbe(c.patroniAPIScheme())
 	}
 	//
 	if c.OpConfig.EnableLivenessProbe {
-		spiloContainer.LivenessProbe = generatePatroniLivenessProbe()
+		spiloContainer.LivenessProbe = generatePatroniLivenessProbe(c.patroniAPIScheme())
 	}
 
 	// generate container specs for sidecars specified in the cluster manifest
@@ -1674,10 +1708,13 @@ func (c *Cluster) generateStatefulSet(spec *cpov1.PostgresSpec) (*appsv1.Statefu
 		}
 	}
 
+	// the noloadbalance label differs per member, it is set by the postStart hook of member tags
+	podLabels := c.labelsSetWithType(true, TYPE_POSTGRESQL, true)
+
 	// generate pod template for the statefulset, based on the spilo container and sidecars
 	podTemplate, err = c.generatePodTemplate(
 		c.Namespace,
-		c.labelsSetWithType(true, TYPE_POSTGRESQL, true),
+		podLabels,
 		c.annotationsSet(podAnnotations),
 		spiloContainer,
 		initContainers,
@@ -2039,7 +2076,7 @@ func (c *Cluster) generateRepoHostStatefulSet(spec *cpov1.PostgresSpec) (*appsv1
 	return statefulSet, nil
 }
 
-func (c *Cluster) generateTlsMounts(spec *cpov1.PostgresSpec, tlsEnv func(key string) string) ([]v1.EnvVar, []cpov1.AdditionalVolume) {
+func (c *Cluster) generateTlsMounts(tls *cpov1.TLSDescription, tlsEnv func(key string) string) ([]v1.EnvVar, []cpov1.AdditionalVolume) {
 	// this is combined with the FSGroup in the section above
 	// to give read access to the postgres user
 	defaultMode := int32(0640)
@@ -2048,40 +2085,40 @@ func (c *Cluster) generateTlsMounts(spec *cpov1.PostgresSpec, tlsEnv func(key st
 	volumes := make([]cpov1.AdditionalVolume, 0)
 
 	volumes = append(volumes, cpov1.AdditionalVolume{
-		Name:      spec.TLS.SecretName,
+		Name:      tls.SecretName,
 		MountPath: mountPath,
 		VolumeSource: v1.VolumeSource{
 			Secret: &v1.SecretVolumeSource{
-				SecretName:  spec.TLS.SecretName,
+				SecretName:  tls.SecretName,
 				DefaultMode: &defaultMode,
 			},
 		},
 	})
 
 	// use the same filenames as Secret resources by default
-	certFile := ensurePath(spec.TLS.CertificateFile, mountPath, "tls.crt")
-	privateKeyFile := ensurePath(spec.TLS.PrivateKeyFile, mountPath, "tls.key")
+	certFile := ensurePath(tls.CertificateFile, mountPath, "tls.crt")
+	privateKeyFile := ensurePath(tls.PrivateKeyFile, mountPath, "tls.key")
 	env = append(env, v1.EnvVar{Name: tlsEnv("tls.crt"), Value: certFile})
 	env = append(env, v1.EnvVar{Name: tlsEnv("tls.key"), Value: privateKeyFile})
 
-	if spec.TLS.CAFile != "" {
+	if tls.CAFile != "" {
 		// support scenario when the ca.crt resides in a different secret, diff path
 		mountPathCA := mountPath
-		if spec.TLS.CASecretName != "" {
+		if tls.CASecretName != "" {
 			mountPathCA = mountPath + "ca"
 		}
 
-		caFile := ensurePath(spec.TLS.CAFile, mountPathCA, "")
+		caFile := ensurePath(tls.CAFile, mountPathCA, "")
 		env = append(env, v1.EnvVar{Name: tlsEnv("tls.ca"), Value: caFile})
 
 		// the ca file from CASecretName secret takes priority
-		if spec.TLS.CASecretName != "" {
+		if tls.CASecretName != "" {
 			volumes = append(volumes, cpov1.AdditionalVolume{
-				Name:      spec.TLS.CASecretName,
+				Name:      tls.CASecretName,
 				MountPath: mountPathCA,
 				VolumeSource: v1.VolumeSource{
 					Secret: &v1.SecretVolumeSource{
-						SecretName:  spec.TLS.CASecretName,
+						SecretName:  tls.CASecretName,
 						DefaultMode: &defaultMode,
 					},
 				},
@@ -2608,11 +2645,10 @@ func (c *Cluster) generateSingleUserSecret(namespace string, pgUser spec.PgUser)
 			Annotations: c.annotationsSet(nil),
 		},
 		Type: v1.SecretTypeOpaque,
-		Data: map[string][]byte{
-			"username": []byte(pgUser.Name),
-			"password": []byte(pgUser.Password),
-		},
+		Data: c.connectionSecretData(username, pgUser.Name, pgUser.Password),
 	}
+	secret.Data["username"] = []byte(pgUser.Name)
+	secret.Data["password"] = []byte(pgUser.Password)
 
 	return &secret
 }
@@ -2673,6 +2709,9 @@ func (c *Cluster) generateService(role PostgresRole, spec *cpov1.PostgresSpec) *
 	if role == Replica || c.patroniKubernetesUseConfigMaps() {
 		// XXX: this seems broken when etcd_host is set. That makes use config maps false, but we should need a selector
 		serviceSpec.Selector = c.roleLabelsSet(false, role)
+		if role == Replica && hasMemberTags(spec) {
+			serviceSpec.Selector[noLoadBalanceLabel] = "false"
+		}
 	}
 
 	if c.shouldCreateLoadBalancerForService(role, spec) {
@@ -115,6 +115,65 @@ func TestGenerateSpiloJSONConfiguration(t *testing.T) {
 			},
 			result: `{"postgresql":{"bin_dir":"/usr/lib/postgresql/17/bin","pg_hba":["hostssl all all 0.0.0.0/0 scram-sha-256","host    all all 0.0.0.0/0 scram-sha-256"]},"bootstrap":{"initdb":[{"auth-host":"scram-sha-256"},"data-checksums",{"auth-local":"trust"},{"encoding":"UTF8"},{"locale":"en_US.UTF-8"},{"locale-provider":"icu"},{"icu-locale":"en_US"}],"users":null,"dcs":{"ttl":30,"loop_wait":10,"retry_timeout":10,"maximum_lag_on_failover":33554432,"synchronous_mode":true,"synchronous_mode_strict":true,"synchronous_node_count":1,"slots":{"permanent_logical_1":{"database":"foo","plugin":"pgoutput","type":"logical"}},"failsafe_mode":true}}}`,
 		},
+		{
+			subtest: "Patroni quorum commit configured",
+			pgParam: &cpov1.PostgresqlParam{PgVersion: "17"},
+			patroni: &cpov1.Patroni{
+				SynchronousMode:        true,
+				SynchronousReplication: "quorum",
+				SynchronousNodeCount:   2,
+			},
+			opConfig: &config.Config{
+				Auth: config.Auth{
+					PamRoleName: "humans",
+				},
+			},
+			tdeConfig: TDEConfig{
+				Enabled: false,
+			},
+			result: `{"postgresql":{"bin_dir":"/usr/lib/postgresql/17/bin"},"bootstrap":{"initdb":[{"auth-host":"scram-sha-256"},"data-checksums",{"auth-local":"trust"},{"encoding":"UTF8"},{"locale":"en_US.UTF-8"},{"locale-provider":"icu"},{"icu-locale":"en_US"}],"users":null,"dcs":{"synchronous_mode":"quorum","synchronous_node_count":2}}}`,
+		},
+		{
+			subtest: "Patroni pg_hba rules merged with operator-wide rules",
+			pgParam: &cpov1.PostgresqlParam{PgVersion: "17"},
+			patroni: &cpov1.Patroni{
+				PgHba: []string{"hostssl all all 0.0.0.0/0 scram-sha-256"},
+				PgHbaRules: []config.PgHbaRule{
+					{Type: "hostssl", Database: "all", User: "all", Address: "10.0.0.0/8", Method: "cert",
+						Options: map[string]string{"clientcert": "verify-full"}},
+				},
+			},
+			opConfig: &config.Config{
+				Auth: config.Auth{
+					PamRoleName: "humans",
+				},
+				PatroniPgHbaRules: []config.PgHbaRule{
+					{Type: "local", Database: "all", User: "all", Method: "trust"},
+				},
+			},
+			tdeConfig: TDEConfig{
+				Enabled: false,
+			},
+			result: `{"postgresql":{"bin_dir":"/usr/lib/postgresql/17/bin","pg_hba":["local all all trust","hostssl all all 10.0.0.0/8 cert clientcert=verify-full","hostssl all all 0.0.0.0/0 scram-sha-256"]},"bootstrap":{"initdb":[{"auth-host":"scram-sha-256"},"data-checksums",{"auth-local":"trust"},{"encoding":"UTF8"},{"locale":"en_US.UTF-8"},{"locale-provider":"icu"},{"icu-locale":"en_US"}],"users":null,"dcs":{}}}`,
+		},
+		{
+			subtest: "Patroni pg_hba rules without plain entries leave the defaults to Spilo",
+			pgParam: &cpov1.PostgresqlParam{PgVersion: "17"},
+			patroni: &cpov1.Patroni{
+				PgHbaRules: []config.PgHbaRule{
+					{Type: "hostssl", Database: "all", User: "all", Address: "10.0.0.0/8", Method: "cert"},
+				},
+			},
+			opConfig: &config.Config{
+				Auth: config.Auth{
+					ReplicationUsername: "standby",
+				},
+			},
+			tdeConfig: TDEConfig{
+				Enabled: false,
+			},
+			result: `{"postgresql":{"bin_dir":"/usr/lib/postgresql/17/bin","pg_hba":["hostssl all all 10.0.0.0/8 cert"]},"bootstrap":{"initdb":[{"auth-host":"scram-sha-256"},"data-checksums",{"auth-local":"trust"},{"encoding":"UTF8"},{"locale":"en_US.UTF-8"},{"locale-provider":"icu"},{"icu-locale":"en_US"}],"users":null,"dcs":{}}}`,
+		},
 		{
 			subtest: "Patroni failsafe_mode configured globally",
 			pgParam: &cpov1.PostgresqlParam{PgVersion: "17"},
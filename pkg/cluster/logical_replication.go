@@ -0,0 +1,526 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+const (
+	getPublicationDefinitionsSQL = `SELECT p.pubname, p.puballtables,
+			ARRAY(SELECT n.nspname || '.' || c.relname FROM pg_catalog.pg_publication_rel pr
+				JOIN pg_catalog.pg_class c ON c.oid = pr.prrelid
+				JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+				WHERE pr.prpubid = p.oid ORDER BY 1)
+		FROM pg_catalog.pg_publication p;`
+	// getSubscriptionsSQL takes the error counters and the join of pg_stat_subscription_stats, which
+	// exists since PostgreSQL 15, as parameters
+	getSubscriptionsSQL = `SELECT s.subname, s.subenabled, s.subconninfo, s.subpublications,
+			COALESCE(EXTRACT(EPOCH FROM now() - (SELECT max(st.latest_end_time) FROM pg_catalog.pg_stat_subscription st
+				WHERE st.subid = s.oid AND st.relid IS NULL))::bigint, -1), %s
+		FROM pg_catalog.pg_subscription s %s
+		WHERE s.subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database());`
+	subscriptionStatsColumns   = `COALESCE(ss.apply_error_count, 0), COALESCE(ss.sync_error_count, 0)`
+	subscriptionStatsJoin      = `LEFT JOIN pg_catalog.pg_stat_subscription_stats ss ON ss.subid = s.oid`
+	noSubscriptionStatsColumns = `0::bigint, 0::bigint`
+
+	createPublicationForTablesSQL    = `CREATE PUBLICATION "%s" FOR TABLE %s`
+	createPublicationForAllTablesSQL = `CREATE PUBLICATION "%s" FOR ALL TABLES`
+	dropPublicationSQL               = `DROP PUBLICATION "%s"`
+	createSubscriptionSQL            = `CREATE SUBSCRIPTION "%s" CONNECTION '%s' PUBLICATION %s`
+	alterSubscriptionConnectionSQL   = `ALTER SUBSCRIPTION "%s" CONNECTION '%s'`
+	alterSubscriptionPublicationSQL  = `ALTER SUBSCRIPTION "%s" SET PUBLICATION %s`
+	subscriptionFailoverOption       = ` WITH (failover = true)`
+	dropSubscriptionSQL              = `DROP SUBSCRIPTION IF EXISTS "%s"`
+	grantSelectOnTablesSQL           = `GRANT SELECT ON TABLE %s TO "%s"`
+	serverVersionNumSQL              = `SHOW server_version_num`
+)
+
+// publicationDefinition is a publication of a database
+type publicationDefinition struct {
+	allTables bool
+	tables    []string
+}
+
+// subscriptionState is a subscription of a database with its statistics
+type subscriptionState struct {
+	enabled      bool
+	conninfo     string
+	publications []string
+	applyLag     int64
+	applyErrors  int64
+	syncErrors   int64
+}
+
+// hasPublications reports whether the cluster publishes tables to other clusters, which requires the
+// logical replication user and wal_level logical
+func hasPublications(spec *cpov1.PostgresSpec) bool {
+	return spec.LogicalReplication != nil && len(spec.LogicalReplication.Publications) > 0
+}
+
+// publicationTables returns the schema qualified tables of a publication, tables without a schema
+// belong to the public schema
+func publicationTables(publication cpov1.Publication) []string {
+	tables := make([]string, 0, len(publication.Tables))
+	for _, table := range publication.Tables {
+		if !strings.Contains(table, ".") {
+			table = "public." + table
+		}
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+func quoteTableList(tables []string) string {
+	quoted := make([]string, 0, len(tables))
+	for _, table := range tables {
+		schemaName, tableName, _ := strings.Cut(table, ".")
+		quoted = append(quoted, fmt.Sprintf(`"%s"."%s"`, schemaName, tableName))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteNameList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// publicationStatements returns the statements which create the publication or bring the existing
+// publication into the desired state. Switching between a table list and all tables requires to
+// recreate the publication.
+func publicationStatements(publication cpov1.Publication, current *publicationDefinition) []string {
+	create := fmt.Sprintf(createPublicationForAllTablesSQL, publication.Name)
+	tables := publicationTables(publication)
+	if !publication.AllTables {
+		create = fmt.Sprintf(createPublicationForTablesSQL, publication.Name, quoteTableList(tables))
+	}
+
+	if current == nil {
+		return []string{create}
+	}
+	if current.allTables != publication.AllTables {
+		return []string{fmt.Sprintf(dropPublicationSQL, publication.Name), create}
+	}
+	if !publication.AllTables && !reflect.DeepEqual(current.tables, tables) {
+		return []string{fmt.Sprintf(alterPublicationSQL, publication.Name, quoteTableList(tables))}
+	}
+	return nil
+}
+
+// subscriptionStatements returns the statements which create the subscription or bring the existing
+// subscription into the desired state. The slot on the publisher is created by the subscription.
+func subscriptionStatements(subscription cpov1.Subscription, conninfo string, current *subscriptionState, failover bool) []string {
+	publications := append([]string{}, subscription.Publications...)
+	sort.Strings(publications)
+	escapedConninfo := strings.ReplaceAll(conninfo, "'", "''")
+
+	if current == nil {
+		statement := fmt.Sprintf(createSubscriptionSQL, subscription.Name, escapedConninfo, quoteNameList(publications))
+		if failover {
+			statement += subscriptionFailoverOption
+		}
+		return []string{statement}
+	}
+
+	statements := make([]string, 0)
+	if current.conninfo != conninfo {
+		statements = append(statements, fmt.Sprintf(alterSubscriptionConnectionSQL, subscription.Name, escapedConninfo))
+	}
+	currentPublications := append([]string{}, current.publications...)
+	sort.Strings(currentPublications)
+	if !reflect.DeepEqual(currentPublications, publications) {
+		statements = append(statements, fmt.Sprintf(alterSubscriptionPublicationSQL, subscription.Name, quoteNameList(publications)))
+	}
+	return statements
+}
+
+// getPublicationDefinitions returns the publications of the connected database
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getPublicationDefinitions() (publications map[string]publicationDefinition, err error) {
+	var (
+		rows *sql.Rows
+	)
+
+	if rows, err = c.pgDb.Query(getPublicationDefinitionsSQL); err != nil {
+		return nil, fmt.Errorf("could not query database publications: %v", err)
+	}
+
+	defer func() {
+		if err2 := rows.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("error when closing query cursor: %v, previous error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("error when closing query cursor: %v", err2)
+			}
+		}
+	}()
+
+	publications = make(map[string]publicationDefinition)
+
+	for rows.Next() {
+		var (
+			name       string
+			definition publicationDefinition
+		)
+
+		if err = rows.Scan(&name, &definition.allTables, pq.Array(&definition.tables)); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		publications[name] = definition
+	}
+
+	return publications, err
+}
+
+// getSubscriptions returns the subscriptions of the connected database
+// The caller is responsible for opening and closing the database connection
+func (c *Cluster) getSubscriptions() (subscriptions map[string]subscriptionState, err error) {
+	var (
+		rows *sql.Rows
+	)
+
+	query := fmt.Sprintf(getSubscriptionsSQL, noSubscriptionStatsColumns, "")
+	if c.GetDesiredMajorVersionAsInt() >= VersionMap["15"] {
+		query = fmt.Sprintf(getSubscriptionsSQL, subscriptionStatsColumns, subscriptionStatsJoin)
+	}
+	if rows, err = c.pgDb.Query(query); err != nil {
+		return nil, fmt.Errorf("could not query database subscriptions: %v", err)
+	}
+
+	defer func() {
+		if err2 := rows.Close(); err2 != nil {
+			if err != nil {
+				err = fmt.Errorf("error when closing query cursor: %v, previous error: %v", err2, err)
+			} else {
+				err = fmt.Errorf("error when closing query cursor: %v", err2)
+			}
+		}
+	}()
+
+	subscriptions = make(map[string]subscriptionState)
+
+	for rows.Next() {
+		var (
+			name         string
+			subscription subscriptionState
+		)
+
+		if err = rows.Scan(&name, &subscription.enabled, &subscription.conninfo, pq.Array(&subscription.publications),
+			&subscription.applyLag, &subscription.applyErrors, &subscription.syncErrors); err != nil {
+			return nil, fmt.Errorf("error when processing row: %v", err)
+		}
+		subscriptions[name] = subscription
+	}
+
+	return subscriptions, err
+}
+
+// subscriptionCredentials returns the credentials of the logical replication user of the source
+// cluster of a subscription
+func (c *Cluster) subscriptionCredentials(subscription cpov1.Subscription) (string, string, error) {
+	secretName := c.credentialSecretNameForCluster(constants.LogicalReplicationUserName, subscription.SourceCluster)
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("could not get secret %s of the replication user of cluster %s: %v", secretName, subscription.SourceCluster, err)
+	}
+	return string(secret.Data["username"]), string(secret.Data["password"]), nil
+}
+
+func subscriptionSourceDatabase(subscription cpov1.Subscription) string {
+	if subscription.SourceDatabase == "" {
+		return subscription.Database
+	}
+	return subscription.SourceDatabase
+}
+
+// subscriptionConninfo returns the connection to the source cluster of a subscription with the
+// credentials of its logical replication user
+func (c *Cluster) subscriptionConninfo(subscription cpov1.Subscription) (string, error) {
+	username, password, err := c.subscriptionCredentials(subscription)
+	if err != nil {
+		return "", err
+	}
+	host, port := c.getClusterServiceConnectionParameters(subscription.SourceCluster)
+	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=require",
+		conninfoValue(host), conninfoValue(port), conninfoValue(subscriptionSourceDatabase(subscription)),
+		conninfoValue(username), conninfoValue(password)), nil
+}
+
+// conninfoValue quotes a value of a libpq connection string. Within single quotes, only backslashes
+// and single quotes have to be escaped.
+func conninfoValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// publisherServerVersion returns the server_version_num of the source cluster of a subscription.
+// The operator connects with the credentials of the logical replication user, like the subscriber.
+func (c *Cluster) publisherServerVersion(subscription cpov1.Subscription) (int, error) {
+	username, password, err := c.subscriptionCredentials(subscription)
+	if err != nil {
+		return 0, err
+	}
+	connstring := fmt.Sprintf("host=%s port=%s dbname=%s sslmode=require user=%s password=%s connect_timeout=%s",
+		conninfoValue(fmt.Sprintf("%s.%s.svc.%s", subscription.SourceCluster, c.Namespace, c.OpConfig.ClusterDomain)),
+		conninfoValue(fmt.Sprintf("%d", pgPort)),
+		conninfoValue(subscriptionSourceDatabase(subscription)),
+		conninfoValue(username),
+		conninfoValue(password),
+		conninfoValue(fmt.Sprintf("%d", constants.PostgresConnectTimeout/time.Second)))
+	conn, err := sql.Open("postgres", connstring)
+	if err != nil {
+		return 0, fmt.Errorf("could not connect to cluster %s: %v", subscription.SourceCluster, err)
+	}
+	defer conn.Close()
+
+	var version int
+	if err = conn.QueryRow(serverVersionNumSQL).Scan(&version); err != nil {
+		return 0, fmt.Errorf("could not get server version of cluster %s: %v", subscription.SourceCluster, err)
+	}
+	return version, nil
+}
+
+// subscriptionFailover reports whether a new subscription creates a failover slot, which requires
+// PostgreSQL 17 on both the subscriber and the publisher
+func (c *Cluster) subscriptionFailover(subscription cpov1.Subscription) (bool, error) {
+	if !c.failoverSlotsSupported() {
+		return false, nil
+	}
+	version, err := c.publisherServerVersion(subscription)
+	if err != nil {
+		return false, err
+	}
+	return version >= VersionMap["17"], nil
+}
+
+// syncLogicalReplication creates and changes the publications and subscriptions of the manifest
+// and reports the state of the subscriptions. Subscriptions which were removed from the manifest
+// are dropped, they are known from the status. Publications and subscriptions which were never part
+// of the manifest are not touched.
+func (c *Cluster) syncLogicalReplication() error {
+	c.setProcessName("syncing logical replication")
+	logicalReplication := c.Spec.LogicalReplication
+	if logicalReplication == nil {
+		if len(c.Status.Subscriptions) == 0 {
+			return c.setSubscriptionsStatus(nil)
+		}
+		logicalReplication = &cpov1.LogicalReplication{}
+	}
+	errors := make([]string, 0)
+
+	publications := make(map[string][]cpov1.Publication)
+	for _, publication := range logicalReplication.Publications {
+		publications[publication.Database] = append(publications[publication.Database], publication)
+	}
+	subscriptions := make(map[string][]cpov1.Subscription)
+	defined := make(map[string]bool)
+	for _, subscription := range logicalReplication.Subscriptions {
+		subscriptions[subscription.Database] = append(subscriptions[subscription.Database], subscription)
+		defined[subscription.Database+"/"+subscription.Name] = true
+	}
+	removed := make(map[string][]string)
+	for _, previous := range c.Status.Subscriptions {
+		if !defined[previous.Database+"/"+previous.Name] {
+			removed[previous.Database] = append(removed[previous.Database], previous.Name)
+		}
+	}
+
+	databaseSet := make(map[string]bool)
+	for database := range publications {
+		databaseSet[database] = true
+	}
+	for database := range subscriptions {
+		databaseSet[database] = true
+	}
+	for database := range removed {
+		databaseSet[database] = true
+	}
+	databases := make([]string, 0, len(databaseSet))
+	for database := range databaseSet {
+		databases = append(databases, database)
+	}
+	sort.Strings(databases)
+
+	status := make([]cpov1.SubscriptionStatus, 0)
+	for _, database := range databases {
+		if err := c.initDbConnWithName(database); err != nil {
+			errors = append(errors, fmt.Sprintf("could not init connection to database %s: %v", database, err))
+			for _, subscription := range subscriptions[database] {
+				status = append(status, cpov1.SubscriptionStatus{Name: subscription.Name, Database: database, Error: err.Error()})
+			}
+			for _, name := range removed[database] {
+				status = append(status, cpov1.SubscriptionStatus{Name: name, Database: database, Error: err.Error()})
+			}
+			continue
+		}
+		if err := c.syncPublicationsOfDatabase(database, publications[database]); err != nil {
+			errors = append(errors, err.Error())
+		}
+		status = append(status, c.dropSubscriptionsOfDatabase(database, removed[database])...)
+		databaseStatus, err := c.syncSubscriptionsOfDatabase(database, subscriptions[database])
+		if err != nil {
+			errors = append(errors, err.Error())
+		}
+		status = append(status, databaseStatus...)
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}
+
+	if err := c.setSubscriptionsStatus(status); err != nil {
+		errors = append(errors, fmt.Sprintf("could not update status of subscriptions: %v", err))
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("error(s) while syncing logical replication: %v", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// dropSubscriptionsOfDatabase drops the subscriptions which were removed from the manifest, which
+// also drops their slots on the source clusters. Subscriptions which could not be dropped are
+// returned with the error, so that the next sync tries again.
+func (c *Cluster) dropSubscriptionsOfDatabase(database string, names []string) []cpov1.SubscriptionStatus {
+	status := make([]cpov1.SubscriptionStatus, 0)
+	for _, name := range names {
+		c.logger.Infof("dropping subscription %q in database %q removed from the manifest", name, database)
+		if _, err := c.pgDb.Exec(fmt.Sprintf(dropSubscriptionSQL, name)); err != nil {
+			c.logger.Warningf("could not drop subscription %q in database %q: %v", name, database, err)
+			status = append(status, cpov1.SubscriptionStatus{Name: name, Database: database, Error: err.Error()})
+		}
+	}
+	return status
+}
+
+// syncPublicationsOfDatabase applies the publications to the connected database
+func (c *Cluster) syncPublicationsOfDatabase(database string, publications []cpov1.Publication) error {
+	if len(publications) == 0 {
+		return nil
+	}
+	errors := make([]string, 0)
+
+	currentPublications, err := c.getPublicationDefinitions()
+	if err != nil {
+		return fmt.Errorf("database %s: %v", database, err)
+	}
+
+	for _, publication := range publications {
+		var current *publicationDefinition
+		if definition, exists := currentPublications[publication.Name]; exists {
+			current = &definition
+		}
+		statements := publicationStatements(publication, current)
+		// without pg_read_all_data the replication user needs access to the published tables
+		if c.GetDesiredMajorVersionAsInt() < VersionMap["14"] && !publication.AllTables {
+			statements = append(statements, fmt.Sprintf(grantSelectOnTablesSQL, quoteTableList(publicationTables(publication)), constants.LogicalReplicationUserName))
+		}
+		for _, statement := range statements {
+			c.logger.Infof("executing %q in database %q", statement, database)
+			if _, err := c.pgDb.Exec(statement); err != nil {
+				errors = append(errors, fmt.Sprintf("could not execute %q in database %s: %v", statement, database, err))
+				break
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// syncSubscriptionsOfDatabase applies the subscriptions to the connected database and returns
+// their state. Statements are not logged, as they contain the password of the replication user.
+func (c *Cluster) syncSubscriptionsOfDatabase(database string, subscriptions []cpov1.Subscription) ([]cpov1.SubscriptionStatus, error) {
+	if len(subscriptions) == 0 {
+		return nil, nil
+	}
+	errors := make([]string, 0)
+	subscriptionErrors := make(map[string]string)
+
+	currentSubscriptions, err := c.getSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("database %s: %v", database, err)
+	}
+
+	changed := false
+	for _, subscription := range subscriptions {
+		conninfo, err := c.subscriptionConninfo(subscription)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("subscription %s: %v", subscription.Name, err))
+			subscriptionErrors[subscription.Name] = err.Error()
+			continue
+		}
+		var current *subscriptionState
+		failover := false
+		if state, exists := currentSubscriptions[subscription.Name]; exists {
+			current = &state
+		} else if failover, err = c.subscriptionFailover(subscription); err != nil {
+			errors = append(errors, fmt.Sprintf("subscription %s: %v", subscription.Name, err))
+			subscriptionErrors[subscription.Name] = err.Error()
+			continue
+		}
+		for _, statement := range subscriptionStatements(subscription, conninfo, current, failover) {
+			c.logger.Infof("syncing subscription %q in database %q", subscription.Name, database)
+			if _, err := c.pgDb.Exec(statement); err != nil {
+				errors = append(errors, fmt.Sprintf("could not sync subscription %s in database %s: %v", subscription.Name, database, err))
+				subscriptionErrors[subscription.Name] = err.Error()
+				break
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		if currentSubscriptions, err = c.getSubscriptions(); err != nil {
+			return nil, fmt.Errorf("database %s: %v", database, err)
+		}
+	}
+	status := make([]cpov1.SubscriptionStatus, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		current, exists := currentSubscriptions[subscription.Name]
+		subscriptionStatus := cpov1.SubscriptionStatus{
+			Name:        subscription.Name,
+			Database:    database,
+			Enabled:     current.enabled,
+			ApplyErrors: current.applyErrors,
+			SyncErrors:  current.syncErrors,
+			Error:       subscriptionErrors[subscription.Name],
+		}
+		if exists && current.applyLag >= 0 {
+			subscriptionStatus.ApplyLag = (time.Duration(current.applyLag) * time.Second).String()
+		}
+		status = append(status, subscriptionStatus)
+	}
+
+	if len(errors) > 0 {
+		return status, fmt.Errorf("%s", strings.Join(errors, `', '`))
+	}
+	return status, nil
+}
+
+func (c *Cluster) setSubscriptionsStatus(status []cpov1.SubscriptionStatus) error {
+	if len(status) == 0 {
+		status = nil
+	}
+	if reflect.DeepEqual(status, c.Status.Subscriptions) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDSubscriptionsStatus(c.clusterName(), status); err != nil {
+		return err
+	}
+	c.Status.Subscriptions = status
+	return nil
+}
@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+func TestPublicationStatements(t *testing.T) {
+	tests := []struct {
+		subTest            string
+		publication        cpov1.Publication
+		current            *publicationDefinition
+		expectedStatements []string
+	}{
+		{
+			subTest:            "create publication for tables",
+			publication:        cpov1.Publication{Name: "orders", Tables: []string{"sales.invoices", "orders"}},
+			expectedStatements: []string{`CREATE PUBLICATION "orders" FOR TABLE "public"."orders", "sales"."invoices"`},
+		},
+		{
+			subTest:            "create publication for all tables",
+			publication:        cpov1.Publication{Name: "everything", AllTables: true},
+			expectedStatements: []string{`CREATE PUBLICATION "everything" FOR ALL TABLES`},
+		},
+		{
+			subTest:            "change table list",
+			publication:        cpov1.Publication{Name: "orders", Tables: []string{"orders", "sales.invoices"}},
+			current:            &publicationDefinition{tables: []string{"public.orders"}},
+			expectedStatements: []string{`ALTER PUBLICATION "orders" SET TABLE "public"."orders", "sales"."invoices";`},
+		},
+		{
+			subTest:     "recreate publication for all tables",
+			publication: cpov1.Publication{Name: "orders", AllTables: true},
+			current:     &publicationDefinition{tables: []string{"public.orders"}},
+			expectedStatements: []string{
+				`DROP PUBLICATION "orders"`,
+				`CREATE PUBLICATION "orders" FOR ALL TABLES`,
+			},
+		},
+		{
+			subTest:     "publication in sync",
+			publication: cpov1.Publication{Name: "orders", Tables: []string{"orders"}},
+			current:     &publicationDefinition{tables: []string{"public.orders"}},
+		},
+	}
+
+	for _, tt := range tests {
+		statements := publicationStatements(tt.publication, tt.current)
+		if !reflect.DeepEqual(statements, tt.expectedStatements) {
+			t.Errorf("%s: expected statements %#v, got %#v", tt.subTest, tt.expectedStatements, statements)
+		}
+	}
+}
+
+func TestSubscriptionStatements(t *testing.T) {
+	subscription := cpov1.Subscription{Name: "shop_orders", Database: "reporting", SourceCluster: "shop",
+		Publications: []string{"orders", "customers"}}
+	conninfo := "host=shop port=5432 dbname=reporting user=cpo_logical_replication password=secret sslmode=require"
+
+	tests := []struct {
+		subTest            string
+		current            *subscriptionState
+		failover           bool
+		expectedStatements []string
+	}{
+		{
+			subTest: "create subscription",
+			expectedStatements: []string{
+				`CREATE SUBSCRIPTION "shop_orders" CONNECTION '` + conninfo + `' PUBLICATION "customers", "orders"`,
+			},
+		},
+		{
+			subTest:  "create failover subscription",
+			failover: true,
+			expectedStatements: []string{
+				`CREATE SUBSCRIPTION "shop_orders" CONNECTION '` + conninfo + `' PUBLICATION "customers", "orders" WITH (failover = true)`,
+			},
+		},
+		{
+			subTest: "change connection and publications",
+			current: &subscriptionState{conninfo: "host=shop password=old", publications: []string{"orders"}},
+			expectedStatements: []string{
+				`ALTER SUBSCRIPTION "shop_orders" CONNECTION '` + conninfo + `'`,
+				`ALTER SUBSCRIPTION "shop_orders" SET PUBLICATION "customers", "orders"`,
+			},
+		},
+		{
+			subTest:            "subscription in sync",
+			current:            &subscriptionState{conninfo: conninfo, publications: []string{"orders", "customers"}},
+			expectedStatements: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		statements := subscriptionStatements(subscription, conninfo, tt.current, tt.failover)
+		if !reflect.DeepEqual(statements, tt.expectedStatements) {
+			t.Errorf("%s: expected statements %#v, got %#v", tt.subTest, tt.expectedStatements, statements)
+		}
+	}
+}
+
+func TestSubscriptionConninfo(t *testing.T) {
+	client, clientSet := newFakeK8sTestClient()
+	client.SecretsGetter = clientSet.CoreV1()
+	cluster := New(Config{OpConfig: config.Config{Auth: config.Auth{
+		SecretNameTemplate: "{username}.{cluster}.credentials",
+	}}}, client, cpov1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+	}, logger, eventRecorder)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cpo-logical-replication.shop.credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"username": []byte(constants.LogicalReplicationUserName),
+			"password": []byte(`it's a \secret$`),
+		},
+	}
+	if _, err := client.Secrets("default").Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("%s: could not create secret: %v", t.Name(), err)
+	}
+
+	conninfo, err := cluster.subscriptionConninfo(cpov1.Subscription{Name: "orders", Database: "reporting", SourceCluster: "shop"})
+	if err != nil {
+		t.Fatalf("%s: could not get conninfo: %v", t.Name(), err)
+	}
+	expected := `host='shop' port='5432' dbname='reporting' user='cpo_logical_replication' password='it\'s a \\secret$' sslmode=require`
+	if conninfo != expected {
+		t.Errorf("%s: expected conninfo %s, got %s", t.Name(), expected, conninfo)
+	}
+}
+
+func TestLogicalReplicationUserMembership(t *testing.T) {
+	tests := []struct {
+		pgVersion        string
+		expectedMemberOf []string
+	}{
+		{pgVersion: "13", expectedMemberOf: nil},
+		{pgVersion: "14", expectedMemberOf: []string{constants.ReadAllDataRoleName}},
+		{pgVersion: "17", expectedMemberOf: []string{constants.ReadAllDataRoleName}},
+	}
+
+	for _, tt := range tests {
+		pg := cpov1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+			Spec: cpov1.PostgresSpec{
+				PostgresqlParam: cpov1.PostgresqlParam{PgVersion: tt.pgVersion},
+				LogicalReplication: &cpov1.LogicalReplication{
+					Publications: []cpov1.Publication{{Name: "orders", Database: "shop", Tables: []string{"orders"}}},
+				},
+			},
+		}
+		cluster := New(Config{OpConfig: config.Config{Auth: config.Auth{
+			SuperUsername:       superUserName,
+			ReplicationUsername: replicationUserName,
+		}}}, k8sutil.KubernetesClient{}, pg, logger, eventRecorder)
+		if err := cluster.initSystemUsers(); err != nil {
+			t.Fatalf("PostgreSQL %s: could not init system users: %v", tt.pgVersion, err)
+		}
+		user, exists := cluster.systemUsers[constants.LogicalReplicationUserName]
+		if !exists {
+			t.Fatalf("PostgreSQL %s: logical replication user is missing", tt.pgVersion)
+		}
+		if !reflect.DeepEqual(user.MemberOf, tt.expectedMemberOf) {
+			t.Errorf("PostgreSQL %s: expected logical replication user to be member of %v, got %v", tt.pgVersion, tt.expectedMemberOf, user.MemberOf)
+		}
+	}
+}
@@ -45,6 +45,11 @@ func (c *Cluster) GetDesiredMajorVersionAsInt() int {
 	return VersionMap[c.GetDesiredMajorVersion()]
 }
 
+// getDesiredMajorVersionNumber returns the major version to use as plain number, e.g. 17
+func (c *Cluster) getDesiredMajorVersionNumber() int {
+	return c.GetDesiredMajorVersionAsInt() / 10000
+}
+
 // GetDesiredMajorVersion returns major version to use, incl. potential auto upgrade
 func (c *Cluster) GetDesiredMajorVersion() string {
 
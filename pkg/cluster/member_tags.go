@@ -0,0 +1,384 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+// noLoadBalanceLabel excludes members tagged noloadbalance from the replica service
+const noLoadBalanceLabel = "member.cpo.opensource.cybertec.at/noloadbalance"
+
+// patroniConfigFile is the Patroni configuration rendered by Spilo on startup
+const patroniConfigFile = "/home/postgres/postgres.yml"
+
+// memberTagsMountPath holds the tags of each member in a file named after its pod
+const memberTagsMountPath = "/etc/patroni/member-tags"
+
+// patroniPIDFunction finds the Patroni process started with the given configuration file
+const patroniPIDFunction = `
+def patroni_pid(path):
+    for pid in os.listdir('/proc'):
+        if not pid.isdigit() or int(pid) == os.getpid():
+            continue
+        try:
+            with open('/proc/%s/cmdline' % pid, 'rb') as f:
+                args = f.read().decode(errors='ignore').split('\0')
+        except OSError:
+            continue
+        if path in args and any(os.path.basename(arg) == 'patroni' for arg in args):
+            return int(pid)
+`
+
+// memberTagsFunctions replaces the tags managed by the operator in the Patroni configuration of a
+// member. Other tags are left untouched.
+const memberTagsFunctions = `import json, os, signal, socket, sys, time, yaml
+
+def update_tags(path, new_tags):
+    with open(path) as f:
+        config = yaml.safe_load(f)
+    tags = config.get('tags') or {}
+    for key in ('nofailover', 'noloadbalance', 'clonefrom', 'nosync', 'failover_priority'):
+        tags.pop(key, None)
+    tags.update(new_tags)
+    config['tags'] = tags
+    with open(path, 'w') as f:
+        yaml.safe_dump(config, f, default_flow_style=False)
+`
+
+// updateMemberTagsScript applies the tags passed as JSON to the running member, which is reloaded
+// by the operator afterwards.
+const updateMemberTagsScript = memberTagsFunctions + `
+update_tags(sys.argv[1], json.loads(sys.argv[2]))
+`
+
+// restoreMemberTagsScript runs as postStart hook of the Spilo container. Spilo renders the Patroni
+// configuration from scratch whenever the container starts, so the tags of the member are read
+// from the mounted config map and written again once Patroni is up, followed by a SIGHUP to reload
+// the configuration. Before that, the pod gets the noloadbalance label derived from its tags, since
+// the container is not ready before the hook finished and the replica service selects on the label.
+// Failures are only printed, as a failing hook would kill the container. An unlabeled pod stays out
+// of the replica service until the operator labels it.
+const restoreMemberTagsScript = memberTagsFunctions + patroniPIDFunction + `
+import ssl, urllib.request
+
+path, tags_file, label = sys.argv[1], os.path.join(sys.argv[2], socket.gethostname()), sys.argv[3]
+
+def label_pod(noloadbalance):
+    account = '/var/run/secrets/kubernetes.io/serviceaccount'
+    with open(os.path.join(account, 'token')) as f:
+        token = f.read().strip()
+    with open(os.path.join(account, 'namespace')) as f:
+        namespace = f.read().strip()
+    host = os.environ['KUBERNETES_SERVICE_HOST']
+    if ':' in host:
+        host = '[%s]' % host
+    url = 'https://%s:%s/api/v1/namespaces/%s/pods/%s' % (
+        host, os.environ['KUBERNETES_SERVICE_PORT'], namespace, socket.gethostname())
+    body = json.dumps({'metadata': {'labels': {label: 'true' if noloadbalance else 'false'}}}).encode()
+    request = urllib.request.Request(url, data=body, method='PATCH', headers={
+        'Authorization': 'Bearer ' + token, 'Content-Type': 'application/merge-patch+json'})
+    context = ssl.create_default_context(cafile=os.path.join(account, 'ca.crt'))
+    urllib.request.urlopen(request, context=context, timeout=10).close()
+
+def api_listening():
+    with open(path) as f:
+        listen = (yaml.safe_load(f).get('restapi') or {}).get('listen') or ':8008'
+    try:
+        socket.create_connection(('127.0.0.1', int(listen.rsplit(':', 1)[1])), 1).close()
+        return True
+    except OSError:
+        return False
+
+new_tags = None
+try:
+    if os.path.exists(tags_file):
+        with open(tags_file) as f:
+            new_tags = json.load(f)
+    label_pod((new_tags or {}).get('noloadbalance', False))
+except Exception as e:
+    print('could not label pod: %s' % e)
+
+try:
+    if new_tags is not None:
+        for _ in range(300):
+            pid = patroni_pid(path) if os.path.exists(path) else None
+            if pid and api_listening():
+                update_tags(path, new_tags)
+                os.kill(pid, signal.SIGHUP)
+                break
+            time.sleep(1)
+except Exception as e:
+    print('could not restore member tags: %s' % e)
+`
+
+// hasMemberTags reports whether the replica service has to exclude members tagged noloadbalance.
+func hasMemberTags(spec *cpov1.PostgresSpec) bool {
+	return len(spec.Patroni.MemberTags) > 0
+}
+
+// desiredMemberTags merges all entries of member_tags matching the pod, later entries take precedence.
+func (c *Cluster) desiredMemberTags(pod *v1.Pod) (patroni.MemberTags, error) {
+	tags := patroni.MemberTags{}
+	ordinal, hasOrdinal := podOrdinal(pod.Name)
+
+	for i, entry := range c.Spec.Patroni.MemberTags {
+		matches := false
+		if hasOrdinal {
+			for _, o := range entry.Ordinals {
+				if o == ordinal {
+					matches = true
+					break
+				}
+			}
+		}
+		if !matches && entry.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(entry.Selector)
+			if err != nil {
+				return tags, fmt.Errorf("invalid selector in member_tags[%d]: %v", i, err)
+			}
+			matches = !selector.Empty() && selector.Matches(labels.Set(pod.Labels))
+		}
+		if !matches {
+			continue
+		}
+
+		if entry.NoFailover != nil {
+			tags.NoFailover = *entry.NoFailover
+		}
+		if entry.NoLoadBalance != nil {
+			tags.NoLoadBalance = *entry.NoLoadBalance
+		}
+		if entry.CloneFrom != nil {
+			tags.CloneFrom = *entry.CloneFrom
+		}
+		if entry.NoSync != nil {
+			tags.NoSync = *entry.NoSync
+		}
+		if entry.FailoverPriority != nil {
+			priority := *entry.FailoverPriority
+			tags.FailoverPriority = &priority
+		}
+	}
+	return tags, nil
+}
+
+func podOrdinal(podName string) (int32, bool) {
+	idx := strings.LastIndex(podName, "-")
+	if idx < 0 {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseInt(podName[idx+1:], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int32(ordinal), true
+}
+
+// syncPatroniMemberTags applies the tags of member_tags to the running members. Spilo renders the
+// same configuration for every pod of the statefulset, so the tags of each member are kept in a
+// config map which the Spilo container applies whenever it starts. Running members get the tags
+// written into their Patroni configuration and reloaded right away.
+func (c *Cluster) syncPatroniMemberTags() error {
+	pods, err := c.listPodsOfType(TYPE_POSTGRESQL)
+	if err != nil {
+		return err
+	}
+
+	if err := c.syncMemberTagsConfigMap(pods); err != nil {
+		return err
+	}
+
+	var errors []string
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		if err := c.syncPodMemberTags(pod); err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", pod.Name, err))
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("could not sync member tags of %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (c *Cluster) getMemberTagsConfigMapName() string {
+	return fmt.Sprintf("%s-member-tags", c.Name)
+}
+
+// generateMemberTagsConfigMapData returns the tags of every member as JSON, keyed by pod name. Members
+// which do not run yet are matched with the labels of the pod template.
+func (c *Cluster) generateMemberTagsConfigMapData(pods []v1.Pod) (map[string]string, error) {
+	data := make(map[string]string)
+	members := make(map[string]*v1.Pod)
+	for i := range pods {
+		members[pods[i].Name] = &pods[i]
+	}
+	for ordinal := int32(0); ordinal < c.getNumberOfInstances(&c.Spec); ordinal++ {
+		podName := fmt.Sprintf("%s-%d", c.Name, ordinal)
+		if _, exists := members[podName]; !exists {
+			members[podName] = &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:   podName,
+				Labels: c.labelsSetWithType(true, TYPE_POSTGRESQL, true),
+			}}
+		}
+	}
+
+	for podName, pod := range members {
+		tags, err := c.desiredMemberTags(pod)
+		if err != nil {
+			return nil, err
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal tags: %v", err)
+		}
+		data[podName] = string(tagsJSON)
+	}
+	return data, nil
+}
+
+// syncMemberTagsConfigMap keeps the tags of the members in a config map, which is removed once
+// member_tags is no longer defined
+func (c *Cluster) syncMemberTagsConfigMap(pods []v1.Pod) error {
+	if !hasMemberTags(&c.Spec) {
+		return c.deleteMemberTagsConfigMap()
+	}
+
+	name := c.getMemberTagsConfigMapName()
+	data, err := c.generateMemberTagsConfigMapData(pods)
+	if err != nil {
+		return err
+	}
+
+	configMap, err := c.KubeClient.ConfigMaps(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get member tags config map: %v", err)
+		}
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: c.Namespace,
+				Labels:    c.labelsSet(true),
+			},
+			Data: data,
+		}
+		if _, err = c.KubeClient.ConfigMaps(c.Namespace).Create(context.TODO(), configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("could not create member tags config map: %v", err)
+		}
+		c.logger.Infof("created member tags config map %q", name)
+		return nil
+	}
+
+	if reflect.DeepEqual(configMap.Data, data) {
+		return nil
+	}
+	configMap.Data = data
+	if _, err = c.KubeClient.ConfigMaps(c.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not update member tags config map: %v", err)
+	}
+	c.logger.Infof("updated member tags config map %q", name)
+	return nil
+}
+
+func (c *Cluster) deleteMemberTagsConfigMap() error {
+	err := c.KubeClient.ConfigMaps(c.Namespace).Delete(context.TODO(), c.getMemberTagsConfigMapName(), c.deleteOptions)
+	if err != nil {
+		if k8sutil.ResourceNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("could not delete member tags config map: %v", err)
+	}
+	c.logger.Infof("member tags config map %q has been deleted", c.getMemberTagsConfigMapName())
+	return nil
+}
+
+// generateMemberTagsVolume mounts the member tags into the Spilo container. The config map is
+// optional, so that pods can start before the operator created it.
+func (c *Cluster) generateMemberTagsVolume() cpov1.AdditionalVolume {
+	defaultMode := int32(0644)
+	return cpov1.AdditionalVolume{
+		Name:             "member-tags",
+		MountPath:        memberTagsMountPath,
+		TargetContainers: []string{constants.PostgresContainerName},
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: c.getMemberTagsConfigMapName()},
+				DefaultMode:          &defaultMode,
+				Optional:             util.True(),
+			},
+		},
+	}
+}
+
+// generateMemberTagsLifecycle restores the tags and the noloadbalance label of a member whenever its
+// Spilo container starts
+func generateMemberTagsLifecycle() *v1.Lifecycle {
+	return &v1.Lifecycle{
+		PostStart: &v1.LifecycleHandler{
+			Exec: &v1.ExecAction{
+				Command: []string{"python3", "-c", restoreMemberTagsScript, patroniConfigFile, memberTagsMountPath, noLoadBalanceLabel},
+			},
+		},
+	}
+}
+
+func (c *Cluster) syncPodMemberTags(pod *v1.Pod) error {
+	desired, err := c.desiredMemberTags(pod)
+	if err != nil {
+		return err
+	}
+
+	memberData, err := c.patroni.GetMemberData(pod)
+	if err != nil {
+		return fmt.Errorf("could not get member data: %v", err)
+	}
+	if !reflect.DeepEqual(memberData.Tags, desired) {
+		tagsJSON, err := json.Marshal(desired)
+		if err != nil {
+			return fmt.Errorf("could not marshal tags: %v", err)
+		}
+		c.logger.Infof("setting Patroni tags of member %q to %s", pod.Name, tagsJSON)
+		podName := util.NameFromMeta(pod.ObjectMeta)
+		if _, err := c.ExecCommand(&podName, "python3", "-c", updateMemberTagsScript, patroniConfigFile, string(tagsJSON)); err != nil {
+			return fmt.Errorf("could not update Patroni configuration: %v", err)
+		}
+		if err := c.patroni.Reload(pod); err != nil {
+			return fmt.Errorf("could not reload Patroni configuration: %v", err)
+		}
+	}
+
+	if !hasMemberTags(&c.Spec) {
+		return nil
+	}
+	label := strconv.FormatBool(desired.NoLoadBalance)
+	if pod.Labels[noLoadBalanceLabel] == label {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]map[string]map[string]string{"metadata": {"labels": {noLoadBalanceLabel: label}}})
+	if err != nil {
+		return fmt.Errorf("could not marshal label patch: %v", err)
+	}
+	if _, err := c.KubeClient.Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("could not label pod: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+func TestDesiredMemberTags(t *testing.T) {
+	priority := int32(5)
+	memberTags := []cpov1.PatroniMemberTags{
+		{
+			Ordinals:   []int32{0, 2},
+			NoFailover: util.True(),
+		},
+		{
+			Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "b"}},
+			NoLoadBalance: util.True(),
+			NoFailover:    util.False(),
+		},
+		{
+			Ordinals:         []int32{1},
+			FailoverPriority: &priority,
+		},
+	}
+
+	tests := []struct {
+		subTest  string
+		podName  string
+		labels   map[string]string
+		expected patroni.MemberTags
+	}{
+		{
+			subTest:  "match by ordinal",
+			podName:  "acid-test-cluster-0",
+			expected: patroni.MemberTags{NoFailover: true},
+		},
+		{
+			subTest:  "later entries take precedence",
+			podName:  "acid-test-cluster-2",
+			labels:   map[string]string{"zone": "b"},
+			expected: patroni.MemberTags{NoLoadBalance: true},
+		},
+		{
+			subTest:  "failover priority",
+			podName:  "acid-test-cluster-1",
+			expected: patroni.MemberTags{FailoverPriority: &priority},
+		},
+		{
+			subTest:  "no matching entry",
+			podName:  "acid-test-cluster-3",
+			labels:   map[string]string{"zone": "a"},
+			expected: patroni.MemberTags{},
+		},
+	}
+
+	cluster := New(Config{}, k8sutil.KubernetesClient{}, cpov1.Postgresql{
+		Spec: cpov1.PostgresSpec{Patroni: cpov1.Patroni{MemberTags: memberTags}},
+	}, logger, eventRecorder)
+
+	for _, tt := range tests {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: tt.podName, Labels: tt.labels}}
+		tags, err := cluster.desiredMemberTags(pod)
+		if err != nil {
+			t.Fatalf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+		}
+		if !reflect.DeepEqual(tags, tt.expected) {
+			t.Errorf("%s [%s]: expected tags %#v, got %#v", t.Name(), tt.subTest, tt.expected, tags)
+		}
+	}
+}
+
+func TestReplicaServiceExcludesNoLoadBalance(t *testing.T) {
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Resources: config.Resources{
+					ClusterLabels:    map[string]string{"application": "cpo"},
+					ClusterNameLabel: "cluster.cpo.opensource.cybertec.at/name",
+					PodRoleLabel:     "member.cpo.opensource.cybertec.at/role",
+				},
+			},
+		}, k8sutil.KubernetesClient{}, cpov1.Postgresql{}, logger, eventRecorder)
+
+	spec := cpov1.PostgresSpec{}
+	if _, ok := cluster.generateService(Replica, &spec).Spec.Selector[noLoadBalanceLabel]; ok {
+		t.Errorf("%s: replica selector must not depend on member tags when none are defined", t.Name())
+	}
+
+	spec.Patroni.MemberTags = []cpov1.PatroniMemberTags{{Ordinals: []int32{1}, NoLoadBalance: util.True()}}
+	if value := cluster.generateService(Replica, &spec).Spec.Selector[noLoadBalanceLabel]; value != "false" {
+		t.Errorf("%s: expected replica selector to exclude noloadbalance members, got %q", t.Name(), value)
+	}
+	if _, ok := cluster.generateService(Master, &spec).Spec.Selector[noLoadBalanceLabel]; ok {
+		t.Errorf("%s: master selector must not depend on member tags", t.Name())
+	}
+}
+
+func TestSyncMemberTagsConfigMap(t *testing.T) {
+	client, clientSet := newFakeK8sTestClient()
+	client.ConfigMapsGetter = clientSet.CoreV1()
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				PodManagementPolicy: "ordered_ready",
+				Resources: config.Resources{
+					ClusterLabels:        map[string]string{"application": "cpo"},
+					ClusterNameLabel:     "cluster.cpo.opensource.cybertec.at/name",
+					DefaultCPURequest:    "300m",
+					DefaultCPULimit:      "300m",
+					DefaultMemoryRequest: "300Mi",
+					DefaultMemoryLimit:   "300Mi",
+					MinInstances:         -1,
+					MaxInstances:         -1,
+				},
+			},
+		}, client, cpov1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+			Spec: cpov1.PostgresSpec{
+				NumberOfInstances: 3,
+				Volume:            cpov1.Volume{Size: "1G"},
+				PostgresqlParam:   cpov1.PostgresqlParam{PgVersion: "17"},
+				Patroni: cpov1.Patroni{MemberTags: []cpov1.PatroniMemberTags{
+					{Ordinals: []int32{2}, NoFailover: util.True()},
+					{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "b"}}, NoLoadBalance: util.True()},
+				}},
+			},
+		}, logger, eventRecorder)
+
+	// pod 0 runs with the label of the selector, the other members are matched with the pod template
+	pods := []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-0", Labels: map[string]string{"zone": "b"}}}}
+	if err := cluster.syncMemberTagsConfigMap(pods); err != nil {
+		t.Fatalf("%s: could not sync member tags config map: %v", t.Name(), err)
+	}
+
+	configMap, err := client.ConfigMaps("default").Get(context.TODO(), cluster.getMemberTagsConfigMapName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get member tags config map: %v", t.Name(), err)
+	}
+	expected := map[string]string{
+		"acid-test-cluster-0": `{"noloadbalance":true}`,
+		"acid-test-cluster-1": `{}`,
+		"acid-test-cluster-2": `{"nofailover":true}`,
+	}
+	if !reflect.DeepEqual(configMap.Data, expected) {
+		t.Errorf("%s: expected member tags %#v, got %#v", t.Name(), expected, configMap.Data)
+	}
+
+	// the tags are restored whenever the Spilo container starts
+	sts, err := cluster.generateStatefulSet(&cluster.Spec)
+	if err != nil {
+		t.Fatalf("%s: could not generate statefulset: %v", t.Name(), err)
+	}
+	spilo := sts.Spec.Template.Spec.Containers[0]
+	if spilo.Lifecycle == nil || spilo.Lifecycle.PostStart == nil {
+		t.Errorf("%s: expected postStart hook restoring the member tags", t.Name())
+	} else if command := spilo.Lifecycle.PostStart.Exec.Command; command[len(command)-1] != noLoadBalanceLabel {
+		t.Errorf("%s: expected postStart hook to set the label %s, got %v", t.Name(), noLoadBalanceLabel, command[3:])
+	}
+	// the label differs per member, so it must not be part of the pod template
+	if _, ok := sts.Spec.Template.Labels[noLoadBalanceLabel]; ok {
+		t.Errorf("%s: pod template must not carry the label %s", t.Name(), noLoadBalanceLabel)
+	}
+	mounted := false
+	for _, mount := range spilo.VolumeMounts {
+		mounted = mounted || mount.MountPath == memberTagsMountPath
+	}
+	if !mounted {
+		t.Errorf("%s: expected member tags to be mounted at %s", t.Name(), memberTagsMountPath)
+	}
+
+	cluster.Spec.Patroni.MemberTags = nil
+	if err := cluster.syncMemberTagsConfigMap(pods); err != nil {
+		t.Fatalf("%s: could not sync member tags config map: %v", t.Name(), err)
+	}
+	if _, err := client.ConfigMaps("default").Get(context.TODO(), cluster.getMemberTagsConfigMapName(), metav1.GetOptions{}); !k8sutil.ResourceNotFound(err) {
+		t.Errorf("%s: expected member tags config map to be deleted, got %v", t.Name(), err)
+	}
+}
@@ -0,0 +1,155 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+// requestedPasswordRotations returns the roles listed in the rotation annotation of the Postgresql resource
+func (c *Cluster) requestedPasswordRotations() []string {
+	value, exists := c.Postgresql.Annotations[constants.RotatePasswordAnnotation]
+	if !exists {
+		return nil
+	}
+	roles := make([]string, 0)
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// passwordRotationRequested reports whether an immediate rotation of the role has been requested,
+// either by listing it in the annotation of the Postgresql resource or by annotating its secret
+func (c *Cluster) passwordRotationRequested(secretUsername string, secret *v1.Secret) bool {
+	if _, exists := secret.Annotations[constants.RotatePasswordAnnotation]; exists {
+		return true
+	}
+	return util.SliceContains(c.requestedPasswordRotations(), secretUsername)
+}
+
+// rotatePasswordOnDemand rotates the password of a role right away. Roles with scheduled rotation
+// keep their mode, i.e. they get a new rotation user unless they are rotated in place, all other
+// roles are rotated in place. Pods reading the credentials from the secret are replaced in both
+// modes. It returns the message for the update of the secret.
+func (c *Cluster) rotatePasswordOnDemand(
+	secret *v1.Secret,
+	secretUsername string,
+	roleOrigin spec.RoleOrigin,
+	scheduledRotation bool,
+	currentTime time.Time,
+	retentionUsers *[]string) string {
+	secretName := util.NameFromMeta(secret.ObjectMeta)
+	updateSecretMsg := fmt.Sprintf("removing password rotation request from secret %s", secretName)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	delete(secret.Annotations, constants.RotatePasswordAnnotation)
+
+	if c.Spec.StandbyCluster != nil {
+		c.logger.Warningf("could not rotate password of role %s: roles of a standby cluster are managed by the source cluster", secretUsername)
+		return updateSecretMsg
+	}
+	if roleOrigin == spec.RoleOriginInfrastructure {
+		c.logger.Warningf("could not rotate password of role %s: rotation of %s roles is not supported", secretUsername, roleOrigin)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "PasswordRotation",
+			"Password rotation of role %s is not supported", secretUsername)
+		return updateSecretMsg
+	}
+
+	if scheduledRotation && !util.SliceContains(c.Spec.UsersWithInPlaceSecretRotation, secretUsername) {
+		rotationUsername := fmt.Sprintf("%s%s", secretUsername, currentTime.Format(constants.RotationUserDateFormat))
+		// the rotation user of the same day only gets a new password
+		if string(secret.Data["username"]) != rotationUsername {
+			secret.Data["username"] = []byte(rotationUsername)
+			c.logger.Infof("updating username in secret %s and creating rotation user %s in the database", secretName, rotationUsername)
+			*retentionUsers = append(*retentionUsers, secretUsername)
+		}
+	}
+	if err := c.replacePodsAfterPasswordRotation(secretUsername, roleOrigin); err != nil {
+		c.logger.Warningf("could not replace pods after password rotation of role %s: %v", secretUsername, err)
+	}
+
+	secret.Data["password"] = []byte(util.RandomPassword(constants.PasswordLength))
+	if scheduledRotation {
+		_, nextRotationDateStr := c.getNextRotationDate(currentTime)
+		secret.Data["nextRotation"] = []byte(nextRotationDateStr)
+	}
+	secret.Annotations[constants.PasswordRotatedAtAnnotation] = currentTime.UTC().Format(time.RFC3339)
+	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "PasswordRotation",
+		"Password of role %s has been rotated on request", secretUsername)
+
+	return fmt.Sprintf("updating secret %s due to requested password rotation", secretName)
+}
+
+// removePasswordRotationRequest removes the rotation annotation from the Postgresql resource once
+// the passwords of all listed roles have been rotated
+func (c *Cluster) removePasswordRotationRequest(generatedSecrets map[string]*v1.Secret) error {
+	requested := c.requestedPasswordRotations()
+	if requested == nil {
+		return nil
+	}
+	for _, role := range requested {
+		if _, exists := generatedSecrets[role]; !exists {
+			c.logger.Warningf("could not rotate password of role %s: role has no secret", role)
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{constants.RotatePasswordAnnotation: nil},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("could not marshal patch: %v", err)
+	}
+	if _, err = c.KubeClient.Postgresqls(c.Namespace).Patch(
+		context.TODO(), c.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("could not remove annotation %s: %v", constants.RotatePasswordAnnotation, err)
+	}
+
+	// the annotations may be shared with the informer cache
+	annotations := make(map[string]string, len(c.Postgresql.Annotations))
+	for key, value := range c.Postgresql.Annotations {
+		if key != constants.RotatePasswordAnnotation {
+			annotations[key] = value
+		}
+	}
+	c.specMu.Lock()
+	c.Postgresql.Annotations = annotations
+	c.specMu.Unlock()
+	return nil
+}
+
+// syncRotationAnnotations copies the rotation annotations of the source secret to the target
+// and reports whether the target has changed
+func syncRotationAnnotations(source, target *v1.Secret) bool {
+	changed := false
+	if _, requested := source.Annotations[constants.RotatePasswordAnnotation]; !requested {
+		if _, exists := target.Annotations[constants.RotatePasswordAnnotation]; exists {
+			delete(target.Annotations, constants.RotatePasswordAnnotation)
+			changed = true
+		}
+	}
+	if rotatedAt, exists := source.Annotations[constants.PasswordRotatedAtAnnotation]; exists &&
+		target.Annotations[constants.PasswordRotatedAtAnnotation] != rotatedAt {
+		if target.Annotations == nil {
+			target.Annotations = map[string]string{}
+		}
+		target.Annotations[constants.PasswordRotatedAtAnnotation] = rotatedAt
+		changed = true
+	}
+	return changed
+}
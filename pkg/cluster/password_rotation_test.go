@@ -0,0 +1,219 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	fakecpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+func TestRotatePasswordOnDemand(t *testing.T) {
+	pg := cpov1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+		Spec: cpov1.PostgresSpec{
+			Users:                          map[string]cpov1.UserDefinition{"foo": {}, "bar": {}, "baz": {}, "qux": {}},
+			UsersWithSecretRotation:        []string{"foo"},
+			UsersWithInPlaceSecretRotation: []string{"bar"},
+		},
+	}
+	acidClientSet := fakecpov1.NewSimpleClientset(&pg)
+	client := k8sutil.KubernetesClient{
+		SecretsGetter:     fake.NewSimpleClientset().CoreV1(),
+		PostgresqlsGetter: acidClientSet.CpoV1(),
+	}
+	recorder := record.NewFakeRecorder(10)
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Auth: config.Auth{
+					SuperUsername:            superUserName,
+					ReplicationUsername:      replicationUserName,
+					SecretNameTemplate:       "{username}.{cluster}.credentials",
+					PasswordRotationInterval: 90,
+				},
+				Resources: config.Resources{
+					ClusterLabels:    map[string]string{"application": "cpo"},
+					ClusterNameLabel: "cluster.cpo.opensource.cybertec.at/name",
+				},
+			},
+		}, client, pg, logger, recorder)
+	secrets := cluster.KubeClient.Secrets("default")
+
+	if err := cluster.initUsers(); err != nil {
+		t.Fatalf("could not init users: %v", err)
+	}
+	if err := cluster.syncSecrets(); err != nil {
+		t.Fatalf("could not sync secrets: %v", err)
+	}
+	passwords := map[string]string{}
+	for _, username := range []string{"foo", "bar", "baz", "qux", superUserName} {
+		secret, err := secrets.Get(context.TODO(), cluster.credentialSecretName(username), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("could not get secret of %s: %v", username, err)
+		}
+		passwords[username] = string(secret.Data["password"])
+	}
+
+	// request rotations on the Postgresql resource and on the secret of baz
+	cluster.Postgresql.Annotations = map[string]string{constants.RotatePasswordAnnotation: "bar, postgres,unknown"}
+	bazSecret, _ := secrets.Get(context.TODO(), cluster.credentialSecretName("baz"), metav1.GetOptions{})
+	bazSecret.Annotations = map[string]string{constants.RotatePasswordAnnotation: "true"}
+	if _, err := secrets.Update(context.TODO(), bazSecret, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("could not update secret: %v", err)
+	}
+	if err := cluster.syncSecrets(); err != nil {
+		t.Fatalf("could not sync secrets: %v", err)
+	}
+
+	tests := []struct {
+		username         string
+		expectedUsername string
+		rotated          bool
+	}{
+		{"bar", "bar", true},
+		{"baz", "baz", true},
+		{"qux", "qux", false},
+	}
+	for _, tt := range tests {
+		secret, err := secrets.Get(context.TODO(), cluster.credentialSecretName(tt.username), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("could not get secret of %s: %v", tt.username, err)
+		}
+		if username := string(secret.Data["username"]); username != tt.expectedUsername {
+			t.Errorf("%s [%s]: expected username %q, got %q", t.Name(), tt.username, tt.expectedUsername, username)
+		}
+		if rotated := string(secret.Data["password"]) != passwords[tt.username]; rotated != tt.rotated {
+			t.Errorf("%s [%s]: expected password rotated: %v, got %v", t.Name(), tt.username, tt.rotated, rotated)
+		}
+		if _, exists := secret.Annotations[constants.PasswordRotatedAtAnnotation]; exists != tt.rotated {
+			t.Errorf("%s [%s]: expected rotation time recorded: %v, got %v", t.Name(), tt.username, tt.rotated, exists)
+		}
+		if _, exists := secret.Annotations[constants.RotatePasswordAnnotation]; exists {
+			t.Errorf("%s [%s]: expected rotation request to be removed from the secret", t.Name(), tt.username)
+		}
+	}
+
+	// the superuser keeps its password until the rotation has been confirmed
+	superuserSecret, err := secrets.Get(context.TODO(), cluster.credentialSecretName(superUserName), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get secret of %s: %v", superUserName, err)
+	}
+	if string(superuserSecret.Data["password"]) != passwords[superUserName] || len(superuserSecret.Data[pendingPasswordKey]) == 0 {
+		t.Errorf("%s: expected pending password for %s next to the current one", t.Name(), superUserName)
+	}
+
+	expectedEvents := []string{
+		"Normal PasswordRotation Password of role bar has been rotated on request",
+		"Normal PasswordRotation Password of role baz has been rotated on request",
+	}
+	events := make([]string, 0)
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	sort.Strings(events)
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("%s: expected events %v, got %v", t.Name(), expectedEvents, events)
+	}
+
+	// roles pick up the rotated credentials
+	if user := cluster.pgUsers["bar"]; user.Rotated || user.Password == passwords["bar"] {
+		t.Errorf("%s: expected in-place rotation for bar, got %#v", t.Name(), user)
+	}
+
+	// the request is removed from the Postgresql resource
+	if _, exists := cluster.Postgresql.Annotations[constants.RotatePasswordAnnotation]; exists {
+		t.Errorf("%s: expected rotation request to be removed from the cluster", t.Name())
+	}
+	current, err := acidClientSet.CpoV1().Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get postgresql: %v", err)
+	}
+	if _, exists := current.Annotations[constants.RotatePasswordAnnotation]; exists {
+		t.Errorf("%s: expected rotation request to be removed from the Postgresql resource", t.Name())
+	}
+
+	// roles with scheduled rotation get a new rotation user
+	fooSecret, _ := secrets.Get(context.TODO(), cluster.credentialSecretName("foo"), metav1.GetOptions{})
+	fooSecret.Annotations = map[string]string{constants.RotatePasswordAnnotation: "true"}
+	rotationUsername := "foo" + time.Now().Format(constants.RotationUserDateFormat)
+	retentionUsers := make([]string, 0)
+	cluster.rotatePasswordOnDemand(fooSecret, "foo", spec.RoleOriginManifest, true, time.Now(), &retentionUsers)
+	if string(fooSecret.Data["username"]) != rotationUsername || string(fooSecret.Data["password"]) == passwords["foo"] {
+		t.Errorf("%s: expected rotation user %s with a new password", t.Name(), rotationUsername)
+	}
+	if len(retentionUsers) != 1 || string(fooSecret.Data["nextRotation"]) == "" {
+		t.Errorf("%s: expected rotation user to be cleaned up and the next rotation to be scheduled", t.Name())
+	}
+
+	// a second request on the same day replaces the password of the rotation user
+	rotatedPassword := string(fooSecret.Data["password"])
+	cluster.rotatePasswordOnDemand(fooSecret, "foo", spec.RoleOriginManifest, true, time.Now(), &retentionUsers)
+	if string(fooSecret.Data["username"]) != rotationUsername || string(fooSecret.Data["password"]) == rotatedPassword {
+		t.Errorf("%s: expected new password for the rotation user of the day", t.Name())
+	}
+	if len(retentionUsers) != 1 {
+		t.Errorf("%s: expected no new rotation user, got %v", t.Name(), retentionUsers)
+	}
+}
+
+func TestSyncRotationAnnotations(t *testing.T) {
+	tests := []struct {
+		subTest  string
+		source   map[string]string
+		target   map[string]string
+		expected map[string]string
+		changed  bool
+	}{
+		{
+			subTest:  "rotation recorded",
+			source:   map[string]string{constants.PasswordRotatedAtAnnotation: "2026-01-01T00:00:00Z"},
+			target:   map[string]string{constants.RotatePasswordAnnotation: "true", "foo": "bar"},
+			expected: map[string]string{constants.PasswordRotatedAtAnnotation: "2026-01-01T00:00:00Z", "foo": "bar"},
+			changed:  true,
+		},
+		{
+			subTest:  "pending request",
+			source:   map[string]string{constants.RotatePasswordAnnotation: "true"},
+			target:   map[string]string{constants.RotatePasswordAnnotation: "true"},
+			expected: map[string]string{constants.RotatePasswordAnnotation: "true"},
+			changed:  false,
+		},
+		{
+			subTest:  "no annotations",
+			source:   nil,
+			target:   nil,
+			expected: nil,
+			changed:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		source := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tt.source}}
+		target := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Annotations: tt.target}}
+		if changed := syncRotationAnnotations(source, target); changed != tt.changed {
+			t.Errorf("%s [%s]: expected changed %v, got %v", t.Name(), tt.subTest, tt.changed, changed)
+		}
+		if len(target.Annotations) != len(tt.expected) {
+			t.Errorf("%s [%s]: expected annotations %v, got %v", t.Name(), tt.subTest, tt.expected, target.Annotations)
+			continue
+		}
+		for key, value := range tt.expected {
+			if target.Annotations[key] != value {
+				t.Errorf("%s [%s]: expected annotations %v, got %v", t.Name(), tt.subTest, tt.expected, target.Annotations)
+			}
+		}
+	}
+}
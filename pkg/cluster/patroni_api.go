@@ -0,0 +1,398 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+const (
+	patroniAPIMountPath       = "/run/patroni-api"
+	patroniAPIUsername        = "patroni"
+	patroniAPIAuthCertificate = "certificate"
+
+	patroniAPICASecretKey           = "ca.crt"
+	patroniAPICAPrivateKeySecretKey = "ca.key"
+	patroniAPICertSecretKey         = "tls.crt"
+	patroniAPIPrivateKeySecretKey   = "tls.key"
+	patroniAPIClientCertSecretKey   = "client.crt"
+	patroniAPIClientKeySecretKey    = "client.key"
+	patroniAPIUsernameSecretKey     = "username" // #nosec G101 this is a name, not a credential
+	patroniAPIPasswordSecretKey     = "password" // #nosec G101 this is a name, not a credential
+)
+
+func (c *Cluster) getPatroniAPISecretName() string {
+	return c.clusterName().Name + "-patroni-api"
+}
+
+// syncPatroniAPI makes sure that the certificates and credentials of the Patroni REST API exist and
+// configures the Patroni client with them. Certificates are renewed before they expire. The secret
+// is kept when TLS is disabled again, so that members which have not been replaced yet remain
+// reachable.
+func (c *Cluster) syncPatroniAPI() error {
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), c.getPatroniAPISecretName(), metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get Patroni API secret: %v", err)
+		}
+		if !c.OpConfig.EnablePatroniAPITLS {
+			return nil
+		}
+		if secret, err = c.createPatroniAPISecret(); err != nil {
+			return err
+		}
+	} else if c.OpConfig.EnablePatroniAPITLS {
+		if secret, err = c.renewPatroniAPICertificates(secret); err != nil {
+			return err
+		}
+	}
+	c.Secrets[secret.UID] = secret
+
+	client, err := c.newPatroniAPIClient(secret)
+	if err != nil {
+		return err
+	}
+	c.patroni = client
+	return nil
+}
+
+func (c *Cluster) createPatroniAPISecret() (*v1.Secret, error) {
+	c.logger.Info("creating Patroni API secret")
+
+	root, err := NewRootCertificateAuthority()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate certificate authority: %v", err)
+	}
+	data, err := c.patroniAPICertificateData(root, nil)
+	if err != nil {
+		return nil, err
+	}
+	data[patroniAPIUsernameSecretKey] = []byte(patroniAPIUsername)
+	data[patroniAPIPasswordSecretKey] = []byte(util.RandomPassword(constants.PasswordLength))
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.getPatroniAPISecretName(),
+			Namespace: c.Namespace,
+			Labels:    c.labelsSet(true),
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+	secret, err = c.KubeClient.Secrets(c.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not create Patroni API secret: %v", err)
+	}
+	c.logger.Debugf("created new secret %s, namespace: %s, uid: %s", util.NameFromMeta(secret.ObjectMeta), secret.Namespace, secret.UID)
+	return secret, nil
+}
+
+// renewPatroniAPICertificates issues the server and client certificates again before they expire.
+// The CA is only replaced when it expires itself or its key is missing, e.g. in secrets of older
+// operator versions. The previous CA stays trusted next to the new one, so that members can be
+// reloaded one after another.
+func (c *Cluster) renewPatroniAPICertificates(secret *v1.Secret) (*v1.Secret, error) {
+	now := currentTime()
+	reason := patroniAPIRenewalReason(secret, c.OpConfig.PostgresTLSRenewBefore, now)
+	if reason == "" {
+		return secret, nil
+	}
+	c.logger.Infof("renewing Patroni API certificates: %s", reason)
+
+	var root RootCertificateAuthority
+	err := root.Certificate.UnmarshalText(secret.Data[patroniAPICASecretKey])
+	if err == nil {
+		err = root.PrivateKey.UnmarshalText(secret.Data[patroniAPICAPrivateKeySecretKey])
+	}
+	var previousCA *Certificate
+	if err != nil || now.Add(c.OpConfig.PostgresTLSRenewBefore).After(root.Certificate.x509.NotAfter) {
+		if root.Certificate.x509 != nil && now.Before(root.Certificate.x509.NotAfter) {
+			previousCA = &Certificate{x509: root.Certificate.x509}
+		}
+		newRoot, err := NewRootCertificateAuthority()
+		if err != nil {
+			return nil, fmt.Errorf("could not generate certificate authority: %v", err)
+		}
+		root = *newRoot
+	}
+
+	data, err := c.patroniAPICertificateData(&root, previousCA)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []string{patroniAPIUsernameSecretKey, patroniAPIPasswordSecretKey} {
+		data[key] = secret.Data[key]
+	}
+	secret.Data = data
+	if secret, err = c.KubeClient.Secrets(c.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("could not update Patroni API secret: %v", err)
+	}
+	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "TLS", "Patroni API certificates have been renewed: %s", reason)
+	return secret, nil
+}
+
+// patroniAPIRenewalReason returns why the server or client certificate in the secret has to be
+// issued again, or an empty string if both can be kept.
+func patroniAPIRenewalReason(secret *v1.Secret, renewBefore time.Duration, now time.Time) string {
+	var root Certificate
+	if err := root.UnmarshalText(secret.Data[patroniAPICASecretKey]); err != nil {
+		return fmt.Sprintf("could not read CA certificate: %v", err)
+	}
+	if now.Add(renewBefore).After(root.x509.NotAfter) {
+		return fmt.Sprintf("CA certificate expires at %s", root.x509.NotAfter.UTC().Format(time.RFC3339))
+	}
+	for _, key := range []string{patroniAPICertSecretKey, patroniAPIClientCertSecretKey} {
+		var cert Certificate
+		if err := cert.UnmarshalText(secret.Data[key]); err != nil {
+			return fmt.Sprintf("could not read certificate %s: %v", key, err)
+		}
+		if err := cert.x509.CheckSignatureFrom(root.x509); err != nil {
+			return fmt.Sprintf("certificate %s is not signed by the current CA", key)
+		}
+		if now.Add(renewBefore).After(cert.x509.NotAfter) {
+			return fmt.Sprintf("certificate %s expires at %s", key, cert.x509.NotAfter.UTC().Format(time.RFC3339))
+		}
+	}
+	return ""
+}
+
+// patroniAPICertificateData issues the server and client certificates with the given CA. A previous
+// CA is appended to the CA file, so that certificates issued by it are still accepted.
+func (c *Cluster) patroniAPICertificateData(root *RootCertificateAuthority, previousCA *Certificate) (map[string][]byte, error) {
+	commonName := c.clientCommonName()
+	dnsNames := []string{
+		commonName,
+		"*." + c.serviceName(ClusterPods) + "." + c.Namespace + ".svc." + c.OpConfig.ClusterDomain,
+	}
+	server, err := root.GenerateLeafCertificate(commonName, dnsNames, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate server certificate: %v", err)
+	}
+	client, err := root.GenerateLeafCertificate(commonName, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate client certificate: %v", err)
+	}
+
+	data := make(map[string][]byte)
+	if previousCA != nil {
+		data[patroniAPICASecretKey], err = certFile(root.Certificate, previousCA)
+	} else {
+		data[patroniAPICASecretKey], err = certFile(root.Certificate)
+	}
+	if err == nil {
+		data[patroniAPICAPrivateKeySecretKey], err = certFile(root.PrivateKey)
+	}
+	if err == nil {
+		data[patroniAPICertSecretKey], err = certFile(server.Certificate)
+	}
+	if err == nil {
+		data[patroniAPIPrivateKeySecretKey], err = certFile(server.PrivateKey)
+	}
+	if err == nil {
+		data[patroniAPIClientCertSecretKey], err = certFile(client.Certificate)
+	}
+	if err == nil {
+		data[patroniAPIClientKeySecretKey], err = certFile(client.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not encode certificates: %v", err)
+	}
+	return data, nil
+}
+
+// newPatroniAPIClient sets up a client that verifies the members against the generated CA. Basic auth
+// credentials and the client certificate are both supplied, so that members keep accepting requests
+// while they are being rolled to another authentication method.
+func (c *Cluster) newPatroniAPIClient(secret *v1.Secret) (patroni.Interface, error) {
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(secret.Data[patroniAPICASecretKey]) {
+		return nil, fmt.Errorf("could not read CA certificate from secret %q", secret.Name)
+	}
+	clientCert, err := tls.X509KeyPair(secret.Data[patroniAPIClientCertSecretKey], secret.Data[patroniAPIClientKeySecretKey])
+	if err != nil {
+		return nil, fmt.Errorf("could not read client certificate from secret %q: %v", secret.Name, err)
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:      caPool,
+		Certificates: []tls.Certificate{clientCert},
+		// members are addressed by their pod IP, which is not part of the certificate
+		ServerName: c.clientCommonName(),
+		MinVersion: tls.VersionTLS12,
+	}
+	return patroni.NewSecure(c.logger, nil, tlsConfig,
+		string(secret.Data[patroniAPIUsernameSecretKey]), string(secret.Data[patroniAPIPasswordSecretKey])), nil
+}
+
+// generatePatroniAPIEnvVars configures the REST API of Patroni with the generated certificates. Basic auth
+// and client certificates only protect the endpoints which change the cluster. The probes cannot
+// authenticate, as the kubelet presents no client certificate and probe headers are visible in the pod spec.
+func (c *Cluster) generatePatroniAPIEnvVars() ([]v1.EnvVar, []cpov1.AdditionalVolume) {
+	secretName := c.getPatroniAPISecretName()
+	envVars := []v1.EnvVar{
+		{Name: patroni.CertFileEnvVar, Value: patroniAPIMountPath + "/" + patroniAPICertSecretKey},
+		{Name: "PATRONI_RESTAPI_KEYFILE", Value: patroniAPIMountPath + "/" + patroniAPIPrivateKeySecretKey},
+		{Name: "PATRONI_RESTAPI_CAFILE", Value: patroniAPIMountPath + "/" + patroniAPICASecretKey},
+		{Name: "SSL_RESTAPI_CERTIFICATE_FILE", Value: patroniAPIMountPath + "/" + patroniAPICertSecretKey},
+		{Name: "SSL_RESTAPI_PRIVATE_KEY_FILE", Value: patroniAPIMountPath + "/" + patroniAPIPrivateKeySecretKey},
+		{Name: "SSL_RESTAPI_CA_FILE", Value: patroniAPIMountPath + "/" + patroniAPICASecretKey},
+		// used by patronictl and by the members when they query each other
+		{Name: "PATRONI_CTL_CACERT", Value: patroniAPIMountPath + "/" + patroniAPICASecretKey},
+		{Name: "PATRONI_CTL_CERTFILE", Value: patroniAPIMountPath + "/" + patroniAPIClientCertSecretKey},
+		{Name: "PATRONI_CTL_KEYFILE", Value: patroniAPIMountPath + "/" + patroniAPIClientKeySecretKey},
+		// members are addressed by the DNS name of the pod, which the server certificate covers
+		{
+			Name: "PATRONI_API_POD_NAME",
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.name"},
+			},
+		},
+		{
+			Name: "PATRONI_RESTAPI_CONNECT_ADDRESS",
+			Value: fmt.Sprintf("$(PATRONI_API_POD_NAME).%s.%s.svc.%s:%d",
+				c.serviceName(ClusterPods), c.Namespace, c.OpConfig.ClusterDomain, patroni.ApiPort),
+		},
+	}
+
+	if c.OpConfig.PatroniAPIAuthMethod == patroniAPIAuthCertificate {
+		envVars = append(envVars, v1.EnvVar{Name: "PATRONI_RESTAPI_VERIFY_CLIENT", Value: "optional"})
+	} else {
+		envVars = append(envVars,
+			v1.EnvVar{
+				Name: "PATRONI_RESTAPI_USERNAME",
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+						Key:                  patroniAPIUsernameSecretKey,
+					},
+				},
+			},
+			v1.EnvVar{
+				Name: "PATRONI_RESTAPI_PASSWORD",
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+						Key:                  patroniAPIPasswordSecretKey,
+					},
+				},
+			})
+	}
+
+	defaultMode := int32(0640)
+	volumes := []cpov1.AdditionalVolume{
+		{
+			Name:      "patroni-api-tls",
+			MountPath: patroniAPIMountPath,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName:  secretName,
+					DefaultMode: &defaultMode,
+					// the private key of the CA stays with the operator
+					Items: []v1.KeyToPath{
+						{Key: patroniAPICASecretKey, Path: patroniAPICASecretKey},
+						{Key: patroniAPICertSecretKey, Path: patroniAPICertSecretKey},
+						{Key: patroniAPIPrivateKeySecretKey, Path: patroniAPIPrivateKeySecretKey},
+						{Key: patroniAPIClientCertSecretKey, Path: patroniAPIClientCertSecretKey},
+						{Key: patroniAPIClientKeySecretKey, Path: patroniAPIClientKeySecretKey},
+					},
+				},
+			},
+		},
+	}
+	return envVars, volumes
+}
+
+// reloadPatroniAPICertificatesScript sends a SIGHUP to Patroni, which loads certificate files that
+// changed on disk. The signal works regardless of the certificates the REST API accepts.
+const reloadPatroniAPICertificatesScript = "import os, signal, sys\n" + patroniPIDFunction + `
+os.kill(patroni_pid(sys.argv[1]), signal.SIGHUP)
+`
+
+// reloadPatroniAPICertificates makes Patroni serve renewed certificates without a restart. Like the
+// Postgres certificates, a member is only reloaded once the renewed files have been mounted.
+func (c *Cluster) reloadPatroniAPICertificates() error {
+	if !c.OpConfig.EnablePatroniAPITLS {
+		return nil
+	}
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), c.getPatroniAPISecretName(), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get Patroni API secret: %v", err)
+	}
+	var expected Certificate
+	if err = expected.UnmarshalText(secret.Data[patroniAPICertSecretKey]); err != nil {
+		return fmt.Errorf("could not read Patroni API certificate: %v", err)
+	}
+
+	pods, err := c.listPods()
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		served, err := servedAPICertificate(net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(patroni.ApiPort)), c.OpConfig.PatroniAPICheckTimeout)
+		if err != nil {
+			c.logger.Warningf("could not get Patroni API certificate served by pod %q: %v", pod.Name, err)
+			continue
+		}
+		if served.Equal(expected.x509) {
+			continue
+		}
+
+		podName := util.NameFromMeta(pod.ObjectMeta)
+		out, err := c.ExecCommand(&podName, "cat", patroniAPIMountPath+"/"+patroniAPICertSecretKey)
+		if err != nil {
+			return fmt.Errorf("could not read Patroni API certificate mounted in pod %q: %v", pod.Name, err)
+		}
+		var mounted Certificate
+		if err = mounted.UnmarshalText([]byte(out)); err != nil || !mounted.Equal(expected) {
+			c.logger.Debugf("renewed Patroni API certificate has not been mounted in pod %q yet", pod.Name)
+			continue
+		}
+
+		if _, err = c.ExecCommand(&podName, "python3", "-c", reloadPatroniAPICertificatesScript, patroniConfigFile); err != nil {
+			return fmt.Errorf("could not reload Patroni in pod %q: %v", pod.Name, err)
+		}
+		c.logger.Infof("pod %q has been reloaded to serve the renewed Patroni API certificate", pod.Name)
+	}
+	return nil
+}
+
+// servedAPICertificate returns the certificate the REST API of Patroni presents. The server is not
+// verified, since only its certificate is of interest.
+func servedAPICertificate(address string, timeout time.Duration) (*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	// #nosec G402 the certificate is only read and compared, nothing is sent over the connection
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("server did not present a certificate")
+	}
+	return certificates[0], nil
+}
+
+func (c *Cluster) patroniAPIScheme() v1.URIScheme {
+	if c.OpConfig.EnablePatroniAPITLS {
+		return v1.URISchemeHTTPS
+	}
+	return v1.URISchemeHTTP
+}
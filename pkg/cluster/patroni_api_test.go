@@ -0,0 +1,225 @@
+package cluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+func patroniAPITestSpec() cpov1.PostgresSpec {
+	return cpov1.PostgresSpec{
+		NumberOfInstances: 1,
+		Resources: &cpov1.Resources{
+			ResourceRequests: cpov1.ResourceDescription{CPU: "1", Memory: "10"},
+			ResourceLimits:   cpov1.ResourceDescription{CPU: "1", Memory: "10"},
+		},
+		Volume: cpov1.Volume{Size: "1G"},
+	}
+}
+
+func patroniAPITestConfig(enableTLS bool, authMethod string) Config {
+	return Config{
+		OpConfig: config.Config{
+			PodManagementPolicy:  "ordered_ready",
+			EnableReadinessProbe: true,
+			EnablePatroniAPITLS:  enableTLS,
+			PatroniAPIAuthMethod: authMethod,
+		},
+	}
+}
+
+func TestSyncPatroniAPI(t *testing.T) {
+	tests := []struct {
+		subTest      string
+		enableTLS    bool
+		expectSecret bool
+	}{
+		{
+			subTest:      "TLS disabled",
+			enableTLS:    false,
+			expectSecret: false,
+		},
+		{
+			subTest:      "TLS enabled",
+			enableTLS:    true,
+			expectSecret: true,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, patroniAPITestSpec(), patroniAPITestConfig(tt.enableTLS, "basic"))
+		plainClient := cluster.patroni
+		if err := cluster.syncPatroniAPI(); err != nil {
+			t.Fatalf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+		}
+		secret, err := cluster.KubeClient.Secrets("default").Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+		if !tt.expectSecret {
+			if !k8sutil.ResourceNotFound(err) {
+				t.Errorf("%s [%s]: expected no Patroni API secret, got %v", t.Name(), tt.subTest, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s [%s]: could not get Patroni API secret: %v", t.Name(), tt.subTest, err)
+		}
+		if cluster.patroni == plainClient || len(cluster.Secrets) != 1 {
+			t.Errorf("%s [%s]: Patroni client has not been configured with the secret", t.Name(), tt.subTest)
+		}
+
+		// the server certificate must be valid for the name the client verifies
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(secret.Data[patroniAPICASecretKey]) {
+			t.Fatalf("%s [%s]: secret does not contain a CA certificate", t.Name(), tt.subTest)
+		}
+		block, _ := pem.Decode(secret.Data[patroniAPICertSecretKey])
+		if block == nil {
+			t.Fatalf("%s [%s]: secret does not contain a server certificate", t.Name(), tt.subTest)
+		}
+		serverCert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatalf("%s [%s]: could not parse server certificate: %v", t.Name(), tt.subTest, err)
+		}
+		if _, err := serverCert.Verify(x509.VerifyOptions{DNSName: cluster.clientCommonName(), Roots: roots}); err != nil {
+			t.Errorf("%s [%s]: server certificate cannot be verified: %v", t.Name(), tt.subTest, err)
+		}
+		if len(secret.Data[patroniAPIPasswordSecretKey]) == 0 {
+			t.Errorf("%s [%s]: secret does not contain a password", t.Name(), tt.subTest)
+		}
+
+		// a second sync reuses the existing secret
+		if err := cluster.syncPatroniAPI(); err != nil {
+			t.Fatalf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+		}
+		reread, _ := cluster.KubeClient.Secrets("default").Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+		if string(reread.Data[patroniAPIPasswordSecretKey]) != string(secret.Data[patroniAPIPasswordSecretKey]) {
+			t.Errorf("%s [%s]: Patroni API credentials must not change on sync", t.Name(), tt.subTest)
+		}
+	}
+}
+
+func TestRenewPatroniAPICertificates(t *testing.T) {
+	defer func() { currentTime = time.Now }()
+	cluster := newTestCluster(t, patroniAPITestSpec(), patroniAPITestConfig(true, "basic"))
+	cluster.OpConfig.PostgresTLSRenewBefore = 30 * 24 * time.Hour
+	if err := cluster.syncPatroniAPI(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	secrets := cluster.KubeClient.Secrets("default")
+	created, _ := secrets.Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+
+	// certificates far from their expiry are kept
+	if err := cluster.syncPatroniAPI(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	kept, _ := secrets.Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+	if string(kept.Data[patroniAPICertSecretKey]) != string(created.Data[patroniAPICertSecretKey]) {
+		t.Errorf("%s: certificate must not be renewed before its expiry", t.Name())
+	}
+
+	// expiring certificates are issued again by the same CA
+	currentTime = func() time.Time { return time.Now().Add(340 * 24 * time.Hour) }
+	if err := cluster.syncPatroniAPI(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	renewed, _ := secrets.Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+	if string(renewed.Data[patroniAPICertSecretKey]) == string(created.Data[patroniAPICertSecretKey]) ||
+		string(renewed.Data[patroniAPIClientCertSecretKey]) == string(created.Data[patroniAPIClientCertSecretKey]) {
+		t.Errorf("%s: expected expiring certificates to be renewed", t.Name())
+	}
+	if string(renewed.Data[patroniAPICASecretKey]) != string(created.Data[patroniAPICASecretKey]) {
+		t.Errorf("%s: expected the CA to be kept", t.Name())
+	}
+	if string(renewed.Data[patroniAPIPasswordSecretKey]) != string(created.Data[patroniAPIPasswordSecretKey]) {
+		t.Errorf("%s: Patroni API credentials must not change on renewal", t.Name())
+	}
+
+	// a secret without the key of its CA gets a new CA, the previous one stays trusted
+	delete(renewed.Data, patroniAPICAPrivateKeySecretKey)
+	if _, err := secrets.Update(context.TODO(), renewed, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("%s: could not update secret: %v", t.Name(), err)
+	}
+	currentTime = func() time.Time { return time.Now().Add(700 * 24 * time.Hour) }
+	if err := cluster.syncPatroniAPI(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	replaced, _ := secrets.Get(context.TODO(), "acid-test-cluster-patroni-api", metav1.GetOptions{})
+	if len(replaced.Data[patroniAPICAPrivateKeySecretKey]) == 0 {
+		t.Errorf("%s: expected the private key of the new CA in the secret", t.Name())
+	}
+	bundle := string(replaced.Data[patroniAPICASecretKey])
+	if !strings.HasSuffix(bundle, string(created.Data[patroniAPICASecretKey])) || bundle == string(created.Data[patroniAPICASecretKey]) {
+		t.Errorf("%s: expected the new CA followed by the previous one, got %s", t.Name(), bundle)
+	}
+}
+
+func TestPatroniAPIPodSpec(t *testing.T) {
+	tests := []struct {
+		subTest        string
+		enableTLS      bool
+		authMethod     string
+		expectedScheme v1.URIScheme
+		expectedEnv    []string
+		unexpectedEnv  []string
+	}{
+		{
+			subTest:        "TLS disabled",
+			enableTLS:      false,
+			authMethod:     "basic",
+			expectedScheme: v1.URISchemeHTTP,
+			unexpectedEnv:  []string{"PATRONI_RESTAPI_CERTFILE", "PATRONI_RESTAPI_USERNAME"},
+		},
+		{
+			subTest:        "basic auth",
+			enableTLS:      true,
+			authMethod:     "basic",
+			expectedScheme: v1.URISchemeHTTPS,
+			expectedEnv:    []string{"PATRONI_RESTAPI_CERTFILE", "PATRONI_RESTAPI_USERNAME", "PATRONI_RESTAPI_PASSWORD"},
+			unexpectedEnv:  []string{"PATRONI_RESTAPI_VERIFY_CLIENT"},
+		},
+		{
+			subTest:        "client certificate auth",
+			enableTLS:      true,
+			authMethod:     "certificate",
+			expectedScheme: v1.URISchemeHTTPS,
+			expectedEnv:    []string{"PATRONI_RESTAPI_CERTFILE", "PATRONI_RESTAPI_VERIFY_CLIENT", "PATRONI_CTL_CERTFILE", "PATRONI_CTL_CACERT", "PATRONI_RESTAPI_CONNECT_ADDRESS"},
+			unexpectedEnv:  []string{"PATRONI_RESTAPI_PASSWORD", "PATRONI_CTL_INSECURE"},
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, patroniAPITestSpec(), patroniAPITestConfig(tt.enableTLS, tt.authMethod))
+		sts, err := cluster.generateStatefulSet(&cluster.Spec)
+		if err != nil {
+			t.Fatalf("%s [%s]: could not generate statefulset: %v", t.Name(), tt.subTest, err)
+		}
+		postgresContainer := getPostgresContainer(&sts.Spec.Template.Spec)
+		if scheme := postgresContainer.ReadinessProbe.HTTPGet.Scheme; scheme != tt.expectedScheme {
+			t.Errorf("%s [%s]: expected readiness probe scheme %s, got %s", t.Name(), tt.subTest, tt.expectedScheme, scheme)
+		}
+
+		envNames := make(map[string]bool)
+		for _, env := range postgresContainer.Env {
+			envNames[env.Name] = true
+		}
+		for _, name := range tt.expectedEnv {
+			if !envNames[name] {
+				t.Errorf("%s [%s]: env var %s is missing", t.Name(), tt.subTest, name)
+			}
+		}
+		for _, name := range tt.unexpectedEnv {
+			if envNames[name] {
+				t.Errorf("%s [%s]: unexpected env var %s", t.Name(), tt.subTest, name)
+			}
+		}
+	}
+}
@@ -486,16 +486,16 @@ func (c *Cluster) getSwitchoverCandidate(master *v1.Pod) (spec.NamespacedName, e
 				return false, err
 			}
 
-			// look for SyncStandby candidates (which also implies pod is in running state)
+			// look for SyncStandby or QuorumStandby candidates (which also implies pod is in running state)
 			for _, member := range members {
-				if PostgresRole(member.Role) == SyncStandby {
+				if isSynchronousStandby(member) && canFailoverTo(member) {
 					syncCandidates = append(syncCandidates, member)
 				}
 			}
 
 			// if synchronous mode is enabled and no SyncStandy was found
 			// return false for retry - cannot failover with no sync candidate
-			if c.Spec.Patroni.SynchronousMode && len(syncCandidates) == 0 {
+			if c.Spec.Patroni.SynchronousReplicationMode() != cpov1.SynchronousReplicationOff && len(syncCandidates) == 0 {
 				c.logger.Warnf("no sync standby found - retrying fetching cluster members")
 				return false, nil
 			}
@@ -507,23 +507,23 @@ func (c *Cluster) getSwitchoverCandidate(master *v1.Pod) (spec.NamespacedName, e
 		return spec.NamespacedName{}, fmt.Errorf("failed to get Patroni cluster members: %s", err)
 	}
 
-	// pick candidate with lowest lag
+	// pick candidate with highest failover priority and lowest lag
 	if len(syncCandidates) > 0 {
 		sort.Slice(syncCandidates, func(i, j int) bool {
-			return syncCandidates[i].Lag < syncCandidates[j].Lag
+			return isBetterCandidate(syncCandidates[i], syncCandidates[j])
 		})
 		return spec.NamespacedName{Namespace: master.Namespace, Name: syncCandidates[0].Name}, nil
 	} else {
 		// in asynchronous mode find running replicas
 		for _, member := range members {
-			if PostgresRole(member.Role) != Leader && PostgresRole(member.Role) != StandbyLeader && member.State == "streaming" {
+			if PostgresRole(member.Role) != Leader && PostgresRole(member.Role) != StandbyLeader && member.State == "streaming" && canFailoverTo(member) {
 				candidates = append(candidates, member)
 			}
 		}
 
 		if len(candidates) > 0 {
 			sort.Slice(candidates, func(i, j int) bool {
-				return candidates[i].Lag < candidates[j].Lag
+				return isBetterCandidate(candidates[i], candidates[j])
 			})
 			return spec.NamespacedName{Namespace: master.Namespace, Name: candidates[0].Name}, nil
 		}
@@ -532,6 +532,35 @@ func (c *Cluster) getSwitchoverCandidate(master *v1.Pod) (spec.NamespacedName, e
 	return spec.NamespacedName{}, fmt.Errorf("no switchover candidate found")
 }
 
+// isSynchronousStandby reports whether the member is part of the synchronous or quorum set
+func isSynchronousStandby(member patroni.ClusterMember) bool {
+	role := PostgresRole(member.Role)
+	return role == SyncStandby || role == QuorumStandby
+}
+
+// canFailoverTo reports whether Patroni would promote the member, i.e. it is neither tagged
+// nofailover nor has a failover priority of 0.
+func canFailoverTo(member patroni.ClusterMember) bool {
+	if member.Tags.NoFailover {
+		return false
+	}
+	return member.Tags.FailoverPriority == nil || *member.Tags.FailoverPriority > 0
+}
+
+func failoverPriority(member patroni.ClusterMember) int32 {
+	if member.Tags.FailoverPriority == nil {
+		return 1
+	}
+	return *member.Tags.FailoverPriority
+}
+
+func isBetterCandidate(a, b patroni.ClusterMember) bool {
+	if failoverPriority(a) != failoverPriority(b) {
+		return failoverPriority(a) > failoverPriority(b)
+	}
+	return a.Lag < b.Lag
+}
+
 func (c *Cluster) podIsEndOfLife(pod *v1.Pod) (bool, error) {
 	node, err := c.KubeClient.Nodes().Get(context.TODO(), pod.Spec.NodeName, metav1.GetOptions{})
 	if err != nil {
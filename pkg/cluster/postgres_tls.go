@@ -0,0 +1,433 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+const (
+	postgresTLSMountPath        = "/tls"
+	postgresTLSAuthorityCluster = "cluster"
+
+	postgresTLSCASecretKey           = "ca.crt"
+	postgresTLSCAPrivateKeySecretKey = "ca.key" // #nosec G101 this is a name, not a credential
+	postgresTLSCertSecretKey         = "tls.crt"
+	postgresTLSPrivateKeySecretKey   = "tls.key" // #nosec G101 this is a name, not a credential
+)
+
+// sslRequest is the message a client sends to ask the Postgres server for a TLS connection.
+var sslRequest = []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}
+
+func (c *Cluster) getPostgresTLSSecretName() string {
+	return c.clusterName().Name + "-tls"
+}
+
+func (c *Cluster) getPostgresTLSCAConfigMapName() string {
+	return c.clusterName().Name + "-ca"
+}
+
+// getPostgresTLSCASecretName returns the secret of the CA signing the server certificates. The
+// operator-wide CA lives in the operator namespace and is shared by all clusters.
+func (c *Cluster) getPostgresTLSCASecretName() spec.NamespacedName {
+	if c.OpConfig.PostgresTLSCertificateAuthority == postgresTLSAuthorityCluster {
+		return spec.NamespacedName{Namespace: c.Namespace, Name: c.clusterName().Name + "-tls-ca"}
+	}
+	return spec.NamespacedName{Namespace: spec.GetOperatorNamespace(), Name: c.OpConfig.PostgresTLSCASecretName}
+}
+
+// generatePostgresTLS reports whether the operator issues the certificates of the cluster, which
+// is only the case when the manifest does not refer to a secret of its own.
+func (c *Cluster) generatePostgresTLS(spec *cpov1.PostgresSpec) bool {
+	return c.OpConfig.EnablePostgresTLSCertificates && (spec.TLS == nil || spec.TLS.SecretName == "")
+}
+
+// postgresTLS returns the TLS configuration of the Postgres and pooler pods, or nil when TLS
+// is not configured.
+func (c *Cluster) postgresTLS(spec *cpov1.PostgresSpec) *cpov1.TLSDescription {
+	if spec.TLS != nil && spec.TLS.SecretName != "" {
+		return spec.TLS
+	}
+	if c.generatePostgresTLS(spec) {
+		return &cpov1.TLSDescription{
+			SecretName: c.getPostgresTLSSecretName(),
+			CAFile:     postgresTLSCASecretKey,
+		}
+	}
+	return nil
+}
+
+// postgresTLSDNSNames lists the names under which clients reach the cluster: the services of
+// the cluster and its poolers in all forms the cluster DNS resolves, and the individual pods.
+func (c *Cluster) postgresTLSDNSNames(spec *cpov1.PostgresSpec) []string {
+	services := []string{c.serviceName(Master), c.serviceName(Replica)}
+	if needMasterConnectionPooler(spec) {
+		services = append(services, c.connectionPoolerName(Master))
+	}
+	if needReplicaConnectionPooler(spec) {
+		services = append(services, c.connectionPoolerName(Replica))
+	}
+
+	dnsNames := []string{"localhost"}
+	for _, service := range services {
+		dnsNames = append(dnsNames,
+			service,
+			service+"."+c.Namespace,
+			service+"."+c.Namespace+".svc",
+			service+"."+c.Namespace+".svc."+c.OpConfig.ClusterDomain)
+	}
+	dnsNames = append(dnsNames, "*."+c.serviceName(ClusterPods)+"."+c.Namespace+".svc."+c.OpConfig.ClusterDomain)
+	return dnsNames
+}
+
+// syncPostgresTLSCertificates issues the server certificate of the cluster and renews it ahead of
+// its expiry, when the service names change or when it was not signed by the current CA. The
+// secret is kept when the generation is disabled again, so that pods which have not been replaced
+// yet keep their certificate.
+func (c *Cluster) syncPostgresTLSCertificates() error {
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), c.getPostgresTLSSecretName(), metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get TLS secret: %v", err)
+		}
+		secret = nil
+	}
+	if !c.generatePostgresTLS(&c.Spec) {
+		if secret != nil {
+			c.Secrets[secret.UID] = secret
+		}
+		return nil
+	}
+
+	root, err := c.syncPostgresTLSCertificateAuthority()
+	if err != nil {
+		return err
+	}
+
+	dnsNames := c.postgresTLSDNSNames(&c.Spec)
+	if secret == nil {
+		c.logger.Info("creating TLS secret")
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.getPostgresTLSSecretName(),
+				Namespace: c.Namespace,
+				Labels:    c.labelsSet(true),
+			},
+			Type: v1.SecretTypeTLS,
+		}
+		if secret.Data, err = postgresTLSSecretData(root, c.clientCommonName(), dnsNames); err != nil {
+			return err
+		}
+		if secret, err = c.KubeClient.Secrets(c.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("could not create TLS secret: %v", err)
+		}
+		c.logger.Debugf("created new secret %s, namespace: %s, uid: %s", util.NameFromMeta(secret.ObjectMeta), secret.Namespace, secret.UID)
+	} else if reason := postgresTLSRenewalReason(secret, root, dnsNames, c.OpConfig.PostgresTLSRenewBefore, currentTime()); reason != "" {
+		c.logger.Infof("renewing TLS certificate: %s", reason)
+		if secret.Data, err = postgresTLSSecretData(root, c.clientCommonName(), dnsNames); err != nil {
+			return err
+		}
+		if secret, err = c.KubeClient.Secrets(c.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("could not update TLS secret: %v", err)
+		}
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "TLS", "TLS certificate has been renewed: %s", reason)
+	}
+	c.Secrets[secret.UID] = secret
+
+	return c.syncPostgresTLSCABundle(secret.Data[postgresTLSCASecretKey])
+}
+
+// syncPostgresTLSCertificateAuthority loads the CA signing the server certificates and creates
+// it on first use. The CA is valid for ten years and is not renewed automatically.
+func (c *Cluster) syncPostgresTLSCertificateAuthority() (*RootCertificateAuthority, error) {
+	name := c.getPostgresTLSCASecretName()
+	secret, err := c.KubeClient.Secrets(name.Namespace).Get(context.TODO(), name.Name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			return nil, fmt.Errorf("could not get TLS CA secret %s: %v", name, err)
+		}
+		if secret, err = c.createPostgresTLSCertificateAuthority(name); err != nil {
+			return nil, err
+		}
+	}
+	// only a CA of the cluster is deleted with it, the operator-wide CA is shared by all clusters
+	if c.OpConfig.PostgresTLSCertificateAuthority == postgresTLSAuthorityCluster {
+		c.Secrets[secret.UID] = secret
+	}
+
+	var root RootCertificateAuthority
+	if err = root.Certificate.UnmarshalText(secret.Data[postgresTLSCASecretKey]); err == nil {
+		err = root.PrivateKey.UnmarshalText(secret.Data[postgresTLSCAPrivateKeySecretKey])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read TLS CA from secret %s: %v", name, err)
+	}
+	return &root, nil
+}
+
+func (c *Cluster) createPostgresTLSCertificateAuthority(name spec.NamespacedName) (*v1.Secret, error) {
+	c.logger.Infof("creating TLS CA secret %s", name)
+
+	root, err := NewRootCertificateAuthority()
+	if err != nil {
+		return nil, fmt.Errorf("could not generate certificate authority: %v", err)
+	}
+	data := make(map[string][]byte)
+	data[postgresTLSCASecretKey], err = certFile(root.Certificate)
+	if err == nil {
+		data[postgresTLSCAPrivateKeySecretKey], err = certFile(root.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not encode certificate authority: %v", err)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+	// the operator-wide CA outlives the cluster, so it must not be selected by its labels
+	if c.OpConfig.PostgresTLSCertificateAuthority == postgresTLSAuthorityCluster {
+		secret.Labels = c.labelsSet(true)
+	}
+	created, err := c.KubeClient.Secrets(name.Namespace).Create(context.TODO(), secret, metav1.CreateOptions{})
+	if k8sutil.ResourceAlreadyExists(err) {
+		// another cluster created the operator-wide CA in the meantime
+		return c.KubeClient.Secrets(name.Namespace).Get(context.TODO(), name.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create TLS CA secret %s: %v", name, err)
+	}
+	return created, nil
+}
+
+// postgresTLSSecretData issues a server certificate and bundles it with the CA certificate, which
+// the pods use to verify client certificates.
+func postgresTLSSecretData(root *RootCertificateAuthority, commonName string, dnsNames []string) (map[string][]byte, error) {
+	server, err := root.GenerateLeafCertificate(commonName, dnsNames, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate server certificate: %v", err)
+	}
+
+	data := make(map[string][]byte)
+	data[postgresTLSCASecretKey], err = certFile(root.Certificate)
+	if err == nil {
+		data[postgresTLSCertSecretKey], err = certFile(server.Certificate)
+	}
+	if err == nil {
+		data[postgresTLSPrivateKeySecretKey], err = certFile(server.PrivateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not encode certificates: %v", err)
+	}
+	return data, nil
+}
+
+// postgresTLSRenewalReason returns why the certificate in the secret has to be issued again,
+// or an empty string if it can be kept.
+func postgresTLSRenewalReason(secret *v1.Secret, root *RootCertificateAuthority, dnsNames []string, renewBefore time.Duration, now time.Time) string {
+	var cert Certificate
+	if err := cert.UnmarshalText(secret.Data[postgresTLSCertSecretKey]); err != nil {
+		return fmt.Sprintf("could not read certificate: %v", err)
+	}
+	if err := cert.x509.CheckSignatureFrom(root.Certificate.x509); err != nil {
+		return "certificate is not signed by the current CA"
+	}
+	if now.Add(renewBefore).After(cert.x509.NotAfter) {
+		return fmt.Sprintf("certificate expires at %s", cert.x509.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	current := append([]string{}, cert.x509.DNSNames...)
+	expected := append([]string{}, dnsNames...)
+	sort.Strings(current)
+	sort.Strings(expected)
+	if !reflect.DeepEqual(current, expected) {
+		return "DNS names of the cluster have changed"
+	}
+	return ""
+}
+
+// syncPostgresTLSCABundle publishes the CA certificate in a config map, so that clients in the
+// namespace can mount it to verify the server.
+func (c *Cluster) syncPostgresTLSCABundle(caCert []byte) error {
+	name := c.getPostgresTLSCAConfigMapName()
+	data := map[string]string{postgresTLSCASecretKey: string(caCert)}
+
+	configMap, err := c.KubeClient.ConfigMaps(c.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !k8sutil.ResourceNotFound(err) {
+			return fmt.Errorf("could not get CA bundle config map: %v", err)
+		}
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: c.Namespace,
+				Labels:    c.labelsSet(true),
+			},
+			Data: data,
+		}
+		if _, err = c.KubeClient.ConfigMaps(c.Namespace).Create(context.TODO(), configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("could not create CA bundle config map: %v", err)
+		}
+		c.logger.Infof("created CA bundle config map %q", name)
+		return nil
+	}
+
+	if reflect.DeepEqual(configMap.Data, data) {
+		return nil
+	}
+	configMap.Data = data
+	if _, err = c.KubeClient.ConfigMaps(c.Namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("could not update CA bundle config map: %v", err)
+	}
+	c.logger.Infof("updated CA bundle config map %q", name)
+	return nil
+}
+
+func (c *Cluster) deletePostgresTLSCABundle() error {
+	c.setProcessName("deleting CA bundle configmap")
+	c.logger.Debugln("deleting CA bundle configmap")
+
+	err := c.KubeClient.ConfigMaps(c.Namespace).Delete(context.TODO(), c.getPostgresTLSCAConfigMapName(), c.deleteOptions)
+	if err != nil {
+		if k8sutil.ResourceNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	c.logger.Infof("configmap %q has been deleted", c.getPostgresTLSCAConfigMapName())
+	return nil
+}
+
+// postgresTLSCertificateSerial returns the serial number of the generated server certificate as
+// last synced, or an empty string when the operator does not manage the certificate.
+func (c *Cluster) postgresTLSCertificateSerial(spec *cpov1.PostgresSpec) string {
+	if (spec.TLS != nil && spec.TLS.SecretName != "") || !c.generatePostgresTLS(spec) {
+		return ""
+	}
+	for _, secret := range c.Secrets {
+		if secret.Name != c.getPostgresTLSSecretName() || secret.Namespace != c.Namespace {
+			continue
+		}
+		var cert Certificate
+		if err := cert.UnmarshalText(secret.Data[postgresTLSCertSecretKey]); err != nil {
+			return ""
+		}
+		return cert.x509.SerialNumber.Text(16)
+	}
+	return ""
+}
+
+// reloadPostgresTLSCertificates makes Postgres pick up a renewed certificate without a restart.
+// Kubernetes updates the mounted secret with a delay, so pods still serving the old certificate
+// are only reloaded once the renewed files have arrived, replicas before the master.
+func (c *Cluster) reloadPostgresTLSCertificates() error {
+	if !c.generatePostgresTLS(&c.Spec) {
+		return nil
+	}
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), c.getPostgresTLSSecretName(), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get TLS secret: %v", err)
+	}
+	var expected Certificate
+	if err = expected.UnmarshalText(secret.Data[postgresTLSCertSecretKey]); err != nil {
+		return fmt.Errorf("could not read TLS certificate: %v", err)
+	}
+
+	pods, err := c.listPods()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		return PostgresRole(pods[i].Labels[c.OpConfig.PodRoleLabel]) != Master &&
+			PostgresRole(pods[j].Labels[c.OpConfig.PodRoleLabel]) == Master
+	})
+
+	for i := range pods {
+		pod := &pods[i]
+		role := PostgresRole(pod.Labels[c.OpConfig.PodRoleLabel])
+		if (role != Master && role != Replica) || pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		address := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(pgPort))
+		served, err := servedCertificate(address, constants.PostgresConnectTimeout)
+		if err != nil {
+			c.logger.Warningf("could not get TLS certificate served by pod %q: %v", pod.Name, err)
+			continue
+		}
+		if served.Equal(expected.x509) {
+			continue
+		}
+
+		podName := util.NameFromMeta(pod.ObjectMeta)
+		out, err := c.ExecCommand(&podName, "cat", postgresTLSMountPath+"/"+postgresTLSCertSecretKey)
+		if err != nil {
+			return fmt.Errorf("could not read TLS certificate mounted in pod %q: %v", pod.Name, err)
+		}
+		var mounted Certificate
+		if err = mounted.UnmarshalText([]byte(out)); err != nil || !mounted.Equal(expected) {
+			c.logger.Debugf("renewed TLS certificate has not been mounted in pod %q yet", pod.Name)
+			continue
+		}
+
+		if _, err = c.execPsql(pod, c.systemUsers[constants.SuperuserKeyName].Name, "postgres", "SELECT pg_reload_conf()"); err != nil {
+			return fmt.Errorf("could not reload configuration of pod %q: %v", pod.Name, err)
+		}
+		c.logger.Infof("pod %q has been reloaded to serve the renewed TLS certificate", pod.Name)
+	}
+	return nil
+}
+
+// servedCertificate returns the certificate a Postgres server presents. The server is not
+// verified, since only its certificate is of interest.
+func servedCertificate(address string, timeout time.Duration) (*x509.Certificate, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.Write(sslRequest); err != nil {
+		return nil, fmt.Errorf("could not send SSL request: %v", err)
+	}
+	response := make([]byte, 1)
+	if _, err = io.ReadFull(conn, response); err != nil {
+		return nil, fmt.Errorf("could not read response to SSL request: %v", err)
+	}
+	if response[0] != 'S' {
+		return nil, fmt.Errorf("server does not accept TLS connections")
+	}
+
+	// #nosec G402 the certificate is only read and compared, nothing is sent over the connection
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12})
+	if err = tlsConn.Handshake(); err != nil {
+		return nil, fmt.Errorf("could not complete TLS handshake: %v", err)
+	}
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("server did not present a certificate")
+	}
+	return certificates[0], nil
+}
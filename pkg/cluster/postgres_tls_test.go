@@ -0,0 +1,361 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+func postgresTLSTestConfig(authority string) Config {
+	return Config{
+		OpConfig: config.Config{
+			EnablePostgresTLSCertificates:   true,
+			PostgresTLSCertificateAuthority: authority,
+			PostgresTLSCASecretName:         "postgres-operator-tls-ca",
+			PostgresTLSRenewBefore:          30 * 24 * time.Hour,
+		},
+	}
+}
+
+func TestPostgresTLS(t *testing.T) {
+	customTLS := &cpov1.TLSDescription{SecretName: "custom-tls", CAFile: "ca.crt"}
+
+	tests := []struct {
+		subTest  string
+		enabled  bool
+		tls      *cpov1.TLSDescription
+		expected *cpov1.TLSDescription
+	}{
+		{
+			subTest:  "disabled without secret in the manifest",
+			expected: nil,
+		},
+		{
+			subTest:  "disabled with secret in the manifest",
+			tls:      customTLS,
+			expected: customTLS,
+		},
+		{
+			subTest:  "enabled without secret in the manifest",
+			enabled:  true,
+			expected: &cpov1.TLSDescription{SecretName: "acid-test-cluster-tls", CAFile: "ca.crt"},
+		},
+		{
+			subTest:  "enabled with empty tls section",
+			enabled:  true,
+			tls:      &cpov1.TLSDescription{},
+			expected: &cpov1.TLSDescription{SecretName: "acid-test-cluster-tls", CAFile: "ca.crt"},
+		},
+		{
+			subTest:  "secret in the manifest takes precedence",
+			enabled:  true,
+			tls:      customTLS,
+			expected: customTLS,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, cpov1.PostgresSpec{TLS: tt.tls}, postgresTLSTestConfig(postgresTLSAuthorityCluster))
+		cluster.OpConfig.EnablePostgresTLSCertificates = tt.enabled
+		if tls := cluster.postgresTLS(&cluster.Spec); !reflect.DeepEqual(tls, tt.expected) {
+			t.Errorf("%s [%s]: expected %#v, got %#v", t.Name(), tt.subTest, tt.expected, tls)
+		}
+	}
+}
+
+func TestPostgresTLSDNSNames(t *testing.T) {
+	cluster := newTestCluster(t, cpov1.PostgresSpec{EnableReplicaConnectionPooler: util.True()}, postgresTLSTestConfig(postgresTLSAuthorityCluster))
+
+	dnsNames := cluster.postgresTLSDNSNames(&cluster.Spec)
+	for _, expected := range []string{
+		"localhost",
+		"acid-test-cluster",
+		"acid-test-cluster.default",
+		"acid-test-cluster.default.svc",
+		"acid-test-cluster.default.svc.cluster.local",
+		"acid-test-cluster-repl.default.svc.cluster.local",
+		"acid-test-cluster-pooler-repl.default.svc",
+		"*.acid-test-cluster-clusterpods.default.svc.cluster.local",
+	} {
+		if !util.SliceContains(dnsNames, expected) {
+			t.Errorf("%s: expected %q in DNS names %v", t.Name(), expected, dnsNames)
+		}
+	}
+	if util.SliceContains(dnsNames, "acid-test-cluster-pooler") {
+		t.Errorf("%s: expected no DNS names of the disabled master pooler, got %v", t.Name(), dnsNames)
+	}
+}
+
+func TestSyncPostgresTLSCertificates(t *testing.T) {
+	defer func(now func() time.Time) { currentTime = now }(currentTime)
+	now := time.Now()
+	currentTime = func() time.Time { return now }
+
+	t.Setenv("OPERATOR_NAMESPACE", "operator")
+
+	for _, authority := range []string{"operator", postgresTLSAuthorityCluster} {
+		cluster := newTestCluster(t, cpov1.PostgresSpec{}, postgresTLSTestConfig(authority))
+		caSecretName := cluster.getPostgresTLSCASecretName()
+
+		if err := cluster.syncPostgresTLSCertificates(); err != nil {
+			t.Fatalf("%s [%s]: could not sync certificates: %v", t.Name(), authority, err)
+		}
+		caSecret, err := cluster.KubeClient.Secrets(caSecretName.Namespace).Get(context.TODO(), caSecretName.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s [%s]: CA secret %s not created: %v", t.Name(), authority, caSecretName, err)
+		}
+		if authority == postgresTLSAuthorityCluster && caSecret.Namespace != "default" {
+			t.Errorf("%s [%s]: expected CA secret in the cluster namespace, got %q", t.Name(), authority, caSecret.Namespace)
+		}
+		if authority != postgresTLSAuthorityCluster && (caSecret.Namespace != "operator" || len(caSecret.Labels) != 0) {
+			t.Errorf("%s [%s]: expected unlabeled CA secret in the operator namespace, got %q with labels %v",
+				t.Name(), authority, caSecret.Namespace, caSecret.Labels)
+		}
+
+		secret, err := cluster.KubeClient.Secrets("default").Get(context.TODO(), "acid-test-cluster-tls", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s [%s]: TLS secret not created: %v", t.Name(), authority, err)
+		}
+		var root, cert Certificate
+		if err = root.UnmarshalText(caSecret.Data[postgresTLSCASecretKey]); err != nil {
+			t.Fatalf("%s [%s]: could not read CA certificate: %v", t.Name(), authority, err)
+		}
+		if err = cert.UnmarshalText(secret.Data[postgresTLSCertSecretKey]); err != nil {
+			t.Fatalf("%s [%s]: could not read server certificate: %v", t.Name(), authority, err)
+		}
+		if err = cert.x509.CheckSignatureFrom(root.x509); err != nil {
+			t.Errorf("%s [%s]: server certificate not signed by the CA: %v", t.Name(), authority, err)
+		}
+		if _, err = tls.X509KeyPair(secret.Data[postgresTLSCertSecretKey], secret.Data[postgresTLSPrivateKeySecretKey]); err != nil {
+			t.Errorf("%s [%s]: server certificate does not match its key: %v", t.Name(), authority, err)
+		}
+		dnsNames := append([]string{}, cert.x509.DNSNames...)
+		expectedDNSNames := cluster.postgresTLSDNSNames(&cluster.Spec)
+		sort.Strings(dnsNames)
+		sort.Strings(expectedDNSNames)
+		if !reflect.DeepEqual(dnsNames, expectedDNSNames) {
+			t.Errorf("%s [%s]: expected DNS names %v, got %v", t.Name(), authority, expectedDNSNames, dnsNames)
+		}
+
+		configMap, err := cluster.KubeClient.ConfigMaps("default").Get(context.TODO(), "acid-test-cluster-ca", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s [%s]: CA bundle not created: %v", t.Name(), authority, err)
+		}
+		if configMap.Data[postgresTLSCASecretKey] != string(caSecret.Data[postgresTLSCASecretKey]) {
+			t.Errorf("%s [%s]: CA bundle does not contain the CA certificate", t.Name(), authority)
+		}
+
+		steps := []struct {
+			step    string
+			change  func()
+			renewed bool
+		}{
+			{
+				step:    "certificate valid",
+				change:  func() {},
+				renewed: false,
+			},
+			{
+				step:    "pooler enabled",
+				change:  func() { cluster.Spec.EnableConnectionPooler = util.True() },
+				renewed: true,
+			},
+			{
+				step:    "expiry outside of renewal window",
+				change:  func() { currentTime = func() time.Time { return now.AddDate(0, 10, 0) } },
+				renewed: false,
+			},
+			{
+				step:    "expiry inside of renewal window",
+				change:  func() { currentTime = func() time.Time { return now.AddDate(0, 11, 10) } },
+				renewed: true,
+			},
+		}
+		for _, step := range steps {
+			step.change()
+			before, _ := cluster.KubeClient.Secrets("default").Get(context.TODO(), "acid-test-cluster-tls", metav1.GetOptions{})
+			if err = cluster.syncPostgresTLSCertificates(); err != nil {
+				t.Fatalf("%s [%s, %s]: could not sync certificates: %v", t.Name(), authority, step.step, err)
+			}
+			after, _ := cluster.KubeClient.Secrets("default").Get(context.TODO(), "acid-test-cluster-tls", metav1.GetOptions{})
+			if renewed := !reflect.DeepEqual(before.Data, after.Data); renewed != step.renewed {
+				t.Errorf("%s [%s, %s]: expected renewal %v, got %v", t.Name(), authority, step.step, step.renewed, renewed)
+			}
+		}
+		currentTime = func() time.Time { return now }
+	}
+}
+
+func TestSharedPostgresTLSCertificateAuthority(t *testing.T) {
+	// the cluster runs in the operator namespace next to the operator-wide CA
+	t.Setenv("OPERATOR_NAMESPACE", "default")
+
+	cluster := newTestCluster(t, cpov1.PostgresSpec{}, postgresTLSTestConfig("operator"))
+	if err := cluster.syncPostgresTLSCertificates(); err != nil {
+		t.Fatalf("%s: could not sync certificates: %v", t.Name(), err)
+	}
+
+	caSecret, err := cluster.KubeClient.Secrets("default").Get(context.TODO(), "postgres-operator-tls-ca", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: CA secret not created: %v", t.Name(), err)
+	}
+	if len(caSecret.Labels) != 0 {
+		t.Errorf("%s: expected the shared CA secret without cluster labels, got %v", t.Name(), caSecret.Labels)
+	}
+	for _, secret := range cluster.Secrets {
+		if secret.Name == caSecret.Name {
+			t.Errorf("%s: the shared CA secret must not be deleted with the cluster", t.Name())
+		}
+	}
+}
+
+func TestPostgresTLSRenewalReason(t *testing.T) {
+	root, err := NewRootCertificateAuthority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRoot, err := NewRootCertificateAuthority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dnsNames := []string{"localhost", "acid-test-cluster"}
+	now := currentTime()
+	renewBefore := 30 * 24 * time.Hour
+
+	tests := []struct {
+		subTest  string
+		root     *RootCertificateAuthority
+		dnsNames []string
+		now      time.Time
+		renew    bool
+	}{
+		{"valid", root, []string{"acid-test-cluster", "localhost"}, now, false},
+		{"signed by another CA", otherRoot, dnsNames, now, true},
+		{"DNS names changed", root, append(dnsNames, "acid-test-cluster-pooler"), now, true},
+		{"expiring", root, dnsNames, now.AddDate(0, 11, 10), true},
+	}
+	for _, tt := range tests {
+		data, err := postgresTLSSecretData(root, "acid-test-cluster", dnsNames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reason := postgresTLSRenewalReason(&v1.Secret{Data: data}, tt.root, tt.dnsNames, renewBefore, tt.now); (reason != "") != tt.renew {
+			t.Errorf("%s [%s]: expected renewal %v, got reason %q", t.Name(), tt.subTest, tt.renew, reason)
+		}
+	}
+}
+
+func TestServedCertificate(t *testing.T) {
+	root, err := NewRootCertificateAuthority()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := postgresTLSSecretData(root, "acid-test-cluster", []string{"localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := tls.X509KeyPair(data[postgresTLSCertSecretKey], data[postgresTLSPrivateKeySecretKey])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected Certificate
+	if err = expected.UnmarshalText(data[postgresTLSCertSecretKey]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, acceptTLS := range []bool{true, false} {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			request := make([]byte, len(sslRequest))
+			if _, err = io.ReadFull(conn, request); err != nil || !reflect.DeepEqual(request, sslRequest) {
+				return
+			}
+			if !acceptTLS {
+				conn.Write([]byte{'N'})
+				return
+			}
+			conn.Write([]byte{'S'})
+			tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{serverCert}}).Handshake()
+		}()
+
+		served, err := servedCertificate(listener.Addr().String(), 5*time.Second)
+		listener.Close()
+		if !acceptTLS {
+			if err == nil {
+				t.Errorf("%s: expected error for server without TLS", t.Name())
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: could not get served certificate: %v", t.Name(), err)
+		}
+		if !served.Equal(expected.x509) {
+			t.Errorf("%s: served certificate differs from the issued one", t.Name())
+		}
+	}
+}
+
+func TestConnectionPoolerTLSCertificateSerial(t *testing.T) {
+	defer func(now func() time.Time) { currentTime = now }(currentTime)
+	now := time.Now()
+	currentTime = func() time.Time { return now }
+
+	cluster := newTestCluster(t, cpov1.PostgresSpec{EnableConnectionPooler: util.True()}, postgresTLSTestConfig(postgresTLSAuthorityCluster))
+	cluster.OpConfig.ConnectionPooler = config.ConnectionPooler{
+		ConnectionPoolerDefaultCPURequest:    "100m",
+		ConnectionPoolerDefaultCPULimit:      "100m",
+		ConnectionPoolerDefaultMemoryRequest: "100Mi",
+		ConnectionPoolerDefaultMemoryLimit:   "100Mi",
+	}
+
+	serial := func() string {
+		podTemplate, err := cluster.generateConnectionPoolerPodTemplate(Master)
+		if err != nil {
+			t.Fatalf("%s: could not generate pod template: %v", t.Name(), err)
+		}
+		return podTemplate.Annotations[constants.TLSCertificateSerialAnnotation]
+	}
+
+	if err := cluster.syncPostgresTLSCertificates(); err != nil {
+		t.Fatalf("%s: could not sync certificates: %v", t.Name(), err)
+	}
+	first := serial()
+	if first == "" {
+		t.Fatalf("%s: expected the certificate serial in the pooler pod template", t.Name())
+	}
+
+	currentTime = func() time.Time { return now.AddDate(0, 11, 10) }
+	if err := cluster.syncPostgresTLSCertificates(); err != nil {
+		t.Fatalf("%s: could not renew certificates: %v", t.Name(), err)
+	}
+	if renewed := serial(); renewed == "" || renewed == first {
+		t.Errorf("%s: expected the pooler pod template to change with the renewed certificate, got serial %q before and %q after",
+			t.Name(), first, renewed)
+	}
+
+	cluster.Spec.TLS = &cpov1.TLSDescription{SecretName: "custom-tls"}
+	if custom := serial(); custom != "" {
+		t.Errorf("%s: expected no certificate serial for a custom secret, got %q", t.Name(), custom)
+	}
+}
@@ -0,0 +1,265 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+// a failed member is only reinitialized automatically after being reported failed for this long,
+// so that Patroni gets the chance to recover it by itself
+const autoReinitializeGracePeriod = 5 * time.Minute
+
+// interval of the syncs that check whether reinitialized members have rejoined the cluster
+const reinitializeCheckInterval = 30 * time.Second
+
+// syncReinitialize executes a pending reinitialization request of the manifest once and,
+// when enabled, rebuilds members that Patroni cannot recover by itself.
+func (c *Cluster) syncReinitialize() error {
+	reinit := c.Spec.Reinitialize
+	if reinit == nil || c.restoreInProgress() {
+		return nil
+	}
+
+	if len(c.Status.ReinitializeMembers) > 0 {
+		if err := c.checkReinitializedMembers(); err != nil {
+			return err
+		}
+	}
+
+	if reinit.ID != "" && reinit.ID != c.Status.ReinitializeID && len(reinit.Members) > 0 {
+		if err := c.reinitializeMembers(reinit.ID, reinit.Members, reinit.RecreatePVC); err != nil {
+			return err
+		}
+	}
+
+	if reinit.Auto && !c.patroniPaused() {
+		if err := c.autoReinitializeFailedMembers(); err != nil {
+			return fmt.Errorf("could not reinitialize failed members: %v", err)
+		}
+	}
+	return nil
+}
+
+// reinitializeMembers rebuilds the given replicas from the primary, either through Patroni
+// or by recreating their data volume. Whether they rejoin the cluster is checked on the following syncs.
+func (c *Cluster) reinitializeMembers(id string, memberNames []string, recreatePVC bool) error {
+	masterPods, err := c.getRolePods(Master)
+	if err != nil {
+		return err
+	}
+	if len(masterPods) == 0 {
+		return fmt.Errorf("no primary found, postponing reinitialization %q", id)
+	}
+	members, err := c.patroni.GetClusterMembers(&masterPods[0])
+	if err != nil {
+		return fmt.Errorf("could not get Patroni cluster members: %v", err)
+	}
+	if err := validateReinitializeMembers(memberNames, members); err != nil {
+		return fmt.Errorf("invalid reinitialization %q: %v", id, err)
+	}
+
+	c.logger.Infof("reinitializing members %v (request %q)", memberNames, id)
+	for _, name := range memberNames {
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "Reinitialize", "Reinitializing member %q", name)
+		podName := spec.NamespacedName{Namespace: c.Namespace, Name: name}
+		if recreatePVC {
+			err = c.rebuildMemberOnNewVolume(podName)
+		} else {
+			err = c.reinitializeMember(podName)
+		}
+		if err != nil {
+			return fmt.Errorf("could not reinitialize member %q: %v", name, err)
+		}
+	}
+
+	// the request is recorded as soon as it has been started, so that a slow rejoin does not trigger it
+	// again, and with its members, so that the check whether they rejoin survives an operator restart
+	started := metav1.Now()
+	if err := c.setReinitializeStatus(id, memberNames, &started); err != nil {
+		return fmt.Errorf("could not record reinitialization %q: %v", id, err)
+	}
+	c.queueSync(reinitializeCheckInterval)
+	return nil
+}
+
+// setReinitializeStatus records the last executed reinitialization request together with its members
+// which have not rejoined the cluster yet
+func (c *Cluster) setReinitializeStatus(id string, members []string, started *metav1.Time) error {
+	if _, err := c.KubeClient.SetCRDReinitializeStatus(c.clusterName(), id, members, started); err != nil {
+		return err
+	}
+	c.Status.ReinitializeID = id
+	c.Status.ReinitializeMembers = members
+	c.Status.ReinitializeStarted = started
+	return nil
+}
+
+// checkReinitializedMembers reports whether the members of the started reinitialization have rejoined
+// the cluster, and schedules another check as long as they are catching up.
+func (c *Cluster) checkReinitializedMembers() error {
+	id, members := c.Status.ReinitializeID, c.Status.ReinitializeMembers
+	started := time.Time{}
+	if c.Status.ReinitializeStarted != nil {
+		started = c.Status.ReinitializeStarted.Time
+	}
+	for _, name := range members {
+		caughtUp, err := c.memberCaughtUp(name)
+		if err != nil {
+			c.logger.Debugf("could not check whether member %q has rejoined the cluster: %v", name, err)
+		}
+		if err != nil || !caughtUp {
+			if time.Since(started) < c.OpConfig.ResourceCheckTimeout {
+				c.queueSync(reinitializeCheckInterval)
+				return nil
+			}
+			if err := c.setReinitializeStatus(id, nil, nil); err != nil {
+				return fmt.Errorf("could not record reinitialization %q: %v", id, err)
+			}
+			c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "Reinitialize",
+				"Reinitialized members %v have not rejoined the cluster yet", members)
+			return fmt.Errorf("reinitialized members %v have not rejoined the cluster within %v", members, c.OpConfig.ResourceCheckTimeout)
+		}
+	}
+
+	if err := c.setReinitializeStatus(id, nil, nil); err != nil {
+		return fmt.Errorf("could not record reinitialization %q: %v", id, err)
+	}
+	c.logger.Infof("reinitialized members %v have rejoined the cluster (request %q)", members, id)
+	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "Reinitialize", "Reinitialized members %v have rejoined the cluster", members)
+	return nil
+}
+
+// queueSync schedules another sync of the cluster, if it is run by a controller.
+func (c *Cluster) queueSync(after time.Duration) {
+	if c.QueueSync != nil {
+		c.QueueSync(c.clusterName(), after)
+	}
+}
+
+// validateReinitializeMembers makes sure that only existing replicas are reinitialized.
+func validateReinitializeMembers(memberNames []string, members []patroni.ClusterMember) error {
+	roles := make(map[string]PostgresRole)
+	for _, member := range members {
+		roles[member.Name] = PostgresRole(member.Role)
+	}
+	for _, name := range memberNames {
+		role, ok := roles[name]
+		if !ok {
+			return fmt.Errorf("member %q does not exist", name)
+		}
+		if role == Leader || role == StandbyLeader || role == Master {
+			return fmt.Errorf("member %q is the leader and cannot be reinitialized", name)
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) reinitializeMember(podName spec.NamespacedName) error {
+	pod, err := c.KubeClient.Pods(podName.Namespace).Get(context.TODO(), podName.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get pod: %v", err)
+	}
+	return c.patroni.Reinitialize(pod, true)
+}
+
+// autoReinitializeFailedMembers reinitializes replicas that failed to start, or stay stopped on a timeline
+// the leader has left because pg_rewind did not succeed, for longer than the grace period.
+func (c *Cluster) autoReinitializeFailedMembers() error {
+	masterPods, err := c.getRolePods(Master)
+	if err != nil {
+		return err
+	}
+	if len(masterPods) == 0 {
+		return nil
+	}
+	members, err := c.patroni.GetClusterMembers(&masterPods[0])
+	if err != nil {
+		return fmt.Errorf("could not get Patroni cluster members: %v", err)
+	}
+
+	failedSince := make(map[string]metav1.Time)
+	for _, member := range c.Status.FailedMembers {
+		failedSince[member.Name] = member.Since
+	}
+	leaderTimeline := 0
+	for _, member := range members {
+		role := PostgresRole(member.Role)
+		if role == Leader || role == StandbyLeader || role == Master {
+			leaderTimeline = member.Timeline
+		}
+	}
+	// members which recovered or have been reinitialized are dropped from the status
+	var failed []cpov1.FailedMember
+	var errors []string
+	now := metav1.Now()
+	for _, member := range members {
+		if !memberNeedsReinitialize(member, leaderTimeline) {
+			continue
+		}
+		since, seen := failedSince[member.Name]
+		if !seen {
+			c.logger.Warningf("member %q is in state %q, reinitializing it if it does not recover within %v", member.Name, member.State, autoReinitializeGracePeriod)
+			failed = append(failed, cpov1.FailedMember{Name: member.Name, Since: now})
+			continue
+		}
+		if now.Sub(since.Time) < autoReinitializeGracePeriod {
+			failed = append(failed, cpov1.FailedMember{Name: member.Name, Since: since})
+			continue
+		}
+
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "Reinitialize",
+			"Reinitializing member %q which is in state %q since %s", member.Name, member.State, since.Format(time.RFC3339))
+		if err := c.reinitializeMember(spec.NamespacedName{Namespace: c.Namespace, Name: member.Name}); err != nil {
+			errors = append(errors, fmt.Sprintf("could not reinitialize member %q: %v", member.Name, err))
+			failed = append(failed, cpov1.FailedMember{Name: member.Name, Since: since})
+		}
+	}
+
+	if err := c.setFailedMembersStatus(failed); err != nil {
+		errors = append(errors, fmt.Sprintf("could not record failed members: %v", err))
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+// setFailedMembersStatus records the replicas reported failed by Patroni, so that the grace period of
+// their automatic reinitialization is kept across operator restarts
+func (c *Cluster) setFailedMembersStatus(failed []cpov1.FailedMember) error {
+	if reflect.DeepEqual(failed, c.Status.FailedMembers) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDFailedMembersStatus(c.clusterName(), failed); err != nil {
+		return err
+	}
+	c.Status.FailedMembers = failed
+	return nil
+}
+
+// memberNeedsReinitialize reports replicas that Patroni does not bring back by itself: Postgres failed to
+// start, or the member remains stopped on an older timeline than the leader after pg_rewind failed. Members
+// stopped on the current timeline, e.g. on purpose by an administrator, are left alone.
+func memberNeedsReinitialize(member patroni.ClusterMember, leaderTimeline int) bool {
+	role := PostgresRole(member.Role)
+	if role == Leader || role == StandbyLeader || role == Master {
+		return false
+	}
+	switch member.State {
+	case "start failed", "restart failed":
+		return true
+	case "stopped":
+		return member.Timeline > 0 && member.Timeline < leaderTimeline
+	}
+	return false
+}
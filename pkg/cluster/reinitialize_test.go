@@ -0,0 +1,226 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/mocks"
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+func TestMemberNeedsReinitialize(t *testing.T) {
+	tests := []struct {
+		member   patroni.ClusterMember
+		expected bool
+	}{
+		{patroni.ClusterMember{Name: "acid-test-cluster-0", Role: "leader", State: "running", Timeline: 2}, false},
+		{patroni.ClusterMember{Name: "acid-test-cluster-0", Role: "leader", State: "stopped", Timeline: 1}, false},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "streaming", Timeline: 2}, false},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "starting"}, false},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "start failed"}, true},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "stopped", Timeline: 1}, true},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "stopped", Timeline: 2}, false},
+		{patroni.ClusterMember{Name: "acid-test-cluster-1", Role: "replica", State: "stopped"}, false},
+	}
+
+	for _, tt := range tests {
+		if result := memberNeedsReinitialize(tt.member, 2); result != tt.expected {
+			t.Errorf("%s: expected %v for %s member in state %q, got %v", t.Name(), tt.expected, tt.member.Role, tt.member.State, result)
+		}
+	}
+}
+
+func TestValidateReinitializeMembers(t *testing.T) {
+	members := []patroni.ClusterMember{
+		{Name: "acid-test-cluster-0", Role: "leader"},
+		{Name: "acid-test-cluster-1", Role: "replica"},
+		{Name: "acid-test-cluster-2", Role: "sync_standby"},
+	}
+	tests := []struct {
+		memberNames []string
+		expectError bool
+	}{
+		{[]string{"acid-test-cluster-1", "acid-test-cluster-2"}, false},
+		{[]string{"acid-test-cluster-0"}, true},
+		{[]string{"acid-test-cluster-3"}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateReinitializeMembers(tt.memberNames, members)
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s: unexpected result for %v: %v", t.Name(), tt.memberNames, err)
+		}
+	}
+}
+
+// newReinitializeTestPostgresql creates the cluster manifest, whose status keeps the state of reinitializations
+func newReinitializeTestPostgresql(t *testing.T, client k8sutil.KubernetesClient) cpov1.Postgresql {
+	pg := cpov1.Postgresql{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"}}
+	if _, err := client.Postgresqls("default").Create(context.TODO(), &pg, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("%s: could not create manifest: %v", t.Name(), err)
+	}
+	return pg
+}
+
+func TestAutoReinitializeFailedMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, _ := newFakeK8sTestClient()
+	pg := newReinitializeTestPostgresql(t, client)
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Resources: config.Resources{
+					ClusterLabels:    map[string]string{"application": "cpo"},
+					ClusterNameLabel: "cluster.cpo.opensource.cybertec.at/name",
+					PodRoleLabel:     "spilo-role",
+				},
+			},
+		}, client, pg, logger, record.NewFakeRecorder(10))
+
+	for i, role := range []string{"master", "replica"} {
+		podLabels := cluster.labelsSet(false)
+		podLabels["spilo-role"] = role
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("acid-test-cluster-%d", i), Namespace: "default", Labels: podLabels},
+			Status:     v1.PodStatus{PodIP: fmt.Sprintf("192.168.100.%d", i+1)},
+		}
+		client.Pods("default").Create(context.TODO(), &pod, metav1.CreateOptions{})
+	}
+
+	clusterJson := `{"members": [{"name": "acid-test-cluster-0", "role": "leader", "state": "running", "timeline": 2}, {"name": "acid-test-cluster-1", "role": "replica", "state": "start failed", "timeline": 1, "lag": "unknown"}]}`
+	mockClient := mocks.NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any()).DoAndReturn(func(url string) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(clusterJson)))}, nil
+	}).AnyTimes()
+	reinitRequests := 0
+	mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "http://192.168.100.2:8008/reinitialize" {
+			t.Errorf("%s: unexpected request to %s", t.Name(), req.URL)
+		}
+		reinitRequests++
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte("reinitialize started")))}, nil
+	}).AnyTimes()
+	cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+	// the first observation only starts the grace period
+	if err := cluster.autoReinitializeFailedMembers(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if reinitRequests != 0 {
+		t.Errorf("%s: member must not be reinitialized within the grace period", t.Name())
+	}
+	stored, err := client.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get manifest: %v", t.Name(), err)
+	}
+	if failed := stored.Status.FailedMembers; len(failed) != 1 || failed[0].Name != "acid-test-cluster-1" {
+		t.Fatalf("%s: failed member has not been recorded in the status, got %v", t.Name(), failed)
+	}
+
+	// the grace period is kept across restarts of the operator, which start from the stored status
+	cluster.Status = stored.Status
+	cluster.Status.FailedMembers[0].Since = metav1.NewTime(time.Now().Add(-autoReinitializeGracePeriod))
+	if err := cluster.autoReinitializeFailedMembers(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if reinitRequests != 1 {
+		t.Errorf("%s: expected one reinitialization, got %d", t.Name(), reinitRequests)
+	}
+	if len(cluster.Status.FailedMembers) != 0 {
+		t.Errorf("%s: reinitialized member must be forgotten, got %v", t.Name(), cluster.Status.FailedMembers)
+	}
+}
+
+func TestCheckReinitializedMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, _ := newFakeK8sTestClient()
+	pg := newReinitializeTestPostgresql(t, client)
+	queuedSyncs := 0
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Resources: config.Resources{
+					ClusterLabels:        map[string]string{"application": "cpo"},
+					ClusterNameLabel:     "cluster.cpo.opensource.cybertec.at/name",
+					PodRoleLabel:         "spilo-role",
+					ResourceCheckTimeout: time.Minute,
+				},
+			},
+			QueueSync: func(clusterName spec.NamespacedName, after time.Duration) { queuedSyncs++ },
+		}, client, pg, logger, record.NewFakeRecorder(10))
+
+	podLabels := cluster.labelsSet(false)
+	podLabels["spilo-role"] = "master"
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-0", Namespace: "default", Labels: podLabels},
+		Status:     v1.PodStatus{PodIP: "192.168.100.1"},
+	}
+	client.Pods("default").Create(context.TODO(), &pod, metav1.CreateOptions{})
+
+	memberState := "creating replica"
+	mockClient := mocks.NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any()).DoAndReturn(func(url string) (*http.Response, error) {
+		clusterJson := fmt.Sprintf(`{"members": [{"name": "acid-test-cluster-0", "role": "leader", "state": "running", "timeline": 2}, {"name": "acid-test-cluster-1", "role": "replica", "state": %q, "timeline": 2, "lag": 0}]}`, memberState)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(clusterJson)))}, nil
+	}).AnyTimes()
+	cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+	started := metav1.Now()
+	if err := cluster.setReinitializeStatus("1", []string{"acid-test-cluster-1"}, &started); err != nil {
+		t.Fatalf("%s: could not record reinitialization: %v", t.Name(), err)
+	}
+
+	// a member that is still catching up is checked again on a later sync
+	if err := cluster.checkReinitializedMembers(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(cluster.Status.ReinitializeMembers) == 0 || queuedSyncs != 1 {
+		t.Errorf("%s: expected the reinitialization to stay pending with one queued sync, got %d", t.Name(), queuedSyncs)
+	}
+
+	memberState = "streaming"
+	if err := cluster.checkReinitializedMembers(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(cluster.Status.ReinitializeMembers) != 0 || queuedSyncs != 1 {
+		t.Errorf("%s: expected the rejoined members to be forgotten without another sync", t.Name())
+	}
+	stored, err := client.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get manifest: %v", t.Name(), err)
+	}
+	if stored.Status.ReinitializeID != "1" || len(stored.Status.ReinitializeMembers) != 0 || stored.Status.ReinitializeStarted != nil {
+		t.Errorf("%s: expected only the id of the finished reinitialization in the status, got %#v", t.Name(), stored.Status)
+	}
+
+	// members that do not rejoin within the timeout are reported
+	memberState = "creating replica"
+	started = metav1.NewTime(time.Now().Add(-time.Hour))
+	cluster.Status.ReinitializeID = "2"
+	cluster.Status.ReinitializeMembers = []string{"acid-test-cluster-1"}
+	cluster.Status.ReinitializeStarted = &started
+	if err := cluster.checkReinitializedMembers(); err == nil {
+		t.Errorf("%s: expected an error for members that have not rejoined", t.Name())
+	}
+	if len(cluster.Status.ReinitializeMembers) != 0 {
+		t.Errorf("%s: timed out reinitialization must be forgotten", t.Name())
+	}
+}
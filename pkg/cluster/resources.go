@@ -188,6 +188,9 @@ func (c *Cluster) preScaleDown(newStatefulSet *appsv1.StatefulSet) error {
 		return fmt.Errorf("pod %q does not belong to cluster", podName)
 	}
 
+	if c.patroniPaused() {
+		return fmt.Errorf("cluster management is paused, cannot move the primary before scaling down")
+	}
 	if err := c.patroni.Switchover(&masterPod[0], masterCandidatePod.Name); err != nil {
 		return fmt.Errorf("could not failover: %v", err)
 	}
@@ -0,0 +1,402 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lib/pq"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/clientset/versioned/scheme"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/selfservice"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/reference"
+)
+
+// selfServiceForbiddenFlags cannot be requested by PostgresUser resources, as they would allow
+// a role to escape the boundaries of its namespace
+var selfServiceForbiddenFlags = []string{
+	constants.RoleFlagSuperuser,
+	constants.RoleFlagReplication,
+	constants.RoleFlagByPassRLS,
+	constants.RoleFlagCreateRole,
+}
+
+const (
+	// selfServiceConditionReady reports whether a PostgresUser or PostgresDatabase was accepted
+	selfServiceConditionReady = "Ready"
+	// selfServiceRoleCommentPrefix marks roles created for a PostgresUser, followed by its namespace and name
+	selfServiceRoleCommentPrefix = "cpo:postgresuser:"
+)
+
+// reservedDatabaseNames cannot be claimed by PostgresDatabase resources
+var reservedDatabaseNames = []string{"postgres", "template0", "template1"}
+
+func (c *Cluster) selfServiceEnabled() bool {
+	return c.OpConfig.EnableSelfServiceCRDs && c.Config.SelfService != nil
+}
+
+// initSelfServiceUsers adds the roles of the PostgresUser resources referring to the cluster
+// and remembers the databases of PostgresDatabase resources for syncDatabases. Resources
+// which violate the policy of the cluster are skipped and reported with a warning event.
+func (c *Cluster) initSelfServiceUsers() {
+	c.selfServiceDbs = map[string]string{}
+	if !c.selfServiceEnabled() {
+		return
+	}
+
+	adminRole := ""
+	if c.OpConfig.EnableAdminRoleForUsers {
+		adminRole = c.OpConfig.TeamAdminRole
+	}
+
+	users := c.Config.SelfService.Users(c.clusterName())
+	for i := range users {
+		user := &users[i]
+		username := selfservice.UserName(user)
+		flags, err := c.validateSelfServiceUser(user, username)
+		if err != nil {
+			c.rejectSelfServiceResource(user, "PostgresUser", user.Namespace, user.Name, err)
+			continue
+		}
+		c.pgUsers[username] = spec.PgUser{
+			Origin:     spec.RoleOriginSelfService,
+			Name:       username,
+			Namespace:  user.Namespace,
+			Password:   util.RandomPassword(constants.PasswordLength),
+			Flags:      flags,
+			AdminRole:  adminRole,
+			Parameters: user.Spec.Parameters,
+		}
+	}
+
+	databases := c.Config.SelfService.Databases(c.clusterName())
+	for i := range databases {
+		database := &databases[i]
+		databaseName := selfservice.DatabaseName(database)
+		if err := c.validateSelfServiceDatabase(database, databaseName); err != nil {
+			c.rejectSelfServiceResource(database, "PostgresDatabase", database.Namespace, database.Name, err)
+			continue
+		}
+		c.selfServiceDbs[databaseName] = database.Spec.Owner
+
+		owner := c.pgUsers[database.Spec.Owner]
+		owner.IsDbOwner = true
+		c.pgUsers[database.Spec.Owner] = owner
+	}
+}
+
+func (c *Cluster) validateSelfServiceUser(user *cpov1.PostgresUser, username string) ([]string, error) {
+	if !selfservice.NamespaceAllowed(c.clusterName(), c.Spec.SelfServiceNamespaces, user.Namespace) {
+		return nil, fmt.Errorf("namespace %q is not allowed to manage roles of cluster %q", user.Namespace, c.clusterName())
+	}
+	if !isValidUsername(username) {
+		return nil, fmt.Errorf("invalid username: %q", username)
+	}
+	if c.isProtectedUsername(username) || c.isSystemUsername(username) {
+		return nil, fmt.Errorf("role %q is a protected or system role", username)
+	}
+	if existing, exists := c.pgUsers[username]; exists {
+		if existing.Origin == spec.RoleOriginSelfService {
+			return nil, fmt.Errorf("role %q is already defined by another PostgresUser", username)
+		}
+		return nil, fmt.Errorf("role %q is already defined as %s", username, existing.Origin)
+	}
+
+	flags, err := normalizeUserFlags(user.Spec.Flags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flags for user %q: %v", username, err)
+	}
+	for _, flag := range flags {
+		if util.SliceContains(selfServiceForbiddenFlags, flag) {
+			return nil, fmt.Errorf("flag %s is not allowed for self-service roles", flag)
+		}
+	}
+	if err := cpov1.ValidateSessionParameters(user.Spec.Parameters, c.getDesiredMajorVersionNumber()); err != nil {
+		return nil, fmt.Errorf("invalid parameters for user %q: %v", username, err)
+	}
+
+	return flags, nil
+}
+
+func (c *Cluster) validateSelfServiceDatabase(database *cpov1.PostgresDatabase, databaseName string) error {
+	if !selfservice.NamespaceAllowed(c.clusterName(), c.Spec.SelfServiceNamespaces, database.Namespace) {
+		return fmt.Errorf("namespace %q is not allowed to manage databases of cluster %q", database.Namespace, c.clusterName())
+	}
+	if !databaseNameRegexp.MatchString(databaseName) || util.SliceContains(reservedDatabaseNames, databaseName) {
+		return fmt.Errorf("invalid database name: %q", databaseName)
+	}
+	if _, exists := c.Spec.Databases[databaseName]; exists {
+		return fmt.Errorf("database %q is already defined in the cluster manifest", databaseName)
+	}
+	if _, exists := c.Spec.PreparedDatabases[databaseName]; exists {
+		return fmt.Errorf("database %q is already defined in the cluster manifest", databaseName)
+	}
+	if _, exists := c.selfServiceDbs[databaseName]; exists {
+		return fmt.Errorf("database %q is already defined by another PostgresDatabase", databaseName)
+	}
+
+	// owners are restricted to roles of the same namespace, otherwise a namespace could hand
+	// over databases to roles it does not control
+	if !c.isSelfServiceRoleOf(database.Spec.Owner, database.Namespace) {
+		return fmt.Errorf("owner %q is not a role of a PostgresUser in namespace %q", database.Spec.Owner, database.Namespace)
+	}
+
+	return nil
+}
+
+// isSelfServiceRoleOf reports whether the role is defined by a PostgresUser of the namespace
+func (c *Cluster) isSelfServiceRoleOf(role, namespace string) bool {
+	pgUser, exists := c.pgUsers[role]
+	return exists && pgUser.Origin == spec.RoleOriginSelfService && pgUser.Namespace == namespace
+}
+
+func (c *Cluster) rejectSelfServiceResource(obj runtime.Object, kind, namespace, name string, err error) {
+	c.logger.Warningf("ignoring %s %s/%s: %v", kind, namespace, name, err)
+	c.setSelfServiceCondition(obj, metav1.ConditionFalse, "Rejected", err.Error())
+
+	ref, refErr := reference.GetReference(scheme.Scheme, obj)
+	if refErr != nil {
+		c.logger.Errorf("could not get reference for %s %s/%s: %v", kind, namespace, name, refErr)
+		return
+	}
+	c.eventRecorder.Eventf(ref, v1.EventTypeWarning, "Rejected", "%v", err)
+}
+
+// acceptSelfServiceResources reports the PostgresUser and PostgresDatabase resources whose roles
+// and databases are managed for the cluster as ready
+func (c *Cluster) acceptSelfServiceResources() {
+	if !c.selfServiceEnabled() {
+		return
+	}
+	users := c.Config.SelfService.Users(c.clusterName())
+	for i := range users {
+		if c.isSelfServiceRoleOf(selfservice.UserName(&users[i]), users[i].Namespace) {
+			c.setSelfServiceCondition(&users[i], metav1.ConditionTrue, "Accepted", "role is managed by the operator")
+		}
+	}
+	databases := c.Config.SelfService.Databases(c.clusterName())
+	for i := range databases {
+		if owner, exists := c.selfServiceDbs[selfservice.DatabaseName(&databases[i])]; exists && owner == databases[i].Spec.Owner {
+			c.setSelfServiceCondition(&databases[i], metav1.ConditionTrue, "Accepted", "database is managed by the operator")
+		}
+	}
+}
+
+// setSelfServiceCondition patches the Ready condition of a PostgresUser or PostgresDatabase if it changed
+func (c *Cluster) setSelfServiceCondition(obj runtime.Object, status metav1.ConditionStatus, reason, message string) {
+	var (
+		objectMeta *metav1.ObjectMeta
+		conditions []metav1.Condition
+	)
+	switch resource := obj.(type) {
+	case *cpov1.PostgresUser:
+		objectMeta = &resource.ObjectMeta
+		conditions = append(conditions, resource.Status.Conditions...)
+	case *cpov1.PostgresDatabase:
+		objectMeta = &resource.ObjectMeta
+		conditions = append(conditions, resource.Status.Conditions...)
+	default:
+		return
+	}
+
+	if !meta.SetStatusCondition(&conditions, metav1.Condition{
+		Type:               selfServiceConditionReady,
+		Status:             status,
+		ObservedGeneration: objectMeta.Generation,
+		Reason:             reason,
+		Message:            message,
+	}) {
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{"status": map[string]interface{}{"conditions": conditions}})
+	if err != nil {
+		c.logger.Errorf("could not marshal status of %s/%s: %v", objectMeta.Namespace, objectMeta.Name, err)
+		return
+	}
+	switch obj.(type) {
+	case *cpov1.PostgresUser:
+		_, err = c.KubeClient.PostgresUsers(objectMeta.Namespace).Patch(
+			context.TODO(), objectMeta.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	case *cpov1.PostgresDatabase:
+		_, err = c.KubeClient.PostgresDatabases(objectMeta.Namespace).Patch(
+			context.TODO(), objectMeta.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	}
+	if err != nil && !k8sutil.ResourceNotFound(err) {
+		c.logger.Warningf("could not update status of %s/%s: %v", objectMeta.Namespace, objectMeta.Name, err)
+	}
+}
+
+func selfServiceRoleComment(user *cpov1.PostgresUser) string {
+	return selfServiceRoleCommentPrefix + user.Namespace + "/" + user.Name
+}
+
+// selfServiceUser returns the PostgresUser that defines the role of the cluster
+func (c *Cluster) selfServiceUser(username string) *cpov1.PostgresUser {
+	pgUser, exists := c.pgUsers[username]
+	if !exists || pgUser.Origin != spec.RoleOriginSelfService {
+		return nil
+	}
+	users := c.Config.SelfService.Users(c.clusterName())
+	for i := range users {
+		if selfservice.UserName(&users[i]) == username && users[i].Namespace == pgUser.Namespace {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// checkSelfServiceRoles verifies the ownership of existing self-service roles before their
+// secrets are synced. Roles which were created before ownership was recorded are adopted if
+// their secret already exists. If the database cannot be reached, the self-service roles are
+// left out of this sync, as their ownership cannot be verified.
+func (c *Cluster) checkSelfServiceRoles() {
+	if !c.selfServiceEnabled() || c.databaseAccessDisabled() || c.getNumberOfInstances(&c.Spec) <= 0 ||
+		c.Spec.StandbyCluster != nil || c.restoreInProgress() {
+		return
+	}
+	usernames := c.selfServiceUsernames()
+	if len(usernames) == 0 {
+		return
+	}
+
+	if err := c.initDbConn(); err != nil {
+		c.logger.Warningf("skipping self-service roles, could not verify their ownership: %v", err)
+		for _, username := range usernames {
+			c.removeSelfServiceUser(username)
+		}
+		return
+	}
+	defer func() {
+		if err := c.closeDbConn(); err != nil {
+			c.logger.Errorf("could not close database connection: %v", err)
+		}
+	}()
+
+	if _, err := c.verifySelfServiceRoles(true); err != nil {
+		c.logger.Warningf("skipping self-service roles: %v", err)
+		for _, username := range usernames {
+			c.removeSelfServiceUser(username)
+		}
+	}
+}
+
+// verifySelfServiceRoles rejects PostgresUser resources whose role already exists in the database
+// without having been created for the same resource, as the operator would otherwise take over
+// a role created manually or for another resource by resetting its password. It returns the
+// comments of the existing self-service roles.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) verifySelfServiceRoles(adoptUnmarked bool) (map[string]string, error) {
+	comments := make(map[string]string)
+	usernames := c.selfServiceUsernames()
+	if len(usernames) == 0 {
+		return comments, nil
+	}
+
+	rows, err := c.pgDb.Query(getRoleCommentsSQL, pq.Array(usernames))
+	if err != nil {
+		return nil, fmt.Errorf("could not query existing roles: %v", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			c.logger.Errorf("error when closing query cursor: %v", err)
+		}
+	}()
+	for rows.Next() {
+		var rolname, comment string
+		if err := rows.Scan(&rolname, &comment); err != nil {
+			return nil, fmt.Errorf("error when processing role rows: %v", err)
+		}
+		comments[rolname] = comment
+	}
+
+	for _, username := range usernames {
+		comment, exists := comments[username]
+		user := c.selfServiceUser(username)
+		if !exists || user == nil || comment == selfServiceRoleComment(user) {
+			continue
+		}
+		if comment == "" && adoptUnmarked && c.selfServiceSecretExists(username) {
+			if err := c.markSelfServiceRole(username, user); err != nil {
+				return nil, err
+			}
+			comments[username] = selfServiceRoleComment(user)
+			continue
+		}
+		c.rejectSelfServiceResource(user, "PostgresUser", user.Namespace, user.Name,
+			fmt.Errorf("role %q already exists and was not created for this PostgresUser", username))
+		for _, database := range c.removeSelfServiceUser(username) {
+			c.rejectSelfServiceResource(database, "PostgresDatabase", database.Namespace, database.Name,
+				fmt.Errorf("owner %q is not a role of a PostgresUser in namespace %q", username, database.Namespace))
+		}
+		delete(comments, username)
+	}
+
+	return comments, nil
+}
+
+// markSelfServiceRoles records the PostgresUser of the self-service roles which are not marked yet.
+// The caller is responsible for opening and closing the database connection.
+func (c *Cluster) markSelfServiceRoles(comments map[string]string) error {
+	for _, username := range c.selfServiceUsernames() {
+		user := c.selfServiceUser(username)
+		if user == nil || comments[username] == selfServiceRoleComment(user) {
+			continue
+		}
+		if err := c.markSelfServiceRole(username, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) markSelfServiceRole(username string, user *cpov1.PostgresUser) error {
+	if _, err := c.pgDb.Exec(fmt.Sprintf(commentOnRoleSQL, username, pq.QuoteLiteral(selfServiceRoleComment(user)))); err != nil {
+		return fmt.Errorf("could not record owner of role %q: %v", username, err)
+	}
+	return nil
+}
+
+func (c *Cluster) selfServiceSecretExists(username string) bool {
+	pgUser := c.pgUsers[username]
+	_, err := c.KubeClient.Secrets(pgUser.Namespace).Get(context.TODO(), c.credentialSecretName(username), metav1.GetOptions{})
+	return err == nil
+}
+
+func (c *Cluster) selfServiceUsernames() []string {
+	usernames := make([]string, 0)
+	for username, pgUser := range c.pgUsers {
+		if pgUser.Origin == spec.RoleOriginSelfService {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames
+}
+
+// removeSelfServiceUser drops a self-service role from the roles to sync, together with the
+// databases it should own, and returns the PostgresDatabase resources of those databases
+func (c *Cluster) removeSelfServiceUser(username string) []*cpov1.PostgresDatabase {
+	removed := make([]*cpov1.PostgresDatabase, 0)
+	delete(c.pgUsers, username)
+	databases := c.Config.SelfService.Databases(c.clusterName())
+	for i := range databases {
+		databaseName := selfservice.DatabaseName(&databases[i])
+		if owner, exists := c.selfServiceDbs[databaseName]; exists && owner == username {
+			delete(c.selfServiceDbs, databaseName)
+			removed = append(removed, &databases[i])
+		}
+	}
+	return removed
+}
@@ -0,0 +1,156 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	fakecpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/selfservice"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func selfServiceMeta(namespace, name string, age int) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:         namespace,
+		Name:              name,
+		CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Duration(age) * time.Hour)),
+	}
+}
+
+func TestInitSelfServiceUsers(t *testing.T) {
+	registry := &selfservice.Registry{}
+	registry.LoadUsers(&cpov1.PostgresUserList{Items: []cpov1.PostgresUser{
+		{ObjectMeta: selfServiceMeta("test", "app-owner", 3),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", UserName: "app_owner", Flags: cpov1.UserFlags{"createdb"}}},
+		{ObjectMeta: selfServiceMeta("team-a", "reporting", 2),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test"}},
+		{ObjectMeta: selfServiceMeta("team-b", "reporting", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test"}},
+		{ObjectMeta: selfServiceMeta("team-a", "app-owner", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test", UserName: "app_owner"}},
+		{ObjectMeta: selfServiceMeta("team-a", "escalation", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test", Flags: cpov1.UserFlags{"superuser"}}},
+		{ObjectMeta: selfServiceMeta("team-a", "role-admin", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test", UserName: "role_admin", Flags: cpov1.UserFlags{"createrole"}}},
+		{ObjectMeta: selfServiceMeta("team-a", "injection", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test",
+				Parameters: map[string]string{"work_mem = '1MB'; ALTER ROLE injection SUPERUSER; --": "1"}}},
+		{ObjectMeta: selfServiceMeta("team-a", "restricted", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test",
+				Parameters: map[string]string{"shared_buffers": "1GB"}}},
+		{ObjectMeta: selfServiceMeta("team-a", "manifest", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test", UserName: "foo"}},
+		{ObjectMeta: selfServiceMeta("team-a", "admin", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "test"}},
+		{ObjectMeta: selfServiceMeta("team-a", "other", 1),
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-other", ClusterNamespace: "test"}},
+	}})
+	registry.LoadDatabases(&cpov1.PostgresDatabaseList{Items: []cpov1.PostgresDatabase{
+		{ObjectMeta: selfServiceMeta("test", "app", 2),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", Owner: "app_owner"}},
+		{ObjectMeta: selfServiceMeta("team-a", "app", 1),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", ClusterNamespace: "test", Owner: "reporting"}},
+		{ObjectMeta: selfServiceMeta("team-a", "reports", 1),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", ClusterNamespace: "test", Owner: "app_owner"}},
+		{ObjectMeta: selfServiceMeta("team-a", "analytics", 1),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", ClusterNamespace: "test", DatabaseName: "analytics", Owner: "reporting"}},
+		{ObjectMeta: selfServiceMeta("team-a", "template1", 1),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", ClusterNamespace: "test", Owner: "reporting"}},
+		{ObjectMeta: selfServiceMeta("team-a", "bar", 1),
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", ClusterNamespace: "test", Owner: "reporting"}},
+	}})
+
+	acidClientSet := fakecpov1.NewSimpleClientset()
+	for _, user := range registry.Users(spec.NamespacedName{Namespace: "test", Name: "acid-test"}) {
+		if _, err := acidClientSet.CpoV1().PostgresUsers(user.Namespace).Create(context.TODO(), &user, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("could not create PostgresUser: %v", err)
+		}
+	}
+	client := k8sutil.NewMockKubernetesClient()
+	client.PostgresUsersGetter = acidClientSet.CpoV1()
+	client.PostgresDatabasesGetter = acidClientSet.CpoV1()
+
+	recorder := record.NewFakeRecorder(20)
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				ProtectedRoles: []string{adminUserName},
+				Auth: config.Auth{
+					SuperUsername:         superUserName,
+					ReplicationUsername:   replicationUserName,
+					EnableSelfServiceCRDs: true,
+				},
+			},
+			SelfService: registry,
+		},
+		client,
+		cpov1.Postgresql{
+			ObjectMeta: metav1.ObjectMeta{Name: "acid-test", Namespace: "test"},
+			Spec: cpov1.PostgresSpec{
+				Users:                 map[string]cpov1.UserDefinition{"foo": {}},
+				Databases:             map[string]cpov1.DatabaseDefinition{"bar": {Owner: "foo"}},
+				SelfServiceNamespaces: []string{"team-a"},
+			},
+		},
+		logger,
+		recorder)
+
+	if err := cluster.initUsers(); err != nil {
+		t.Fatalf("could not init users: %v", err)
+	}
+
+	expectedUsers := map[string]spec.PgUser{
+		"app_owner": {Origin: spec.RoleOriginSelfService, Name: "app_owner", Namespace: "test",
+			Flags: []string{"CREATEDB", "LOGIN"}, IsDbOwner: true},
+		"reporting": {Origin: spec.RoleOriginSelfService, Name: "reporting", Namespace: "team-a",
+			Flags: []string{"LOGIN"}, IsDbOwner: true},
+	}
+	for name, expected := range expectedUsers {
+		pgUser, exists := cluster.pgUsers[name]
+		if !exists {
+			t.Errorf("expected self-service role %q", name)
+			continue
+		}
+		pgUser.Password = ""
+		if !reflect.DeepEqual(pgUser, expected) {
+			t.Errorf("expected role %#v, got %#v", expected, pgUser)
+		}
+	}
+	if cluster.pgUsers["foo"].Origin != spec.RoleOriginManifest {
+		t.Errorf("expected role foo to remain a manifest role, got %s", cluster.pgUsers["foo"].Origin)
+	}
+	for _, name := range []string{"escalation", "role_admin", "injection", "restricted", "admin", "other"} {
+		if _, exists := cluster.pgUsers[name]; exists {
+			t.Errorf("expected role %q to be rejected", name)
+		}
+	}
+
+	expectedDatabases := map[string]string{"app": "app_owner", "analytics": "reporting"}
+	if !reflect.DeepEqual(cluster.selfServiceDbs, expectedDatabases) {
+		t.Errorf("expected self-service databases %#v, got %#v", expectedDatabases, cluster.selfServiceDbs)
+	}
+
+	// rejected: team-b namespace, duplicate app_owner, superuser flag, createrole flag, invalid parameter name,
+	// parameter not settable for a session, manifest role foo, protected admin, duplicate database app,
+	// foreign owner of reports, reserved template1, manifest database bar
+	if len(recorder.Events) != 12 {
+		t.Errorf("expected 12 warning events, got %d", len(recorder.Events))
+	}
+
+	rejected, err := acidClientSet.CpoV1().PostgresUsers("team-a").Get(context.TODO(), "role-admin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("could not get PostgresUser: %v", err)
+	}
+	condition := meta.FindStatusCondition(rejected.Status.Conditions, selfServiceConditionReady)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "Rejected" {
+		t.Errorf("expected rejected Ready condition, got %#v", condition)
+	}
+}
@@ -0,0 +1,288 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/retryutil"
+)
+
+// default of Patroni's maximum_lag_on_failover, used to decide if a rebuilt member has caught up
+const storageMigrationDefaultMaxLag = 1024 * 1024
+
+// syncStorageClassMigration moves the data volumes to the storage class of the manifest, one member at a time.
+// Replicas are rebuilt first on new PVCs, the primary is switched over and rebuilt last. Every call performs
+// at most one step, so that a rebuilt member can catch up with the primary between two syncs.
+func (c *Cluster) syncStorageClassMigration() error {
+	targetClass := c.Spec.Volume.StorageClass
+	if targetClass == "" || c.Statefulset == nil || c.restoreInProgress() {
+		return nil
+	}
+	if c.patroniPaused() {
+		c.logger.Debugf("cluster management is paused, postponing storage migration")
+		return nil
+	}
+	// the statefulset must already create new volumes with the target storage class
+	if len(c.Statefulset.Spec.VolumeClaimTemplates) == 0 ||
+		stringValue(c.Statefulset.Spec.VolumeClaimTemplates[0].Spec.StorageClassName) != targetClass {
+		c.logger.Debugf("statefulset does not use storage class %q yet, postponing storage migration", targetClass)
+		return nil
+	}
+
+	pvcs, err := c.listPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+	pods, err := c.listPodsOfType(TYPE_POSTGRESQL)
+	if err != nil {
+		return fmt.Errorf("could not list pods: %v", err)
+	}
+	outdated := c.membersOnOutdatedStorageClass(pvcs, pods, targetClass)
+
+	migration := c.Status.StorageMigration
+	if migration == nil || migration.TargetStorageClass != targetClass {
+		if len(outdated) == 0 {
+			return nil
+		}
+		migration = &cpov1.StorageMigrationStatus{
+			TargetStorageClass: targetClass,
+			Phase:              cpov1.StorageMigrationRunning,
+		}
+		c.logger.Infof("starting migration of %d data volume(s) to storage class %q", len(outdated), targetClass)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "StorageMigration",
+			"Migrating data volumes to storage class %q", targetClass)
+	} else {
+		migration = migration.DeepCopy()
+	}
+
+	if migration.CurrentMember != "" {
+		caughtUp, err := c.memberCaughtUp(migration.CurrentMember)
+		if err != nil {
+			return fmt.Errorf("could not check progress of rebuilt member %q: %v", migration.CurrentMember, err)
+		}
+		if !caughtUp {
+			c.logger.Infof("waiting for rebuilt member %q to catch up before migrating the next volume", migration.CurrentMember)
+			return nil
+		}
+		c.logger.Infof("member %q has been migrated to storage class %q", migration.CurrentMember, targetClass)
+		migration.MigratedMembers = append(migration.MigratedMembers, migration.CurrentMember)
+		migration.CurrentMember = ""
+		migration.Message = ""
+	}
+
+	if len(outdated) == 0 {
+		if migration.Phase != cpov1.StorageMigrationCompleted {
+			migration.Phase = cpov1.StorageMigrationCompleted
+			migration.Message = ""
+			c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "StorageMigration",
+				"All data volumes have been migrated to storage class %q", targetClass)
+			return c.setStorageMigrationStatus(migration)
+		}
+		return nil
+	}
+
+	if len(pods) < 2 {
+		msg := "online storage migration requires at least two instances"
+		if migration.Phase != cpov1.StorageMigrationFailed || migration.Message != msg {
+			c.logger.Warningf("cannot migrate data volumes to storage class %q: %s", targetClass, msg)
+			c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "StorageMigration",
+				"Cannot migrate data volumes to storage class %q: %s", targetClass, msg)
+			migration.Phase = cpov1.StorageMigrationFailed
+			migration.Message = msg
+			return c.setStorageMigrationStatus(migration)
+		}
+		return nil
+	}
+	migration.Phase = cpov1.StorageMigrationRunning
+
+	member, err := c.nextMemberToMigrate(outdated, pods)
+	if err != nil {
+		migration.Message = err.Error()
+		if statusErr := c.setStorageMigrationStatus(migration); statusErr != nil {
+			c.logger.Warningf("%v", statusErr)
+		}
+		return err
+	}
+
+	c.logger.Infof("rebuilding member %q on a new volume with storage class %q", member, targetClass)
+	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "StorageMigration",
+		"Rebuilding member %q on a new volume with storage class %q", member.Name, targetClass)
+	if err := c.rebuildMemberOnNewVolume(member); err != nil {
+		migration.Message = fmt.Sprintf("could not rebuild member %q: %v", member.Name, err)
+		if statusErr := c.setStorageMigrationStatus(migration); statusErr != nil {
+			c.logger.Warningf("%v", statusErr)
+		}
+		return fmt.Errorf("could not rebuild member %q: %v", member.Name, err)
+	}
+	migration.CurrentMember = member.Name
+	migration.Message = ""
+
+	return c.setStorageMigrationStatus(migration)
+}
+
+// membersOnOutdatedStorageClass returns the names of running pods whose data volume uses another storage class.
+func (c *Cluster) membersOnOutdatedStorageClass(pvcs []v1.PersistentVolumeClaim, pods []v1.Pod, targetClass string) []string {
+	podNames := make(map[string]bool)
+	for _, pod := range pods {
+		podNames[pod.Name] = true
+	}
+
+	outdated := make([]string, 0)
+	for _, pvc := range pvcs {
+		if !strings.HasPrefix(pvc.Name, constants.DataVolumeName+"-") || pvc.DeletionTimestamp != nil {
+			continue
+		}
+		podName := strings.TrimPrefix(pvc.Name, constants.DataVolumeName+"-")
+		if !podNames[podName] {
+			continue
+		}
+		if stringValue(pvc.Spec.StorageClassName) != targetClass {
+			outdated = append(outdated, podName)
+		}
+	}
+	sort.Strings(outdated)
+	return outdated
+}
+
+// nextMemberToMigrate picks a replica with an outdated volume. If only the primary is left,
+// it is switched over to a replica first.
+func (c *Cluster) nextMemberToMigrate(outdated []string, pods []v1.Pod) (spec.NamespacedName, error) {
+	var masterPod *v1.Pod
+	for i, pod := range pods {
+		role := PostgresRole(pod.Labels[c.OpConfig.PodRoleLabel])
+		if role == Master {
+			masterPod = &pods[i]
+		}
+	}
+	if masterPod == nil {
+		return spec.NamespacedName{}, fmt.Errorf("no primary found, postponing storage migration")
+	}
+
+	for _, name := range outdated {
+		if name != masterPod.Name {
+			return spec.NamespacedName{Namespace: c.Namespace, Name: name}, nil
+		}
+	}
+
+	candidate, err := c.getSwitchoverCandidate(masterPod)
+	if err != nil {
+		return spec.NamespacedName{}, fmt.Errorf("could not find a switchover candidate for the primary: %v", err)
+	}
+	if err := c.Switchover(masterPod, candidate); err != nil {
+		return spec.NamespacedName{}, fmt.Errorf("could not switch over before migrating the primary: %v", err)
+	}
+	return util.NameFromMeta(masterPod.ObjectMeta), nil
+}
+
+// rebuildMemberOnNewVolume drops the data volume of a replica together with its pod. The statefulset
+// recreates both from the current volume claim template and Patroni bootstraps the member from the primary.
+func (c *Cluster) rebuildMemberOnNewVolume(podName spec.NamespacedName) error {
+	pvcName := constants.DataVolumeName + "-" + podName.Name
+	oldPVC, err := c.KubeClient.PersistentVolumeClaims(podName.Namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("could not get persistent volume claim %q: %v", pvcName, err)
+	}
+
+	// the claim stays in use until the pod is gone, deleting it first prevents the new pod from reusing it
+	if err := c.KubeClient.PersistentVolumeClaims(podName.Namespace).Delete(context.TODO(), pvcName, c.deleteOptions); err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete persistent volume claim %q: %v", pvcName, err)
+	}
+
+	ch := c.registerPodSubscriber(podName)
+	defer c.unregisterPodSubscriber(podName)
+	if err := c.deletePodForRebuild(podName); err != nil {
+		return err
+	}
+	if err := c.waitForPodDeletion(ch); err != nil {
+		return err
+	}
+
+	return retryutil.Retry(c.OpConfig.ResourceCheckInterval, c.OpConfig.ResourceCheckTimeout,
+		func() (bool, error) {
+			pvc, err := c.KubeClient.PersistentVolumeClaims(podName.Namespace).Get(context.TODO(), pvcName, metav1.GetOptions{})
+			if err == nil {
+				return pvc.UID != oldPVC.UID, nil
+			}
+			if !k8serrors.IsNotFound(err) {
+				return false, err
+			}
+			// a pod created while the old claim was still terminating cannot start,
+			// delete it again so that the statefulset creates the claim together with the pod
+			pod, err := c.KubeClient.Pods(podName.Namespace).Get(context.TODO(), podName.Name, metav1.GetOptions{})
+			if err == nil && pod.DeletionTimestamp == nil && pod.Status.Phase == v1.PodPending {
+				c.logger.Debugf("pod %q was created without a data volume, recreating it", podName)
+				return false, c.deletePodForRebuild(podName)
+			}
+			return false, nil
+		})
+}
+
+func (c *Cluster) deletePodForRebuild(podName spec.NamespacedName) error {
+	err := c.KubeClient.Pods(podName.Namespace).Delete(context.TODO(), podName.Name, c.deleteOptions)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete pod %q: %v", podName, err)
+	}
+	return nil
+}
+
+// memberCaughtUp checks if a rebuilt member replicates from the primary with a lag
+// below Patroni's maximum_lag_on_failover.
+func (c *Cluster) memberCaughtUp(memberName string) (bool, error) {
+	masterPods, err := c.getRolePods(Master)
+	if err != nil {
+		return false, err
+	}
+	if len(masterPods) == 0 {
+		return false, nil
+	}
+	members, err := c.patroni.GetClusterMembers(&masterPods[0])
+	if err != nil {
+		return false, err
+	}
+
+	maxLag := uint64(storageMigrationDefaultMaxLag)
+	if c.Spec.Patroni.MaximumLagOnFailover > 0 {
+		maxLag = uint64(c.Spec.Patroni.MaximumLagOnFailover)
+	}
+	for _, member := range members {
+		if member.Name != memberName {
+			continue
+		}
+		role := PostgresRole(member.Role)
+		if role == Leader || role == StandbyLeader {
+			return true, nil
+		}
+		return (member.State == "streaming" || member.State == "running") && uint64(member.Lag) <= maxLag, nil
+	}
+	return false, nil
+}
+
+func (c *Cluster) setStorageMigrationStatus(migration *cpov1.StorageMigrationStatus) error {
+	pg, err := c.KubeClient.SetCRDStorageMigrationStatus(c.clusterName(), migration)
+	if err != nil {
+		return fmt.Errorf("could not update storage migration status: %v", err)
+	}
+	if pg != nil && pg.Name != "" {
+		c.Status.StorageMigration = pg.Status.StorageMigration
+	} else {
+		c.Status.StorageMigration = migration
+	}
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+// addStorageMigrationMembers creates the pods and volume claims of the members on the given storage class,
+// while the statefulset already requests the target storage class
+func addStorageMigrationMembers(cluster *Cluster, instances int, pvcClass, targetClass string) {
+	client := cluster.KubeClient
+	cluster.Statefulset = &appsv1.StatefulSet{
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+				{Spec: v1.PersistentVolumeClaimSpec{StorageClassName: &targetClass}},
+			},
+		},
+	}
+
+	for i := 0; i < instances; i++ {
+		podName := fmt.Sprintf("acid-test-cluster-%d", i)
+		podLabels := cluster.labelsSetWithType(false, TYPE_POSTGRESQL, true)
+		podLabels["spilo-role"] = "replica"
+		if i == 0 {
+			podLabels["spilo-role"] = "master"
+		}
+		pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: "default", Labels: podLabels}}
+		client.Pods("default").Create(context.TODO(), &pod, metav1.CreateOptions{})
+
+		pvc := v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.DataVolumeName + "-" + podName,
+				Namespace: "default",
+				Labels:    cluster.labelsSet(false),
+			},
+			Spec: v1.PersistentVolumeClaimSpec{StorageClassName: &pvcClass},
+		}
+		client.PersistentVolumeClaims("default").Create(context.TODO(), &pvc, metav1.CreateOptions{})
+	}
+}
+
+func TestMembersOnOutdatedStorageClass(t *testing.T) {
+	oldClass, newClass := "gp2", "ebs-csi-encrypted"
+	pvc := func(name string, class *string) v1.PersistentVolumeClaim {
+		return v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.PersistentVolumeClaimSpec{StorageClassName: class},
+		}
+	}
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-2"}},
+	}
+	pvcs := []v1.PersistentVolumeClaim{
+		pvc("pgdata-acid-test-cluster-2", &oldClass),
+		pvc("pgdata-acid-test-cluster-1", &newClass),
+		pvc("pgdata-acid-test-cluster-0", nil),
+		// volume of a scaled down member
+		pvc("pgdata-acid-test-cluster-3", &oldClass),
+		// not a data volume
+		pvc("repo1-acid-test-cluster-0", &oldClass),
+	}
+
+	cluster := New(Config{}, k8sutil.KubernetesClient{}, cpov1.Postgresql{}, logger, eventRecorder)
+	outdated := cluster.membersOnOutdatedStorageClass(pvcs, pods, newClass)
+	expected := []string{"acid-test-cluster-0", "acid-test-cluster-2"}
+	if !reflect.DeepEqual(outdated, expected) {
+		t.Errorf("%s: expected %v, got %v", t.Name(), expected, outdated)
+	}
+}
+
+func TestStorageClassMigrationStatus(t *testing.T) {
+	tests := []struct {
+		subTest       string
+		instances     int
+		pvcClass      string
+		status        *cpov1.StorageMigrationStatus
+		expectedPhase string
+	}{
+		{
+			subTest:       "volumes already on target storage class",
+			instances:     2,
+			pvcClass:      "encrypted",
+			expectedPhase: "",
+		},
+		{
+			subTest:       "single instance cannot be migrated online",
+			instances:     1,
+			pvcClass:      "gp2",
+			expectedPhase: cpov1.StorageMigrationFailed,
+		},
+		{
+			subTest:   "last member has caught up",
+			instances: 2,
+			pvcClass:  "encrypted",
+			status: &cpov1.StorageMigrationStatus{
+				TargetStorageClass: "encrypted",
+				Phase:              cpov1.StorageMigrationRunning,
+				MigratedMembers:    []string{"acid-test-cluster-1", "acid-test-cluster-0"},
+			},
+			expectedPhase: cpov1.StorageMigrationCompleted,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, cpov1.PostgresSpec{
+			NumberOfInstances: int32(tt.instances),
+			Volume:            cpov1.Volume{Size: "1Gi", StorageClass: "encrypted"},
+		}, Config{})
+		addStorageMigrationMembers(cluster, tt.instances, tt.pvcClass, "encrypted")
+		cluster.Status.StorageMigration = tt.status
+
+		if err := cluster.syncStorageClassMigration(); err != nil {
+			t.Errorf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+			continue
+		}
+
+		pg, err := cluster.KubeClient.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s [%s]: could not get postgresql: %v", t.Name(), tt.subTest, err)
+		}
+		phase := ""
+		if pg.Status.StorageMigration != nil {
+			phase = pg.Status.StorageMigration.Phase
+		}
+		if phase != tt.expectedPhase {
+			t.Errorf("%s [%s]: expected phase %q, got %q", t.Name(), tt.subTest, tt.expectedPhase, phase)
+		}
+		if cluster.Status.StorageMigration != nil && cluster.Status.StorageMigration.Phase != phase {
+			t.Errorf("%s [%s]: cluster status %q not in sync with the resource %q", t.Name(), tt.subTest, cluster.Status.StorageMigration.Phase, phase)
+		}
+	}
+}
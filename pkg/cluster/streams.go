@@ -331,7 +331,7 @@ func (c *Cluster) syncStreams() error {
 	}
 
 	// sync logical replication slots in Patroni config
-	configPatched, _, _, err := c.syncPatroniConfig(pods, requiredPatroniConfig, nil)
+	configPatched, _, _, _, err := c.syncPatroniConfig(pods, requiredPatroniConfig, nil)
 	if err != nil {
 		c.logger.Warningf("Patroni config updated? %v - errors during config sync: %v", configPatched, err)
 	}
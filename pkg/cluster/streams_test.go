@@ -51,8 +51,8 @@ var (
 			Namespace: namespace,
 		},
 		Spec: cpov1.PostgresSpec{
-			Databases: map[string]string{
-				dbName: fmt.Sprintf("%s%s", dbName, constants.UserRoleNameSuffix),
+			Databases: map[string]cpov1.DatabaseDefinition{
+				dbName: {Owner: fmt.Sprintf("%s%s", dbName, constants.UserRoleNameSuffix)},
 			},
 			Streams: []cpov1.Stream{
 				{
@@ -14,6 +14,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,7 @@ import (
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/pgparameters"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/retryutil"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
@@ -184,8 +186,8 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 		if err != nil {
 			c.logger.Warningf("error while syncing cluster state: %v", err)
 			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusSyncFailed)
-		} else if !c.Status.Running() {
-			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), cpov1.ClusterStatusRunning)
+		} else if c.Status.PostgresClusterStatus != c.runningStatus() {
+			c.KubeClient.SetPostgresCRDStatus(c.clusterName(), c.runningStatus())
 		}
 	}()
 
@@ -218,6 +220,7 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 		err = fmt.Errorf("could not init users: %v", err)
 		return err
 	}
+	c.checkSelfServiceRoles()
 
 	//TODO: mind the secrets of the deleted/new users
 	if err = c.syncSecrets(); err != nil {
@@ -225,6 +228,16 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 		return err
 	}
 
+	if err = c.syncPatroniAPI(); err != nil {
+		err = fmt.Errorf("could not sync Patroni API credentials: %v", err)
+		return err
+	}
+
+	if err = c.syncPostgresTLSCertificates(); err != nil {
+		err = fmt.Errorf("could not sync TLS certificates: %v", err)
+		return err
+	}
+
 	if err = c.syncServices(); err != nil {
 		err = fmt.Errorf("could not sync services: %v", err)
 		return err
@@ -261,6 +274,54 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 		}
 	}
 
+	c.logger.Debug("reloading renewed TLS certificates")
+	if err = c.reloadPostgresTLSCertificates(); err != nil {
+		err = fmt.Errorf("could not reload TLS certificates: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("reloading renewed Patroni API certificates")
+	if err = c.reloadPatroniAPICertificates(); err != nil {
+		err = fmt.Errorf("could not reload Patroni API certificates: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing Patroni member tags")
+	if err = c.syncPatroniMemberTags(); err != nil {
+		err = fmt.Errorf("could not sync Patroni member tags: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing synchronous standbys status")
+	if err = c.syncSynchronousStandbysStatus(); err != nil {
+		err = fmt.Errorf("could not sync synchronous standbys status: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing timeline history")
+	if err = c.syncTimelineHistory(); err != nil {
+		err = fmt.Errorf("could not sync timeline history: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing failover slots")
+	if err = c.syncFailoverSlots(); err != nil {
+		err = fmt.Errorf("could not sync failover slots: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing storage class migration")
+	if err = c.syncStorageClassMigration(); err != nil {
+		err = fmt.Errorf("could not migrate volumes to the new storage class: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
+	c.logger.Debug("syncing member reinitialization")
+	if err = c.syncReinitialize(); err != nil {
+		err = fmt.Errorf("could not reinitialize members: %v", err)
+		syncErrors = append(syncErrors, err)
+	}
+
 	// add or remove standby_cluster section from Patroni config depending on changes in standby section
 	if !reflect.DeepEqual(oldSpec.Spec.StandbyCluster, newSpec.Spec.StandbyCluster) {
 		if err := c.syncStandbyClusterConfiguration(); err != nil {
@@ -306,6 +367,11 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 			c.logger.Errorf("could not sync roles: %v", err)
 		}
 
+		c.logger.Debug("syncing password rotation of system users")
+		if err = c.syncSystemUserRotation(); err != nil {
+			c.logger.Errorf("could not rotate passwords of system users: %v", err)
+		}
+
 		c.logger.Debug("syncing databases")
 		if err = c.syncDatabases(); err != nil {
 			c.logger.Errorf("could not sync databases: %v", err)
@@ -315,6 +381,29 @@ func (c *Cluster) Sync(newSpec *cpov1.Postgresql) error {
 		if err = c.syncPreparedDatabases(); err != nil {
 			c.logger.Errorf("could not sync prepared database: %v", err)
 		}
+
+		c.logger.Debug("syncing database extensions")
+		if err = c.syncDatabaseExtensions(); err != nil {
+			c.logger.Errorf("could not sync database extensions: %v", err)
+		}
+
+		c.logger.Debug("syncing grants")
+		if err = c.syncGrants(true); err != nil {
+			c.logger.Errorf("could not sync grants: %v", err)
+		}
+
+		c.logger.Debug("syncing logical replication")
+		if err = c.syncLogicalReplication(); err != nil {
+			c.logger.Errorf("could not sync logical replication: %v", err)
+		}
+
+		c.logger.Debug("syncing pending deletions")
+		if err = c.registerRemovedObjects(nil, &c.Spec); err != nil {
+			c.logger.Errorf("could not register removed databases and roles: %v", err)
+		}
+		if err = c.syncPendingDeletions(); err != nil {
+			c.logger.Errorf("could not sync pending deletions: %v", err)
+		}
 	}
 
 	// if !(c.databaseAccessDisabled() || c.getNumberOfInstances(&newSpec.Spec) <= 0 || c.Spec.StandbyCluster != nil || c.restoreInProgress()) {
@@ -556,11 +645,17 @@ func (c *Cluster) syncStatefulSet() error {
 		restartWait         uint32
 		configPatched       bool
 		restartPrimaryFirst bool
+		restartParameters   []string
 	)
 	podsToRecreate := make([]v1.Pod, 0)
 	isSafeToRecreatePods := true
 	switchoverCandidates := make([]spec.NamespacedName, 0)
 
+	requiredPgParameters, err := c.desiredPostgresParameters(&c.Spec)
+	if err != nil {
+		return fmt.Errorf("could not generate Postgres parameters: %v", err)
+	}
+
 	pods, err := c.listPodsOfType(TYPE_POSTGRESQL)
 	if err != nil {
 		c.logger.Warnf("could not list pods of the statefulset: %v", err)
@@ -702,24 +797,25 @@ func (c *Cluster) syncStatefulSet() error {
 		c.logger.Warnf("could not get list of pods to apply PostgreSQL parameters only to be set via Patroni API: %v", err)
 	}
 
-	requiredPgParameters := make(map[string]string)
-	for k, v := range c.Spec.Parameters {
-		requiredPgParameters[k] = v
-	}
-	// if streams are defined wal_level must be switched to logical
-	if len(c.Spec.Streams) > 0 {
+	// if streams or publications are defined wal_level must be switched to logical
+	if len(c.Spec.Streams) > 0 || hasPublications(&c.Spec) {
 		requiredPgParameters["wal_level"] = "logical"
 	}
+	c.addFailoverSlotParameters(requiredPgParameters)
 
 	// sync Patroni config
 	c.logger.Debug("syncing Patroni config")
-	if configPatched, restartPrimaryFirst, restartWait, err = c.syncPatroniConfig(pods, c.Spec.Patroni, requiredPgParameters); err != nil {
+	if configPatched, restartPrimaryFirst, restartParameters, restartWait, err = c.syncPatroniConfig(pods, c.Spec.Patroni, requiredPgParameters); err != nil {
 		c.logger.Warningf("Patroni config updated? %v - errors during config sync: %v", configPatched, err)
 		isSafeToRecreatePods = false
 	}
 
-	// restart Postgres where it is still pending
-	if err = c.restartInstances(pods, restartWait, restartPrimaryFirst); err != nil {
+	// restart Postgres where it is still pending, pods about to be recreated pick up the new configuration anyway
+	restartPods := pods
+	if isSafeToRecreatePods && !c.patroniPaused() {
+		restartPods = podsToRestart(pods, podsToRecreate)
+	}
+	if err = c.restartInstances(restartPods, restartWait, restartPrimaryFirst, restartParameters); err != nil {
 		c.logger.Errorf("errors while restarting Postgres in pods via Patroni API: %v", err)
 		isSafeToRecreatePods = false
 	}
@@ -727,7 +823,9 @@ func (c *Cluster) syncStatefulSet() error {
 	// if we get here we also need to re-create the pods (either leftovers from the old
 	// statefulset or those that got their configuration from the outdated statefulset)
 	if len(podsToRecreate) > 0 {
-		if isSafeToRecreatePods {
+		if c.patroniPaused() {
+			c.logger.Warningf("cluster management is paused, postponing rolling update of %d pod(s)", len(podsToRecreate))
+		} else if isSafeToRecreatePods {
 			c.logger.Debugln("performing rolling update")
 			c.eventRecorder.Event(c.GetReference(), v1.EventTypeNormal, "Update", "Performing rolling update")
 			if err := c.recreatePods(podsToRecreate, switchoverCandidates); err != nil {
@@ -742,13 +840,14 @@ func (c *Cluster) syncStatefulSet() error {
 	return nil
 }
 
-func (c *Cluster) syncPatroniConfig(pods []v1.Pod, requiredPatroniConfig cpov1.Patroni, requiredPgParameters map[string]string) (bool, bool, uint32, error) {
+func (c *Cluster) syncPatroniConfig(pods []v1.Pod, requiredPatroniConfig cpov1.Patroni, requiredPgParameters map[string]string) (bool, bool, []string, uint32, error) {
 	var (
 		effectivePatroniConfig cpov1.Patroni
 		effectivePgParameters  map[string]string
 		loopWait               uint32
 		configPatched          bool
 		restartPrimaryFirst    bool
+		restartParameters      []string
 		err                    error
 	)
 
@@ -768,7 +867,7 @@ func (c *Cluster) syncPatroniConfig(pods []v1.Pod, requiredPatroniConfig cpov1.P
 		if reflect.DeepEqual(effectivePatroniConfig, cpov1.Patroni{}) || len(effectivePgParameters) == 0 {
 			errors = append(errors, fmt.Sprintf("empty Patroni config on pod %s - skipping config patch", podName))
 		} else {
-			configPatched, restartPrimaryFirst, err = c.checkAndSetGlobalPostgreSQLConfiguration(&pod, effectivePatroniConfig, requiredPatroniConfig, effectivePgParameters, requiredPgParameters)
+			configPatched, restartPrimaryFirst, restartParameters, err = c.checkAndSetGlobalPostgreSQLConfiguration(&pod, effectivePatroniConfig, requiredPatroniConfig, effectivePgParameters, requiredPgParameters)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("could not set PostgreSQL configuration options for pod %s: %v", podName, err))
 				continue
@@ -788,10 +887,12 @@ func (c *Cluster) syncPatroniConfig(pods []v1.Pod, requiredPatroniConfig cpov1.P
 		err = fmt.Errorf("%v", strings.Join(errors, `', '`))
 	}
 
-	return configPatched, restartPrimaryFirst, loopWait, err
+	return configPatched, restartPrimaryFirst, restartParameters, loopWait, err
 }
 
-func (c *Cluster) restartInstances(pods []v1.Pod, restartWait uint32, restartPrimaryFirst bool) (err error) {
+// restartInstances restarts Postgres where Patroni reports a pending restart and, when the operator
+// changed parameters the catalog marks as requiring a restart, on every given pod.
+func (c *Cluster) restartInstances(pods []v1.Pod, restartWait uint32, restartPrimaryFirst bool, restartParameters []string) (err error) {
 	errors := make([]string, 0)
 	remainingPods := make([]*v1.Pod, 0)
 
@@ -806,7 +907,7 @@ func (c *Cluster) restartInstances(pods []v1.Pod, restartWait uint32, restartPri
 			remainingPods = append(remainingPods, &pods[i])
 			continue
 		}
-		if err = c.restartInstance(&pod, restartWait); err != nil {
+		if err = c.restartInstance(&pod, restartWait, restartParameters); err != nil {
 			errors = append(errors, fmt.Sprintf("%v", err))
 		}
 	}
@@ -814,7 +915,7 @@ func (c *Cluster) restartInstances(pods []v1.Pod, restartWait uint32, restartPri
 	// in most cases only the master should be left to restart
 	if len(remainingPods) > 0 {
 		for _, remainingPod := range remainingPods {
-			if err = c.restartInstance(remainingPod, restartWait); err != nil {
+			if err = c.restartInstance(remainingPod, restartWait, restartParameters); err != nil {
 				errors = append(errors, fmt.Sprintf("%v", err))
 			}
 		}
@@ -827,7 +928,7 @@ func (c *Cluster) restartInstances(pods []v1.Pod, restartWait uint32, restartPri
 	return nil
 }
 
-func (c *Cluster) restartInstance(pod *v1.Pod, restartWait uint32) error {
+func (c *Cluster) restartInstance(pod *v1.Pod, restartWait uint32, restartParameters []string) error {
 	// if the config update requires a restart, call Patroni restart
 	podName := util.NameFromMeta(pod.ObjectMeta)
 	role := PostgresRole(pod.Labels[c.OpConfig.PodRoleLabel])
@@ -836,9 +937,13 @@ func (c *Cluster) restartInstance(pod *v1.Pod, restartWait uint32) error {
 		return fmt.Errorf("could not restart Postgres in %s pod %s: %v", role, podName, err)
 	}
 
-	// do restart only when it is pending
-	if memberData.PendingRestart {
-		c.eventRecorder.Event(c.GetReference(), v1.EventTypeNormal, "Update", fmt.Sprintf("restarting Postgres server within %s pod %s", role, podName))
+	// do restart only when it is pending or required by the changed parameters
+	if memberData.PendingRestart || len(restartParameters) > 0 {
+		reason := "pending restart"
+		if len(restartParameters) > 0 {
+			reason = fmt.Sprintf("changed %s", strings.Join(restartParameters, ", "))
+		}
+		c.eventRecorder.Event(c.GetReference(), v1.EventTypeNormal, "Update", fmt.Sprintf("restarting Postgres server within %s pod %s (%s)", role, podName, reason))
 		if err := c.patroni.Restart(pod); err != nil {
 			return err
 		}
@@ -849,6 +954,22 @@ func (c *Cluster) restartInstance(pod *v1.Pod, restartWait uint32) error {
 	return nil
 }
 
+// podsToRestart returns the pods which are not recreated by the rolling update of this sync.
+// Recreated pods start with the new configuration, restarting them before would be in vain.
+func podsToRestart(pods, podsToRecreate []v1.Pod) []v1.Pod {
+	recreated := make(map[string]bool, len(podsToRecreate))
+	for _, pod := range podsToRecreate {
+		recreated[pod.Name] = true
+	}
+	result := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if !recreated[pod.Name] {
+			result = append(result, pod)
+		}
+	}
+	return result
+}
+
 // AnnotationsToPropagate get the annotations to update if required
 // based on the annotations in postgres CRD
 func (c *Cluster) AnnotationsToPropagate(annotations map[string]string) map[string]string {
@@ -881,14 +1002,54 @@ func (c *Cluster) AnnotationsToPropagate(annotations map[string]string) map[stri
 	return nil
 }
 
+// syncSynchronousStandbysStatus reports the members of the current synchronous or quorum set in the status.
+func (c *Cluster) syncSynchronousStandbysStatus() error {
+	if c.Spec.Patroni.SynchronousReplicationMode() == cpov1.SynchronousReplicationOff && len(c.Status.SynchronousStandbys) == 0 {
+		return nil
+	}
+
+	var standbys []string
+	if c.Spec.Patroni.SynchronousReplicationMode() != cpov1.SynchronousReplicationOff {
+		masterPods, err := c.getRolePods(Master)
+		if err != nil {
+			return err
+		}
+		if len(masterPods) == 0 {
+			c.logger.Debug("no primary found, skipping synchronous standbys status")
+			return nil
+		}
+		members, err := c.patroni.GetClusterMembers(&masterPods[0])
+		if err != nil {
+			return fmt.Errorf("could not get Patroni cluster members: %v", err)
+		}
+		for _, member := range members {
+			if isSynchronousStandby(member) {
+				standbys = append(standbys, member.Name)
+			}
+		}
+		sort.Strings(standbys)
+	}
+
+	if reflect.DeepEqual(standbys, c.Status.SynchronousStandbys) {
+		return nil
+	}
+	if _, err := c.KubeClient.SetCRDSynchronousStandbysStatus(c.clusterName(), standbys); err != nil {
+		return err
+	}
+	c.Status.SynchronousStandbys = standbys
+	return nil
+}
+
 // checkAndSetGlobalPostgreSQLConfiguration checks whether cluster-wide API parameters
 // (like max_connections) have changed and if necessary sets it via the Patroni API
-func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectivePatroniConfig, desiredPatroniConfig cpov1.Patroni, effectivePgParameters, desiredPgParameters map[string]string) (bool, bool, error) {
+func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectivePatroniConfig, desiredPatroniConfig cpov1.Patroni, effectivePgParameters, desiredPgParameters map[string]string) (bool, bool, []string, error) {
 	configToSet := make(map[string]interface{})
 	parametersToSet := make(map[string]string)
 	restartPrimary := make([]bool, 0)
+	restartParameters := make([]string, 0)
 	configPatched := false
 	requiresMasterRestart := false
+	majorVersion := c.getDesiredMajorVersionNumber()
 
 	// compare effective and desired Patroni config options
 	if desiredPatroniConfig.LoopWait > 0 && desiredPatroniConfig.LoopWait != effectivePatroniConfig.LoopWait {
@@ -897,21 +1058,35 @@ func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectiv
 	if desiredPatroniConfig.MaximumLagOnFailover > 0 && desiredPatroniConfig.MaximumLagOnFailover != effectivePatroniConfig.MaximumLagOnFailover {
 		configToSet["maximum_lag_on_failover"] = desiredPatroniConfig.MaximumLagOnFailover
 	}
-	if desiredPatroniConfig.PgHba != nil && !reflect.DeepEqual(desiredPatroniConfig.PgHba, effectivePatroniConfig.PgHba) {
-		configToSet["pg_hba"] = desiredPatroniConfig.PgHba
+	desiredPgHba := pgHbaEntries(&c.OpConfig, &desiredPatroniConfig)
+	if desiredPgHba != nil && !reflect.DeepEqual(desiredPgHba, effectivePatroniConfig.PgHba) {
+		configToSet["pg_hba"] = desiredPgHba
 	}
 	if desiredPatroniConfig.RetryTimeout > 0 && desiredPatroniConfig.RetryTimeout != effectivePatroniConfig.RetryTimeout {
 		configToSet["retry_timeout"] = desiredPatroniConfig.RetryTimeout
 	}
-	if desiredPatroniConfig.SynchronousMode != effectivePatroniConfig.SynchronousMode {
-		configToSet["synchronous_mode"] = desiredPatroniConfig.SynchronousMode
+	desiredSynchronousMode := desiredPatroniConfig.SynchronousReplicationMode()
+	effectiveSynchronousMode := effectivePatroniConfig.SynchronousReplicationMode()
+	if patroniSynchronousMode(desiredSynchronousMode) != patroniSynchronousMode(effectiveSynchronousMode) {
+		configToSet["synchronous_mode"] = patroniSynchronousMode(desiredSynchronousMode)
 	}
-	if desiredPatroniConfig.SynchronousModeStrict != effectivePatroniConfig.SynchronousModeStrict {
-		configToSet["synchronous_mode_strict"] = desiredPatroniConfig.SynchronousModeStrict
+	if desiredStrict := desiredSynchronousMode == cpov1.SynchronousReplicationStrict; desiredStrict != effectivePatroniConfig.SynchronousModeStrict {
+		configToSet["synchronous_mode_strict"] = desiredStrict
+	}
+	if desiredPatroniConfig.SynchronousNodeCount > 0 && desiredPatroniConfig.SynchronousNodeCount != effectivePatroniConfig.SynchronousNodeCount {
+		configToSet["synchronous_node_count"] = desiredPatroniConfig.SynchronousNodeCount
 	}
 	if desiredPatroniConfig.TTL > 0 && desiredPatroniConfig.TTL != effectivePatroniConfig.TTL {
 		configToSet["ttl"] = desiredPatroniConfig.TTL
 	}
+	if desiredPatroniConfig.Pause != effectivePatroniConfig.Pause {
+		if desiredPatroniConfig.Pause {
+			configToSet["pause"] = true
+		} else {
+			// like patronictl resume, remove the key instead of setting it to false
+			configToSet["pause"] = nil
+		}
+	}
 
 	var desiredFailsafe *bool
 	if desiredPatroniConfig.FailsafeMode != nil {
@@ -963,6 +1138,9 @@ func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectiv
 		effectiveValue, exists := effectivePgParameters[desiredOption]
 		if isBootstrapOnlyParameter(desiredOption) && (!exists || effectiveValue != desiredValue) {
 			parametersToSet[desiredOption] = desiredValue
+			if pgparameters.RequiresRestart(desiredOption, majorVersion) {
+				restartParameters = append(restartParameters, desiredOption)
+			}
 			if util.SliceContains(requirePrimaryRestartWhenDecreased, desiredOption) {
 				effectiveValueNum, errConv := strconv.Atoi(effectiveValue)
 				desiredValueNum, errConv2 := strconv.Atoi(desiredValue)
@@ -988,7 +1166,7 @@ func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectiv
 	}
 
 	if len(configToSet) == 0 {
-		return configPatched, requiresMasterRestart, nil
+		return configPatched, requiresMasterRestart, nil, nil
 	}
 
 	configToSetJson, err := json.Marshal(configToSet)
@@ -1002,11 +1180,17 @@ func (c *Cluster) checkAndSetGlobalPostgreSQLConfiguration(pod *v1.Pod, effectiv
 	c.logger.Debugf("patching Postgres config via Patroni API on pod %s with following options: %s",
 		podName, configToSetJson)
 	if err = c.patroni.SetConfig(pod, configToSet); err != nil {
-		return configPatched, requiresMasterRestart, fmt.Errorf("could not patch postgres parameters within pod %s: %v", podName, err)
+		return configPatched, requiresMasterRestart, nil, fmt.Errorf("could not patch postgres parameters within pod %s: %v", podName, err)
 	}
 	configPatched = true
 
-	return configPatched, requiresMasterRestart, nil
+	if len(restartParameters) == 0 {
+		return configPatched, requiresMasterRestart, nil, nil
+	}
+	sort.Strings(restartParameters)
+	c.logger.Infof("changing %s requires a restart of Postgres", strings.Join(restartParameters, ", "))
+
+	return configPatched, requiresMasterRestart, restartParameters, nil
 }
 
 // syncStandbyClusterConfiguration checks whether standby cluster
@@ -1074,6 +1258,12 @@ func (c *Cluster) syncSecrets() error {
 	currentTime := time.Now()
 
 	for secretUsername, generatedSecret := range generatedSecrets {
+		if c.CredentialStore != nil {
+			if err := c.syncStoredCredentials(secretUsername, generatedSecret, &retentionUsers, currentTime); err != nil {
+				return fmt.Errorf("could not sync credentials of user %s: %w", secretUsername, err)
+			}
+			continue
+		}
 		secret, err := c.KubeClient.Secrets(generatedSecret.Namespace).Create(context.TODO(), generatedSecret, metav1.CreateOptions{})
 		if err == nil {
 			c.Secrets[secret.UID] = secret
@@ -1089,6 +1279,14 @@ func (c *Cluster) syncSecrets() error {
 		}
 	}
 
+	if err := c.removePasswordRotationRequest(generatedSecrets); err != nil {
+		c.logger.Warningf("%v", err)
+	}
+
+	if err := c.syncConsumerSecrets(generatedSecrets); err != nil {
+		c.logger.Warningf("%v", err)
+	}
+
 	// remove rotation users that exceed the retention interval
 	if len(retentionUsers) > 0 {
 		err := c.initDbConn()
@@ -1119,7 +1317,6 @@ func (c *Cluster) updateSecret(
 	var (
 		secret          *v1.Secret
 		err             error
-		updateSecret    bool
 		updateSecretMsg string
 	)
 
@@ -1129,6 +1326,32 @@ func (c *Cluster) updateSecret(
 	}
 	c.Secrets[secret.UID] = secret
 
+	secret, updateSecretMsg = c.reconcileUserSecret(secretUsername, generatedSecret, secret, retentionUsers, currentTime)
+	if updateSecretMsg != "" {
+		c.logger.Debugf("%s", updateSecretMsg)
+		if _, err = c.KubeClient.Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("could not update secret %s: %v", util.NameFromMeta(secret.ObjectMeta), err)
+		}
+		c.Secrets[secret.UID] = secret
+	}
+
+	return nil
+}
+
+// reconcileUserSecret rotates the credentials in the secret when due and updates the role with
+// the credentials of the secret. It returns the secret to keep and a message if it has changed.
+func (c *Cluster) reconcileUserSecret(
+	secretUsername string,
+	generatedSecret *v1.Secret,
+	secret *v1.Secret,
+	retentionUsers *[]string,
+	currentTime time.Time) (*v1.Secret, string) {
+	var (
+		err             error
+		updateSecret    bool
+		updateSecretMsg string
+	)
+
 	// fetch user map to update later
 	var userMap map[string]spec.PgUser
 	var userKey string
@@ -1169,16 +1392,25 @@ func (c *Cluster) updateSecret(
 	secretName := util.NameFromMeta(secret.ObjectMeta)
 
 	// if password rotation is enabled update password and username if rotation interval has been passed
-	// rotation can be enabled globally or via the manifest (excluding the Postgres superuser)
-	rotationEnabledInManifest := secretUsername != constants.SuperuserKeyName &&
-		(util.SliceContains(c.Spec.UsersWithSecretRotation, secretUsername) ||
-			util.SliceContains(c.Spec.UsersWithInPlaceSecretRotation, secretUsername))
+	// rotation can be enabled globally or via the manifest
+	rotationEnabledInManifest := util.SliceContains(c.Spec.UsersWithSecretRotation, secretUsername) ||
+		util.SliceContains(c.Spec.UsersWithInPlaceSecretRotation, secretUsername)
 
 	// globally enabled rotation is only allowed for manifest and bootstrapped roles
 	allowedRoleTypes := []spec.RoleOrigin{spec.RoleOriginManifest, spec.RoleOriginBootstrap}
 	rotationAllowed := !pwdUser.IsDbOwner && util.SliceContains(allowedRoleTypes, pwdUser.Origin) && c.Spec.StandbyCluster == nil
 
-	if (c.OpConfig.EnablePasswordRotation && rotationAllowed) || rotationEnabledInManifest {
+	scheduledRotation := (c.OpConfig.EnablePasswordRotation && rotationAllowed) || rotationEnabledInManifest
+
+	if pwdUser.Origin == spec.RoleOriginSystem {
+		// the superuser and the replication user are rotated in coordination with Patroni
+		if updateSecretMsg = c.requestSystemUserRotation(secret, secretUsername, rotationEnabledInManifest, currentTime); updateSecretMsg != "" {
+			updateSecret = true
+		}
+	} else if c.passwordRotationRequested(secretUsername, secret) {
+		updateSecretMsg = c.rotatePasswordOnDemand(secret, secretUsername, pwdUser.Origin, scheduledRotation, currentTime, retentionUsers)
+		updateSecret = true
+	} else if scheduledRotation {
 		updateSecretMsg, err = c.rotatePasswordInSecret(secret, secretUsername, pwdUser.Origin, currentTime, retentionUsers)
 		if err != nil {
 			c.logger.Warnf("password rotation failed for user %s: %v", secretUsername, err)
@@ -1211,19 +1443,22 @@ func (c *Cluster) updateSecret(
 		if secretUsername != pwdUser.Name {
 			pwdUser.Rotated = true
 			pwdUser.MemberOf = []string{secretUsername}
+			// administered roles are granted to the original role only
+			pwdUser.AdminOf = nil
 		}
 		userMap[userKey] = pwdUser
 	}
 
-	if updateSecret {
-		c.logger.Debugf("%s", updateSecretMsg)
-		if _, err = c.KubeClient.Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
-			return fmt.Errorf("could not update secret %s: %v", secretName, err)
-		}
-		c.Secrets[secret.UID] = secret
+	// keep the connection details in line with the credentials and the cluster
+	if c.updateConnectionSecretData(secret, secretUsername) && !updateSecret {
+		updateSecret = true
+		updateSecretMsg = fmt.Sprintf("updating connection details in secret %s", secretName)
 	}
 
-	return nil
+	if !updateSecret {
+		return secret, ""
+	}
+	return secret, updateSecretMsg
 }
 
 func (c *Cluster) rotatePasswordInSecret(
@@ -1265,42 +1500,8 @@ func (c *Cluster) rotatePasswordInSecret(
 			c.logger.Infof("updating username in secret %s and creating rotation user %s in the database", secretName, rotationUsername)
 			// whenever there is a rotation, check if old rotation users can be deleted
 			*retentionUsers = append(*retentionUsers, secretUsername)
-		} else {
-			// when passwords of system users are rotated in place, pods have to be replaced
-			if roleOrigin == spec.RoleOriginSystem {
-				pods, err := c.listPodsOfType(TYPE_POSTGRESQL)
-				if err != nil {
-					return "", fmt.Errorf("could not list pods of the statefulset: %v", err)
-				}
-				for _, pod := range pods {
-					if err = c.markRollingUpdateFlagForPod(&pod,
-						fmt.Sprintf("replace pod due to password rotation of system user %s", secretUsername)); err != nil {
-						c.logger.Warnf("marking pod for rolling update due to password rotation failed: %v", err)
-					}
-				}
-			}
-
-			// when password of connection pooler is rotated in place, pooler pods have to be replaced
-			if roleOrigin == spec.RoleOriginConnectionPooler {
-				listOptions := metav1.ListOptions{
-					LabelSelector: c.poolerLabelsSet(true, false).String(),
-				}
-				poolerPods, err := c.listPoolerPods(listOptions)
-				if err != nil {
-					return "", fmt.Errorf("could not list pods of the pooler deployment: %v", err)
-				}
-				for _, poolerPod := range poolerPods {
-					if err = c.markRollingUpdateFlagForPod(&poolerPod,
-						fmt.Sprintf("replace pooler pod due to password rotation of pooler user %s", secretUsername)); err != nil {
-						c.logger.Warnf("marking pooler pod for rolling update due to password rotation failed: %v", err)
-					}
-				}
-			}
-
-			// when password of stream user is rotated in place, it should trigger rolling update in FES deployment
-			if roleOrigin == spec.RoleOriginStream {
-				c.logger.Warnf("password in secret of stream user %s changed", constants.EventStreamSourceSlotPrefix+constants.UserRoleNameSuffix)
-			}
+		} else if err = c.replacePodsAfterPasswordRotation(secretUsername, roleOrigin); err != nil {
+			return "", err
 		}
 		secret.Data["password"] = []byte(util.RandomPassword(constants.PasswordLength))
 		secret.Data["nextRotation"] = []byte(nextRotationDateStr)
@@ -1310,6 +1511,33 @@ func (c *Cluster) rotatePasswordInSecret(
 	return updateSecretMsg, nil
 }
 
+// replacePodsAfterPasswordRotation marks the pods which use the credentials of a role rotated in
+// place for replacement
+func (c *Cluster) replacePodsAfterPasswordRotation(secretUsername string, roleOrigin spec.RoleOrigin) error {
+	// when password of connection pooler is rotated in place, pooler pods have to be replaced
+	if roleOrigin == spec.RoleOriginConnectionPooler {
+		listOptions := metav1.ListOptions{
+			LabelSelector: c.poolerLabelsSet(true, false).String(),
+		}
+		poolerPods, err := c.listPoolerPods(listOptions)
+		if err != nil {
+			return fmt.Errorf("could not list pods of the pooler deployment: %v", err)
+		}
+		for _, poolerPod := range poolerPods {
+			if err = c.markRollingUpdateFlagForPod(&poolerPod,
+				fmt.Sprintf("replace pooler pod due to password rotation of pooler user %s", secretUsername)); err != nil {
+				c.logger.Warnf("marking pooler pod for rolling update due to password rotation failed: %v", err)
+			}
+		}
+	}
+
+	// when password of stream user is rotated in place, it should trigger rolling update in FES deployment
+	if roleOrigin == spec.RoleOriginStream {
+		c.logger.Warnf("password in secret of stream user %s changed", constants.EventStreamSourceSlotPrefix+constants.UserRoleNameSuffix)
+	}
+	return nil
+}
+
 func (c *Cluster) syncRoles() (err error) {
 	c.setProcessName("syncing roles")
 
@@ -1334,6 +1562,11 @@ func (c *Cluster) syncRoles() (err error) {
 		}
 	}()
 
+	selfServiceRoleComments, err := c.verifySelfServiceRoles(false)
+	if err != nil {
+		return fmt.Errorf("could not verify self-service roles: %v", err)
+	}
+
 	//Check if monitoring user is added in manifest
 	if _, ok := c.Spec.Users["cpo-exporter"]; ok {
 		c.logger.Error("creating user of name cpo-exporter is not allowed as it is reserved for monitoring")
@@ -1417,6 +1650,15 @@ DBUSERS:
 		return fmt.Errorf("error executing sync statements: %v", err)
 	}
 
+	if err = c.setParametersStatus(c.Status.ParameterDatabases, sortedNames(keySet(c.Spec.GetUserParameters()))); err != nil {
+		return fmt.Errorf("could not update status of role parameters: %v", err)
+	}
+
+	if err = c.markSelfServiceRoles(selfServiceRoleComments); err != nil {
+		return err
+	}
+	c.acceptSelfServiceResources()
+
 	return nil
 }
 
@@ -1453,17 +1695,31 @@ func (c *Cluster) syncDatabases() error {
 		}
 	}
 
-	for databaseName, newOwner := range c.Spec.Databases {
+	for databaseName, database := range c.Spec.Databases {
+		currentOwner, exists := currentDatabases[databaseName]
+		if !exists {
+			createDatabases[databaseName] = database.Owner
+		} else if currentOwner != database.Owner {
+			alterOwnerDatabases[databaseName] = database.Owner
+		}
+	}
+
+	// databases of PostgresDatabase resources only change hands within the namespace of the resource
+	for databaseName, newOwner := range c.selfServiceDbs {
 		currentOwner, exists := currentDatabases[databaseName]
 		if !exists {
 			createDatabases[databaseName] = newOwner
 		} else if currentOwner != newOwner {
+			if !c.isSelfServiceRoleOf(currentOwner, c.pgUsers[newOwner].Namespace) {
+				errors = append(errors, fmt.Sprintf("database %q of a PostgresDatabase is owned by %q, which is not a role of the same namespace", databaseName, currentOwner))
+				continue
+			}
 			alterOwnerDatabases[databaseName] = newOwner
 		}
 	}
 
-	if len(createDatabases)+len(alterOwnerDatabases) == 0 {
-		return nil
+	if len(createDatabases)+len(alterOwnerDatabases)+len(errors) == 0 {
+		return c.syncDatabaseParameters()
 	}
 
 	for databaseName, owner := range createDatabases {
@@ -1476,6 +1732,9 @@ func (c *Cluster) syncDatabases() error {
 			errors = append(errors, err.Error())
 		}
 	}
+	if err = c.syncDatabaseParameters(); err != nil {
+		errors = append(errors, err.Error())
+	}
 
 	if len(createDatabases) > 0 {
 		// trigger creation of pooler objects in new database in syncConnectionPooler
@@ -1507,6 +1766,53 @@ func (c *Cluster) syncDatabases() error {
 	return nil
 }
 
+// syncDatabaseParameters applies the parameters defined for databases and prepared databases and
+// resets the parameters of databases which no longer define any. Other databases keep their settings.
+func (c *Cluster) syncDatabaseParameters() error {
+	desiredParameters := c.Spec.GetDatabaseParameters()
+	desiredDatabases := keySet(desiredParameters)
+	removedDatabases := removedNames(c.Status.ParameterDatabases, desiredDatabases)
+	if len(desiredDatabases)+len(removedDatabases) == 0 {
+		return nil
+	}
+	errors := make([]string, 0)
+
+	currentDatabases, err := c.getDatabases()
+	if err != nil {
+		return fmt.Errorf("could not get current databases: %v", err)
+	}
+	currentParameters, err := c.getDatabaseParameters()
+	if err != nil {
+		return fmt.Errorf("could not get current database parameters: %v", err)
+	}
+
+	for databaseName, parameters := range desiredParameters {
+		if _, exists := currentDatabases[databaseName]; !exists {
+			c.logger.Warningf("skipping parameters of database %q, which does not exist", databaseName)
+			continue
+		}
+		if err := c.executeAlterDatabaseParameters(databaseName, currentParameters[databaseName], parameters); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+	for _, databaseName := range removedDatabases {
+		if _, exists := currentDatabases[databaseName]; !exists {
+			continue
+		}
+		if err := c.executeAlterDatabaseParameters(databaseName, currentParameters[databaseName], nil); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("error(s) while syncing database parameters: %v", strings.Join(errors, `', '`))
+	}
+	if err = c.setParametersStatus(sortedNames(desiredDatabases), c.Status.ParameterRoles); err != nil {
+		return fmt.Errorf("could not update status of database parameters: %v", err)
+	}
+	return nil
+}
+
 func (c *Cluster) syncPreparedDatabases() error {
 	c.setProcessName("syncing prepared databases")
 	errors := make([]string, 0)
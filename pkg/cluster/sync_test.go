@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -26,6 +27,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 )
 
 var patroniLogger = logrus.New().WithField("test", "patroni")
@@ -362,7 +364,7 @@ func TestCheckAndSetGlobalPostgreSQLConfiguration(t *testing.T) {
 			}
 		}
 
-		configPatched, requirePrimaryRestart, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(mockPod, tt.patroni, cluster.Spec.Patroni, tt.pgParams, cluster.Spec.Parameters)
+		configPatched, requirePrimaryRestart, _, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(mockPod, tt.patroni, cluster.Spec.Patroni, tt.pgParams, cluster.Spec.Parameters)
 		assert.NoError(t, err)
 		if configPatched != tt.shouldBePatched {
 			t.Errorf("%s - %s: expected config update did not happen", testName, tt.subtest)
@@ -376,6 +378,36 @@ func TestCheckAndSetGlobalPostgreSQLConfiguration(t *testing.T) {
 		cluster.replicationSlots = make(map[string]interface{})
 	}
 
+	// only parameters taking effect after a restart make the restart required
+	testsRestart := []struct {
+		subtest           string
+		pgParams          map[string]string
+		restartParameters []string
+	}{
+		{
+			subtest: "reloadable parameter differs",
+			pgParams: map[string]string{
+				"log_min_duration_statement": "500", // desired 200
+				"max_connections":            "50",
+			},
+			restartParameters: nil,
+		},
+		{
+			subtest: "postmaster parameter differs",
+			pgParams: map[string]string{
+				"log_min_duration_statement": "200",
+				"max_connections":            "100", // desired 50
+			},
+			restartParameters: []string{"max_connections"},
+		},
+	}
+
+	for _, tt := range testsRestart {
+		_, _, restartParameters, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(mockPod, defaultPatroniParameters, cluster.Spec.Patroni, tt.pgParams, cluster.Spec.Parameters)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.restartParameters, restartParameters, "%s - %s: unexpected restart parameters", testName, tt.subtest)
+	}
+
 	testsFailsafe := []struct {
 		subtest         string
 		operatorVal     *bool
@@ -469,7 +501,7 @@ func TestCheckAndSetGlobalPostgreSQLConfiguration(t *testing.T) {
 		}
 		cluster.Spec.Patroni.FailsafeMode = &tt.desiredVal
 
-		configPatched, requirePrimaryRestart, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(mockPod, patroniConf, cluster.Spec.Patroni, defaultPgParameters, cluster.Spec.Parameters)
+		configPatched, requirePrimaryRestart, _, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(mockPod, patroniConf, cluster.Spec.Patroni, defaultPgParameters, cluster.Spec.Parameters)
 		assert.NoError(t, err)
 		if configPatched != tt.shouldBePatched {
 			t.Errorf("%s - %s: expected update went wrong", testName, tt.subtest)
@@ -480,6 +512,154 @@ func TestCheckAndSetGlobalPostgreSQLConfiguration(t *testing.T) {
 	}
 }
 
+func TestRestartInstance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		subtest           string
+		pendingRestart    bool
+		restartParameters []string
+		expectedRestart   bool
+	}{
+		{
+			subtest:           "restart for parameters requiring it without waiting for Patroni",
+			pendingRestart:    false,
+			restartParameters: []string{"shared_buffers"},
+			expectedRestart:   true,
+		},
+		{
+			subtest:           "restart pending without changed parameters",
+			pendingRestart:    true,
+			restartParameters: nil,
+			expectedRestart:   true,
+		},
+		{
+			subtest:           "no restart when neither required nor pending",
+			pendingRestart:    false,
+			restartParameters: nil,
+			expectedRestart:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(Config{OpConfig: config.Config{
+			PatroniAPICheckInterval: time.Millisecond,
+			PatroniAPICheckTimeout:  50 * time.Millisecond,
+		}}, k8sutil.KubernetesClient{}, cpov1.Postgresql{}, logger, record.NewFakeRecorder(10))
+
+		memberRequests := 0
+		restarted := false
+		mockClient := mocks.NewMockHTTPClient(ctrl)
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			restarted = req.Method == http.MethodPost && req.URL.Path == "/restart"
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte("{}")))}, nil
+		}).AnyTimes()
+		mockClient.EXPECT().Get(gomock.Any()).DoAndReturn(func(url string) (*http.Response, error) {
+			memberRequests++
+			body := fmt.Sprintf(`{"state": "running", "role": "replica", "pending_restart": %t}`, tt.pendingRestart)
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(body)))}, nil
+		}).AnyTimes()
+		cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+		if err := cluster.restartInstance(newMockPod("192.168.100.1"), 0, tt.restartParameters); err != nil {
+			t.Errorf("%s [%s]: unexpected error: %v", t.Name(), tt.subtest, err)
+		}
+		if restarted != tt.expectedRestart {
+			t.Errorf("%s [%s]: expected restart %v, got %v", t.Name(), tt.subtest, tt.expectedRestart, restarted)
+		}
+		if memberRequests != 1 {
+			t.Errorf("%s [%s]: expected member data to be read once, got %d requests", t.Name(), tt.subtest, memberRequests)
+		}
+	}
+}
+
+func TestPodsToRestart(t *testing.T) {
+	pod := func(name string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	pods := []v1.Pod{pod("acid-test-cluster-0"), pod("acid-test-cluster-1"), pod("acid-test-cluster-2")}
+
+	restartPods := podsToRestart(pods, []v1.Pod{pod("acid-test-cluster-1")})
+	names := make([]string, 0, len(restartPods))
+	for _, p := range restartPods {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"acid-test-cluster-0", "acid-test-cluster-2"}, names)
+	assert.Len(t, podsToRestart(pods, nil), 3)
+}
+
+func TestPatroniPause(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		subtest         string
+		effectivePause  bool
+		desiredPause    bool
+		expectedPatch   string
+		expectedStatus  string
+		allowSwitchover bool
+	}{
+		{
+			subtest:         "pause a running cluster",
+			effectivePause:  false,
+			desiredPause:    true,
+			expectedPatch:   "{\"pause\":true}\n",
+			expectedStatus:  cpov1.ClusterStatusPaused,
+			allowSwitchover: false,
+		},
+		{
+			subtest:         "resume a paused cluster",
+			effectivePause:  true,
+			desiredPause:    false,
+			expectedPatch:   "{\"pause\":null}\n",
+			expectedStatus:  cpov1.ClusterStatusRunning,
+			allowSwitchover: true,
+		},
+		{
+			subtest:         "cluster stays paused",
+			effectivePause:  true,
+			desiredPause:    true,
+			expectedPatch:   "",
+			expectedStatus:  cpov1.ClusterStatusPaused,
+			allowSwitchover: false,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := New(Config{}, k8sutil.KubernetesClient{}, cpov1.Postgresql{}, logger, eventRecorder)
+		cluster.Spec.Patroni.Pause = tt.desiredPause
+
+		patch := ""
+		mockClient := mocks.NewMockHTTPClient(ctrl)
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			patch = string(body)
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte("{}")))}, nil
+		}).AnyTimes()
+		cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+		effective := cpov1.Patroni{Pause: tt.effectivePause}
+		if _, _, _, err := cluster.checkAndSetGlobalPostgreSQLConfiguration(newMockPod("192.168.100.1"), effective, cluster.Spec.Patroni, map[string]string{}, map[string]string{}); err != nil {
+			t.Errorf("%s [%s]: unexpected error: %v", t.Name(), tt.subtest, err)
+		}
+		if patch != tt.expectedPatch {
+			t.Errorf("%s [%s]: expected config patch %q, got %q", t.Name(), tt.subtest, tt.expectedPatch, patch)
+		}
+		if status := cluster.runningStatus(); status != tt.expectedStatus {
+			t.Errorf("%s [%s]: expected status %q, got %q", t.Name(), tt.subtest, tt.expectedStatus, status)
+		}
+
+		if !tt.allowSwitchover {
+			err := cluster.Switchover(newMockPod("192.168.100.1"), spec.NamespacedName{Namespace: "default", Name: "acid-test-cluster-1"})
+			if err == nil {
+				t.Errorf("%s [%s]: switchover must be refused while paused", t.Name(), tt.subtest)
+			}
+		}
+	}
+}
+
 func TestUpdateSecret(t *testing.T) {
 	testName := "test syncing secrets"
 	client, _ := newFakeK8sSyncSecretsClient()
@@ -498,8 +678,8 @@ func TestUpdateSecret(t *testing.T) {
 			Namespace: namespace,
 		},
 		Spec: cpov1.PostgresSpec{
-			Databases:                      map[string]string{dbname: dbowner},
-			Users:                          map[string]cpov1.UserFlags{"foo": {}, dbowner: {}},
+			Databases:                      map[string]cpov1.DatabaseDefinition{dbname: {Owner: dbowner}},
+			Users:                          map[string]cpov1.UserDefinition{"foo": {}, dbowner: {}},
 			UsersWithInPlaceSecretRotation: []string{dbowner},
 			Streams: []cpov1.Stream{
 				{
@@ -0,0 +1,379 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+// pendingPasswordKey holds the new password of a system user in its secret until the rotation
+// has been confirmed. The password key keeps the current password, which is used by the pods.
+const pendingPasswordKey = "pendingPassword"
+
+// updatePatroniAuthenticationScript sets the password of a system user in the Patroni configuration
+// of a member. The password is read from stdin, so it does not show up in the process list. With a
+// third argument Patroni is signalled to reload its configuration, which is used for members of
+// other clusters, as their REST API expects their own credentials.
+const updatePatroniAuthenticationScript = "import os, signal, sys, yaml\n" + patroniPIDFunction + `
+path, section, password = sys.argv[1], sys.argv[2], sys.stdin.read()
+with open(path) as f:
+    config = yaml.safe_load(f)
+authentication = config.setdefault('postgresql', {}).setdefault('authentication', {})
+authentication.setdefault(section, {})['password'] = password
+with open(path, 'w') as f:
+    yaml.safe_dump(config, f, default_flow_style=False)
+if len(sys.argv) > 3:
+    os.kill(patroni_pid(path), signal.SIGHUP)
+`
+
+// requestSystemUserRotation schedules the rotation of the superuser or the replication user by
+// storing a new password next to the current one. The rotation itself needs database access and
+// is completed by syncSystemUserRotation. It returns the message for the update of the secret.
+func (c *Cluster) requestSystemUserRotation(
+	secret *v1.Secret,
+	secretUsername string,
+	scheduledRotation bool,
+	currentTime time.Time) string {
+	var updateSecretMsg string
+	secretName := util.NameFromMeta(secret.ObjectMeta)
+
+	requested := c.passwordRotationRequested(secretUsername, secret)
+	if _, exists := secret.Annotations[constants.RotatePasswordAnnotation]; exists {
+		delete(secret.Annotations, constants.RotatePasswordAnnotation)
+		updateSecretMsg = fmt.Sprintf("removing password rotation request from secret %s", secretName)
+	}
+	if c.Spec.StandbyCluster != nil {
+		if requested || scheduledRotation {
+			c.logger.Warningf("could not rotate password of system user %s: roles of a standby cluster are managed by the source cluster", secretUsername)
+		}
+		return updateSecretMsg
+	}
+	// a rotation in progress has to be completed first
+	if _, pending := secret.Data[pendingPasswordKey]; pending {
+		return updateSecretMsg
+	}
+
+	if scheduledRotation {
+		_, nextRotationDateStr := c.getNextRotationDate(currentTime)
+		nextRotationDate, err := time.ParseInLocation(time.RFC3339, string(secret.Data["nextRotation"]), currentTime.UTC().Location())
+		if err != nil {
+			secret.Data["nextRotation"] = []byte(nextRotationDateStr)
+			updateSecretMsg = fmt.Sprintf("rotation date not found in secret %s. Setting it to %s", secretName, nextRotationDateStr)
+		} else if currentTime.After(nextRotationDate) {
+			requested = true
+		}
+		if requested {
+			secret.Data["nextRotation"] = []byte(nextRotationDateStr)
+		}
+	}
+	if !requested {
+		return updateSecretMsg
+	}
+
+	secret.Data[pendingPasswordKey] = []byte(util.RandomPassword(constants.PasswordLength))
+	c.logger.Infof("new password of system user %s is pending until the rotation has been confirmed", secretUsername)
+	return fmt.Sprintf("updating secret %s with a pending password for system user %s", secretName, secretUsername)
+}
+
+// syncSystemUserRotation completes pending password rotations of the superuser and the replication user
+func (c *Cluster) syncSystemUserRotation() (err error) {
+	c.setProcessName("rotating passwords of system users")
+
+	pending := make(map[string]*v1.Secret)
+	for _, userKey := range []string{constants.SuperuserKeyName, constants.ReplicationUserKeyName} {
+		secret, err := c.getSystemUserSecret(c.systemUsers[userKey].Name)
+		if err != nil {
+			return err
+		}
+		if secret != nil {
+			if _, exists := secret.Data[pendingPasswordKey]; exists {
+				pending[userKey] = secret
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err = c.initDbConn(); err != nil {
+		return fmt.Errorf("could not init db connection: %v", err)
+	}
+	defer func() {
+		if err2 := c.closeDbConn(); err2 != nil {
+			if err == nil {
+				err = fmt.Errorf("could not close database connection: %v", err2)
+			} else {
+				err = fmt.Errorf("could not close database connection: %v (prior error: %v)", err2, err)
+			}
+		}
+	}()
+
+	standbyClusters, err := c.listStandbyClusters()
+	if err != nil {
+		c.logger.Warningf("could not find standby clusters of the cluster: %v", err)
+	}
+
+	errors := make([]string, 0)
+	for _, userKey := range []string{constants.SuperuserKeyName, constants.ReplicationUserKeyName} {
+		if secret, exists := pending[userKey]; exists {
+			if err := c.rotateSystemUser(userKey, secret, standbyClusters); err != nil {
+				errors = append(errors, err.Error())
+			}
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, `', '`))
+	}
+	return nil
+}
+
+// getSystemUserSecret returns the secret of a system user with the data of the credential store
+func (c *Cluster) getSystemUserSecret(username string) (*v1.Secret, error) {
+	secret, err := c.KubeClient.Secrets(c.Namespace).Get(context.TODO(), c.credentialSecretName(username), metav1.GetOptions{})
+	if k8sutil.ResourceNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not get secret of system user %s: %v", username, err)
+	}
+	if c.CredentialStore != nil {
+		data, err := c.CredentialStore.Get(credentialStorePath(secret))
+		if err != nil {
+			return nil, fmt.Errorf("could not get credentials of system user %s from the credential store: %v", username, err)
+		}
+		secret.Data = credentialsToSecretData(data)
+	}
+	return secret, nil
+}
+
+// rotateSystemUser sets the pending password of a system user in the database and in the Patroni
+// configuration of all members, including those of standby clusters streaming from this cluster.
+// Only when a new connection with the pending password succeeds, the password replaces the current
+// one in the secrets. Otherwise all changes are rolled back and the pending password is discarded.
+func (c *Cluster) rotateSystemUser(userKey string, secret *v1.Secret, standbyClusters []cpov1.Postgresql) error {
+	user := c.systemUsers[userKey]
+	currentPassword := string(secret.Data["password"])
+	newPassword := string(secret.Data[pendingPasswordKey])
+	c.logger.Infof("rotating password of system user %s", user.Name)
+
+	err := c.applySystemUserPassword(userKey, newPassword, standbyClusters)
+	if err == nil {
+		err = c.checkSystemUserConnection(userKey, newPassword)
+	}
+	if err != nil {
+		c.logger.Warningf("rotation of system user %s failed, restoring the current password: %v", user.Name, err)
+		c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeWarning, "PasswordRotation",
+			"Rotation of system user %s failed: %v", user.Name, err)
+		if rollbackErr := c.applySystemUserPassword(userKey, currentPassword, standbyClusters); rollbackErr != nil {
+			c.logger.Errorf("could not restore the password of system user %s: %v", user.Name, rollbackErr)
+		}
+		delete(secret.Data, pendingPasswordKey)
+		if updateErr := c.updateSystemUserSecret(secret); updateErr != nil {
+			c.logger.Errorf("could not discard the pending password of system user %s: %v", user.Name, updateErr)
+		}
+		return fmt.Errorf("could not rotate password of system user %s: %v", user.Name, err)
+	}
+
+	secret.Data["password"] = []byte(newPassword)
+	delete(secret.Data, pendingPasswordKey)
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[constants.PasswordRotatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if err = c.updateSystemUserSecret(secret); err != nil {
+		return fmt.Errorf("could not update secret of system user %s after rotation: %v", user.Name, err)
+	}
+	for _, standbyCluster := range standbyClusters {
+		if err = c.updateStandbySystemUserSecret(&standbyCluster, user.Name, newPassword); err != nil {
+			c.logger.Warningf("could not update secret of system user %s of standby cluster %s: %v",
+				user.Name, util.NameFromMeta(standbyCluster.ObjectMeta), err)
+		}
+	}
+
+	user.Password = newPassword
+	c.systemUsers[userKey] = user
+	c.logger.Infof("password of system user %s has been rotated", user.Name)
+	c.eventRecorder.Eventf(c.GetReference(), v1.EventTypeNormal, "PasswordRotation",
+		"Password of system user %s has been rotated", user.Name)
+	return nil
+}
+
+// applySystemUserPassword sets the password of the role and in the Patroni configuration of the
+// members of this cluster and its standby clusters
+func (c *Cluster) applySystemUserPassword(userKey, password string, standbyClusters []cpov1.Postgresql) error {
+	user := c.systemUsers[userKey]
+	alterRequest := spec.PgSyncUserRequest{
+		Kind: spec.PGsyncUserAlter,
+		User: spec.PgUser{Name: user.Name, Password: password},
+	}
+	if err := c.userSyncStrategy.ExecuteSyncRequests([]spec.PgSyncUserRequest{alterRequest}, c.pgDb); err != nil {
+		return fmt.Errorf("could not set password of role: %v", err)
+	}
+
+	pods, err := c.listPodsOfType(TYPE_POSTGRESQL)
+	if err != nil {
+		return err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		if err := c.setPatroniAuthentication(pod, userKey, password, false); err != nil {
+			return err
+		}
+		if err := c.patroni.Reload(pod); err != nil {
+			return fmt.Errorf("could not reload Patroni configuration of pod %s: %v", pod.Name, err)
+		}
+	}
+
+	for _, standbyCluster := range standbyClusters {
+		pods, err := c.listStandbyClusterPods(&standbyCluster)
+		if err != nil {
+			return err
+		}
+		for i := range pods {
+			if pods[i].Status.Phase != v1.PodRunning {
+				continue
+			}
+			if err := c.setPatroniAuthentication(&pods[i], userKey, password, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) setPatroniAuthentication(pod *v1.Pod, userKey, password string, reload bool) error {
+	podName := util.NameFromMeta(pod.ObjectMeta)
+	command := []string{"python3", "-c", updatePatroniAuthenticationScript, patroniConfigFile, userKey}
+	if reload {
+		command = append(command, "reload")
+	}
+	if _, err := c.ExecCommandWithStdin(&podName, strings.NewReader(password), command...); err != nil {
+		return fmt.Errorf("could not update Patroni configuration of pod %s: %v", podName, err)
+	}
+	return nil
+}
+
+// checkSystemUserConnection opens a new connection with the password. The replication user
+// connects with the replication protocol, as used by the replicas.
+func (c *Cluster) checkSystemUserConnection(userKey, password string) error {
+	user := c.systemUsers[userKey]
+	connstring := c.pgConnectionStringForUser("postgres", user.Name, password)
+	if userKey == constants.ReplicationUserKeyName {
+		connstring += " replication='database'"
+	}
+
+	conn, err := sql.Open("postgres", connstring)
+	if err != nil {
+		return fmt.Errorf("could not connect with the new password: %v", err)
+	}
+	defer conn.Close()
+	if err = conn.Ping(); err != nil {
+		return fmt.Errorf("could not connect with the new password: %v", err)
+	}
+	return nil
+}
+
+// updateSystemUserSecret writes the secret of a system user to the credential store and the cluster
+func (c *Cluster) updateSystemUserSecret(secret *v1.Secret) error {
+	if c.CredentialStore != nil {
+		if err := c.CredentialStore.Put(credentialStorePath(secret), secretDataToCredentials(secret.Data)); err != nil {
+			return fmt.Errorf("could not store credentials: %v", err)
+		}
+	}
+	updatedSecret, err := c.KubeClient.Secrets(secret.Namespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("could not update secret %s: %v", util.NameFromMeta(secret.ObjectMeta), err)
+	}
+	c.Secrets[updatedSecret.UID] = updatedSecret
+	return nil
+}
+
+// updateStandbySystemUserSecret sets the password in the secret of a standby cluster, so that its
+// pods use the new password after a restart
+func (c *Cluster) updateStandbySystemUserSecret(standbyCluster *cpov1.Postgresql, username, password string) error {
+	secrets := c.KubeClient.Secrets(standbyCluster.Namespace)
+	secret, err := secrets.Get(context.TODO(), c.credentialSecretNameForCluster(username, standbyCluster.Name), metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	secret.Data["password"] = []byte(password)
+	if c.CredentialStore != nil {
+		if err = c.CredentialStore.Put(credentialStorePath(secret), secretDataToCredentials(secret.Data)); err != nil {
+			return fmt.Errorf("could not store credentials: %v", err)
+		}
+	}
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
+
+// listStandbyClusters returns the standby clusters streaming from the services of this cluster
+func (c *Cluster) listStandbyClusters() ([]cpov1.Postgresql, error) {
+	list, err := c.KubeClient.Postgresqls(c.OpConfig.WatchedNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not list postgresql resources: %v", err)
+	}
+	standbyClusters := make([]cpov1.Postgresql, 0)
+	for _, pg := range list.Items {
+		if pg.Namespace == c.Namespace && pg.Name == c.Name {
+			continue
+		}
+		if pg.Spec.StandbyCluster != nil && c.isStandbyHost(pg.Spec.StandbyCluster.StandbyHost, pg.Namespace) {
+			standbyClusters = append(standbyClusters, pg)
+		}
+	}
+	return standbyClusters, nil
+}
+
+// isStandbyHost reports whether the host of a standby cluster in the namespace refers to a service
+// of this cluster
+func (c *Cluster) isStandbyHost(host, namespace string) bool {
+	if host == "" {
+		return false
+	}
+	for _, role := range []PostgresRole{Master, Replica} {
+		serviceName := c.serviceName(role)
+		names := []string{
+			fmt.Sprintf("%s.%s", serviceName, c.Namespace),
+			fmt.Sprintf("%s.%s.svc", serviceName, c.Namespace),
+			fmt.Sprintf("%s.%s.svc.%s", serviceName, c.Namespace, c.OpConfig.ClusterDomain),
+		}
+		if namespace == c.Namespace {
+			names = append(names, serviceName)
+		}
+		if util.SliceContains(names, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cluster) listStandbyClusterPods(standbyCluster *cpov1.Postgresql) ([]v1.Pod, error) {
+	selector := make(map[string]string)
+	for key, value := range c.OpConfig.ClusterLabels {
+		selector[key] = value
+	}
+	selector[c.OpConfig.ClusterNameLabel] = standbyCluster.Name
+	selector["member.cpo.opensource.cybertec.at/type"] = string(TYPE_POSTGRESQL)
+
+	pods, err := c.KubeClient.Pods(standbyCluster.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.Set(selector).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not list pods of standby cluster %s: %v", util.NameFromMeta(standbyCluster.ObjectMeta), err)
+	}
+	return pods.Items, nil
+}
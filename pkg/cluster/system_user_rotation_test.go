@@ -0,0 +1,142 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+func TestRequestSystemUserRotation(t *testing.T) {
+	currentTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pastRotation := currentTime.AddDate(0, 0, -1).Format(time.RFC3339)
+	futureRotation := currentTime.AddDate(0, 0, 1).Format(time.RFC3339)
+	nextRotation := currentTime.AddDate(0, 0, 90).Format(time.RFC3339)
+
+	tests := []struct {
+		subTest              string
+		data                 map[string]string
+		annotations          map[string]string
+		scheduled            bool
+		standby              bool
+		expectedPending      bool
+		expectedNextRotation string
+		expectedUpdate       bool
+	}{
+		{
+			subTest:              "no rotation",
+			data:                 map[string]string{"password": "current"},
+			expectedNextRotation: "",
+		},
+		{
+			subTest:              "requested on the secret",
+			data:                 map[string]string{"password": "current"},
+			annotations:          map[string]string{constants.RotatePasswordAnnotation: "true"},
+			expectedPending:      true,
+			expectedNextRotation: "",
+			expectedUpdate:       true,
+		},
+		{
+			subTest:              "schedule initialized",
+			data:                 map[string]string{"password": "current"},
+			scheduled:            true,
+			expectedNextRotation: nextRotation,
+			expectedUpdate:       true,
+		},
+		{
+			subTest:              "schedule not due",
+			data:                 map[string]string{"password": "current", "nextRotation": futureRotation},
+			scheduled:            true,
+			expectedNextRotation: futureRotation,
+		},
+		{
+			subTest:              "schedule due",
+			data:                 map[string]string{"password": "current", "nextRotation": pastRotation},
+			scheduled:            true,
+			expectedPending:      true,
+			expectedNextRotation: nextRotation,
+			expectedUpdate:       true,
+		},
+		{
+			subTest:              "requested before schedule is due",
+			data:                 map[string]string{"password": "current", "nextRotation": futureRotation},
+			annotations:          map[string]string{constants.RotatePasswordAnnotation: "true"},
+			scheduled:            true,
+			expectedPending:      true,
+			expectedNextRotation: nextRotation,
+			expectedUpdate:       true,
+		},
+		{
+			subTest:              "rotation in progress",
+			data:                 map[string]string{"password": "current", pendingPasswordKey: "pending", "nextRotation": pastRotation},
+			scheduled:            true,
+			expectedPending:      true,
+			expectedNextRotation: pastRotation,
+		},
+		{
+			subTest:              "standby cluster",
+			data:                 map[string]string{"password": "current"},
+			annotations:          map[string]string{constants.RotatePasswordAnnotation: "true"},
+			standby:              true,
+			expectedNextRotation: "",
+			expectedUpdate:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		cluster := newTestCluster(t, cpov1.PostgresSpec{}, connectionSecretsTestConfig)
+		cluster.OpConfig.PasswordRotationInterval = 90
+		if tt.standby {
+			cluster.Spec.StandbyCluster = &cpov1.StandbyDescription{StandbyHost: "acid-source-cluster"}
+		}
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres.acid-test-cluster.credentials", Namespace: "default", Annotations: tt.annotations},
+			Data:       credentialsToSecretData(tt.data),
+		}
+
+		updateSecretMsg := cluster.requestSystemUserRotation(secret, superUserName, tt.scheduled, currentTime)
+		if (updateSecretMsg != "") != tt.expectedUpdate {
+			t.Errorf("%s [%s]: expected secret update %v, got message %q", t.Name(), tt.subTest, tt.expectedUpdate, updateSecretMsg)
+		}
+		if _, pending := secret.Data[pendingPasswordKey]; pending != tt.expectedPending {
+			t.Errorf("%s [%s]: expected pending password %v, got %v", t.Name(), tt.subTest, tt.expectedPending, pending)
+		}
+		if string(secret.Data["password"]) != "current" {
+			t.Errorf("%s [%s]: expected current password to be kept, got %q", t.Name(), tt.subTest, secret.Data["password"])
+		}
+		if nextRotation := string(secret.Data["nextRotation"]); nextRotation != tt.expectedNextRotation {
+			t.Errorf("%s [%s]: expected next rotation %q, got %q", t.Name(), tt.subTest, tt.expectedNextRotation, nextRotation)
+		}
+		if _, exists := secret.Annotations[constants.RotatePasswordAnnotation]; exists {
+			t.Errorf("%s [%s]: expected rotation request to be removed from the secret", t.Name(), tt.subTest)
+		}
+	}
+}
+
+func TestIsStandbyHost(t *testing.T) {
+	cluster := newTestCluster(t, cpov1.PostgresSpec{}, connectionSecretsTestConfig)
+
+	tests := []struct {
+		host      string
+		namespace string
+		expected  bool
+	}{
+		{"acid-test-cluster", "default", true},
+		{"acid-test-cluster", "other", false},
+		{"acid-test-cluster.default", "other", true},
+		{"acid-test-cluster-repl.default.svc", "default", true},
+		{"acid-test-cluster.default.svc.cluster.local", "other", true},
+		{"acid-test-cluster.other.svc", "other", false},
+		{"acid-other-cluster.default.svc", "default", false},
+		{"", "default", false},
+	}
+	for _, tt := range tests {
+		if isStandbyHost := cluster.isStandbyHost(tt.host, tt.namespace); isStandbyHost != tt.expected {
+			t.Errorf("%s [%s in %s]: expected %v, got %v", t.Name(), tt.host, tt.namespace, tt.expected, isStandbyHost)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"fmt"
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+// maxTimelineHistory limits the number of timelines kept in the status
+const maxTimelineHistory = 20
+
+// syncTimelineHistory records the timeline history reported by Patroni in the status and emits an
+// event for every timeline that has not been seen before. The history found on the first observation
+// of the cluster is only recorded, as its timelines ended before.
+func (c *Cluster) syncTimelineHistory() error {
+	masterPods, err := c.getRolePods(Master)
+	if err != nil {
+		return err
+	}
+	if len(masterPods) == 0 {
+		c.logger.Debug("no primary found, skipping timeline history")
+		return nil
+	}
+
+	history, err := c.patroni.GetHistory(&masterPods[0])
+	if err != nil {
+		return fmt.Errorf("could not get Patroni history: %v", err)
+	}
+	entries := timelineHistoryEntries(history)
+	if reflect.DeepEqual(entries, c.Status.TimelineHistory) {
+		return nil
+	}
+
+	firstObservation := len(c.Status.TimelineHistory) == 0
+	lastTimeline := 0
+	for _, entry := range c.Status.TimelineHistory {
+		if entry.Timeline > lastTimeline {
+			lastTimeline = entry.Timeline
+		}
+	}
+	for _, entry := range entries {
+		if firstObservation || entry.Timeline <= lastTimeline {
+			continue
+		}
+		message := fmt.Sprintf("Timeline %d ended at %s", entry.Timeline, entry.SwitchLSN)
+		if entry.NewLeader != "" {
+			message += fmt.Sprintf(", new leader %q", entry.NewLeader)
+		}
+		if entry.Reason != "" {
+			message += ": " + entry.Reason
+		}
+		c.logger.Info(message)
+		c.eventRecorder.Event(c.GetReference(), v1.EventTypeNormal, "Timeline", message)
+	}
+
+	if _, err := c.KubeClient.SetCRDTimelineHistoryStatus(c.clusterName(), entries); err != nil {
+		return err
+	}
+	c.Status.TimelineHistory = entries
+	return nil
+}
+
+func timelineHistoryEntries(history []patroni.TimelineHistory) []cpov1.TimelineHistoryEntry {
+	if len(history) > maxTimelineHistory {
+		history = history[len(history)-maxTimelineHistory:]
+	}
+
+	var entries []cpov1.TimelineHistoryEntry
+	for _, h := range history {
+		entries = append(entries, cpov1.TimelineHistoryEntry{
+			Timeline:  h.Timeline,
+			SwitchLSN: fmt.Sprintf("%X/%X", h.SwitchLSN>>32, h.SwitchLSN&0xFFFFFFFF),
+			Reason:    h.Reason,
+			Timestamp: h.Timestamp,
+			NewLeader: h.NewLeader,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/mocks"
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	fakecpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/patroni"
+)
+
+func TestSyncTimelineHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	clientSet := fake.NewSimpleClientset()
+	acidClientSet := fakecpov1.NewSimpleClientset()
+	client := k8sutil.KubernetesClient{
+		PodsGetter:        clientSet.CoreV1(),
+		PostgresqlsGetter: acidClientSet.CpoV1(),
+	}
+	pg := cpov1.Postgresql{ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"}}
+	if _, err := acidClientSet.CpoV1().Postgresqls("default").Create(context.TODO(), &pg, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("%s: could not create postgresql: %v", t.Name(), err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	cluster := New(
+		Config{
+			OpConfig: config.Config{
+				Resources: config.Resources{
+					ClusterLabels:    map[string]string{"application": "cpo"},
+					ClusterNameLabel: "cluster.cpo.opensource.cybertec.at/name",
+					PodRoleLabel:     "spilo-role",
+				},
+			},
+		}, client, pg, logger, recorder)
+
+	podLabels := cluster.labelsSet(false)
+	podLabels["spilo-role"] = "master"
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster-0", Namespace: "default", Labels: podLabels},
+		Status:     v1.PodStatus{PodIP: "192.168.100.1"},
+	}
+	client.Pods("default").Create(context.TODO(), &pod, metav1.CreateOptions{})
+
+	historyJson := `[[1, 25623960, "no recovery target specified", "2024-09-23T16:57:57+02:00"], [2, 4294967296, "failover", "2024-09-24T03:12:00+02:00", "acid-test-cluster-1"]]`
+	mockClient := mocks.NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any()).DoAndReturn(func(url string) (*http.Response, error) {
+		if url != "http://192.168.100.1:8008/history" {
+			t.Errorf("%s: unexpected request to %s", t.Name(), url)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(historyJson)))}, nil
+	}).AnyTimes()
+	cluster.patroni = patroni.New(patroniLogger, mockClient)
+
+	// the history found on the first observation is recorded without events
+	if err := cluster.syncTimelineHistory(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("%s: expected no events for the initial history, got %d", t.Name(), len(recorder.Events))
+	}
+
+	result, err := client.Postgresqls("default").Get(context.TODO(), "acid-test-cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("%s: could not get postgresql: %v", t.Name(), err)
+	}
+	expected := cpov1.TimelineHistoryEntry{Timeline: 1, SwitchLSN: "0/186FD98", Reason: "no recovery target specified", Timestamp: "2024-09-23T16:57:57+02:00"}
+	if len(result.Status.TimelineHistory) != 2 || result.Status.TimelineHistory[0] != expected {
+		t.Errorf("%s: expected timeline history %v, got %v", t.Name(), expected, result.Status.TimelineHistory)
+	}
+
+	// a later failover only reports the new timeline
+	historyJson = `[[1, 25623960, "no recovery target specified", "2024-09-23T16:57:57+02:00"], [2, 4294967296, "failover", "2024-09-24T03:12:00+02:00", "acid-test-cluster-1"], [3, 8589934592, "switchover", "2024-09-25T10:00:00+02:00", "acid-test-cluster-0"]]`
+	if err := cluster.syncTimelineHistory(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("%s: expected one event for the new timeline, got %d", t.Name(), len(recorder.Events))
+	}
+	if event := <-recorder.Events; event != `Normal Timeline Timeline 3 ended at 2/0, new leader "acid-test-cluster-0": switchover` {
+		t.Errorf("%s: unexpected event %q", t.Name(), event)
+	}
+
+	// nothing changes without a new timeline
+	if err := cluster.syncTimelineHistory(); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("%s: unexpected event for a known timeline", t.Name())
+	}
+}
@@ -0,0 +1,126 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+// tuningProfile holds the workload specific factors of a tuning profile
+type tuningProfile struct {
+	maxConnections int64
+	// divisor of the memory for maintenance_work_mem
+	maintenanceWorkMemDivisor int64
+	// number of work_mem allocations expected per connection
+	workMemPerConnection int64
+	// upper limit of max_parallel_workers_per_gather, 0 means half of the CPUs
+	maxParallelWorkersPerGather int64
+}
+
+var tuningProfiles = map[string]tuningProfile{
+	cpov1.TuningProfileOLTP:  {maxConnections: 200, maintenanceWorkMemDivisor: 16, workMemPerConnection: 3, maxParallelWorkersPerGather: 2},
+	cpov1.TuningProfileMixed: {maxConnections: 100, maintenanceWorkMemDivisor: 16, workMemPerConnection: 2, maxParallelWorkersPerGather: 4},
+	cpov1.TuningProfileOLAP:  {maxConnections: 40, maintenanceWorkMemDivisor: 8, workMemPerConnection: 1},
+}
+
+// maxMaintenanceWorkMem caps maintenance_work_mem, more memory hardly speeds up maintenance
+const maxMaintenanceWorkMem = 2 << 30
+
+// desiredPostgresParameters returns the parameters of the manifest, complemented by the ones derived
+// from the resources of the Postgres container when a tuning profile is selected.
+func (c *Cluster) desiredPostgresParameters(spec *cpov1.PostgresSpec) (map[string]string, error) {
+	parameters := make(map[string]string)
+	if spec.PostgresqlParam.Tuning != nil {
+		resources, err := c.generateResourceRequirements(spec.Resources, makeDefaultResources(&c.OpConfig), constants.PostgresContainerName)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate resource requirements: %v", err)
+		}
+		for k, v := range tunedParameters(spec.PostgresqlParam.Tuning, resources, spec.PostgresqlParam.Parameters) {
+			parameters[k] = v
+		}
+	}
+	// parameters set explicitly take precedence
+	for k, v := range spec.PostgresqlParam.Parameters {
+		parameters[k] = v
+	}
+	return parameters, nil
+}
+
+// tunedParameters derives the memory and parallelism settings from the limits of the container, or
+// its requests if no limits are set, and the planner costs from the storage type.
+func tunedParameters(tuning *cpov1.PostgresTuning, resources *v1.ResourceRequirements, explicit map[string]string) map[string]string {
+	profile, ok := tuningProfiles[tuning.Profile]
+	if !ok {
+		return nil
+	}
+	parameters := make(map[string]string)
+
+	if tuning.StorageType == cpov1.StorageTypeHDD {
+		parameters["random_page_cost"] = "4"
+		parameters["effective_io_concurrency"] = "2"
+	} else {
+		parameters["random_page_cost"] = "1.1"
+		parameters["effective_io_concurrency"] = "200"
+	}
+
+	maxConnections := profile.maxConnections
+	if value, err := strconv.ParseInt(explicit["max_connections"], 10, 64); err == nil && value > 0 {
+		maxConnections = value
+	} else {
+		parameters["max_connections"] = strconv.FormatInt(maxConnections, 10)
+	}
+
+	var cpus int64
+	if cpu, ok := resourceValue(resources, v1.ResourceCPU); ok {
+		// round up fractional CPUs, a parallel worker needs a whole one to be useful
+		cpus = (cpu.MilliValue() + 999) / 1000
+	}
+	if cpus < 1 {
+		cpus = 1
+	}
+	parallelWorkersPerGather := cpus / 2
+	if profile.maxParallelWorkersPerGather > 0 && parallelWorkersPerGather > profile.maxParallelWorkersPerGather {
+		parallelWorkersPerGather = profile.maxParallelWorkersPerGather
+	}
+	parameters["max_worker_processes"] = strconv.FormatInt(max(8, cpus), 10)
+	parameters["max_parallel_workers"] = strconv.FormatInt(cpus, 10)
+	parameters["max_parallel_workers_per_gather"] = strconv.FormatInt(parallelWorkersPerGather, 10)
+	parameters["max_parallel_maintenance_workers"] = strconv.FormatInt(min(4, cpus/2), 10)
+
+	memory, ok := resourceValue(resources, v1.ResourceMemory)
+	if !ok {
+		return parameters
+	}
+	memoryBytes := memory.Value()
+	sharedBuffers := memoryBytes / 4
+	parameters["shared_buffers"] = formatMemory(sharedBuffers)
+	parameters["effective_cache_size"] = formatMemory(memoryBytes * 3 / 4)
+	parameters["maintenance_work_mem"] = formatMemory(min(memoryBytes/profile.maintenanceWorkMemDivisor, maxMaintenanceWorkMem))
+	workMem := (memoryBytes - sharedBuffers) / (maxConnections * profile.workMemPerConnection) / max(1, parallelWorkersPerGather)
+	parameters["work_mem"] = formatMemory(max(workMem, 64<<10))
+
+	return parameters
+}
+
+func resourceValue(resources *v1.ResourceRequirements, name v1.ResourceName) (*resource.Quantity, bool) {
+	if q, exists := resources.Limits[name]; exists && !q.IsZero() {
+		return &q, true
+	}
+	if q, exists := resources.Requests[name]; exists && !q.IsZero() {
+		return &q, true
+	}
+	return nil, false
+}
+
+// formatMemory uses megabytes where possible and rounds down to kilobytes otherwise
+func formatMemory(bytes int64) string {
+	if bytes >= 1<<20 && bytes%(1<<20) == 0 {
+		return fmt.Sprintf("%dMB", bytes>>20)
+	}
+	return fmt.Sprintf("%dkB", bytes>>10)
+}
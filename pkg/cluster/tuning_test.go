@@ -0,0 +1,119 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
+)
+
+func TestTunedParameters(t *testing.T) {
+	resources := func(cpu, memory string) *v1.ResourceRequirements {
+		return &v1.ResourceRequirements{Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+
+	tests := []struct {
+		subTest   string
+		tuning    cpov1.PostgresTuning
+		resources *v1.ResourceRequirements
+		explicit  map[string]string
+		expected  map[string]string
+	}{
+		{
+			subTest:   "oltp on ssd",
+			tuning:    cpov1.PostgresTuning{Profile: cpov1.TuningProfileOLTP},
+			resources: resources("4", "8Gi"),
+			expected: map[string]string{
+				"random_page_cost":                 "1.1",
+				"effective_io_concurrency":         "200",
+				"max_connections":                  "200",
+				"max_worker_processes":             "8",
+				"max_parallel_workers":             "4",
+				"max_parallel_workers_per_gather":  "2",
+				"max_parallel_maintenance_workers": "2",
+				"shared_buffers":                   "2048MB",
+				"effective_cache_size":             "6144MB",
+				"maintenance_work_mem":             "512MB",
+				"work_mem":                         "5242kB",
+			},
+		},
+		{
+			subTest:   "olap on hdd with explicit max_connections",
+			tuning:    cpov1.PostgresTuning{Profile: cpov1.TuningProfileOLAP, StorageType: cpov1.StorageTypeHDD},
+			resources: resources("16", "64Gi"),
+			explicit:  map[string]string{"max_connections": "20"},
+			expected: map[string]string{
+				"random_page_cost":                 "4",
+				"effective_io_concurrency":         "2",
+				"max_worker_processes":             "16",
+				"max_parallel_workers":             "16",
+				"max_parallel_workers_per_gather":  "8",
+				"max_parallel_maintenance_workers": "4",
+				"shared_buffers":                   "16384MB",
+				"effective_cache_size":             "49152MB",
+				"maintenance_work_mem":             "2048MB",
+				"work_mem":                         "314572kB",
+			},
+		},
+		{
+			subTest:   "no memory known",
+			tuning:    cpov1.PostgresTuning{Profile: cpov1.TuningProfileMixed},
+			resources: &v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m")}},
+			expected: map[string]string{
+				"random_page_cost":                 "1.1",
+				"effective_io_concurrency":         "200",
+				"max_connections":                  "100",
+				"max_worker_processes":             "8",
+				"max_parallel_workers":             "1",
+				"max_parallel_workers_per_gather":  "0",
+				"max_parallel_maintenance_workers": "0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		parameters := tunedParameters(&tt.tuning, tt.resources, tt.explicit)
+		if !reflect.DeepEqual(parameters, tt.expected) {
+			t.Errorf("%s [%s]: expected parameters %v, got %v", t.Name(), tt.subTest, tt.expected, parameters)
+		}
+	}
+}
+
+func TestDesiredPostgresParameters(t *testing.T) {
+	cluster := New(Config{OpConfig: config.Config{Resources: config.Resources{
+		DefaultCPURequest:    "1",
+		DefaultMemoryRequest: "1Gi",
+		DefaultCPULimit:      "2",
+		DefaultMemoryLimit:   "4Gi",
+	}}}, k8sutil.KubernetesClient{}, cpov1.Postgresql{}, logger, eventRecorder)
+
+	spec := cpov1.PostgresSpec{PostgresqlParam: cpov1.PostgresqlParam{
+		Parameters: map[string]string{"shared_buffers": "512MB"},
+	}}
+	parameters, err := cluster.desiredPostgresParameters(&spec)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if !reflect.DeepEqual(parameters, spec.Parameters) {
+		t.Errorf("%s: expected only the manifest parameters without tuning profile, got %v", t.Name(), parameters)
+	}
+
+	spec.Tuning = &cpov1.PostgresTuning{Profile: cpov1.TuningProfileOLTP}
+	if parameters, err = cluster.desiredPostgresParameters(&spec); err != nil {
+		t.Fatalf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if parameters["shared_buffers"] != "512MB" {
+		t.Errorf("%s: expected explicit shared_buffers to win, got %q", t.Name(), parameters["shared_buffers"])
+	}
+	if parameters["effective_cache_size"] != "3072MB" {
+		t.Errorf("%s: expected effective_cache_size derived from the memory limit, got %q", t.Name(), parameters["effective_cache_size"])
+	}
+}
@@ -22,6 +22,7 @@ const (
 	Leader        PostgresRole = "leader"
 	StandbyLeader PostgresRole = "standby_leader"
 	SyncStandby   PostgresRole = "sync_standby"
+	QuorumStandby PostgresRole = "quorum_standby"
 
 	// clusterrole for service
 	ClusterPods PostgresRole = "clusterpods"
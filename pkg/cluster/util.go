@@ -157,6 +157,19 @@ func normalizeUserFlags(userFlags []string) ([]string, error) {
 	return flags, nil
 }
 
+// normalizeValidUntil converts the expiry of a role to UTC in the format used when reading the
+// roles from the database, so both can be compared
+func normalizeValidUntil(validUntil string) (string, error) {
+	if validUntil == "" || validUntil == cpov1.ValidUntilInfinity {
+		return validUntil, nil
+	}
+	expiry, err := time.Parse(time.RFC3339, validUntil)
+	if err != nil {
+		return "", err
+	}
+	return expiry.UTC().Format("2006-01-02T15:04:05Z"), nil
+}
+
 // specPatch produces a JSON of the Kubernetes object specification passed (typically service or
 // statefulset) to use it in a MergePatch.
 func specPatch(spec interface{}) ([]byte, error) {
@@ -741,6 +754,20 @@ func (c *Cluster) patroniKubernetesUseConfigMaps() bool {
 	return c.OpConfig.KubernetesUseConfigMaps
 }
 
+// patroniPaused reports if Patroni is in maintenance mode. The operator then
+// refrains from switchovers and rolling updates as well.
+func (c *Cluster) patroniPaused() bool {
+	return c.Spec.Patroni.Pause
+}
+
+// runningStatus returns the status of a cluster without pending operations
+func (c *Cluster) runningStatus() string {
+	if c.patroniPaused() {
+		return cpov1.ClusterStatusPaused
+	}
+	return cpov1.ClusterStatusRunning
+}
+
 // Earlier arguments take priority
 func mergeContainers(containers ...[]v1.Container) ([]v1.Container, []string) {
 	containerNameTaken := map[string]bool{}
@@ -12,8 +12,39 @@ import (
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 )
 
+// newTestCluster creates the cluster acid-test-cluster in the default namespace with the given spec on fake
+// clients. The labels and cluster domain of the tests are added to the operator configuration, and the
+// Postgres resource is created as well, so that status updates can be verified.
+func newTestCluster(t *testing.T, spec cpov1.PostgresSpec, cfg Config) *Cluster {
+	clientSet := k8sFake.NewSimpleClientset()
+	acidClientSet := fakecpov1.NewSimpleClientset()
+	client := k8sutil.KubernetesClient{
+		ConfigMapsGetter:             clientSet.CoreV1(),
+		JobsGetter:                   clientSet.BatchV1(),
+		PersistentVolumeClaimsGetter: clientSet.CoreV1(),
+		PodsGetter:                   clientSet.CoreV1(),
+		SecretsGetter:                clientSet.CoreV1(),
+		PostgresqlsGetter:            acidClientSet.CpoV1(),
+	}
+
+	pg := cpov1.Postgresql{
+		ObjectMeta: metav1.ObjectMeta{Name: "acid-test-cluster", Namespace: "default"},
+		Spec:       spec,
+	}
+	if _, err := acidClientSet.CpoV1().Postgresqls("default").Create(context.TODO(), &pg, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("%s: could not create postgresql: %v", t.Name(), err)
+	}
+
+	cfg.OpConfig.ClusterLabels = map[string]string{"application": "cpo"}
+	cfg.OpConfig.ClusterNameLabel = "cluster.cpo.opensource.cybertec.at/name"
+	cfg.OpConfig.ClusterDomain = "cluster.local"
+	cfg.OpConfig.PodRoleLabel = "spilo-role"
+	return New(cfg, client, pg, logger, record.NewFakeRecorder(10))
+}
+
 func newFakeK8sAnnotationsClient() (k8sutil.KubernetesClient, *k8sFake.Clientset) {
 	clientSet := k8sFake.NewSimpleClientset()
 	acidClientSet := fakecpov1.NewSimpleClientset()
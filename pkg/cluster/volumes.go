@@ -15,7 +15,6 @@ import (
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
-	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/filesystems"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/volumes"
 )
 
@@ -29,6 +28,10 @@ func (c *Cluster) syncVolumes() error {
 		return fmt.Errorf("could not parse volume size from the manifest: %v", err)
 	}
 
+	if (c.OpConfig.StorageResizeMode == "ebs" || c.OpConfig.StorageResizeMode == "mixed") && c.VolumeResizer == nil {
+		return fmt.Errorf("no volume resizer set for the %q storage resize mode, check the storage_resize_provider", c.OpConfig.StorageResizeMode)
+	}
+
 	if c.OpConfig.StorageResizeMode == "mixed" {
 		// mixed op uses AWS API to adjust size, throughput, iops, and calls pvc change for file system resize
 		// in case of errors we proceed to let K8s do its work, favoring disk space increase of other adjustments
@@ -71,6 +74,11 @@ func (c *Cluster) syncVolumes() error {
 	return nil
 }
 
+// isAWSStorageResizeProvider reports whether EBS specific defaults (gp3 migration, minimal IOPS and throughput) apply.
+func (c *Cluster) isAWSStorageResizeProvider() bool {
+	return c.OpConfig.StorageResizeProvider == "" || c.OpConfig.StorageResizeProvider == "aws"
+}
+
 func (c *Cluster) syncUnderlyingEBSVolume() error {
 	c.logger.Infof("starting to sync EBS volumes: type, iops, throughput, and size")
 
@@ -88,6 +96,12 @@ func (c *Cluster) syncUnderlyingEBSVolume() error {
 	awsGp3 := aws.String("gp3")
 	awsIo2 := aws.String("io2")
 
+	// EBS gp3 volumes cannot go below 3000 IOPS and 125 MB/s, other providers validate on their own
+	minIops, minThroughput := int64(3000), int64(125)
+	if !c.isAWSStorageResizeProvider() {
+		minIops, minThroughput = 1, 1
+	}
+
 	errors := make([]string, 0)
 
 	for _, volume := range c.EBSVolumes {
@@ -96,13 +110,13 @@ func (c *Cluster) syncUnderlyingEBSVolume() error {
 		var modifySize *int64
 		var modifyType *string
 
-		if targetValue.Iops != nil && *targetValue.Iops >= int64(3000) {
+		if targetValue.Iops != nil && *targetValue.Iops >= minIops {
 			if volume.Iops != *targetValue.Iops {
 				modifyIops = targetValue.Iops
 			}
 		}
 
-		if targetValue.Throughput != nil && *targetValue.Throughput >= int64(125) {
+		if targetValue.Throughput != nil && *targetValue.Throughput >= minThroughput {
 			if volume.Throughput != *targetValue.Throughput {
 				modifyThroughput = targetValue.Throughput
 			}
@@ -112,6 +126,20 @@ func (c *Cluster) syncUnderlyingEBSVolume() error {
 			modifySize = &targetSize
 		}
 
+		if !c.isAWSStorageResizeProvider() {
+			// only change the type when it is requested explicitly
+			if targetValue.VolumeType != "" && targetValue.VolumeType != volume.VolumeType {
+				modifyType = &targetValue.VolumeType
+			}
+			if modifyIops != nil || modifyThroughput != nil || modifySize != nil || modifyType != nil {
+				err = c.VolumeResizer.ModifyVolume(volume.VolumeID, modifyType, modifySize, modifyIops, modifyThroughput)
+				if err != nil {
+					errors = append(errors, fmt.Sprintf("modify failed: %v, showing current values: volume-id=%s type=%s size=%d iops=%d throughput=%d", err, volume.VolumeID, volume.VolumeType, volume.Size, volume.Iops, volume.Throughput))
+				}
+			}
+			continue
+		}
+
 		if modifyIops != nil || modifyThroughput != nil || modifySize != nil {
 			if modifyIops != nil || modifyThroughput != nil {
 				// we default to gp3 if iops and throughput are configured
@@ -157,7 +185,14 @@ func (c *Cluster) populateVolumeMetaData() error {
 	volumeIds := []string{}
 	var volumeID string
 	for _, pv := range pvs {
-		volumeID, err = c.VolumeResizer.ExtractVolumeID(pv.Spec.AWSElasticBlockStore.VolumeID)
+		// volumes of other providers are never passed to the configured resizer
+		if pv.Spec.AWSElasticBlockStore != nil && c.isAWSStorageResizeProvider() {
+			volumeID, err = c.VolumeResizer.ExtractVolumeID(pv.Spec.AWSElasticBlockStore.VolumeID)
+		} else if pv.Spec.AWSElasticBlockStore == nil && c.VolumeResizer.VolumeBelongsToProvider(pv) {
+			volumeID, err = c.VolumeResizer.GetProviderVolumeID(pv)
+		} else {
+			continue
+		}
 		if err != nil {
 			continue
 		}
@@ -400,17 +435,17 @@ func (c *Cluster) resizeVolumes() error {
 				}
 			}()
 		}
-		awsVolumeID, err := resizer.GetProviderVolumeID(pv)
+		providerVolumeID, err := resizer.GetProviderVolumeID(pv)
 		if err != nil {
 			return err
 		}
 		c.logger.Debugf("updating persistent volume %q to %d", pv.Name, newSize)
-		if err := resizer.ResizeVolume(awsVolumeID, newSize); err != nil {
-			return fmt.Errorf("could not resize EBS volume %q: %v", awsVolumeID, err)
+		if err := resizer.ResizeVolume(providerVolumeID, newSize); err != nil {
+			return fmt.Errorf("could not resize volume %q: %v", providerVolumeID, err)
 		}
 		c.logger.Debugf("resizing the filesystem on the volume %q", pv.Name)
 		podName := getPodNameFromPersistentVolume(pv)
-		if err := c.resizePostgresFilesystem(podName, []filesystems.FilesystemResizer{&filesystems.Ext234Resize{}}); err != nil {
+		if err := c.resizePostgresFilesystem(podName, postgresFilesystemResizers); err != nil {
 			return fmt.Errorf("could not resize the filesystem on pod %q: %v", podName, err)
 		}
 		c.logger.Debugf("filesystem resize successful on volume %q", pv.Name)
@@ -29,6 +29,8 @@ type testVolume struct {
 	iops        int64
 	throughtput int64
 	volType     string
+	// csi replaces the default EBS volume source of the persistent volume
+	csi *v1.CSIPersistentVolumeSource
 }
 
 var testVol = testVolume{
@@ -236,8 +238,12 @@ func initTestVolumesAndPods(client k8sutil.KubernetesClient, namespace, clustern
 		storage1Gi, _ := resource.ParseQuantity(fmt.Sprintf("%d", v.size))
 
 		ps := v1.PersistentVolumeSpec{}
-		ps.AWSElasticBlockStore = &v1.AWSElasticBlockStoreVolumeSource{}
-		ps.AWSElasticBlockStore.VolumeID = fmt.Sprintf("aws://eu-central-1b/ebs-volume-%d", i+1)
+		if v.csi != nil {
+			ps.CSI = v.csi
+		} else {
+			ps.AWSElasticBlockStore = &v1.AWSElasticBlockStoreVolumeSource{}
+			ps.AWSElasticBlockStore.VolumeID = fmt.Sprintf("aws://eu-central-1b/ebs-volume-%d", i+1)
+		}
 
 		pv := v1.PersistentVolume{
 			ObjectMeta: metav1.ObjectMeta{
@@ -336,6 +342,72 @@ func TestMigrateGp3Support(t *testing.T) {
 	cluster.syncVolumes()
 }
 
+func TestModifyNonEBSVolumes(t *testing.T) {
+	client, _ := newFakeK8sPVCclient()
+	clusterName := "acid-test-cluster"
+	namespace := "default"
+
+	// new cluster with mixed storage resize mode on Azure
+	var cluster = New(
+		Config{
+			OpConfig: config.Config{
+				Resources: config.Resources{
+					ClusterLabels:    map[string]string{"application": "cpo"},
+					ClusterNameLabel: "cluster.cpo.opensource.cybertec.at/name",
+				},
+				StorageResizeMode:     "mixed",
+				StorageResizeProvider: "azure",
+			},
+		}, client, cpov1.Postgresql{}, logger, eventRecorder)
+
+	cluster.Spec.Volume.Size = "150Gi"
+	cluster.Spec.Volume.VolumeType = "PremiumV2_LRS"
+	cluster.Spec.Volume.Iops = aws.Int64(2000)
+
+	// set metadata, so that labels will get correct values
+	cluster.Name = clusterName
+	cluster.Namespace = namespace
+	filterLabels := cluster.labelsSet(false)
+
+	azureDisk := func(name string) testVolume {
+		return testVolume{size: 100, csi: &v1.CSIPersistentVolumeSource{
+			Driver:       constants.AzureDiskCSIDriver,
+			VolumeHandle: "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/" + name,
+		}}
+	}
+	gceDisk := testVolume{size: 100, csi: &v1.CSIPersistentVolumeSource{
+		Driver:       constants.GCEPDCSIDriver,
+		VolumeHandle: "projects/p/zones/europe-west3-a/disks/disk-3",
+	}}
+	// volumes of other providers, here GCE and EBS, are left alone
+	testVolumes := []testVolume{azureDisk("disk-1"), azureDisk("disk-2"), gceDisk, testVol}
+
+	initTestVolumesAndPods(cluster.KubeClient, namespace, clusterName, filterLabels, testVolumes)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	resizer := mocks.NewMockVolumeResizer(ctrl)
+	azure := &volumes.AzureDiskVolumeResizer{}
+	disk1 := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/disk-1"
+	disk2 := "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/disk-2"
+
+	resizer.EXPECT().VolumeBelongsToProvider(gomock.Any()).DoAndReturn(azure.VolumeBelongsToProvider).Times(3)
+	resizer.EXPECT().GetProviderVolumeID(gomock.Any()).DoAndReturn(azure.GetProviderVolumeID).Times(2)
+
+	resizer.EXPECT().DescribeVolumes(gomock.Eq([]string{disk1, disk2})).Return(
+		[]volumes.VolumeProperties{
+			{VolumeID: disk1, VolumeType: "PremiumV2_LRS", Size: 100, Iops: 3000, Throughput: 125},
+			{VolumeID: disk2, VolumeType: "Premium_LRS", Size: 150, Iops: 2000, Throughput: 125}}, nil)
+
+	// no EBS minimums and no gp3 type, the type is changed only where it differs from the manifest
+	resizer.EXPECT().ModifyVolume(gomock.Eq(disk1), gomock.Nil(), gomock.Eq(aws.Int64(150)), gomock.Eq(aws.Int64(2000)), gomock.Nil()).Return(nil)
+	resizer.EXPECT().ModifyVolume(gomock.Eq(disk2), gomock.Eq(aws.String("PremiumV2_LRS")), gomock.Nil(), gomock.Nil(), gomock.Nil()).Return(nil)
+
+	cluster.VolumeResizer = resizer
+	cluster.syncVolumes()
+}
+
 func TestManualGp2Gp3Support(t *testing.T) {
 	client, _ := newFakeK8sPVCclient()
 	clusterName := "acid-test-cluster"
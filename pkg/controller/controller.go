@@ -14,11 +14,13 @@ import (
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apiserver"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/cluster"
 	cpov1informer "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/generated/informers/externalversions/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/selfservice"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/teams"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/config"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/credentials"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/k8sutil"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/ringlog"
 	"github.com/sirupsen/logrus"
@@ -35,9 +37,12 @@ import (
 
 // Controller represents operator controller
 type Controller struct {
-	config    spec.ControllerConfig
-	opConfig  *config.Config
-	pgTeamMap teams.PostgresTeamMap
+	config      spec.ControllerConfig
+	opConfig    *config.Config
+	pgTeamMap   teams.PostgresTeamMap
+	selfService selfservice.Registry
+
+	credentialStore credentials.Store
 
 	logger     *logrus.Entry
 	KubeClient k8sutil.KubernetesClient
@@ -58,11 +63,14 @@ type Controller struct {
 	clusterHistory   map[spec.NamespacedName]ringlog.RingLogger // history of the cluster changes
 	teamClusters     map[string][]spec.NamespacedName
 
-	postgresqlInformer   cache.SharedIndexInformer
-	postgresTeamInformer cache.SharedIndexInformer
-	podInformer          cache.SharedIndexInformer
-	nodesInformer        cache.SharedIndexInformer
-	podCh                chan cluster.PodEvent
+	postgresqlInformer       cache.SharedIndexInformer
+	postgresTeamInformer     cache.SharedIndexInformer
+	postgresUserInformer     cache.SharedIndexInformer
+	postgresDatabaseInformer cache.SharedIndexInformer
+	podInformer              cache.SharedIndexInformer
+	secretInformer           cache.SharedIndexInformer
+	nodesInformer            cache.SharedIndexInformer
+	podCh                    chan cluster.PodEvent
 
 	clusterEventQueues    []*cache.FIFO // [workerID]Queue
 	lastClusterSyncTime   int64
@@ -340,12 +348,29 @@ func (c *Controller) initController() {
 		c.loadPostgresTeams()
 	}
 
+	if c.opConfig.EnableSelfServiceCRDs {
+		c.loadPostgresUsers()
+		c.loadPostgresDatabases()
+	}
+
 	if c.opConfig.DebugLogging {
 		c.logger.Logger.Level = logrus.DebugLevel
 	}
 
 	logMultiLineConfig(c.logger, c.opConfig.MustMarshal())
 
+	credentialStore, err := credentials.NewStore(credentials.Config{
+		Store:           c.opConfig.CredentialStore,
+		VaultAddress:    c.opConfig.VaultAddress,
+		VaultKVMount:    c.opConfig.VaultKVMount,
+		VaultPathPrefix: c.opConfig.VaultPathPrefix,
+		VaultTokenFile:  c.opConfig.VaultTokenFile,
+	}, c.logger)
+	if err != nil {
+		c.logger.Fatalf("could not set up credential store: %v", err)
+	}
+	c.credentialStore = credentialStore
+
 	roleDefs := c.getInfrastructureRoleDefinitions()
 	if infraRoles, err := c.getInfrastructureRoles(roleDefs); err != nil {
 		c.logger.Warningf("could not get infrastructure roles: %v", err)
@@ -398,6 +423,33 @@ func (c *Controller) initSharedInformers() {
 		})
 	}
 
+	// PostgresUsers and PostgresDatabases
+	if c.opConfig.EnableSelfServiceCRDs {
+		c.postgresUserInformer = cpov1informer.NewPostgresUserInformer(
+			c.KubeClient.CpoV1ClientSet,
+			c.opConfig.WatchedNamespace,
+			constants.QueueResyncPeriodTPR,
+			cache.Indexers{})
+
+		c.postgresUserInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.postgresUserAdd,
+			UpdateFunc: c.postgresUserUpdate,
+			DeleteFunc: c.postgresUserDelete,
+		})
+
+		c.postgresDatabaseInformer = cpov1informer.NewPostgresDatabaseInformer(
+			c.KubeClient.CpoV1ClientSet,
+			c.opConfig.WatchedNamespace,
+			constants.QueueResyncPeriodTPR,
+			cache.Indexers{})
+
+		c.postgresDatabaseInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.postgresDatabaseAdd,
+			UpdateFunc: c.postgresDatabaseUpdate,
+			DeleteFunc: c.postgresDatabaseDelete,
+		})
+	}
+
 	// Pods
 	podLw := &cache.ListWatch{
 		ListFunc:  c.podListFunc,
@@ -416,6 +468,22 @@ func (c *Controller) initSharedInformers() {
 		DeleteFunc: c.podDelete,
 	})
 
+	// Secrets of the clusters, only watched for password rotation requests
+	secretLw := &cache.ListWatch{
+		ListFunc:  c.secretListFunc,
+		WatchFunc: c.secretWatchFunc,
+	}
+
+	c.secretInformer = cache.NewSharedIndexInformer(
+		secretLw,
+		&v1.Secret{},
+		constants.QueueResyncPeriodTPR,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	c.secretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.secretUpdate,
+	})
+
 	// Kubernetes Nodes
 	nodeLw := &cache.ListWatch{
 		ListFunc:  c.nodeListFunc,
@@ -451,8 +519,9 @@ func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 		panic("could not acquire initial list of clusters")
 	}
 
-	wg.Add(5 + util.Bool2Int(c.opConfig.EnablePostgresTeamCRD))
+	wg.Add(6 + util.Bool2Int(c.opConfig.EnablePostgresTeamCRD) + 2*util.Bool2Int(c.opConfig.EnableSelfServiceCRDs))
 	go c.runPodInformer(stopCh, wg)
+	go c.runSecretInformer(stopCh, wg)
 	go c.runPostgresqlInformer(stopCh, wg)
 	go c.clusterResync(stopCh, wg)
 	go c.apiserver.Run(stopCh, wg)
@@ -462,6 +531,11 @@ func (c *Controller) Run(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 		go c.runPostgresTeamInformer(stopCh, wg)
 	}
 
+	if c.opConfig.EnableSelfServiceCRDs {
+		go c.runPostgresUserInformer(stopCh, wg)
+		go c.runPostgresDatabaseInformer(stopCh, wg)
+	}
+
 	c.logger.Info("started working in background")
 }
 
@@ -471,6 +545,12 @@ func (c *Controller) runPodInformer(stopCh <-chan struct{}, wg *sync.WaitGroup)
 	c.podInformer.Run(stopCh)
 }
 
+func (c *Controller) runSecretInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.secretInformer.Run(stopCh)
+}
+
 func (c *Controller) runPostgresqlInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -483,6 +563,18 @@ func (c *Controller) runPostgresTeamInformer(stopCh <-chan struct{}, wg *sync.Wa
 	c.postgresTeamInformer.Run(stopCh)
 }
 
+func (c *Controller) runPostgresUserInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.postgresUserInformer.Run(stopCh)
+}
+
+func (c *Controller) runPostgresDatabaseInformer(stopCh <-chan struct{}, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	c.postgresDatabaseInformer.Run(stopCh)
+}
+
 func queueClusterKey(eventType EventType, uid types.UID) string {
 	return fmt.Sprintf("%s-%s", eventType, uid)
 }
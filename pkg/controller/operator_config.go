@@ -58,6 +58,13 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *cpov1.OperatorConfigura
 	result.EnablePasswordRotation = fromCRD.PostgresUsersConfiguration.EnablePasswordRotation
 	result.PasswordRotationInterval = util.CoalesceUInt32(fromCRD.PostgresUsersConfiguration.PasswordRotationInterval, 90)
 	result.PasswordRotationUserRetention = util.CoalesceUInt32(fromCRD.PostgresUsersConfiguration.DeepCopy().PasswordRotationUserRetention, 180)
+	result.EnableSelfServiceCRDs = fromCRD.PostgresUsersConfiguration.EnableSelfServiceCRDs
+	result.CredentialStore = util.Coalesce(fromCRD.PostgresUsersConfiguration.CredentialStore, "kubernetes")
+	result.MirrorCredentialSecrets = util.CoalesceBool(fromCRD.PostgresUsersConfiguration.MirrorCredentialSecrets, util.True())
+	result.VaultAddress = fromCRD.PostgresUsersConfiguration.VaultAddress
+	result.VaultKVMount = util.Coalesce(fromCRD.PostgresUsersConfiguration.VaultKVMount, "secret")
+	result.VaultPathPrefix = util.Coalesce(fromCRD.PostgresUsersConfiguration.VaultPathPrefix, "postgres-operator")
+	result.VaultTokenFile = fromCRD.PostgresUsersConfiguration.VaultTokenFile
 
 	// major version upgrade config
 	result.MajorVersionUpgradeMode = util.Coalesce(fromCRD.MajorVersionUpgrade.MajorVersionUpgradeMode, "off")
@@ -85,12 +92,17 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *cpov1.OperatorConfigura
 	result.PDBNameFormat = fromCRD.Kubernetes.PDBNameFormat
 	result.EnablePodDisruptionBudget = util.CoalesceBool(fromCRD.Kubernetes.EnablePodDisruptionBudget, util.True())
 	result.StorageResizeMode = util.Coalesce(fromCRD.Kubernetes.StorageResizeMode, "pvc")
+	result.StorageResizeProvider = util.Coalesce(fromCRD.Kubernetes.StorageResizeProvider, "aws")
 	result.EnableInitContainers = util.CoalesceBool(fromCRD.Kubernetes.EnableInitContainers, util.True())
 	result.EnableSidecars = util.CoalesceBool(fromCRD.Kubernetes.EnableSidecars, util.True())
 	result.SharePgSocketWithSidecars = util.CoalesceBool(fromCRD.Kubernetes.SharePgSocketWithSidecars, util.False())
 	result.SecretNameTemplate = fromCRD.Kubernetes.SecretNameTemplate
 	result.OAuthTokenSecretName = fromCRD.Kubernetes.OAuthTokenSecretName
 	result.EnableCrossNamespaceSecret = fromCRD.Kubernetes.EnableCrossNamespaceSecret
+	result.EnablePostgresTLSCertificates = fromCRD.Kubernetes.EnablePostgresTLSCertificates
+	result.PostgresTLSCertificateAuthority = util.Coalesce(fromCRD.Kubernetes.PostgresTLSCertificateAuthority, "operator")
+	result.PostgresTLSCASecretName = util.Coalesce(fromCRD.Kubernetes.PostgresTLSCASecretName, "postgres-operator-tls-ca")
+	result.PostgresTLSRenewBefore = util.CoalesceDuration(time.Duration(fromCRD.Kubernetes.PostgresTLSRenewBefore), "720h")
 
 	result.InfrastructureRolesSecretName = fromCRD.Kubernetes.InfrastructureRolesSecretName
 	if fromCRD.Kubernetes.InfrastructureRolesDefs != nil {
@@ -232,6 +244,12 @@ func (c *Controller) importConfigurationFromCRD(fromCRD *cpov1.OperatorConfigura
 
 	// Patroni config
 	result.EnablePatroniFailsafeMode = util.CoalesceBool(fromCRD.Patroni.FailsafeMode, util.False())
+	result.EnablePatroniAPITLS = fromCRD.Patroni.EnableAPITLS
+	result.PatroniAPIAuthMethod = util.Coalesce(fromCRD.Patroni.APIAuthMethod, "basic")
+	if err := config.ValidatePgHbaRules(fromCRD.Patroni.PgHbaRules); err != nil {
+		panic(fmt.Errorf("invalid operator-wide pg_hba rules: %v", err))
+	}
+	result.PatroniPgHbaRules = fromCRD.Patroni.PgHbaRules
 
 	// Connection pooler. Looks like we can't use defaulting in CRD before 1.17,
 	// so ensure default values here.
@@ -456,6 +456,18 @@ func (c *Controller) queueClusterEvent(informerOldSpec, informerNewSpec *cpov1.P
 		}
 	}
 
+	if clusterError == "" && eventType != EventDelete {
+		if err := cpov1.ValidatePostgresParameters(&informerNewSpec.Spec); err != nil {
+			if postgresParametersChanged(informerOldSpec, informerNewSpec) {
+				clusterError = err.Error()
+			} else {
+				// the cluster was accepted before, e.g. by an operator with an older catalog
+				c.logger.WithField("cluster.cpo.opensource.cybertec.at/name", clusterName).Warningf("%v", err)
+				c.eventRecorder.Eventf(c.GetReference(informerNewSpec), v1.EventTypeWarning, "Parameters", "%v", err)
+			}
+		}
+	}
+
 	if clusterError != "" && eventType != EventDelete {
 		c.logger.WithField("cluster.cpo.opensource.cybertec.at/name", clusterName).Debugf("skipping %q event for the invalid cluster: %s", eventType, clusterError)
 
@@ -518,6 +530,16 @@ func (c *Controller) queueClusterEvent(informerOldSpec, informerNewSpec *cpov1.P
 	}
 }
 
+// postgresParametersChanged tells whether the PostgreSQL parameters are new or were changed with the
+// event. Only then invalid parameters reject the event, existing clusters keep being synced.
+func postgresParametersChanged(oldSpec, newSpec *cpov1.Postgresql) bool {
+	if oldSpec == nil {
+		// a cluster the operator has not processed yet
+		return newSpec.Status.PostgresClusterStatus == ""
+	}
+	return !reflect.DeepEqual(oldSpec.Spec.PostgresqlParam, newSpec.Spec.PostgresqlParam)
+}
+
 func (c *Controller) postgresqlAdd(obj interface{}) {
 	pg := c.postgresqlCheck(obj)
 	if pg != nil {
@@ -93,6 +93,33 @@ func TestMergeDeprecatedPostgreSQLSpecParameters(t *testing.T) {
 	}
 }
 
+func TestPostgresParametersChanged(t *testing.T) {
+	pg := func(status string, parameters map[string]string) *cpov1.Postgresql {
+		return &cpov1.Postgresql{
+			Spec:   cpov1.PostgresSpec{PostgresqlParam: cpov1.PostgresqlParam{PgVersion: "17", Parameters: parameters}},
+			Status: cpov1.PostgresStatus{PostgresClusterStatus: status},
+		}
+	}
+	invalid := map[string]string{"shared_bufers": "1GB"}
+
+	tests := []struct {
+		name    string
+		old     *cpov1.Postgresql
+		new     *cpov1.Postgresql
+		changed bool
+	}{
+		{"new cluster", nil, pg("", invalid), true},
+		{"existing cluster loaded by the operator", nil, pg(cpov1.ClusterStatusRunning, invalid), false},
+		{"update without parameter changes", pg(cpov1.ClusterStatusRunning, invalid), pg(cpov1.ClusterStatusRunning, invalid), false},
+		{"update changing the parameters", pg(cpov1.ClusterStatusRunning, nil), pg(cpov1.ClusterStatusRunning, invalid), true},
+	}
+	for _, tt := range tests {
+		if changed := postgresParametersChanged(tt.old, tt.new); changed != tt.changed {
+			t.Errorf("%s: expected changed %v, got %v", tt.name, tt.changed, changed)
+		}
+	}
+}
+
 func TestMeetsClusterDeleteAnnotations(t *testing.T) {
 	// set delete annotations in configuration
 	postgresqlTestController.opConfig.DeleteAnnotationDateKey = "delete-date"
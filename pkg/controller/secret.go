@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+)
+
+// secretListFunc lists the secrets carrying the cluster name label, which includes the secrets of
+// the connection pooler with their own application label
+func (c *Controller) secretListFunc(options metav1.ListOptions) (runtime.Object, error) {
+	opts := metav1.ListOptions{
+		LabelSelector:   c.opConfig.ClusterNameLabel,
+		Watch:           options.Watch,
+		ResourceVersion: options.ResourceVersion,
+		TimeoutSeconds:  options.TimeoutSeconds,
+	}
+
+	return c.KubeClient.Secrets(c.opConfig.WatchedNamespace).List(context.TODO(), opts)
+}
+
+func (c *Controller) secretWatchFunc(options metav1.ListOptions) (watch.Interface, error) {
+	opts := metav1.ListOptions{
+		LabelSelector:   c.opConfig.ClusterNameLabel,
+		Watch:           options.Watch,
+		ResourceVersion: options.ResourceVersion,
+		TimeoutSeconds:  options.TimeoutSeconds,
+	}
+
+	return c.KubeClient.Secrets(c.opConfig.WatchedNamespace).Watch(context.TODO(), opts)
+}
+
+// secretUpdate queues a sync of the cluster as soon as the password rotation of a role is
+// requested by annotating its secret, instead of waiting for the next periodic sync
+func (c *Controller) secretUpdate(prev, cur interface{}) {
+	prevSecret, ok := prev.(*v1.Secret)
+	if !ok {
+		return
+	}
+	curSecret, ok := cur.(*v1.Secret)
+	if !ok {
+		return
+	}
+
+	request, requested := curSecret.Annotations[constants.RotatePasswordAnnotation]
+	if !requested {
+		return
+	}
+	if prevRequest, exists := prevSecret.Annotations[constants.RotatePasswordAnnotation]; exists && prevRequest == request {
+		return
+	}
+
+	clusterName, ok := c.secretClusterName(curSecret)
+	if !ok {
+		return
+	}
+	c.logger.Debugf("password rotation requested in secret %q, syncing cluster %q", util.NameFromMeta(curSecret.ObjectMeta), clusterName)
+	c.queueClusterSync(clusterName)
+}
+
+// secretClusterName returns the cluster a secret belongs to. Secrets of roles in other namespaces
+// only carry the name of the cluster, so the cluster is looked up among the known ones.
+func (c *Controller) secretClusterName(secret *v1.Secret) (spec.NamespacedName, bool) {
+	name, ok := secret.Labels[c.opConfig.ClusterNameLabel]
+	if !ok {
+		return spec.NamespacedName{}, false
+	}
+	clusterName := spec.NamespacedName{Namespace: secret.Namespace, Name: name}
+
+	c.clustersMu.RLock()
+	defer c.clustersMu.RUnlock()
+	if _, exists := c.clusters[clusterName]; exists {
+		return clusterName, true
+	}
+	for known := range c.clusters {
+		if known.Name == name {
+			return known, true
+		}
+	}
+	return spec.NamespacedName{}, false
+}
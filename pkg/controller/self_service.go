@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/selfservice"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (c *Controller) loadPostgresUsers() {
+	pgUsers, err := c.KubeClient.PostgresUsersGetter.PostgresUsers(c.opConfig.WatchedNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		c.logger.Errorf("could not list postgres user objects: %v", err)
+		return
+	}
+
+	c.selfService.LoadUsers(pgUsers)
+}
+
+func (c *Controller) loadPostgresDatabases() {
+	pgDatabases, err := c.KubeClient.PostgresDatabasesGetter.PostgresDatabases(c.opConfig.WatchedNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		c.logger.Errorf("could not list postgres database objects: %v", err)
+		return
+	}
+
+	c.selfService.LoadDatabases(pgDatabases)
+}
+
+// queueClusterSync queues a sync of a cluster, e.g. the one a PostgresUser or PostgresDatabase
+// refers to, which reconciles the roles, secrets and databases of the self-service resources
+func (c *Controller) queueClusterSync(clusterName spec.NamespacedName) {
+	obj, exists, err := c.postgresqlInformer.GetStore().GetByKey(clusterName.String())
+	if err != nil {
+		c.logger.Errorf("could not get cluster %q from the informer cache: %v", clusterName, err)
+		return
+	}
+	if !exists {
+		c.logger.Debugf("cluster %q to sync does not exist", clusterName)
+		return
+	}
+
+	pg := c.postgresqlCheck(obj)
+	if pg != nil {
+		c.queueClusterEvent(nil, pg, EventSync)
+	}
+}
+
+// queueClusterSyncAfter queues a sync of the cluster once the delay has passed, for clusters that wait for
+// something to finish in the background.
+func (c *Controller) queueClusterSyncAfter(clusterName spec.NamespacedName, after time.Duration) {
+	time.AfterFunc(after, func() {
+		c.queueClusterSync(clusterName)
+	})
+}
+
+func (c *Controller) postgresUserAdd(obj interface{}) {
+	pgUser, ok := obj.(*cpov1.PostgresUser)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresUser spec")
+		return
+	}
+	c.logger.Debugf("PostgresUser %q added", util.NameFromMeta(pgUser.ObjectMeta))
+	c.loadPostgresUsers()
+	c.queueClusterSync(selfservice.TargetCluster(pgUser.ObjectMeta, pgUser.Spec.ClusterName, pgUser.Spec.ClusterNamespace))
+}
+
+func (c *Controller) postgresUserUpdate(prev, cur interface{}) {
+	pgUserOld, okOld := prev.(*cpov1.PostgresUser)
+	pgUser, ok := cur.(*cpov1.PostgresUser)
+	if !okOld || !ok {
+		c.logger.Errorf("could not cast to PostgresUser spec")
+		return
+	}
+	if reflect.DeepEqual(pgUserOld.Spec, pgUser.Spec) {
+		// status changes are written by the operator itself, the registry only has to reflect them
+		if !reflect.DeepEqual(pgUserOld.Status, pgUser.Status) {
+			c.loadPostgresUsers()
+		}
+		return
+	}
+	c.logger.Debugf("PostgresUser %q updated", util.NameFromMeta(pgUser.ObjectMeta))
+	c.loadPostgresUsers()
+
+	oldCluster := selfservice.TargetCluster(pgUserOld.ObjectMeta, pgUserOld.Spec.ClusterName, pgUserOld.Spec.ClusterNamespace)
+	newCluster := selfservice.TargetCluster(pgUser.ObjectMeta, pgUser.Spec.ClusterName, pgUser.Spec.ClusterNamespace)
+	if oldCluster != newCluster {
+		c.queueClusterSync(oldCluster)
+	}
+	c.queueClusterSync(newCluster)
+}
+
+func (c *Controller) postgresUserDelete(obj interface{}) {
+	pgUser, ok := obj.(*cpov1.PostgresUser)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresUser spec")
+		return
+	}
+	// the role and its secret are kept, deletion only releases the name for other resources
+	c.logger.Debugf("PostgresUser %q deleted", util.NameFromMeta(pgUser.ObjectMeta))
+	c.loadPostgresUsers()
+	c.queueClusterSync(selfservice.TargetCluster(pgUser.ObjectMeta, pgUser.Spec.ClusterName, pgUser.Spec.ClusterNamespace))
+}
+
+func (c *Controller) postgresDatabaseAdd(obj interface{}) {
+	pgDatabase, ok := obj.(*cpov1.PostgresDatabase)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresDatabase spec")
+		return
+	}
+	c.logger.Debugf("PostgresDatabase %q added", util.NameFromMeta(pgDatabase.ObjectMeta))
+	c.loadPostgresDatabases()
+	c.queueClusterSync(selfservice.TargetCluster(pgDatabase.ObjectMeta, pgDatabase.Spec.ClusterName, pgDatabase.Spec.ClusterNamespace))
+}
+
+func (c *Controller) postgresDatabaseUpdate(prev, cur interface{}) {
+	pgDatabaseOld, okOld := prev.(*cpov1.PostgresDatabase)
+	pgDatabase, ok := cur.(*cpov1.PostgresDatabase)
+	if !okOld || !ok {
+		c.logger.Errorf("could not cast to PostgresDatabase spec")
+		return
+	}
+	if reflect.DeepEqual(pgDatabaseOld.Spec, pgDatabase.Spec) {
+		// status changes are written by the operator itself, the registry only has to reflect them
+		if !reflect.DeepEqual(pgDatabaseOld.Status, pgDatabase.Status) {
+			c.loadPostgresDatabases()
+		}
+		return
+	}
+	c.logger.Debugf("PostgresDatabase %q updated", util.NameFromMeta(pgDatabase.ObjectMeta))
+	c.loadPostgresDatabases()
+
+	oldCluster := selfservice.TargetCluster(pgDatabaseOld.ObjectMeta, pgDatabaseOld.Spec.ClusterName, pgDatabaseOld.Spec.ClusterNamespace)
+	newCluster := selfservice.TargetCluster(pgDatabase.ObjectMeta, pgDatabase.Spec.ClusterName, pgDatabase.Spec.ClusterNamespace)
+	if oldCluster != newCluster {
+		c.queueClusterSync(oldCluster)
+	}
+	c.queueClusterSync(newCluster)
+}
+
+func (c *Controller) postgresDatabaseDelete(obj interface{}) {
+	pgDatabase, ok := obj.(*cpov1.PostgresDatabase)
+	if !ok {
+		c.logger.Errorf("could not cast to PostgresDatabase spec")
+		return
+	}
+	// the database is kept, deletion only releases the name for other resources
+	c.logger.Debugf("PostgresDatabase %q deleted", util.NameFromMeta(pgDatabase.ObjectMeta))
+	c.loadPostgresDatabases()
+	c.queueClusterSync(selfservice.TargetCluster(pgDatabase.ObjectMeta, pgDatabase.Spec.ClusterName, pgDatabase.Spec.ClusterNamespace))
+}
@@ -31,6 +31,9 @@ func (c *Controller) makeClusterConfig() cluster.Config {
 		RestConfig:          c.config.RestConfig,
 		OpConfig:            config.Copy(c.opConfig),
 		PgTeamMap:           &c.pgTeamMap,
+		SelfService:         &c.selfService,
+		CredentialStore:     c.credentialStore,
+		QueueSync:           c.queueClusterSyncAfter,
 		InfrastructureRoles: infrastructureRoles,
 		PodServiceAccount:   c.PodServiceAccount,
 	}
@@ -64,6 +64,37 @@ func TestPodClusterName(t *testing.T) {
 	}
 }
 
+func TestSecretClusterName(t *testing.T) {
+	controller := newUtilTestController()
+	controller.clusters[spec.NamespacedName{Namespace: v1.NamespaceDefault, Name: "testcluster"}] = nil
+	controller.clusters[spec.NamespacedName{Namespace: "databases", Name: "othercluster"}] = nil
+
+	secret := func(namespace, clusterName string) *v1.Secret {
+		labels := map[string]string{}
+		if clusterName != "" {
+			labels[controller.opConfig.ClusterNameLabel] = clusterName
+		}
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Labels: labels}}
+	}
+
+	var testTable = []struct {
+		in       *v1.Secret
+		expected spec.NamespacedName
+		found    bool
+	}{
+		{secret(v1.NamespaceDefault, ""), spec.NamespacedName{}, false},
+		{secret(v1.NamespaceDefault, "testcluster"), spec.NamespacedName{Namespace: v1.NamespaceDefault, Name: "testcluster"}, true},
+		{secret("apps", "othercluster"), spec.NamespacedName{Namespace: "databases", Name: "othercluster"}, true},
+		{secret(v1.NamespaceDefault, "unknown"), spec.NamespacedName{}, false},
+	}
+	for _, test := range testTable {
+		clusterName, found := controller.secretClusterName(test.in)
+		if clusterName != test.expected || found != test.found {
+			t.Errorf("expected cluster %v (found %v), got %v (found %v)", test.expected, test.found, clusterName, found)
+		}
+	}
+}
+
 func TestClusterWorkerID(t *testing.T) {
 	var testTable = []struct {
 		in       spec.NamespacedName
@@ -35,7 +35,9 @@ import (
 type CpoV1Interface interface {
 	RESTClient() rest.Interface
 	OperatorConfigurationsGetter
+	PostgresDatabasesGetter
 	PostgresTeamsGetter
+	PostgresUsersGetter
 	PostgresqlsGetter
 }
 
@@ -48,10 +50,18 @@ func (c *CpoV1Client) OperatorConfigurations(namespace string) OperatorConfigura
 	return newOperatorConfigurations(c, namespace)
 }
 
+func (c *CpoV1Client) PostgresDatabases(namespace string) PostgresDatabaseInterface {
+	return newPostgresDatabases(c, namespace)
+}
+
 func (c *CpoV1Client) PostgresTeams(namespace string) PostgresTeamInterface {
 	return newPostgresTeams(c, namespace)
 }
 
+func (c *CpoV1Client) PostgresUsers(namespace string) PostgresUserInterface {
+	return newPostgresUsers(c, namespace)
+}
+
 func (c *CpoV1Client) Postgresqls(namespace string) PostgresqlInterface {
 	return newPostgresqls(c, namespace)
 }
@@ -38,10 +38,18 @@ func (c *FakeCpoV1) OperatorConfigurations(namespace string) v1.OperatorConfigur
 	return &FakeOperatorConfigurations{c, namespace}
 }
 
+func (c *FakeCpoV1) PostgresDatabases(namespace string) v1.PostgresDatabaseInterface {
+	return &FakePostgresDatabases{c, namespace}
+}
+
 func (c *FakeCpoV1) PostgresTeams(namespace string) v1.PostgresTeamInterface {
 	return &FakePostgresTeams{c, namespace}
 }
 
+func (c *FakeCpoV1) PostgresUsers(namespace string) v1.PostgresUserInterface {
+	return &FakePostgresUsers{c, namespace}
+}
+
 func (c *FakeCpoV1) Postgresqls(namespace string) v1.PostgresqlInterface {
 	return &FakePostgresqls{c, namespace}
 }
@@ -26,6 +26,10 @@ package v1
 
 type OperatorConfigurationExpansion interface{}
 
+type PostgresDatabaseExpansion interface{}
+
 type PostgresTeamExpansion interface{}
 
+type PostgresUserExpansion interface{}
+
 type PostgresqlExpansion interface{}
@@ -30,8 +30,12 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// PostgresDatabases returns a PostgresDatabaseInformer.
+	PostgresDatabases() PostgresDatabaseInformer
 	// PostgresTeams returns a PostgresTeamInformer.
 	PostgresTeams() PostgresTeamInformer
+	// PostgresUsers returns a PostgresUserInformer.
+	PostgresUsers() PostgresUserInformer
 	// Postgresqls returns a PostgresqlInformer.
 	Postgresqls() PostgresqlInformer
 }
@@ -47,11 +51,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// PostgresDatabases returns a PostgresDatabaseInformer.
+func (v *version) PostgresDatabases() PostgresDatabaseInformer {
+	return &postgresDatabaseInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // PostgresTeams returns a PostgresTeamInformer.
 func (v *version) PostgresTeams() PostgresTeamInformer {
 	return &postgresTeamInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// PostgresUsers returns a PostgresUserInformer.
+func (v *version) PostgresUsers() PostgresUserInformer {
+	return &postgresUserInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // Postgresqls returns a PostgresqlInformer.
 func (v *version) Postgresqls() PostgresqlInformer {
 	return &postgresqlInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
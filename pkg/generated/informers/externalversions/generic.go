@@ -60,8 +60,12 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=cpo.opensource.cybertec.at, Version=v1
+	case v1.SchemeGroupVersion.WithResource("postgresdatabases"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cpo().V1().PostgresDatabases().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("postgresteams"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cpo().V1().PostgresTeams().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("postgresusers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Cpo().V1().PostgresUsers().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("postgresqls"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Cpo().V1().Postgresqls().Informer()}, nil
 
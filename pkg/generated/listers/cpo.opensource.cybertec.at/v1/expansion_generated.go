@@ -24,6 +24,14 @@ SOFTWARE.
 
 package v1
 
+// PostgresDatabaseListerExpansion allows custom methods to be added to
+// PostgresDatabaseLister.
+type PostgresDatabaseListerExpansion interface{}
+
+// PostgresDatabaseNamespaceListerExpansion allows custom methods to be added to
+// PostgresDatabaseNamespaceLister.
+type PostgresDatabaseNamespaceListerExpansion interface{}
+
 // PostgresTeamListerExpansion allows custom methods to be added to
 // PostgresTeamLister.
 type PostgresTeamListerExpansion interface{}
@@ -32,6 +40,14 @@ type PostgresTeamListerExpansion interface{}
 // PostgresTeamNamespaceLister.
 type PostgresTeamNamespaceListerExpansion interface{}
 
+// PostgresUserListerExpansion allows custom methods to be added to
+// PostgresUserLister.
+type PostgresUserListerExpansion interface{}
+
+// PostgresUserNamespaceListerExpansion allows custom methods to be added to
+// PostgresUserNamespaceLister.
+type PostgresUserNamespaceListerExpansion interface{}
+
 // PostgresqlListerExpansion allows custom methods to be added to
 // PostgresqlLister.
 type PostgresqlListerExpansion interface{}
@@ -0,0 +1,138 @@
+package selfservice
+
+import (
+	"sort"
+	"sync"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AllNamespaces allows resources of every namespace to refer to a cluster
+const AllNamespaces = "*"
+
+// Registry is the operator's internal representation of all PostgresUser and PostgresDatabase
+// CRDs, grouped by the cluster they refer to. It is shared between the controller, which loads
+// it, and the clusters, which read it during the sync of roles and databases.
+type Registry struct {
+	mu        sync.RWMutex
+	users     map[spec.NamespacedName][]cpov1.PostgresUser
+	databases map[spec.NamespacedName][]cpov1.PostgresDatabase
+}
+
+// TargetCluster returns the cluster a PostgresUser or PostgresDatabase refers to. Without a
+// cluster namespace the cluster is expected in the namespace of the resource.
+func TargetCluster(meta metav1.ObjectMeta, clusterName, clusterNamespace string) spec.NamespacedName {
+	if clusterNamespace == "" {
+		clusterNamespace = meta.Namespace
+	}
+	return spec.NamespacedName{Namespace: clusterNamespace, Name: clusterName}
+}
+
+// UserName returns the name of the role of a PostgresUser, which defaults to the resource name
+func UserName(user *cpov1.PostgresUser) string {
+	if user.Spec.UserName != "" {
+		return user.Spec.UserName
+	}
+	return user.Name
+}
+
+// DatabaseName returns the name of the database of a PostgresDatabase, which defaults to the
+// resource name
+func DatabaseName(database *cpov1.PostgresDatabase) string {
+	if database.Spec.DatabaseName != "" {
+		return database.Spec.DatabaseName
+	}
+	return database.Name
+}
+
+// NamespaceAllowed reports whether resources of the namespace may refer to the cluster. Resources
+// in the namespace of the cluster are always allowed, others only when their namespace is listed
+// in the selfServiceNamespaces of the cluster manifest.
+func NamespaceAllowed(cluster spec.NamespacedName, allowedNamespaces []string, namespace string) bool {
+	if namespace == cluster.Namespace {
+		return true
+	}
+	for _, allowed := range allowedNamespaces {
+		if allowed == namespace || allowed == AllNamespaces {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadUsers replaces the PostgresUsers of the registry
+func (r *Registry) LoadUsers(list *cpov1.PostgresUserList) {
+	users := make(map[spec.NamespacedName][]cpov1.PostgresUser)
+	if list != nil {
+		for _, user := range list.Items {
+			cluster := TargetCluster(user.ObjectMeta, user.Spec.ClusterName, user.Spec.ClusterNamespace)
+			users[cluster] = append(users[cluster], user)
+		}
+	}
+	for cluster := range users {
+		sort.SliceStable(users[cluster], func(i, j int) bool {
+			return olderThan(users[cluster][i].ObjectMeta, users[cluster][j].ObjectMeta)
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users = users
+}
+
+// LoadDatabases replaces the PostgresDatabases of the registry
+func (r *Registry) LoadDatabases(list *cpov1.PostgresDatabaseList) {
+	databases := make(map[spec.NamespacedName][]cpov1.PostgresDatabase)
+	if list != nil {
+		for _, database := range list.Items {
+			cluster := TargetCluster(database.ObjectMeta, database.Spec.ClusterName, database.Spec.ClusterNamespace)
+			databases[cluster] = append(databases[cluster], database)
+		}
+	}
+	for cluster := range databases {
+		sort.SliceStable(databases[cluster], func(i, j int) bool {
+			return olderThan(databases[cluster][i].ObjectMeta, databases[cluster][j].ObjectMeta)
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.databases = databases
+}
+
+// Users returns the PostgresUsers referring to the cluster, oldest first
+func (r *Registry) Users(cluster spec.NamespacedName) []cpov1.PostgresUser {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]cpov1.PostgresUser, 0, len(r.users[cluster]))
+	for _, user := range r.users[cluster] {
+		users = append(users, *user.DeepCopy())
+	}
+	return users
+}
+
+// Databases returns the PostgresDatabases referring to the cluster, oldest first
+func (r *Registry) Databases(cluster spec.NamespacedName) []cpov1.PostgresDatabase {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	databases := make([]cpov1.PostgresDatabase, 0, len(r.databases[cluster]))
+	for _, database := range r.databases[cluster] {
+		databases = append(databases, *database.DeepCopy())
+	}
+	return databases
+}
+
+// olderThan orders resources by creation, so the first resource claiming a name keeps it
+func olderThan(a, b metav1.ObjectMeta) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
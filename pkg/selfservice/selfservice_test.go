@@ -0,0 +1,75 @@
+package selfservice
+
+import (
+	"testing"
+	"time"
+
+	cpov1 "github.com/cybertec-postgresql/cybertec-pg-operator/pkg/apis/cpo.opensource.cybertec.at/v1"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceAllowed(t *testing.T) {
+	cluster := spec.NamespacedName{Namespace: "db", Name: "acid-test"}
+	tests := []struct {
+		allowed   []string
+		namespace string
+		expected  bool
+	}{
+		{nil, "db", true},
+		{nil, "team-a", false},
+		{[]string{"team-a"}, "team-a", true},
+		{[]string{"team-a"}, "team-b", false},
+		{[]string{AllNamespaces}, "team-b", true},
+	}
+
+	for _, tt := range tests {
+		if got := NamespaceAllowed(cluster, tt.allowed, tt.namespace); got != tt.expected {
+			t.Errorf("TestNamespaceAllowed %v %s: expected %t, got %t", tt.allowed, tt.namespace, tt.expected, got)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	created := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	older := metav1.NewTime(created.Add(-time.Hour))
+
+	registry := &Registry{}
+	registry.LoadUsers(&cpov1.PostgresUserList{Items: []cpov1.PostgresUser{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "app", CreationTimestamp: created},
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "db"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "app", CreationTimestamp: created},
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "db", UserName: "app_user"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-c", Name: "app", CreationTimestamp: older},
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test", ClusterNamespace: "db"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "local", CreationTimestamp: created},
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-test"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "elsewhere", CreationTimestamp: created},
+			Spec: cpov1.PostgresUserSpec{ClusterName: "acid-other"}},
+	}})
+
+	users := registry.Users(spec.NamespacedName{Namespace: "db", Name: "acid-test"})
+	expected := []string{"team-c/app", "db/local", "team-a/app_user", "team-b/app"}
+	if len(users) != len(expected) {
+		t.Fatalf("expected %d users, got %d", len(expected), len(users))
+	}
+	for i := range users {
+		if got := users[i].Namespace + "/" + UserName(&users[i]); got != expected[i] {
+			t.Errorf("expected user %d to be %s, got %s", i, expected[i], got)
+		}
+	}
+
+	registry.LoadDatabases(&cpov1.PostgresDatabaseList{Items: []cpov1.PostgresDatabase{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "app"},
+			Spec: cpov1.PostgresDatabaseSpec{ClusterName: "acid-test", DatabaseName: "app_db", Owner: "app_user"}},
+	}})
+	databases := registry.Databases(spec.NamespacedName{Namespace: "db", Name: "acid-test"})
+	if len(databases) != 1 || DatabaseName(&databases[0]) != "app_db" {
+		t.Errorf("expected database app_db, got %v", databases)
+	}
+
+	registry.LoadUsers(nil)
+	if users := registry.Users(spec.NamespacedName{Namespace: "db", Name: "acid-test"}); len(users) != 0 {
+		t.Errorf("expected no users after reload, got %d", len(users))
+	}
+}
@@ -27,6 +27,7 @@ type RoleOrigin int
 // resolveNameConflict(...) to work.
 const (
 	RoleOriginUnknown RoleOrigin = iota
+	RoleOriginSelfService
 	RoleOriginManifest
 	RoleOriginInfrastructure
 	RoleOriginTeamsAPI
@@ -35,6 +36,7 @@ const (
 	RoleOriginConnectionPooler
 	RoleMonitoring
 	RoleOriginStream
+	RoleOriginLogicalReplication
 )
 
 type syncUserOperation int
@@ -55,6 +57,9 @@ type PgUser struct {
 	Password   string            `yaml:"-"`
 	Flags      []string          `yaml:"user_flags"`
 	MemberOf   []string          `yaml:"inrole"`
+	AdminOf    []string          `yaml:"-"`
+	ConnLimit  *int32            `yaml:"-"`
+	ValidUntil string            `yaml:"-"`
 	Parameters map[string]string `yaml:"db_parameters"`
 	AdminRole  string            `yaml:"admin_role"`
 	IsDbOwner  bool              `yaml:"is_db_owner"`
@@ -187,6 +192,8 @@ func (r RoleOrigin) String() string {
 	switch r {
 	case RoleOriginUnknown:
 		return "unknown"
+	case RoleOriginSelfService:
+		return "self-service role"
 	case RoleOriginManifest:
 		return "manifest role"
 	case RoleOriginInfrastructure:
@@ -201,6 +208,10 @@ func (r RoleOrigin) String() string {
 		return "connection pooler role"
 	case RoleMonitoring:
 		return "Monitoring role"
+	case RoleOriginStream:
+		return "stream role"
+	case RoleOriginLogicalReplication:
+		return "logical replication role"
 	default:
 		panic(fmt.Sprintf("bogus role origin value %d", r))
 	}
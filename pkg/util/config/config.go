@@ -111,6 +111,13 @@ type Auth struct {
 	EnablePasswordRotation        bool                  `name:"enable_password_rotation" default:"false"`
 	PasswordRotationInterval      uint32                `name:"password_rotation_interval" default:"90"`
 	PasswordRotationUserRetention uint32                `name:"password_rotation_user_retention" default:"180"`
+	EnableSelfServiceCRDs         bool                  `name:"enable_self_service_crds" default:"false"`
+	CredentialStore               string                `name:"credential_store" default:"kubernetes"`
+	MirrorCredentialSecrets       *bool                 `name:"mirror_credential_secrets" default:"true"`
+	VaultAddress                  string                `name:"vault_address"`
+	VaultKVMount                  string                `name:"vault_kv_mount" default:"secret"`
+	VaultPathPrefix               string                `name:"vault_path_prefix" default:"postgres-operator"`
+	VaultTokenFile                string                `name:"vault_token_file"`
 }
 
 // Scalyr holds the configuration for the Scalyr Agent sidecar for log shipping:
@@ -231,6 +238,7 @@ type Config struct {
 	PodAntiAffinityPreferredDuringScheduling bool              `name:"pod_antiaffinity_preferred_during_scheduling" default:"false"`
 	PodAntiAffinityTopologyKey               string            `name:"pod_antiaffinity_topology_key" default:"kubernetes.io/hostname"`
 	StorageResizeMode                        string            `name:"storage_resize_mode" default:"pvc"`
+	StorageResizeProvider                    string            `name:"storage_resize_provider" default:"aws"`
 	EnableLoadBalancer                       *bool             `name:"enable_load_balancer"` // deprecated and kept for backward compatibility
 	ExternalTrafficPolicy                    string            `name:"external_traffic_policy" default:"Cluster"`
 	MasterDNSNameFormat                      StringTemplate    `name:"master_dns_name_format" default:"{cluster}.{namespace}.{hostedzone}"`
@@ -266,6 +274,13 @@ type Config struct {
 	PatroniAPICheckInterval                  time.Duration     `name:"patroni_api_check_interval" default:"1s"`
 	PatroniAPICheckTimeout                   time.Duration     `name:"patroni_api_check_timeout" default:"5s"`
 	EnablePatroniFailsafeMode                *bool             `name:"enable_patroni_failsafe_mode" default:"false"`
+	EnablePatroniAPITLS                      bool              `name:"enable_patroni_api_tls" default:"false"`
+	EnablePostgresTLSCertificates            bool              `name:"enable_postgres_tls_certificates" default:"false"`
+	PostgresTLSCertificateAuthority          string            `name:"postgres_tls_certificate_authority" default:"operator"`
+	PostgresTLSCASecretName                  string            `name:"postgres_tls_ca_secret_name" default:"postgres-operator-tls-ca"`
+	PostgresTLSRenewBefore                   time.Duration     `name:"postgres_tls_renew_before" default:"720h"`
+	PatroniAPIAuthMethod                     string            `name:"patroni_api_auth_method" default:"basic"`
+	PatroniPgHbaRules                        []PgHbaRule       `name:"-"`
 	PersistentVolumeClaimRetentionPolicy     map[string]string `name:"persistent_volume_claim_retention_policy" default:"when_deleted:retain,when_scaled:retain"`
 }
 
@@ -332,6 +347,12 @@ func validate(cfg *Config) (err error) {
 		err = fmt.Errorf(msg, constants.ConnectionPoolerMinInstances)
 	}
 
+	switch cfg.StorageResizeProvider {
+	case "", "aws", "gcp", "azure":
+	default:
+		err = fmt.Errorf("storage resize provider %q is not supported, expected one of aws, gcp or azure", cfg.StorageResizeProvider)
+	}
+
 	if cfg.ConnectionPooler.User == cfg.SuperUsername {
 		msg := "connection pool user is not allowed to be the same as super user, username: %s"
 		err = fmt.Errorf(msg, cfg.ConnectionPooler.User)
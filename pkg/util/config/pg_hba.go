@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PgHbaRule describes a single pg_hba.conf entry
+type PgHbaRule struct {
+	Type     string            `json:"type"`
+	Database string            `json:"database"`
+	User     string            `json:"user"`
+	Address  string            `json:"address,omitempty"`
+	Method   string            `json:"method"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+var (
+	pgHbaTypes   = []string{"local", "host", "hostssl", "hostnossl", "hostgssenc", "hostnogssenc"}
+	pgHbaMethods = []string{"trust", "reject", "scram-sha-256", "md5", "password", "gss", "sspi", "ident", "peer", "pam", "ldap", "radius", "cert", "oauth"}
+
+	// pgHbaOptions lists the authentication options of each method, clientcert and clientname
+	// are accepted for every method of hostssl entries
+	pgHbaOptions = map[string][]string{
+		"ident":  {"map"},
+		"peer":   {"map"},
+		"gss":    {"map", "include_realm", "krb_realm", "compat_realm", "upn_username"},
+		"sspi":   {"map", "include_realm", "krb_realm", "compat_realm", "upn_username"},
+		"cert":   {"map"},
+		"pam":    {"pamservice", "pam_use_hostname"},
+		"radius": {"radiusservers", "radiussecrets", "radiusidentifiers", "radiusports"},
+		"oauth":  {"issuer", "scope", "validator", "map", "delegate_ident_mapping"},
+		"ldap": {"ldapserver", "ldapport", "ldapscheme", "ldaptls", "ldapprefix", "ldapsuffix", "ldapbasedn",
+			"ldapbinddn", "ldapbindpasswd", "ldapsearchattribute", "ldapsearchfilter", "ldapurl"},
+	}
+
+	pgHbaKeywordAddresses = []string{"all", "samehost", "samenet"}
+	pgHbaHostnameRegex    = regexp.MustCompile(`^\.?[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([-a-zA-Z0-9]*[a-zA-Z0-9])?)*$`)
+)
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DeepCopyInto copies the rule including its options
+func (r *PgHbaRule) DeepCopyInto(out *PgHbaRule) {
+	*out = *r
+	if r.Options != nil {
+		out.Options = make(map[string]string, len(r.Options))
+		for key, val := range r.Options {
+			out.Options[key] = val
+		}
+	}
+}
+
+// Validate checks that the rule results in a valid pg_hba.conf line
+func (r PgHbaRule) Validate() error {
+	if !containsString(pgHbaTypes, r.Type) {
+		return fmt.Errorf("invalid type %q, must be one of %s", r.Type, strings.Join(pgHbaTypes, ", "))
+	}
+	if !containsString(pgHbaMethods, r.Method) {
+		return fmt.Errorf("invalid method %q, must be one of %s", r.Method, strings.Join(pgHbaMethods, ", "))
+	}
+	for _, field := range [][2]string{{"database", r.Database}, {"user", r.User}} {
+		if field[1] == "" {
+			return fmt.Errorf("%s must not be empty", field[0])
+		}
+		if strings.ContainsAny(field[1], " \t\n\"") {
+			return fmt.Errorf("%s %q must not contain whitespace or quotes", field[0], field[1])
+		}
+	}
+
+	if r.Type == "local" {
+		if r.Address != "" {
+			return fmt.Errorf("local entries must not have an address")
+		}
+	} else {
+		if err := validatePgHbaAddress(r.Address); err != nil {
+			return err
+		}
+		if r.Method == "peer" {
+			return fmt.Errorf("method peer is only supported for local entries")
+		}
+	}
+
+	for _, key := range r.optionKeys() {
+		value := r.Options[key]
+		switch {
+		case key == "clientcert" || key == "clientname":
+			if r.Type != "hostssl" {
+				return fmt.Errorf("option %s requires type hostssl", key)
+			}
+			if key == "clientcert" && value != "verify-ca" && value != "verify-full" {
+				return fmt.Errorf("option clientcert must be verify-ca or verify-full")
+			}
+			if key == "clientname" && value != "CN" && value != "DN" {
+				return fmt.Errorf("option clientname must be CN or DN")
+			}
+		case !containsString(pgHbaOptions[r.Method], key):
+			return fmt.Errorf("option %q is not supported by method %s", key, r.Method)
+		}
+		if strings.ContainsAny(value, "\"\n") {
+			return fmt.Errorf("option %s must not contain quotes or line breaks", key)
+		}
+	}
+	if r.Method == "ldap" && r.Options["ldapserver"] == "" && r.Options["ldapurl"] == "" {
+		return fmt.Errorf("method ldap requires the option ldapserver or ldapurl")
+	}
+	if r.Method == "radius" && (r.Options["radiusservers"] == "" || r.Options["radiussecrets"] == "") {
+		return fmt.Errorf("method radius requires the options radiusservers and radiussecrets")
+	}
+	return nil
+}
+
+func validatePgHbaAddress(address string) error {
+	switch {
+	case address == "":
+		return fmt.Errorf("address must not be empty for host entries")
+	case containsString(pgHbaKeywordAddresses, address):
+		return nil
+	case strings.Contains(address, "/"):
+		if _, _, err := net.ParseCIDR(address); err != nil {
+			return fmt.Errorf("invalid address %q: %v", address, err)
+		}
+		return nil
+	case net.ParseIP(address) != nil:
+		return fmt.Errorf("address %q needs a CIDR mask, e.g. %s/32", address, address)
+	case !pgHbaHostnameRegex.MatchString(address):
+		return fmt.Errorf("invalid address %q", address)
+	}
+	return nil
+}
+
+// String renders the rule as pg_hba.conf line
+func (r PgHbaRule) String() string {
+	fields := []string{r.Type, r.Database, r.User}
+	if r.Type != "local" {
+		fields = append(fields, r.Address)
+	}
+	fields = append(fields, r.Method)
+
+	for _, key := range r.optionKeys() {
+		value := r.Options[key]
+		if value == "" || strings.ContainsAny(value, " \t,=") {
+			value = `"` + value + `"`
+		}
+		fields = append(fields, key+"="+value)
+	}
+	return strings.Join(fields, " ")
+}
+
+func (r PgHbaRule) optionKeys() []string {
+	keys := make([]string, 0, len(r.Options))
+	for key := range r.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidatePgHbaRules validates all rules and reports the first invalid one
+func ValidatePgHbaRules(rules []PgHbaRule) error {
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid pg_hba rule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// PgHbaEntries merges the pg_hba.conf lines in their order of precedence: the operator-wide rules,
+// the rules of the cluster and finally the plain entries of the cluster. Spilo appends its own
+// default entries to the list when it renders the Patroni configuration, so the rules are inserted
+// in front of them instead of copying the defaults of the image. No entries are returned if none of
+// them is defined, leaving pg_hba.conf to Spilo.
+func PgHbaEntries(operatorRules, clusterRules []PgHbaRule, clusterEntries []string) []string {
+	if len(operatorRules) == 0 && len(clusterRules) == 0 {
+		return clusterEntries
+	}
+	entries := make([]string, 0, len(operatorRules)+len(clusterRules)+len(clusterEntries))
+	for _, rule := range operatorRules {
+		entries = append(entries, rule.String())
+	}
+	for _, rule := range clusterRules {
+		entries = append(entries, rule.String())
+	}
+	return append(entries, clusterEntries...)
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var pgHbaRuleValidationTest = []struct {
+	in  PgHbaRule
+	err string
+}{
+	{PgHbaRule{Type: "local", Database: "all", User: "all", Method: "peer", Options: map[string]string{"map": "local"}}, ""},
+	{PgHbaRule{Type: "hostssl", Database: "replication", User: "standby", Address: "10.0.0.0/8", Method: "cert",
+		Options: map[string]string{"clientname": "DN"}}, ""},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: ".example.com", Method: "scram-sha-256"}, ""},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "samenet", Method: "radius",
+		Options: map[string]string{"radiusservers": "radius1,radius2", "radiussecrets": "secret"}}, ""},
+	{PgHbaRule{Type: "remote", Database: "all", User: "all", Method: "md5"}, `invalid type "remote"`},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "all", Method: "crypt"}, `invalid method "crypt"`},
+	{PgHbaRule{Type: "local", Database: "", User: "all", Method: "trust"}, "database must not be empty"},
+	{PgHbaRule{Type: "local", Database: "all", User: "a b", Method: "trust"}, "must not contain whitespace"},
+	{PgHbaRule{Type: "local", Database: "all", User: "all", Address: "all", Method: "trust"}, "local entries must not have an address"},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "10.0.0.1", Method: "md5"}, "needs a CIDR mask"},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "10.0.0.0/33", Method: "md5"}, "invalid address"},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "all", Method: "peer"}, "only supported for local entries"},
+	{PgHbaRule{Type: "hostssl", Database: "all", User: "all", Address: "all", Method: "md5",
+		Options: map[string]string{"clientcert": "1"}}, "clientcert must be verify-ca or verify-full"},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "all", Method: "md5",
+		Options: map[string]string{"map": "users"}}, `option "map" is not supported by method md5`},
+	{PgHbaRule{Type: "host", Database: "all", User: "all", Address: "all", Method: "ldap",
+		Options: map[string]string{"ldapport": "389"}}, "requires the option ldapserver or ldapurl"},
+}
+
+func TestPgHbaRuleValidate(t *testing.T) {
+	for _, tt := range pgHbaRuleValidationTest {
+		err := tt.in.Validate()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("TestPgHbaRuleValidate with %v: unexpected error: %v", tt.in, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("TestPgHbaRuleValidate with %v: expected error containing %q, got %v", tt.in, tt.err, err)
+		}
+	}
+}
+
+func TestPgHbaEntries(t *testing.T) {
+	operatorRules := []PgHbaRule{
+		{Type: "local", Database: "all", User: "all", Method: "trust"},
+	}
+	clusterRules := []PgHbaRule{
+		{Type: "host", Database: "all", User: "all", Address: "all", Method: "ldap",
+			Options: map[string]string{"ldapserver": "ldap.example.com", "ldapsuffix": ", dc=example, dc=com", "ldapprefix": "cn="}},
+	}
+	clusterEntries := []string{"hostssl all all all md5"}
+
+	tests := []struct {
+		about          string
+		operatorRules  []PgHbaRule
+		clusterRules   []PgHbaRule
+		clusterEntries []string
+		expected       []string
+	}{
+		{"no entries", nil, nil, nil, nil},
+		{"plain entries only", nil, nil, clusterEntries, clusterEntries},
+		{"merged", operatorRules, clusterRules, clusterEntries, []string{
+			"local all all trust",
+			`host all all all ldap ldapprefix="cn=" ldapserver=ldap.example.com ldapsuffix=", dc=example, dc=com"`,
+			"hostssl all all all md5",
+		}},
+		// the default entries are added by Spilo
+		{"rules only", nil, clusterRules, nil, []string{
+			`host all all all ldap ldapprefix="cn=" ldapserver=ldap.example.com ldapsuffix=", dc=example, dc=com"`,
+		}},
+		{"operator rules only", operatorRules, nil, nil, []string{
+			"local all all trust",
+		}},
+	}
+
+	for _, tt := range tests {
+		got := PgHbaEntries(tt.operatorRules, tt.clusterRules, tt.clusterEntries)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("TestPgHbaEntries %s: expected %#v, got %#v", tt.about, tt.expected, got)
+		}
+	}
+}
@@ -8,4 +8,9 @@ const (
 	KubeIAmAnnotation                  = "iam.amazonaws.com/role"
 	VolumeStorateProvisionerAnnotation = "pv.kubernetes.io/provisioned-by"
 	PostgresqlControllerAnnotationKey  = "cpo.opensource.cybertec.at/controller"
+	ConsumerNamespacesAnnotation       = "cpo.opensource.cybertec.at/consumer-namespaces"
+	ConsumerSecretSourceAnnotation     = "cpo.opensource.cybertec.at/source-secret"
+	RotatePasswordAnnotation           = "cpo.opensource.cybertec.at/rotate-password"
+	PasswordRotatedAtAnnotation        = "cpo.opensource.cybertec.at/password-rotated-at"
+	TLSCertificateSerialAnnotation     = "cpo.opensource.cybertec.at/tls-certificate-serial"
 )
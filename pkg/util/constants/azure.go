@@ -0,0 +1,18 @@
+package constants
+
+import "time"
+
+// Azure specific constants used by other modules
+const (
+	// Managed Disk related constants
+	AzureDiskProvisioner          = "kubernetes.io/azure-disk"
+	AzureDiskCSIDriver            = "disk.csi.azure.com"
+	AzureResourceManagerEndpoint  = "https://management.azure.com"
+	AzureInstanceMetadataEndpoint = "http://169.254.169.254/metadata"
+	AzureDiskAPIVersion           = "2023-04-02"
+	AzureOperationStatusSucceeded = "Succeeded"
+	AzureOperationStatusFailed    = "Failed"
+	AzureOperationStatusCanceled  = "Canceled"
+	AzureDiskResizeWaitInterval   = 2 * time.Second
+	AzureDiskResizeWaitTimeout    = 60 * time.Second
+)
@@ -0,0 +1,15 @@
+package constants
+
+import "time"
+
+// GCP specific constants used by other modules
+const (
+	// Persistent Disk related constants
+	GCEPDProvisioner          = "kubernetes.io/gce-pd"
+	GCEPDCSIDriver            = "pd.csi.storage.gke.io"
+	GCEComputeEndpoint        = "https://compute.googleapis.com/compute/v1"
+	GCEMetadataEndpoint       = "http://metadata.google.internal/computeMetadata/v1"
+	GCEOperationStatusDone    = "DONE"
+	GCEDiskResizeWaitInterval = 2 * time.Second
+	GCEDiskResizeWaitTimeout  = 60 * time.Second
+)
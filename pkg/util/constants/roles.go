@@ -8,6 +8,8 @@ const (
 	ConnectionPoolerUserKeyName = "pooler"
 	MonitoringUserKeyName       = "cpo_exporter"
 	EventStreamUserKeyName      = "streamer"
+	LogicalReplicationUserName  = "cpo_logical_replication"
+	ReadAllDataRoleName         = "pg_read_all_data"
 	RoleFlagSuperuser           = "SUPERUSER"
 	RoleFlagInherit             = "INHERIT"
 	RoleFlagLogin               = "LOGIN"
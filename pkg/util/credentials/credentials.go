@@ -0,0 +1,57 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StoreKubernetes keeps credentials only in Kubernetes secrets
+	StoreKubernetes = "kubernetes"
+	// StoreVault keeps credentials in the KV secrets engine of HashiCorp Vault
+	StoreVault = "vault"
+)
+
+// ErrNotFound is returned when no credentials are stored under a path
+var ErrNotFound = errors.New("credentials not found")
+
+// Store keeps the credentials of Postgres roles, e.g. username, password and next rotation date,
+// outside of the Kubernetes secrets
+type Store interface {
+	// Get returns the credentials stored under the path or ErrNotFound
+	Get(path string) (map[string]string, error)
+	// Put stores the credentials under the path, replacing the previous ones
+	Put(path string, data map[string]string) error
+}
+
+// Config describes the credential store of the operator
+type Config struct {
+	Store           string
+	VaultAddress    string
+	VaultKVMount    string
+	VaultPathPrefix string
+	VaultTokenFile  string
+}
+
+// NewStore creates the configured credential store. Kubernetes secrets need no extra store,
+// in which case nil is returned.
+func NewStore(cfg Config, log *logrus.Entry) (Store, error) {
+	switch cfg.Store {
+	case "", StoreKubernetes:
+		return nil, nil
+	case StoreVault:
+		if cfg.VaultAddress == "" {
+			return nil, fmt.Errorf("vault_address must be set for the vault credential store")
+		}
+		token := os.Getenv("VAULT_TOKEN")
+		if cfg.VaultTokenFile == "" && token == "" {
+			return nil, fmt.Errorf("either vault_token_file or the VAULT_TOKEN environment variable must be set for the vault credential store")
+		}
+		return NewVaultStore(cfg.VaultAddress, cfg.VaultKVMount, cfg.VaultPathPrefix, cfg.VaultTokenFile, token, log), nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q, must be %q or %q", cfg.Store, StoreKubernetes, StoreVault)
+	}
+}
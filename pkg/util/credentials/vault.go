@@ -0,0 +1,143 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// VaultStore keeps credentials in a KV version 2 secrets engine of HashiCorp Vault
+type VaultStore struct {
+	httpClient
+	address    string
+	mount      string
+	pathPrefix string
+	tokenFile  string
+	token      string
+	logger     *logrus.Entry
+}
+
+type vaultKVData struct {
+	Data map[string]string `json:"data"`
+}
+
+type vaultResponse struct {
+	Data   *vaultKVData `json:"data"`
+	Errors []string     `json:"errors"`
+}
+
+// NewVaultStore creates a store for the KV engine mounted at mount. The token is read from the
+// token file on every request, so that it can be renewed by e.g. the Vault agent, and falls back
+// to the given token.
+func NewVaultStore(address, mount, pathPrefix, tokenFile, token string, log *logrus.Entry) *VaultStore {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultStore{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		address:    strings.TrimRight(address, "/"),
+		mount:      strings.Trim(mount, "/"),
+		pathPrefix: strings.Trim(pathPrefix, "/"),
+		tokenFile:  tokenFile,
+		token:      token,
+		logger:     log.WithField("pkg", "credentials"),
+	}
+}
+
+// Get returns the latest version of the credentials stored under the path
+func (v *VaultStore) Get(secretPath string) (map[string]string, error) {
+	response, statusCode, err := v.request(http.MethodGet, secretPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	// deleted or destroyed versions are reported as not found, too
+	if statusCode == http.StatusNotFound || response.Data == nil || response.Data.Data == nil {
+		return nil, ErrNotFound
+	}
+	return response.Data.Data, nil
+}
+
+// Put writes a new version of the credentials under the path
+func (v *VaultStore) Put(secretPath string, data map[string]string) error {
+	body, err := json.Marshal(vaultKVData{Data: data})
+	if err != nil {
+		return fmt.Errorf("could not marshal credentials: %v", err)
+	}
+	_, statusCode, err := v.request(http.MethodPost, secretPath, body)
+	if err != nil {
+		return err
+	}
+	if statusCode == http.StatusNotFound {
+		return fmt.Errorf("could not write credentials to %s: no KV secrets engine mounted at %q", secretPath, v.mount)
+	}
+	return nil
+}
+
+func (v *VaultStore) request(method, secretPath string, body []byte) (*vaultResponse, int, error) {
+	token, err := v.getToken()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", v.address, v.mount, path.Join(v.pathPrefix, secretPath))
+	v.logger.Debugf("%s request to vault: %s", method, url)
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not reach vault: %v", err)
+	}
+	defer resp.Body.Close()
+
+	response := &vaultResponse{}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("could not read vault response: %v", err)
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, response); err != nil {
+			return nil, resp.StatusCode, fmt.Errorf("vault request failed with status code %d and malformed response: %v", resp.StatusCode, err)
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && len(response.Errors) == 0:
+		return response, resp.StatusCode, nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		if len(response.Errors) > 0 {
+			return nil, resp.StatusCode, fmt.Errorf("vault request failed with status code %d: %s", resp.StatusCode, strings.Join(response.Errors, ", "))
+		}
+		return nil, resp.StatusCode, fmt.Errorf("vault request failed with status code %d", resp.StatusCode)
+	}
+	return response, resp.StatusCode, nil
+}
+
+func (v *VaultStore) getToken() (string, error) {
+	if v.tokenFile == "" {
+		return v.token, nil
+	}
+	token, err := os.ReadFile(v.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("could not read vault token: %v", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
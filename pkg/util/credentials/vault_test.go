@@ -0,0 +1,194 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.New().WithField("test", "credentials")
+
+const devRootToken = "dev-root-token"
+
+// devVault mimics the KV version 2 engine of a Vault server in dev mode, which mounts it at secret/
+type devVault struct {
+	mu      sync.Mutex
+	secrets map[string][]map[string]string
+}
+
+func newDevVault() (*devVault, *httptest.Server) {
+	vault := &devVault{secrets: map[string][]map[string]string{}}
+	return vault, httptest.NewServer(vault)
+}
+
+func (d *devVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if r.Header.Get("X-Vault-Token") != devRootToken {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/v1/secret/data/") {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":["no handler for route \"` + r.URL.Path + `\". route entry not found."]}`))
+		return
+	}
+	secretPath := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+
+	switch r.Method {
+	case http.MethodGet:
+		versions := d.secrets[secretPath]
+		if len(versions) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     versions[len(versions)-1],
+				"metadata": map[string]interface{}{"version": len(versions)},
+			},
+		})
+	case http.MethodPost, http.MethodPut:
+		var body vaultKVData
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":["error parsing JSON"]}`))
+			return
+		}
+		d.secrets[secretPath] = append(d.secrets[secretPath], body.Data)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"version": len(d.secrets[secretPath])},
+		})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestVaultStore(t *testing.T) {
+	vault, server := newDevVault()
+	defer server.Close()
+
+	store := NewVaultStore(server.URL+"/", "", "postgres-operator", "", devRootToken, logger)
+	secretPath := "default/foo.acid-test.credentials"
+
+	if _, err := store.Get(secretPath); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected credentials to be not found, got %v", err)
+	}
+
+	first := map[string]string{"username": "foo", "password": "secret"}
+	if err := store.Put(secretPath, first); err != nil {
+		t.Fatalf("could not put credentials: %v", err)
+	}
+	rotated := map[string]string{"username": "foo", "password": "rotated", "nextRotation": "2024-01-01T00:00:00Z"}
+	if err := store.Put(secretPath, rotated); err != nil {
+		t.Fatalf("could not put credentials: %v", err)
+	}
+
+	data, err := store.Get(secretPath)
+	if err != nil {
+		t.Fatalf("could not get credentials: %v", err)
+	}
+	if !reflect.DeepEqual(data, rotated) {
+		t.Errorf("expected latest credentials %v, got %v", rotated, data)
+	}
+	if versions := vault.secrets["postgres-operator/"+secretPath]; len(versions) != 2 {
+		t.Errorf("expected two versions under the path prefix, got %d", len(versions))
+	}
+}
+
+func TestVaultStoreErrors(t *testing.T) {
+	_, server := newDevVault()
+	defer server.Close()
+
+	tests := []struct {
+		subTest string
+		store   *VaultStore
+		err     string
+	}{
+		{
+			subTest: "invalid token",
+			store:   NewVaultStore(server.URL, "secret", "", "", "wrong-token", logger),
+			err:     "vault request failed with status code 403: permission denied",
+		},
+		{
+			subTest: "missing mount",
+			store:   NewVaultStore(server.URL, "kv", "", "", devRootToken, logger),
+			err:     `vault request failed with status code 404: no handler for route "/v1/kv/data/foo". route entry not found.`,
+		},
+		{
+			subTest: "missing token file",
+			store:   NewVaultStore(server.URL, "secret", "", filepath.Join(t.TempDir(), "token"), devRootToken, logger),
+			err:     "could not read vault token",
+		},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.store.Get("foo"); err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+			t.Errorf("%s [%s]: expected error %q, got %v", t.Name(), tt.subTest, tt.err, err)
+		}
+	}
+}
+
+func TestVaultStoreTokenFile(t *testing.T) {
+	_, server := newDevVault()
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("expired-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store := NewVaultStore(server.URL, "secret", "", tokenFile, "", logger)
+	if err := store.Put("foo", map[string]string{"password": "secret"}); err == nil {
+		t.Errorf("expected the expired token to be refused")
+	}
+
+	// a renewed token is picked up without restarting the operator
+	if err := os.WriteFile(tokenFile, []byte(devRootToken+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put("foo", map[string]string{"password": "secret"}); err != nil {
+		t.Errorf("expected the renewed token to be used, got %v", err)
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+
+	tests := []struct {
+		subTest string
+		cfg     Config
+		isNil   bool
+		err     string
+	}{
+		{"default store", Config{}, true, ""},
+		{"kubernetes store", Config{Store: StoreKubernetes}, true, ""},
+		{"vault store", Config{Store: StoreVault, VaultAddress: "http://vault:8200", VaultTokenFile: "/vault/token"}, false, ""},
+		{"vault store without address", Config{Store: StoreVault, VaultTokenFile: "/vault/token"}, true,
+			"vault_address must be set for the vault credential store"},
+		{"vault store without token", Config{Store: StoreVault, VaultAddress: "http://vault:8200"}, true,
+			"either vault_token_file or the VAULT_TOKEN environment variable must be set for the vault credential store"},
+		{"unknown store", Config{Store: "aws"}, true, `unknown credential store "aws", must be "kubernetes" or "vault"`},
+	}
+
+	for _, tt := range tests {
+		store, err := NewStore(tt.cfg, logger)
+		if (err == nil && tt.err != "") || (err != nil && err.Error() != tt.err) {
+			t.Errorf("%s [%s]: expected error %q, got %v", t.Name(), tt.subTest, tt.err, err)
+		}
+		if (store == nil) != tt.isNil {
+			t.Errorf("%s [%s]: expected nil store: %v, got %v", t.Name(), tt.subTest, tt.isNil, store)
+		}
+	}
+}
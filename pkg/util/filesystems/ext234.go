@@ -27,7 +27,7 @@ func (c *Ext234Resize) CanResizeFilesystem(fstype string) bool {
 }
 
 // ResizeFilesystem calls resize2fs to resize the filesystem if necessary.
-func (c *Ext234Resize) ResizeFilesystem(deviceName string, commandExecutor func(cmd string) (out string, err error)) error {
+func (c *Ext234Resize) ResizeFilesystem(deviceName, mountPoint string, commandExecutor func(cmd string) (out string, err error)) error {
 	command := fmt.Sprintf("%s %s 2>&1", resize2fs, deviceName)
 	out, err := commandExecutor(command)
 	if err != nil {
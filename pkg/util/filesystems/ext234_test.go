@@ -0,0 +1,41 @@
+package filesystems
+
+import (
+	"testing"
+)
+
+func TestExt234ResizeFilesystem(t *testing.T) {
+	var tests = []struct {
+		subtest     string
+		output      string
+		expectedErr bool
+	}{
+		{
+			subtest: "grown online",
+			output: "resize2fs 1.46.5 (30-Dec-2021)\nFilesystem at /dev/nvme1n1 is mounted on /home/postgres/pgdata; on-line resizing required\n" +
+				"old_desc_blocks = 2, new_desc_blocks = 3\nThe filesystem on /dev/nvme1n1 is now 5242880 (4k) blocks long.\n",
+		},
+		{
+			subtest: "nothing to do",
+			output:  "resize2fs 1.46.5 (30-Dec-2021)\nThe filesystem is already 2621440 (4k) blocks long.  Nothing to do!\n",
+		},
+		{
+			subtest:     "unrecognized output",
+			output:      "resize2fs: Permission denied to resize filesystem\n",
+			expectedErr: true,
+		},
+	}
+
+	resizer := Ext234Resize{}
+	for _, tt := range tests {
+		err := resizer.ResizeFilesystem("/dev/nvme1n1", "/home/postgres/pgdata", func(cmd string) (string, error) {
+			if cmd != "resize2fs /dev/nvme1n1 2>&1" {
+				t.Errorf("%s [%s]: unexpected command %q", t.Name(), tt.subtest, cmd)
+			}
+			return tt.output, nil
+		})
+		if (err != nil) != tt.expectedErr {
+			t.Errorf("%s [%s]: expected error %t, got %v", t.Name(), tt.subtest, tt.expectedErr, err)
+		}
+	}
+}
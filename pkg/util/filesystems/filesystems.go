@@ -3,5 +3,5 @@ package filesystems
 // FilesystemResizer has methods to work with resizing of a filesystem
 type FilesystemResizer interface {
 	CanResizeFilesystem(fstype string) bool
-	ResizeFilesystem(deviceName string, commandExecutor func(string) (out string, err error)) error
+	ResizeFilesystem(deviceName, mountPoint string, commandExecutor func(string) (out string, err error)) error
 }
@@ -0,0 +1,60 @@
+package filesystems
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	xfsDataBlocksChangedRegexp = regexp.MustCompile(`data blocks changed from \d+ to \d+`)
+	xfsGeometryRegexp          = regexp.MustCompile(`(?m)^meta-data=\S+\s+isize=\d+`)
+	xfsErrorRegexp             = regexp.MustCompile(`(?m)^xfs_growfs: (.+)$`)
+	xfsTooSmallRegexp          = regexp.MustCompile(`data size \d+ too small, old size is \d+`)
+)
+
+const (
+	xfs       = "xfs"
+	xfsGrowfs = "xfs_growfs"
+)
+
+// XfsResize implements the FilesystemResizer interface for XFS.
+type XfsResize struct {
+}
+
+// CanResizeFilesystem checks whether XfsResize can resize this filesystem.
+func (c *XfsResize) CanResizeFilesystem(fstype string) bool {
+	return fstype == xfs
+}
+
+// ResizeFilesystem calls xfs_growfs to grow the filesystem to the size of the underlying device.
+// Unlike resize2fs, xfs_growfs operates on the mount point rather than on the block device.
+func (c *XfsResize) ResizeFilesystem(deviceName, mountPoint string, commandExecutor func(cmd string) (out string, err error)) error {
+	if mountPoint == "" {
+		return fmt.Errorf("could not resize XFS filesystem on %q: mount point is unknown", deviceName)
+	}
+	command := fmt.Sprintf("%s -d %s 2>&1", xfsGrowfs, mountPoint)
+	out, err := commandExecutor(command)
+	if err != nil {
+		return err
+	}
+	return parseXfsGrowfsOutput(out)
+}
+
+// parseXfsGrowfsOutput interprets the output of xfs_growfs. The tool prints the current geometry
+// first and then either reports the new number of data blocks or nothing, if the filesystem
+// already spans the whole device. Errors are prefixed with the program name.
+func parseXfsGrowfsOutput(out string) error {
+	if m := xfsErrorRegexp.FindStringSubmatch(out); m != nil {
+		return fmt.Errorf("xfs_growfs failed: %s", strings.TrimSpace(m[1]))
+	}
+	if m := xfsTooSmallRegexp.FindString(out); m != "" {
+		return fmt.Errorf("xfs_growfs failed: %s", m)
+	}
+	if xfsDataBlocksChangedRegexp.MatchString(out) ||
+		strings.Contains(out, "data size unchanged") ||
+		xfsGeometryRegexp.MatchString(out) {
+		return nil
+	}
+	return fmt.Errorf("unrecognized output: %q, assuming error", out)
+}
@@ -0,0 +1,100 @@
+package filesystems
+
+import (
+	"fmt"
+	"testing"
+)
+
+const xfsGeometry = `meta-data=/dev/nvme1n1           isize=512    agcount=4, agsize=655360 blks
+         =                       sectsz=512   attr=2, projid32bit=1
+         =                       crc=1        finobt=1, sparse=1, rmapbt=0
+         =                       reflink=1    bigtime=1 inobtcount=1
+data     =                       bsize=4096   blocks=2621440, imaxpct=25
+         =                       sunit=0      swidth=0 blks
+naming   =version 2              bsize=4096   ascii-ci=0, ftype=1
+log      =internal log           bsize=4096   blocks=2560, version=2
+         =                       sectsz=512   sunit=0 blks, lazy-count=1
+realtime =none                   extsz=4096   blocks=0, rtextents=0
+`
+
+func TestXfsCanResizeFilesystem(t *testing.T) {
+	resizer := XfsResize{}
+	for fstype, expected := range map[string]bool{"xfs": true, "ext4": false, "btrfs": false, "": false} {
+		if got := resizer.CanResizeFilesystem(fstype); got != expected {
+			t.Errorf("%s: for %q expected %t, got %t", t.Name(), fstype, expected, got)
+		}
+	}
+}
+
+func TestXfsResizeFilesystem(t *testing.T) {
+	var tests = []struct {
+		subtest     string
+		output      string
+		execErr     error
+		expectedErr bool
+	}{
+		{
+			subtest: "grown",
+			output:  xfsGeometry + "data blocks changed from 2621440 to 5242880\n",
+		},
+		{
+			subtest: "already at full size",
+			output:  xfsGeometry,
+		},
+		{
+			subtest: "already at full size, newer xfsprogs",
+			output:  xfsGeometry + "data size unchanged, skipping\n",
+		},
+		{
+			subtest:     "not a mounted XFS filesystem",
+			output:      "xfs_growfs: /home/postgres/pgdata is not a mounted XFS filesystem\n",
+			expectedErr: true,
+		},
+		{
+			subtest:     "ioctl failure after geometry",
+			output:      xfsGeometry + "xfs_growfs: XFS_IOC_FSGROWFSDATA xfsctl failed: No space left on device\n",
+			expectedErr: true,
+		},
+		{
+			subtest:     "shrink attempt",
+			output:      xfsGeometry + "data size 1024 too small, old size is 2621440\n",
+			expectedErr: true,
+		},
+		{
+			subtest:     "unrecognized output",
+			output:      "bash: xfs_growfs: command not found\n",
+			expectedErr: true,
+		},
+		{
+			subtest:     "executor error",
+			execErr:     fmt.Errorf("command terminated with exit code 1"),
+			expectedErr: true,
+		},
+	}
+
+	resizer := XfsResize{}
+	for _, tt := range tests {
+		var command string
+		err := resizer.ResizeFilesystem("/dev/nvme1n1", "/home/postgres/pgdata", func(cmd string) (string, error) {
+			command = cmd
+			return tt.output, tt.execErr
+		})
+		if command != "xfs_growfs -d /home/postgres/pgdata 2>&1" {
+			t.Errorf("%s [%s]: unexpected command %q", t.Name(), tt.subtest, command)
+		}
+		if (err != nil) != tt.expectedErr {
+			t.Errorf("%s [%s]: expected error %t, got %v", t.Name(), tt.subtest, tt.expectedErr, err)
+		}
+	}
+}
+
+func TestXfsResizeFilesystemWithoutMountPoint(t *testing.T) {
+	resizer := XfsResize{}
+	err := resizer.ResizeFilesystem("/dev/nvme1n1", "", func(cmd string) (string, error) {
+		t.Errorf("%s: command %q must not be executed", t.Name(), cmd)
+		return "", nil
+	})
+	if err == nil {
+		t.Errorf("%s: expected an error for an empty mount point", t.Name())
+	}
+}
@@ -64,8 +64,11 @@ type KubernetesClient struct {
 	policyv1.PodDisruptionBudgetsGetter
 	apiextv1.CustomResourceDefinitionsGetter
 	clientbatchv1.CronJobsGetter
+	clientbatchv1.JobsGetter
 	cpov1.OperatorConfigurationsGetter
+	cpov1.PostgresDatabasesGetter
 	cpov1.PostgresTeamsGetter
+	cpov1.PostgresUsersGetter
 	cpov1.PostgresqlsGetter
 	zalandov1.FabricEventStreamsGetter
 
@@ -160,6 +163,7 @@ func NewFromConfig(cfg *rest.Config) (KubernetesClient, error) {
 	kubeClient.RESTClient = client.CoreV1().RESTClient()
 	kubeClient.RoleBindingsGetter = client.RbacV1()
 	kubeClient.CronJobsGetter = client.BatchV1()
+	kubeClient.JobsGetter = client.BatchV1()
 	kubeClient.EventsGetter = client.CoreV1()
 
 	apiextClient, err := apiextclient.NewForConfig(cfg)
@@ -179,7 +183,9 @@ func NewFromConfig(cfg *rest.Config) (KubernetesClient, error) {
 	}
 
 	kubeClient.OperatorConfigurationsGetter = kubeClient.CpoV1ClientSet.CpoV1()
+	kubeClient.PostgresDatabasesGetter = kubeClient.CpoV1ClientSet.CpoV1()
 	kubeClient.PostgresTeamsGetter = kubeClient.CpoV1ClientSet.CpoV1()
+	kubeClient.PostgresUsersGetter = kubeClient.CpoV1ClientSet.CpoV1()
 	kubeClient.PostgresqlsGetter = kubeClient.CpoV1ClientSet.CpoV1()
 	kubeClient.FabricEventStreamsGetter = kubeClient.Zalandov1ClientSet.ZalandoV1()
 
@@ -246,6 +252,113 @@ func (client *KubernetesClient) SetCRDRestoreStatus(clusterName spec.NamespacedN
 	return pg, nil
 }
 
+// patchCRDStatus merges the given fields into the status subresource of the Postgres cluster. A field set to
+// nil is removed from the status.
+func (client *KubernetesClient) patchCRDStatus(clusterName spec.NamespacedName, fields map[string]interface{}) (*apicpov1.Postgresql, error) {
+	var pg *apicpov1.Postgresql
+	patch, err := json.Marshal(struct {
+		PgStatus interface{} `json:"status"`
+	}{fields})
+
+	if err != nil {
+		return pg, fmt.Errorf("could not marshal status: %v", err)
+	}
+
+	pg, err = client.PostgresqlsGetter.Postgresqls(clusterName.Namespace).Patch(
+		context.TODO(), clusterName.Name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+	if err != nil {
+		return pg, fmt.Errorf("could not update status: %v", err)
+	}
+
+	return pg, nil
+}
+
+// statusMapPatch returns the patch value that replaces the previous map of a status field with the current
+// one. A merge patch keeps the keys it does not mention, so keys that are gone are set to null.
+func statusMapPatch[T any](previous, current map[string]T) interface{} {
+	if len(current) == 0 {
+		return nil
+	}
+	patch := make(map[string]interface{}, len(previous)+len(current))
+	for key := range previous {
+		patch[key] = nil
+	}
+	for key, value := range current {
+		patch[key] = value
+	}
+	return patch
+}
+
+// SetCRDReinitializeStatus records the id of the last executed reinitialization request together with
+// its members which have not rejoined the cluster yet and the time it was started
+func (client *KubernetesClient) SetCRDReinitializeStatus(clusterName spec.NamespacedName, reinitializeID string, members []string, started *metav1.Time) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{
+		"ReinitializeID":      reinitializeID,
+		"ReinitializeMembers": members,
+		"ReinitializeStarted": started,
+	})
+}
+
+// SetCRDFailedMembersStatus records the replicas reported failed by Patroni and since when
+func (client *KubernetesClient) SetCRDFailedMembersStatus(clusterName spec.NamespacedName, members []apicpov1.FailedMember) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"FailedMembers": members})
+}
+
+// SetCRDStorageMigrationStatus records the progress of a storage class migration in the status subresource
+func (client *KubernetesClient) SetCRDStorageMigrationStatus(clusterName spec.NamespacedName, migration *apicpov1.StorageMigrationStatus) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"StorageMigration": migration})
+}
+
+// SetCRDSynchronousStandbysStatus records the members of the synchronous or quorum set in the status subresource
+func (client *KubernetesClient) SetCRDSynchronousStandbysStatus(clusterName spec.NamespacedName, members []string) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"SynchronousStandbys": members})
+}
+
+// SetCRDTimelineHistoryStatus records the timeline history of the cluster in the status subresource
+func (client *KubernetesClient) SetCRDTimelineHistoryStatus(clusterName spec.NamespacedName, history []apicpov1.TimelineHistoryEntry) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"TimelineHistory": history})
+}
+
+// SetCRDMissingFailoverSlotsStatus replaces the previously recorded failover slots which are not synchronized
+// to the members
+func (client *KubernetesClient) SetCRDMissingFailoverSlotsStatus(clusterName spec.NamespacedName, previous, missing map[string][]string) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"MissingFailoverSlots": statusMapPatch(previous, missing)})
+}
+
+// SetCRDExtensionsStatus records the installed and available versions of the managed extensions
+func (client *KubernetesClient) SetCRDExtensionsStatus(clusterName spec.NamespacedName, extensions []apicpov1.ExtensionStatus) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"Extensions": extensions})
+}
+
+// SetCRDSubscriptionsStatus of Postgres cluster
+func (client *KubernetesClient) SetCRDSubscriptionsStatus(clusterName spec.NamespacedName, subscriptions []apicpov1.SubscriptionStatus) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"Subscriptions": subscriptions})
+}
+
+// SetCRDPendingDeletionsStatus records the databases and roles waiting for their deletion next to
+// the databases and roles of the manifest, which are deleted once they disappear from it
+func (client *KubernetesClient) SetCRDPendingDeletionsStatus(clusterName spec.NamespacedName, pending []apicpov1.PendingDeletion, databases, roles []string) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{
+		"PendingDeletions": pending,
+		"ManagedDatabases": databases,
+		"ManagedRoles":     roles,
+	})
+}
+
+// SetCRDParametersStatus records the databases and roles whose parameters are set from the
+// manifest, so that their parameters are reset once they disappear from it
+func (client *KubernetesClient) SetCRDParametersStatus(clusterName spec.NamespacedName, databases, roles []string) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{
+		"ParameterDatabases": databases,
+		"ParameterRoles":     roles,
+	})
+}
+
+// SetCRDGrantsStatus of Postgres cluster
+func (client *KubernetesClient) SetCRDGrantsStatus(clusterName spec.NamespacedName, grants []apicpov1.AppliedGrants) (*apicpov1.Postgresql, error) {
+	return client.patchCRDStatus(clusterName, map[string]interface{}{"Grants": grants})
+}
+
 // SamePDB compares the PodDisruptionBudgets
 func SamePDB(cur, new *apipolicyv1.PodDisruptionBudget) (match bool, reason string) {
 	//TODO: improve comparison
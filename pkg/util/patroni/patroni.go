@@ -2,13 +2,16 @@ package patroni
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
@@ -26,8 +29,14 @@ const (
 	statusPath   = "/patroni"
 	restartPath  = "/restart"
 	leaderPath   = "/leader"
+	reinitPath   = "/reinitialize"
+	reloadPath   = "/reload"
+	historyPath  = "/history"
 	ApiPort      = 8008
 	timeout      = 30 * time.Second
+
+	// CertFileEnvVar points Patroni to the certificate of its REST API, the API is served over https when it is set
+	CertFileEnvVar = "PATRONI_RESTAPI_CERTFILE"
 )
 
 // Interface describe patroni methods
@@ -36,17 +45,23 @@ type Interface interface {
 	Switchover(master *v1.Pod, candidate string) error
 	SetPostgresParameters(server *v1.Pod, options map[string]string) error
 	SetStandbyClusterParameters(server *v1.Pod, options map[string]interface{}) error
+	GetHistory(server *v1.Pod) ([]TimelineHistory, error)
 	GetMemberData(server *v1.Pod) (MemberData, error)
 	Restart(server *v1.Pod) error
 	GetConfig(server *v1.Pod) (cpov1.Patroni, map[string]string, error)
 	SetConfig(server *v1.Pod, config map[string]interface{}) error
 	IsLeader(server *v1.Pod) (bool, error)
+	Reinitialize(server *v1.Pod, force bool) error
+	Reload(server *v1.Pod) error
 }
 
 // Patroni API client
 type Patroni struct {
 	httpClient httpclient.HTTPClient
 	logger     *logrus.Entry
+	secure     bool
+	username   string
+	password   string
 }
 
 // New create patroni
@@ -65,6 +80,26 @@ func New(logger *logrus.Entry, client httpclient.HTTPClient) *Patroni {
 	}
 }
 
+// NewSecure create patroni client for a REST API served over TLS. The server certificate is verified with
+// tlsConfig, which also holds the client certificate if Patroni requires one. Username and password are sent
+// with every request when set.
+func NewSecure(logger *logrus.Entry, client httpclient.HTTPClient, tlsConfig *tls.Config, username, password string) *Patroni {
+	if client == nil {
+		client = &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+	}
+
+	return &Patroni{
+		logger:     logger,
+		httpClient: client,
+		secure:     true,
+		username:   username,
+		password:   password,
+	}
+}
+
 func apiURL(masterPod *v1.Pod) (string, error) {
 	ip := net.ParseIP(masterPod.Status.PodIP)
 	if ip == nil {
@@ -80,10 +115,55 @@ func apiURL(masterPod *v1.Pod) (string, error) {
 	return fmt.Sprintf("http://%s", net.JoinHostPort(ip.String(), strconv.Itoa(ApiPort))), nil
 }
 
-func (p *Patroni) httpPostOrPatch(method string, url string, body *bytes.Buffer) (err error) {
+// memberURL returns the REST API address of the pod. Pods started before TLS was enabled
+// keep serving plain http until they are replaced, so the scheme is taken from the pod spec.
+func (p *Patroni) memberURL(server *v1.Pod) (string, error) {
+	url, err := apiURL(server)
+	if err != nil || !p.secure || !servesTLS(server) {
+		return url, err
+	}
+	return "https" + strings.TrimPrefix(url, "http"), nil
+}
+
+func servesTLS(pod *v1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == CertFileEnvVar {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newRequest creates a request to the REST API. The credentials are only sent over https, so they never
+// leave the operator in clear text to members which still serve plain http.
+func (p *Patroni) newRequest(method string, url string, body io.Reader) (*http.Request, error) {
 	request, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return fmt.Errorf("could not create request: %v", err)
+		return nil, fmt.Errorf("could not create request: %v", err)
+	}
+	if p.username != "" && request.URL.Scheme == "https" {
+		request.SetBasicAuth(p.username, p.password)
+	}
+	return request, nil
+}
+
+func (p *Patroni) get(url string) (*http.Response, error) {
+	if p.username == "" {
+		return p.httpClient.Get(url)
+	}
+	request, err := p.newRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.httpClient.Do(request)
+}
+
+func (p *Patroni) httpPostOrPatch(method string, url string, body *bytes.Buffer) (err error) {
+	request, err := p.newRequest(method, url, body)
+	if err != nil {
+		return err
 	}
 
 	if p.logger != nil {
@@ -119,7 +199,7 @@ func (p *Patroni) httpPostOrPatch(method string, url string, body *bytes.Buffer)
 func (p *Patroni) httpGet(url string) (string, error) {
 	p.logger.Debugf("making GET http request: %s", url)
 
-	response, err := p.httpClient.Get(url)
+	response, err := p.get(url)
 	if err != nil {
 		return "", fmt.Errorf("could not make request: %v", err)
 	}
@@ -144,7 +224,7 @@ func (p *Patroni) Switchover(master *v1.Pod, candidate string) error {
 	if err != nil {
 		return fmt.Errorf("could not encode json: %v", err)
 	}
-	apiURLString, err := apiURL(master)
+	apiURLString, err := p.memberURL(master)
 	if err != nil {
 		return err
 	}
@@ -160,7 +240,7 @@ func (p *Patroni) SetPostgresParameters(server *v1.Pod, parameters map[string]st
 	if err != nil {
 		return fmt.Errorf("could not encode json: %v", err)
 	}
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return err
 	}
@@ -179,7 +259,7 @@ func (p *Patroni) SetConfig(server *v1.Pod, config map[string]interface{}) error
 	if err != nil {
 		return fmt.Errorf("could not encode json: %v", err)
 	}
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return err
 	}
@@ -198,6 +278,16 @@ type ClusterMember struct {
 	State    string         `json:"state"`
 	Timeline int            `json:"timeline"`
 	Lag      ReplicationLag `json:"lag,omitempty"`
+	Tags     MemberTags     `json:"tags,omitempty"`
+}
+
+// MemberTags are the Patroni tags of a member that affect failover, replication and load balancing
+type MemberTags struct {
+	NoFailover       bool   `json:"nofailover,omitempty"`
+	NoLoadBalance    bool   `json:"noloadbalance,omitempty"`
+	CloneFrom        bool   `json:"clonefrom,omitempty"`
+	NoSync           bool   `json:"nosync,omitempty"`
+	FailoverPriority *int32 `json:"failover_priority,omitempty"`
 }
 
 type ReplicationLag uint64
@@ -216,6 +306,58 @@ func (rl *ReplicationLag) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// TimelineHistory is an entry of the Patroni history, describing how a timeline ended
+type TimelineHistory struct {
+	Timeline  int
+	SwitchLSN uint64
+	Reason    string
+	Timestamp string
+	NewLeader string
+}
+
+// UnmarshalJSON reads a history entry, which Patroni returns as an array of timeline, switch LSN,
+// reason and, depending on the Patroni version, timestamp and new leader.
+func (h *TimelineHistory) UnmarshalJSON(data []byte) error {
+	var fields []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// LSNs may exceed the precision of float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return err
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("incomplete history entry: %s", data)
+	}
+
+	timeline, ok := fields[0].(json.Number)
+	if !ok {
+		return fmt.Errorf("could not read timeline of history entry: %s", data)
+	}
+	tl, err := strconv.Atoi(timeline.String())
+	if err != nil {
+		return fmt.Errorf("could not read timeline of history entry: %v", err)
+	}
+	lsn, ok := fields[1].(json.Number)
+	if !ok {
+		return fmt.Errorf("could not read switch LSN of history entry: %s", data)
+	}
+	switchLSN, err := strconv.ParseUint(lsn.String(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not read switch LSN of history entry: %v", err)
+	}
+	*h = TimelineHistory{Timeline: tl, SwitchLSN: switchLSN}
+	if len(fields) > 2 {
+		h.Reason, _ = fields[2].(string)
+	}
+	if len(fields) > 3 {
+		h.Timestamp, _ = fields[3].(string)
+	}
+	if len(fields) > 4 {
+		h.NewLeader, _ = fields[4].(string)
+	}
+	return nil
+}
+
 // MemberDataPatroni child element
 type MemberDataPatroni struct {
 	Version string `json:"version"`
@@ -230,6 +372,7 @@ type MemberData struct {
 	PendingRestart  bool              `json:"pending_restart"`
 	ClusterUnlocked bool              `json:"cluster_unlocked"`
 	Patroni         MemberDataPatroni `json:"patroni"`
+	Tags            MemberTags        `json:"tags,omitempty"`
 }
 
 func (p *Patroni) GetConfig(server *v1.Pod) (cpov1.Patroni, map[string]string, error) {
@@ -237,7 +380,7 @@ func (p *Patroni) GetConfig(server *v1.Pod) (cpov1.Patroni, map[string]string, e
 		patroniConfig cpov1.Patroni
 		pgConfig      map[string]interface{}
 	)
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return patroniConfig, nil, err
 	}
@@ -245,16 +388,35 @@ func (p *Patroni) GetConfig(server *v1.Pod) (cpov1.Patroni, map[string]string, e
 	if err != nil {
 		return patroniConfig, nil, err
 	}
-	err = json.Unmarshal([]byte(body), &patroniConfig)
+
+	// unmarshalling postgresql parameters and the synchronous mode needs a detour
+	err = json.Unmarshal([]byte(body), &pgConfig)
 	if err != nil {
 		return patroniConfig, nil, err
 	}
-
-	// unmarshalling postgresql parameters needs a detour
-	err = json.Unmarshal([]byte(body), &pgConfig)
+	// synchronous_mode is a string in quorum mode, but a boolean otherwise
+	synchronousMode, isString := pgConfig["synchronous_mode"].(string)
+	if isString {
+		delete(pgConfig, "synchronous_mode")
+		stripped, err := json.Marshal(pgConfig)
+		if err != nil {
+			return patroniConfig, nil, err
+		}
+		body = string(stripped)
+	}
+	err = json.Unmarshal([]byte(body), &patroniConfig)
 	if err != nil {
 		return patroniConfig, nil, err
 	}
+	if isString {
+		switch strings.ToLower(synchronousMode) {
+		case cpov1.SynchronousReplicationQuorum:
+			patroniConfig.SynchronousMode = true
+			patroniConfig.SynchronousReplication = cpov1.SynchronousReplicationQuorum
+		case "on", "true":
+			patroniConfig.SynchronousMode = true
+		}
+	}
 	pgParameters := make(map[string]string)
 	if _, exists := pgConfig["postgresql"]; exists {
 		effectivePostgresql := pgConfig["postgresql"].(map[string]interface{})
@@ -275,7 +437,7 @@ func (p *Patroni) Restart(server *v1.Pod) error {
 	if err != nil {
 		return fmt.Errorf("could not encode json: %v", err)
 	}
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return err
 	}
@@ -287,10 +449,39 @@ func (p *Patroni) Restart(server *v1.Pod) error {
 	return nil
 }
 
+// Reinitialize method rebuilds the data directory of a replica from the leader via Patroni POST API call.
+// With force the running Postgres is stopped instead of failing the request.
+func (p *Patroni) Reinitialize(server *v1.Pod, force bool) error {
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(map[string]interface{}{"force": force})
+	if err != nil {
+		return fmt.Errorf("could not encode json: %v", err)
+	}
+	apiURLString, err := p.memberURL(server)
+	if err != nil {
+		return err
+	}
+	if err := p.httpPostOrPatch(http.MethodPost, apiURLString+reinitPath, buf); err != nil {
+		return err
+	}
+	p.logger.Infof("reinitialization of member %s has been started", server.Name)
+
+	return nil
+}
+
+// Reload makes Patroni re-read its local configuration file via Patroni POST API call.
+func (p *Patroni) Reload(server *v1.Pod) error {
+	apiURLString, err := p.memberURL(server)
+	if err != nil {
+		return err
+	}
+	return p.httpPostOrPatch(http.MethodPost, apiURLString+reloadPath, &bytes.Buffer{})
+}
+
 // GetClusterMembers read cluster data from patroni API
 func (p *Patroni) GetClusterMembers(server *v1.Pod) ([]ClusterMember, error) {
 
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return []ClusterMember{}, err
 	}
@@ -308,10 +499,28 @@ func (p *Patroni) GetClusterMembers(server *v1.Pod) ([]ClusterMember, error) {
 	return data.Members, nil
 }
 
+// GetHistory returns the timeline history of the cluster from Patroni API
+func (p *Patroni) GetHistory(server *v1.Pod) ([]TimelineHistory, error) {
+	apiURLString, err := p.memberURL(server)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.httpGet(apiURLString + historyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []TimelineHistory
+	if err := json.Unmarshal([]byte(body), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
 // GetMemberData read member data from patroni API
 func (p *Patroni) GetMemberData(server *v1.Pod) (MemberData, error) {
 
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return MemberData{}, err
 	}
@@ -331,12 +540,12 @@ func (p *Patroni) GetMemberData(server *v1.Pod) (MemberData, error) {
 
 // Call leader-Endpoint (expecting statuscode 200 or 503)
 func (p *Patroni) IsLeader(server *v1.Pod) (bool, error) {
-	apiURLString, err := apiURL(server)
+	apiURLString, err := p.memberURL(server)
 	if err != nil {
 		return false, err
 	}
 
-	resp, err := p.httpClient.Get(apiURLString + leaderPath)
+	resp, err := p.get(apiURLString + leaderPath)
 	if err != nil {
 		return false, fmt.Errorf("request failed: %v", err)
 	}
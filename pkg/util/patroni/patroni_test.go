@@ -255,6 +255,62 @@ func TestGetConfig(t *testing.T) {
 	}
 }
 
+func TestGetConfigQuorumMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	configJson := `{"loop_wait": 10, "synchronous_mode": "quorum", "synchronous_node_count": 2, "ttl": 30}`
+	response := http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(configJson))),
+	}
+
+	mockClient := mocks.NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any()).Return(&response, nil)
+
+	p := New(logger, mockClient)
+
+	patroniConfig, _, err := p.GetConfig(newMockPod("192.168.100.1"))
+	if err != nil {
+		t.Fatalf("Could not read Patroni config endpoint: %v", err)
+	}
+	if mode := patroniConfig.SynchronousReplicationMode(); mode != cpov1.SynchronousReplicationQuorum {
+		t.Errorf("expected synchronous replication mode %q, got %q", cpov1.SynchronousReplicationQuorum, mode)
+	}
+	if patroniConfig.SynchronousNodeCount != 2 || patroniConfig.LoopWait != 10 {
+		t.Errorf("Patroni config not read completely: %#v", patroniConfig)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	historyJson := `[[1, 25623960, "no recovery target specified", "2019-09-23T16:57:57+02:00"], [2, 18446744073709551615, "failover"], [3, 50331800, "switchover", "2019-09-24T10:00:00+02:00", "acid-test-cluster-1"]]`
+	expectedHistory := []TimelineHistory{
+		{Timeline: 1, SwitchLSN: 25623960, Reason: "no recovery target specified", Timestamp: "2019-09-23T16:57:57+02:00"},
+		{Timeline: 2, SwitchLSN: 18446744073709551615, Reason: "failover"},
+		{Timeline: 3, SwitchLSN: 50331800, Reason: "switchover", Timestamp: "2019-09-24T10:00:00+02:00", NewLeader: "acid-test-cluster-1"},
+	}
+	response := http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(historyJson))),
+	}
+
+	mockClient := mocks.NewMockHTTPClient(ctrl)
+	mockClient.EXPECT().Get(gomock.Any()).Return(&response, nil)
+
+	p := New(logger, mockClient)
+
+	history, err := p.GetHistory(newMockPod("192.168.100.1"))
+	if err != nil {
+		t.Fatalf("Could not read Patroni history endpoint: %v", err)
+	}
+	if !reflect.DeepEqual(expectedHistory, history) {
+		t.Errorf("Patroni history differs: expected: %#v, got: %#v", expectedHistory, history)
+	}
+}
+
 func TestSetPostgresParameters(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -283,3 +339,79 @@ func TestSetPostgresParameters(t *testing.T) {
 	}
 
 }
+
+func TestReinitialize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		statusCode  int
+		body        string
+		expectError bool
+	}{
+		{http.StatusOK, "reinitialize started", false},
+		{http.StatusServiceUnavailable, "I am the leader, can not reinitialize", true},
+	}
+
+	for _, tt := range tests {
+		response := http.Response{
+			StatusCode: tt.statusCode,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(tt.body))),
+		}
+
+		mockClient := mocks.NewMockHTTPClient(ctrl)
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			body, _ := ioutil.ReadAll(req.Body)
+			if req.Method != http.MethodPost || req.URL.String() != "http://192.168.100.1:8008/reinitialize" || string(body) != "{\"force\":true}\n" {
+				t.Errorf("%s: unexpected request %s %s %s", t.Name(), req.Method, req.URL, body)
+			}
+			return &response, nil
+		})
+
+		p := New(logger, mockClient)
+		err := p.Reinitialize(newMockPod("192.168.100.1"), true)
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s: unexpected error for status %d: %v", t.Name(), tt.statusCode, err)
+		}
+	}
+}
+
+func TestSecureRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tlsPod := newMockPod("192.168.100.1")
+	tlsPod.Spec.Containers = []v1.Container{{Env: []v1.EnvVar{{Name: CertFileEnvVar, Value: "/run/patroni-api/tls.crt"}}}}
+
+	tests := []struct {
+		pod           *v1.Pod
+		expectedURL   string
+		authenticated bool
+	}{
+		{tlsPod, "https://192.168.100.1:8008/cluster", true},
+		// not restarted since TLS has been enabled, credentials must not be sent in clear text
+		{newMockPod("192.168.100.2"), "http://192.168.100.2:8008/cluster", false},
+	}
+
+	for _, tt := range tests {
+		mockClient := mocks.NewMockHTTPClient(ctrl)
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			if req.URL.String() != tt.expectedURL {
+				t.Errorf("%s: expected request to %s, got %s", t.Name(), tt.expectedURL, req.URL)
+			}
+			username, password, ok := req.BasicAuth()
+			if tt.authenticated && (!ok || username != "patroni" || password != "secret") {
+				t.Errorf("%s: request to %s is not authenticated", t.Name(), req.URL)
+			}
+			if !tt.authenticated && ok {
+				t.Errorf("%s: request to %s sends credentials over http", t.Name(), req.URL)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader([]byte(`{"members": []}`)))}, nil
+		})
+
+		p := NewSecure(logger, mockClient, nil, "patroni", "secret")
+		if _, err := p.GetClusterMembers(tt.pod); err != nil {
+			t.Errorf("%s: unexpected error: %v", t.Name(), err)
+		}
+	}
+}
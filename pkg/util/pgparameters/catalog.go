@@ -0,0 +1,508 @@
+package pgparameters
+
+import "math"
+
+const (
+	maxInt  = math.MaxInt32
+	maxReal = math.MaxFloat64
+)
+
+var (
+	boolAliases  = []string{"true", "false", "yes", "no", "1", "0"}
+	messageLevel = []string{"debug5", "debug4", "debug3", "debug2", "debug1", "info", "notice", "warning", "error", "log", "fatal", "panic"}
+	clientLevel  = []string{"debug5", "debug4", "debug3", "debug2", "debug1", "log", "notice", "warning", "error", "info"}
+)
+
+func boolean(name string, context Context) Parameter {
+	return Parameter{Name: name, Type: TypeBool, Context: context}
+}
+
+func integer(name string, context Context, unit string, lower, upper float64) Parameter {
+	return Parameter{Name: name, Type: TypeInteger, Context: context, Unit: unit, Min: lower, Max: upper}
+}
+
+func float(name string, context Context, unit string, lower, upper float64) Parameter {
+	return Parameter{Name: name, Type: TypeReal, Context: context, Unit: unit, Min: lower, Max: upper}
+}
+
+func str(name string, context Context) Parameter {
+	return Parameter{Name: name, Type: TypeString, Context: context}
+}
+
+func enum(name string, context Context, values ...string) Parameter {
+	return Parameter{Name: name, Type: TypeEnum, Context: context, EnumValues: values}
+}
+
+func internal(name string) Parameter {
+	return Parameter{Name: name, Type: TypeString, Context: ContextInternal}
+}
+
+func (p Parameter) since(version int) Parameter {
+	p.MinVersion = version
+	return p
+}
+
+func (p Parameter) until(version int) Parameter {
+	p.MaxVersion = version
+	return p
+}
+
+func withAliases(values ...string) []string {
+	return append(values, boolAliases...)
+}
+
+// parameters lists the configuration parameters of PostgreSQL 13 and later. A parameter whose
+// definition changed between major versions has one entry per definition.
+var parameters = []Parameter{
+	// file locations
+	str("config_file", ContextPostmaster),
+	str("data_directory", ContextPostmaster),
+	str("external_pid_file", ContextPostmaster),
+	str("hba_file", ContextPostmaster),
+	str("ident_file", ContextPostmaster),
+
+	// connections and authentication
+	integer("authentication_timeout", ContextSighup, "s", 1, 600),
+	boolean("bonjour", ContextPostmaster),
+	str("bonjour_name", ContextPostmaster),
+	integer("client_connection_check_interval", ContextUser, "ms", 0, maxInt).since(14),
+	boolean("db_user_namespace", ContextSighup).until(16),
+	boolean("gss_accept_delegation", ContextSighup).since(16),
+	boolean("krb_caseins_users", ContextSighup),
+	str("krb_server_keyfile", ContextSighup),
+	str("listen_addresses", ContextPostmaster),
+	integer("max_connections", ContextPostmaster, "", 1, 262143),
+	boolean("md5_password_warnings", ContextUser).since(18),
+	str("oauth_validator_libraries", ContextSighup).since(18),
+	enum("password_encryption", ContextUser, "md5", "scram-sha-256", "on", "off").until(13),
+	enum("password_encryption", ContextUser, "md5", "scram-sha-256").since(14),
+	integer("port", ContextPostmaster, "", 1, 65535),
+	integer("reserved_connections", ContextPostmaster, "", 0, 262143).since(16),
+	integer("scram_iterations", ContextUser, "", 1, maxInt).since(16),
+	integer("superuser_reserved_connections", ContextPostmaster, "", 0, 262143),
+	integer("tcp_keepalives_count", ContextUser, "", 0, maxInt),
+	integer("tcp_keepalives_idle", ContextUser, "s", 0, maxInt),
+	integer("tcp_keepalives_interval", ContextUser, "s", 0, maxInt),
+	integer("tcp_user_timeout", ContextUser, "ms", 0, maxInt),
+	str("unix_socket_directories", ContextPostmaster),
+	str("unix_socket_group", ContextPostmaster),
+	integer("unix_socket_permissions", ContextPostmaster, "", 0, 511),
+
+	// ssl
+	boolean("ssl", ContextSighup),
+	str("ssl_ca_file", ContextSighup),
+	str("ssl_cert_file", ContextSighup),
+	str("ssl_ciphers", ContextSighup),
+	str("ssl_crl_dir", ContextSighup).since(14),
+	str("ssl_crl_file", ContextSighup),
+	str("ssl_dh_params_file", ContextSighup),
+	str("ssl_ecdh_curve", ContextSighup),
+	str("ssl_groups", ContextSighup).since(18),
+	str("ssl_key_file", ContextSighup),
+	enum("ssl_max_protocol_version", ContextSighup, "", "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"),
+	enum("ssl_min_protocol_version", ContextSighup, "TLSv1", "TLSv1.1", "TLSv1.2", "TLSv1.3"),
+	str("ssl_passphrase_command", ContextSighup),
+	boolean("ssl_passphrase_command_supports_reload", ContextSighup),
+	boolean("ssl_prefer_server_ciphers", ContextSighup),
+	str("ssl_tls13_ciphers", ContextSighup).since(18),
+
+	// resource usage
+	integer("autovacuum_work_mem", ContextSighup, "kB", -1, maxInt),
+	integer("backend_flush_after", ContextUser, "8kB", 0, 256),
+	integer("bgwriter_delay", ContextSighup, "ms", 10, 10000),
+	integer("bgwriter_flush_after", ContextSighup, "8kB", 0, 256),
+	integer("bgwriter_lru_maxpages", ContextSighup, "", 0, 1073741823),
+	float("bgwriter_lru_multiplier", ContextSighup, "", 0, 10),
+	integer("commit_timestamp_buffers", ContextPostmaster, "8kB", 0, 131072).since(17),
+	enum("dynamic_shared_memory_type", ContextPostmaster, "posix", "sysv", "windows", "mmap"),
+	integer("effective_io_concurrency", ContextUser, "", 0, 1000),
+	enum("file_copy_method", ContextUser, "copy", "clone").since(18),
+	float("hash_mem_multiplier", ContextUser, "", 1, 1000),
+	integer("huge_page_size", ContextPostmaster, "kB", 0, maxInt).since(14),
+	enum("huge_pages", ContextPostmaster, withAliases("off", "on", "try")...),
+	integer("io_combine_limit", ContextUser, "8kB", 1, 32).since(17).until(17),
+	integer("io_combine_limit", ContextUser, "8kB", 1, 128).since(18),
+	integer("io_max_combine_limit", ContextPostmaster, "8kB", 1, 128).since(18),
+	integer("io_max_concurrency", ContextPostmaster, "", -1, 1024).since(18),
+	enum("io_method", ContextPostmaster, "worker", "sync", "io_uring").since(18),
+	integer("io_workers", ContextSighup, "", 1, 32).since(18),
+	integer("logical_decoding_work_mem", ContextUser, "kB", 64, maxInt),
+	integer("maintenance_io_concurrency", ContextUser, "", 0, 1000),
+	integer("maintenance_work_mem", ContextUser, "kB", 1024, maxInt),
+	integer("max_files_per_process", ContextPostmaster, "", 25, maxInt),
+	integer("max_notify_queue_pages", ContextPostmaster, "", 64, maxInt).since(17),
+	integer("max_parallel_maintenance_workers", ContextUser, "", 0, 1024),
+	integer("max_parallel_workers", ContextUser, "", 0, 1024),
+	integer("max_parallel_workers_per_gather", ContextUser, "", 0, 1024),
+	integer("max_prepared_transactions", ContextPostmaster, "", 0, 262143),
+	integer("max_stack_depth", ContextSuperuser, "kB", 100, maxInt),
+	integer("max_worker_processes", ContextPostmaster, "", 0, 262143),
+	integer("min_dynamic_shared_memory", ContextPostmaster, "MB", 0, maxInt).since(14),
+	integer("multixact_member_buffers", ContextPostmaster, "8kB", 16, 131072).since(17),
+	integer("multixact_offset_buffers", ContextPostmaster, "8kB", 16, 131072).since(17),
+	integer("notify_buffers", ContextPostmaster, "8kB", 16, 131072).since(17),
+	integer("old_snapshot_threshold", ContextPostmaster, "min", -1, 86400).until(16),
+	boolean("parallel_leader_participation", ContextUser),
+	integer("serializable_buffers", ContextPostmaster, "8kB", 16, 131072).since(17),
+	integer("shared_buffers", ContextPostmaster, "8kB", 16, 1073741823),
+	enum("shared_memory_type", ContextPostmaster, "mmap", "sysv", "windows"),
+	integer("subtransaction_buffers", ContextPostmaster, "8kB", 0, 131072).since(17),
+	integer("temp_buffers", ContextUser, "8kB", 100, 1073741823),
+	integer("temp_file_limit", ContextSuperuser, "kB", -1, maxInt),
+	integer("transaction_buffers", ContextPostmaster, "8kB", 0, 131072).since(17),
+	integer("vacuum_buffer_usage_limit", ContextUser, "kB", 0, 16777216).since(16),
+	float("vacuum_cost_delay", ContextUser, "ms", 0, 100),
+	integer("vacuum_cost_limit", ContextUser, "", 1, 10000),
+	integer("vacuum_cost_page_dirty", ContextUser, "", 0, 10000),
+	integer("vacuum_cost_page_hit", ContextUser, "", 0, 10000),
+	integer("vacuum_cost_page_miss", ContextUser, "", 0, 10000),
+	integer("work_mem", ContextUser, "kB", 64, maxInt),
+
+	// write-ahead log and archiving
+	str("archive_cleanup_command", ContextSighup),
+	str("archive_command", ContextSighup),
+	str("archive_library", ContextSighup).since(15),
+	enum("archive_mode", ContextPostmaster, withAliases("always", "on", "off")...),
+	integer("archive_timeout", ContextSighup, "s", 0, 1073741823),
+	float("checkpoint_completion_target", ContextSighup, "", 0, 1),
+	integer("checkpoint_flush_after", ContextSighup, "8kB", 0, 256),
+	integer("checkpoint_timeout", ContextSighup, "s", 30, 86400),
+	integer("checkpoint_warning", ContextSighup, "s", 0, maxInt),
+	integer("commit_delay", ContextSuperuser, "", 0, 100000),
+	integer("commit_siblings", ContextUser, "", 0, 1000),
+	boolean("fsync", ContextSighup),
+	boolean("full_page_writes", ContextSighup),
+	integer("max_wal_size", ContextSighup, "MB", 2, maxInt),
+	integer("min_wal_size", ContextSighup, "MB", 2, maxInt),
+	str("recovery_end_command", ContextSighup),
+	enum("recovery_init_sync_method", ContextSighup, "fsync", "syncfs").since(14),
+	integer("recovery_min_apply_delay", ContextSighup, "ms", 0, maxInt),
+	enum("recovery_prefetch", ContextSighup, withAliases("off", "on", "try")...).since(15),
+	str("recovery_target", ContextPostmaster),
+	enum("recovery_target_action", ContextPostmaster, "pause", "promote", "shutdown"),
+	boolean("recovery_target_inclusive", ContextPostmaster),
+	str("recovery_target_lsn", ContextPostmaster),
+	str("recovery_target_name", ContextPostmaster),
+	str("recovery_target_time", ContextPostmaster),
+	str("recovery_target_timeline", ContextPostmaster),
+	str("recovery_target_xid", ContextPostmaster),
+	str("restore_command", ContextPostmaster).until(13),
+	str("restore_command", ContextSighup).since(14),
+	boolean("summarize_wal", ContextSighup).since(17),
+	enum("synchronous_commit", ContextUser, withAliases("local", "remote_write", "remote_apply", "on", "off")...),
+	integer("wal_buffers", ContextPostmaster, "8kB", -1, 262143),
+	boolean("wal_compression", ContextSuperuser).until(14),
+	enum("wal_compression", ContextSuperuser, withAliases("pglz", "lz4", "zstd", "on", "off")...).since(15),
+	str("wal_consistency_checking", ContextSuperuser),
+	integer("wal_decode_buffer_size", ContextPostmaster, "B", 65536, 1073741823).since(15),
+	boolean("wal_init_zero", ContextSuperuser),
+	enum("wal_level", ContextPostmaster, "minimal", "replica", "logical", "archive", "hot_standby"),
+	boolean("wal_log_hints", ContextPostmaster),
+	boolean("wal_recycle", ContextSuperuser),
+	integer("wal_skip_threshold", ContextUser, "kB", 0, maxInt),
+	integer("wal_summary_keep_time", ContextSighup, "min", 0, 35791394).since(17),
+	enum("wal_sync_method", ContextSighup, "fsync", "fdatasync", "open_sync", "open_datasync", "fsync_writethrough"),
+	integer("wal_writer_delay", ContextSighup, "ms", 1, 10000),
+	integer("wal_writer_flush_after", ContextSighup, "8kB", 0, maxInt),
+
+	// replication
+	boolean("hot_standby", ContextPostmaster),
+	boolean("hot_standby_feedback", ContextSighup),
+	integer("idle_replication_slot_timeout", ContextSighup, "s", 0, maxInt).since(18),
+	integer("max_active_replication_origins", ContextPostmaster, "", 0, 262143).since(18),
+	integer("max_logical_replication_workers", ContextPostmaster, "", 0, 262143),
+	integer("max_parallel_apply_workers_per_subscription", ContextSighup, "", 0, 1024).since(16),
+	integer("max_replication_slots", ContextPostmaster, "", 0, 262143),
+	integer("max_slot_wal_keep_size", ContextSighup, "MB", -1, maxInt),
+	integer("max_standby_archive_delay", ContextSighup, "ms", -1, maxInt),
+	integer("max_standby_streaming_delay", ContextSighup, "ms", -1, maxInt),
+	integer("max_sync_workers_per_subscription", ContextSighup, "", 0, 262143),
+	integer("max_wal_senders", ContextPostmaster, "", 0, 262143),
+	str("primary_conninfo", ContextSighup),
+	str("primary_slot_name", ContextSighup),
+	str("promote_trigger_file", ContextSighup).until(15),
+	boolean("sync_replication_slots", ContextSighup).since(17),
+	str("synchronized_standby_slots", ContextSighup).since(17),
+	str("synchronous_standby_names", ContextSighup),
+	boolean("track_commit_timestamp", ContextPostmaster),
+	integer("vacuum_defer_cleanup_age", ContextSighup, "", 0, 1000000).until(15),
+	integer("wal_keep_size", ContextSighup, "MB", 0, maxInt),
+	boolean("wal_receiver_create_temp_slot", ContextSighup),
+	integer("wal_receiver_status_interval", ContextSighup, "s", 0, 2147483),
+	integer("wal_receiver_timeout", ContextSighup, "ms", 0, maxInt),
+	integer("wal_retrieve_retry_interval", ContextSighup, "ms", 1, maxInt),
+	integer("wal_sender_timeout", ContextUser, "ms", 0, maxInt),
+
+	// query tuning
+	enum("constraint_exclusion", ContextUser, withAliases("partition", "on", "off")...),
+	float("cpu_index_tuple_cost", ContextUser, "", 0, maxReal),
+	float("cpu_operator_cost", ContextUser, "", 0, maxReal),
+	float("cpu_tuple_cost", ContextUser, "", 0, maxReal),
+	float("cursor_tuple_fraction", ContextUser, "", 0, 1),
+	enum("debug_parallel_query", ContextUser, withAliases("off", "on", "regress")...).since(16),
+	integer("default_statistics_target", ContextUser, "", 1, 10000),
+	integer("effective_cache_size", ContextUser, "8kB", 1, maxInt),
+	boolean("enable_async_append", ContextUser).since(14),
+	boolean("enable_bitmapscan", ContextUser),
+	boolean("enable_distinct_reordering", ContextUser).since(18),
+	boolean("enable_gathermerge", ContextUser),
+	boolean("enable_group_by_reordering", ContextUser).since(17),
+	boolean("enable_hashagg", ContextUser),
+	boolean("enable_hashjoin", ContextUser),
+	boolean("enable_incremental_sort", ContextUser),
+	boolean("enable_indexonlyscan", ContextUser),
+	boolean("enable_indexscan", ContextUser),
+	boolean("enable_material", ContextUser),
+	boolean("enable_memoize", ContextUser).since(14),
+	boolean("enable_mergejoin", ContextUser),
+	boolean("enable_nestloop", ContextUser),
+	boolean("enable_parallel_append", ContextUser),
+	boolean("enable_parallel_hash", ContextUser),
+	boolean("enable_partition_pruning", ContextUser),
+	boolean("enable_partitionwise_aggregate", ContextUser),
+	boolean("enable_partitionwise_join", ContextUser),
+	boolean("enable_presorted_aggregate", ContextUser).since(16),
+	boolean("enable_self_join_elimination", ContextUser).since(18),
+	boolean("enable_seqscan", ContextUser),
+	boolean("enable_sort", ContextUser),
+	boolean("enable_tidscan", ContextUser),
+	enum("force_parallel_mode", ContextUser, withAliases("off", "on", "regress")...).until(15),
+	integer("from_collapse_limit", ContextUser, "", 1, maxInt),
+	boolean("geqo", ContextUser),
+	integer("geqo_effort", ContextUser, "", 1, 10),
+	integer("geqo_generations", ContextUser, "", 0, maxInt),
+	integer("geqo_pool_size", ContextUser, "", 0, maxInt),
+	float("geqo_seed", ContextUser, "", 0, 1),
+	float("geqo_selection_bias", ContextUser, "", 1.5, 2),
+	integer("geqo_threshold", ContextUser, "", 2, maxInt),
+	boolean("jit", ContextUser),
+	float("jit_above_cost", ContextUser, "", -1, maxReal),
+	float("jit_inline_above_cost", ContextUser, "", -1, maxReal),
+	float("jit_optimize_above_cost", ContextUser, "", -1, maxReal),
+	integer("join_collapse_limit", ContextUser, "", 1, maxInt),
+	integer("min_parallel_index_scan_size", ContextUser, "8kB", 0, 715827882),
+	integer("min_parallel_table_scan_size", ContextUser, "8kB", 0, 715827882),
+	float("parallel_setup_cost", ContextUser, "", 0, maxReal),
+	float("parallel_tuple_cost", ContextUser, "", 0, maxReal),
+	enum("plan_cache_mode", ContextUser, "auto", "force_generic_plan", "force_custom_plan"),
+	float("random_page_cost", ContextUser, "", 0, maxReal),
+	float("recursive_worktable_factor", ContextUser, "", 0.001, 1000000).since(15),
+	float("seq_page_cost", ContextUser, "", 0, maxReal),
+
+	// reporting and logging
+	str("backtrace_functions", ContextSuperuser),
+	enum("client_min_messages", ContextUser, clientLevel...),
+	str("cluster_name", ContextPostmaster),
+	boolean("debug_pretty_print", ContextUser),
+	boolean("debug_print_parse", ContextUser),
+	boolean("debug_print_plan", ContextUser),
+	boolean("debug_print_rewritten", ContextUser),
+	str("event_source", ContextPostmaster),
+	integer("log_autovacuum_min_duration", ContextSighup, "ms", -1, maxInt),
+	boolean("log_checkpoints", ContextSighup),
+	boolean("log_connections", ContextSuperuserBackend).until(17),
+	str("log_connections", ContextSuperuserBackend).since(18),
+	str("log_destination", ContextSighup),
+	str("log_directory", ContextSighup),
+	boolean("log_disconnections", ContextSuperuserBackend),
+	boolean("log_duration", ContextSuperuser),
+	enum("log_error_verbosity", ContextSuperuser, "terse", "default", "verbose"),
+	boolean("log_executor_stats", ContextSuperuser),
+	integer("log_file_mode", ContextSighup, "", 0, 511),
+	str("log_filename", ContextSighup),
+	boolean("log_hostname", ContextSighup),
+	str("log_line_prefix", ContextSighup),
+	boolean("log_lock_failures", ContextSuperuser).since(18),
+	boolean("log_lock_waits", ContextSuperuser),
+	integer("log_min_duration_sample", ContextSuperuser, "ms", -1, maxInt),
+	integer("log_min_duration_statement", ContextSuperuser, "ms", -1, maxInt),
+	enum("log_min_error_statement", ContextSuperuser, messageLevel...),
+	enum("log_min_messages", ContextSuperuser, messageLevel...),
+	integer("log_parameter_max_length", ContextSuperuser, "B", -1, 1073741823),
+	integer("log_parameter_max_length_on_error", ContextUser, "B", -1, 1073741823),
+	boolean("log_parser_stats", ContextSuperuser),
+	boolean("log_planner_stats", ContextSuperuser),
+	boolean("log_recovery_conflict_waits", ContextSighup).since(14),
+	boolean("log_replication_commands", ContextSuperuser),
+	integer("log_rotation_age", ContextSighup, "min", 0, 35791394),
+	integer("log_rotation_size", ContextSighup, "kB", 0, 2097151),
+	integer("log_startup_progress_interval", ContextSighup, "ms", 0, maxInt).since(15),
+	enum("log_statement", ContextSuperuser, "none", "ddl", "mod", "all"),
+	float("log_statement_sample_rate", ContextSuperuser, "", 0, 1),
+	boolean("log_statement_stats", ContextSuperuser),
+	integer("log_temp_files", ContextSuperuser, "kB", -1, maxInt),
+	str("log_timezone", ContextSighup),
+	float("log_transaction_sample_rate", ContextSuperuser, "", 0, 1),
+	boolean("log_truncate_on_rotation", ContextSighup),
+	boolean("logging_collector", ContextPostmaster),
+	enum("syslog_facility", ContextSighup, "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"),
+	str("syslog_ident", ContextSighup),
+	boolean("syslog_sequence_numbers", ContextSighup),
+	boolean("syslog_split_messages", ContextSighup),
+	boolean("update_process_title", ContextSuperuser),
+
+	// statistics
+	enum("compute_query_id", ContextSuperuser, withAliases("auto", "regress", "on", "off")...).since(14),
+	enum("stats_fetch_consistency", ContextUser, "none", "cache", "snapshot").since(15),
+	str("stats_temp_directory", ContextSighup).until(14),
+	boolean("track_activities", ContextSuperuser),
+	integer("track_activity_query_size", ContextPostmaster, "B", 100, 1048576),
+	boolean("track_cost_delay_timing", ContextSuperuser).since(18),
+	boolean("track_counts", ContextSuperuser),
+	enum("track_functions", ContextSuperuser, "none", "pl", "all"),
+	boolean("track_io_timing", ContextSuperuser),
+	boolean("track_wal_io_timing", ContextSuperuser).since(14),
+
+	// autovacuum
+	boolean("autovacuum", ContextSighup),
+	float("autovacuum_analyze_scale_factor", ContextSighup, "", 0, 100),
+	integer("autovacuum_analyze_threshold", ContextSighup, "", 0, maxInt),
+	integer("autovacuum_freeze_max_age", ContextPostmaster, "", 100000, 2000000000),
+	integer("autovacuum_max_workers", ContextPostmaster, "", 1, 262143).until(17),
+	integer("autovacuum_max_workers", ContextSighup, "", 1, 262143).since(18),
+	integer("autovacuum_multixact_freeze_max_age", ContextPostmaster, "", 10000, 2000000000),
+	integer("autovacuum_naptime", ContextSighup, "s", 1, 2147483),
+	float("autovacuum_vacuum_cost_delay", ContextSighup, "ms", -1, 100),
+	integer("autovacuum_vacuum_cost_limit", ContextSighup, "", -1, 10000),
+	float("autovacuum_vacuum_insert_scale_factor", ContextSighup, "", 0, 100),
+	integer("autovacuum_vacuum_insert_threshold", ContextSighup, "", -1, maxInt),
+	integer("autovacuum_vacuum_max_threshold", ContextSighup, "", -1, maxInt).since(18),
+	float("autovacuum_vacuum_scale_factor", ContextSighup, "", 0, 100),
+	integer("autovacuum_vacuum_threshold", ContextSighup, "", 0, maxInt),
+	integer("autovacuum_worker_slots", ContextPostmaster, "", 1, 262143).since(18),
+
+	// client connection defaults
+	enum("bytea_output", ContextUser, "escape", "hex"),
+	boolean("check_function_bodies", ContextUser),
+	str("client_encoding", ContextUser),
+	str("createrole_self_grant", ContextUser).since(16),
+	str("datestyle", ContextUser),
+	str("default_table_access_method", ContextUser),
+	str("default_tablespace", ContextUser),
+	str("default_text_search_config", ContextUser),
+	enum("default_toast_compression", ContextUser, "pglz", "lz4").since(14),
+	boolean("default_transaction_deferrable", ContextUser),
+	enum("default_transaction_isolation", ContextUser, "serializable", "repeatable read", "read committed", "read uncommitted"),
+	boolean("default_transaction_read_only", ContextUser),
+	str("dynamic_library_path", ContextSuperuser),
+	boolean("event_triggers", ContextSuperuser).since(17),
+	str("extension_control_path", ContextSuperuser).since(18),
+	integer("extra_float_digits", ContextUser, "", -15, 3),
+	integer("gin_fuzzy_search_limit", ContextUser, "", 0, maxInt),
+	integer("gin_pending_list_limit", ContextUser, "kB", 64, maxInt),
+	enum("icu_validation_level", ContextUser, append([]string{"disabled"}, clientLevel...)...).since(16),
+	integer("idle_in_transaction_session_timeout", ContextUser, "ms", 0, maxInt),
+	integer("idle_session_timeout", ContextUser, "ms", 0, maxInt).since(14),
+	enum("intervalstyle", ContextUser, "postgres", "postgres_verbose", "sql_standard", "iso_8601"),
+	str("jit_provider", ContextPostmaster),
+	str("lc_messages", ContextSuperuser),
+	str("lc_monetary", ContextUser),
+	str("lc_numeric", ContextUser),
+	str("lc_time", ContextUser),
+	str("local_preload_libraries", ContextUser),
+	integer("lock_timeout", ContextUser, "ms", 0, maxInt),
+	str("restrict_nonsystem_relation_kind", ContextUser),
+	boolean("row_security", ContextUser),
+	str("search_path", ContextUser),
+	str("session_preload_libraries", ContextSuperuser),
+	enum("session_replication_role", ContextSuperuser, "origin", "replica", "local"),
+	str("shared_preload_libraries", ContextPostmaster),
+	integer("statement_timeout", ContextUser, "ms", 0, maxInt),
+	str("temp_tablespaces", ContextUser),
+	str("timezone", ContextUser),
+	str("timezone_abbreviations", ContextUser),
+	integer("transaction_timeout", ContextUser, "ms", 0, maxInt).since(17),
+	float("vacuum_cleanup_index_scale_factor", ContextUser, "", 0, 1e10).until(13),
+	integer("vacuum_failsafe_age", ContextUser, "", 0, 2100000000).since(14),
+	integer("vacuum_freeze_min_age", ContextUser, "", 0, 1000000000),
+	integer("vacuum_freeze_table_age", ContextUser, "", 0, 2000000000),
+	float("vacuum_max_eager_freeze_failure_rate", ContextUser, "", 0, 1).since(18),
+	integer("vacuum_multixact_failsafe_age", ContextUser, "", 0, 2100000000).since(14),
+	integer("vacuum_multixact_freeze_min_age", ContextUser, "", 0, 1000000000),
+	integer("vacuum_multixact_freeze_table_age", ContextUser, "", 0, 2000000000),
+	boolean("vacuum_truncate", ContextUser).since(18),
+	enum("xmlbinary", ContextUser, "base64", "hex"),
+	enum("xmloption", ContextUser, "content", "document"),
+
+	// lock management
+	integer("deadlock_timeout", ContextSuperuser, "ms", 1, maxInt),
+	integer("max_locks_per_transaction", ContextPostmaster, "", 10, maxInt),
+	integer("max_pred_locks_per_page", ContextSighup, "", 0, maxInt),
+	integer("max_pred_locks_per_relation", ContextSighup, "", math.MinInt32, maxInt),
+	integer("max_pred_locks_per_transaction", ContextPostmaster, "", 10, maxInt),
+
+	// version and platform compatibility
+	boolean("array_nulls", ContextUser),
+	enum("backslash_quote", ContextUser, withAliases("safe_encoding", "on", "off")...),
+	boolean("escape_string_warning", ContextUser),
+	boolean("lo_compat_privileges", ContextSuperuser),
+	boolean("operator_precedence_warning", ContextUser).until(13),
+	boolean("quote_all_identifiers", ContextUser),
+	boolean("standard_conforming_strings", ContextUser),
+	boolean("synchronize_seqscans", ContextUser),
+	boolean("transform_null_equals", ContextUser),
+
+	// error handling
+	boolean("data_sync_retry", ContextPostmaster),
+	boolean("exit_on_error", ContextUser),
+	boolean("remove_temp_files_after_crash", ContextSighup).since(14),
+	boolean("restart_after_crash", ContextSighup),
+
+	// developer options
+	boolean("allow_alter_system", ContextSighup).since(17),
+	boolean("allow_system_table_mods", ContextSuperuser),
+	str("application_name", ContextUser),
+	integer("debug_discard_caches", ContextSuperuser, "", 0, 5).since(14),
+	str("debug_io_direct", ContextPostmaster).since(16),
+	enum("debug_logical_replication_streaming", ContextUser, "buffered", "immediate").since(16),
+	boolean("ignore_checksum_failure", ContextSuperuser),
+	boolean("ignore_invalid_pages", ContextPostmaster),
+	boolean("ignore_system_indexes", ContextBackend),
+	boolean("jit_debugging_support", ContextSuperuserBackend),
+	boolean("jit_dump_bitcode", ContextSuperuser),
+	boolean("jit_expressions", ContextUser),
+	boolean("jit_profiling_support", ContextSuperuserBackend),
+	boolean("jit_tuple_deforming", ContextUser),
+	integer("post_auth_delay", ContextBackend, "s", 0, 2147),
+	integer("pre_auth_delay", ContextSighup, "s", 0, 60),
+	boolean("send_abort_for_crash", ContextSighup).since(16),
+	boolean("send_abort_for_kill", ContextSighup).since(16),
+	boolean("trace_connection_negotiation", ContextPostmaster).since(17),
+	boolean("trace_notify", ContextUser),
+	enum("trace_recovery_messages", ContextSighup, clientLevel...).until(16),
+	boolean("trace_sort", ContextUser),
+	boolean("zero_damaged_pages", ContextSuperuser),
+
+	// preset options
+	internal("block_size"),
+	internal("data_checksums"),
+	internal("data_directory_mode"),
+	internal("debug_assertions"),
+	internal("huge_pages_status").since(17),
+	internal("in_hot_standby").since(14),
+	internal("integer_datetimes"),
+	internal("lc_collate").until(15),
+	internal("lc_ctype").until(15),
+	internal("max_function_args"),
+	internal("max_identifier_length"),
+	internal("max_index_keys"),
+	internal("num_os_semaphores").since(18),
+	internal("segment_size"),
+	internal("server_encoding"),
+	internal("server_version"),
+	internal("server_version_num"),
+	internal("shared_memory_size").since(15),
+	internal("shared_memory_size_in_huge_pages").since(15),
+	internal("ssl_library"),
+	internal("wal_block_size"),
+	internal("wal_segment_size"),
+}
+
+// catalog holds the definitions of every parameter by name
+var catalog = func() map[string][]Parameter {
+	c := make(map[string][]Parameter)
+	for _, p := range parameters {
+		c[p.Name] = append(c[p.Name], p)
+	}
+	return c
+}()
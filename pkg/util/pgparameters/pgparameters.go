@@ -0,0 +1,314 @@
+// Package pgparameters provides a catalog of the PostgreSQL configuration parameters of the supported
+// major versions, used to validate the parameters of a manifest before they are passed to Patroni.
+package pgparameters
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Type is the data type of a parameter as reported by pg_settings.vartype
+type Type string
+
+// Parameter types
+const (
+	TypeBool    Type = "bool"
+	TypeInteger Type = "integer"
+	TypeReal    Type = "real"
+	TypeString  Type = "string"
+	TypeEnum    Type = "enum"
+)
+
+// Context tells when a parameter change takes effect, as reported by pg_settings.context
+type Context string
+
+// Parameter contexts
+const (
+	ContextInternal         Context = "internal"
+	ContextPostmaster       Context = "postmaster"
+	ContextSighup           Context = "sighup"
+	ContextSuperuserBackend Context = "superuser-backend"
+	ContextBackend          Context = "backend"
+	ContextSuperuser        Context = "superuser"
+	ContextUser             Context = "user"
+)
+
+// Parameter describes a configuration parameter. Min and Max are given in Unit.
+type Parameter struct {
+	Name       string
+	Type       Type
+	Context    Context
+	Unit       string
+	Min        float64
+	Max        float64
+	EnumValues []string
+	// MinVersion and MaxVersion limit the major versions providing the parameter, 0 means no limit
+	MinVersion int
+	MaxVersion int
+}
+
+// availableIn reports whether the parameter exists in the given major version, 0 matches any version
+func (p Parameter) availableIn(majorVersion int) bool {
+	if majorVersion == 0 {
+		return true
+	}
+	return (p.MinVersion == 0 || majorVersion >= p.MinVersion) && (p.MaxVersion == 0 || majorVersion <= p.MaxVersion)
+}
+
+var memoryUnits = map[string]float64{
+	"B":   1,
+	"kB":  1024,
+	"8kB": 8192,
+	"MB":  1024 * 1024,
+	"GB":  1024 * 1024 * 1024,
+	"TB":  1024 * 1024 * 1024 * 1024,
+}
+
+var timeUnits = map[string]float64{
+	"us":  0.001,
+	"ms":  1,
+	"s":   1000,
+	"min": 60 * 1000,
+	"h":   60 * 60 * 1000,
+	"d":   24 * 60 * 60 * 1000,
+}
+
+var boolValues = map[string]bool{
+	"on": true, "off": false, "true": true, "false": false, "yes": true, "no": false, "1": true, "0": false,
+}
+
+// IsCustom reports whether the name is a placeholder of an extension, like pg_stat_statements.max,
+// which is not known to the catalog.
+func IsCustom(name string) bool {
+	return strings.Contains(name, ".")
+}
+
+// Lookup returns the definition of a parameter in the given major version. Parameter names are
+// case-insensitive.
+func Lookup(name string, majorVersion int) (Parameter, bool) {
+	for _, p := range catalog[strings.ToLower(name)] {
+		if p.availableIn(majorVersion) {
+			return p, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// RequiresRestart reports whether changing the parameter only takes effect after a restart of the
+// server. Unknown and custom parameters are reported as not requiring a restart.
+func RequiresRestart(name string, majorVersion int) bool {
+	p, ok := Lookup(name, majorVersion)
+	return ok && p.Context == ContextPostmaster
+}
+
+// Validate checks the name and value of a parameter against the catalog of the given major version.
+// Custom parameters of extensions are accepted as they are.
+func Validate(name, value string, majorVersion int) error {
+	if IsCustom(name) {
+		return nil
+	}
+	p, ok := Lookup(name, majorVersion)
+	if !ok {
+		if _, exists := catalog[strings.ToLower(name)]; exists {
+			return fmt.Errorf("parameter %q is not available in PostgreSQL %d", name, majorVersion)
+		}
+		if suggestion := suggest(name); suggestion != "" {
+			return fmt.Errorf("unknown parameter %q, did you mean %q?", name, suggestion)
+		}
+		return fmt.Errorf("unknown parameter %q", name)
+	}
+	if p.Context == ContextInternal {
+		return fmt.Errorf("parameter %q cannot be changed", name)
+	}
+	if err := p.validateValue(strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("invalid value %q for parameter %q: %v", value, name, err)
+	}
+	return nil
+}
+
+// ValidateSession additionally rejects parameters which cannot be set for a session, as done by
+// ALTER ROLE ... SET and ALTER DATABASE ... SET.
+func ValidateSession(name, value string, majorVersion int) error {
+	if err := Validate(name, value, majorVersion); err != nil {
+		return err
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("empty value for parameter %q", name)
+	}
+	if p, ok := Lookup(name, majorVersion); ok && (p.Context == ContextPostmaster || p.Context == ContextSighup) {
+		return fmt.Errorf("parameter %q cannot be set per role or database", name)
+	}
+	return nil
+}
+
+// ValidateAll validates all parameters and reports every invalid one
+func ValidateAll(parameters map[string]string, majorVersion int) error {
+	return validateAll(parameters, majorVersion, Validate)
+}
+
+// ValidateSessionAll validates all session parameters and reports every invalid one
+func ValidateSessionAll(parameters map[string]string, majorVersion int) error {
+	return validateAll(parameters, majorVersion, ValidateSession)
+}
+
+func validateAll(parameters map[string]string, majorVersion int, validate func(name, value string, majorVersion int) error) error {
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errors []string
+	for _, name := range names {
+		if err := validate(name, parameters[name], majorVersion); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, ", "))
+	}
+	return nil
+}
+
+func (p Parameter) validateValue(value string) error {
+	switch p.Type {
+	case TypeBool:
+		if !isBool(value) {
+			return fmt.Errorf("boolean expected")
+		}
+	case TypeEnum:
+		for _, v := range p.EnumValues {
+			if strings.EqualFold(v, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %s", strings.Join(p.EnumValues, ", "))
+	case TypeInteger, TypeReal:
+		number, err := p.parseNumber(value)
+		if err != nil {
+			return err
+		}
+		if number < p.Min || number > p.Max {
+			return fmt.Errorf("out of range [%s, %s]", formatNumber(p.Min, p.Unit), formatNumber(p.Max, p.Unit))
+		}
+	}
+	return nil
+}
+
+// isBool accepts the spellings of PostgreSQL, including unique prefixes like "of" and "t"
+func isBool(value string) bool {
+	value = strings.ToLower(value)
+	if _, ok := boolValues[value]; ok {
+		return true
+	}
+	if len(value) < 2 && value != "t" && value != "f" && value != "y" && value != "n" {
+		return false
+	}
+	for _, word := range []string{"true", "false", "yes", "no", "off"} {
+		if strings.HasPrefix(word, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNumber converts the value into the unit of the parameter, honouring an optional unit suffix
+func (p Parameter) parseNumber(value string) (float64, error) {
+	end := len(value)
+	for end > 0 && isUnitChar(value[end-1]) {
+		end--
+	}
+	// hexadecimal integers like 0x1F end with letters, which are no unit
+	if p.Type == TypeInteger && strings.HasPrefix(strings.ToLower(value), "0x") {
+		end = len(value)
+	}
+	numberPart, unit := strings.TrimSpace(value[:end]), value[end:]
+
+	var number float64
+	if p.Type == TypeInteger && unit == "" {
+		n, err := strconv.ParseInt(numberPart, 0, 64)
+		if err == nil {
+			number = float64(n)
+		} else if number, err = strconv.ParseFloat(numberPart, 64); err != nil {
+			return 0, fmt.Errorf("integer expected")
+		}
+	} else {
+		n, err := strconv.ParseFloat(numberPart, 64)
+		if err != nil || math.IsNaN(n) {
+			return 0, fmt.Errorf("number expected")
+		}
+		number = n
+	}
+	if unit == "" {
+		return number, nil
+	}
+
+	if p.Unit == "" {
+		return 0, fmt.Errorf("parameter has no unit")
+	}
+	if factor, ok := memoryUnits[unit]; ok && unit != "8kB" {
+		if base, isMemory := memoryUnits[p.Unit]; isMemory {
+			return math.Round(number * factor / base), nil
+		}
+	}
+	if factor, ok := timeUnits[unit]; ok {
+		if base, isTime := timeUnits[p.Unit]; isTime {
+			converted := number * factor / base
+			if p.Type == TypeInteger {
+				converted = math.Round(converted)
+			}
+			return converted, nil
+		}
+	}
+	if _, isMemory := memoryUnits[p.Unit]; isMemory {
+		return 0, fmt.Errorf("valid units are B, kB, MB, GB and TB")
+	}
+	return 0, fmt.Errorf("valid units are us, ms, s, min, h and d")
+}
+
+func isUnitChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func formatNumber(number float64, unit string) string {
+	formatted := strconv.FormatFloat(number, 'g', -1, 64)
+	if unit != "" {
+		formatted += " " + unit
+	}
+	return formatted
+}
+
+// suggest returns the known parameter closest to a misspelled name
+func suggest(name string) string {
+	name = strings.ToLower(name)
+	best, bestDistance := "", 3
+	for candidate := range catalog {
+		if d := levenshtein(name, candidate); d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
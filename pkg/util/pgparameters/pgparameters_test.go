@@ -0,0 +1,110 @@
+package pgparameters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		subTest      string
+		name         string
+		value        string
+		majorVersion int
+		err          string
+	}{
+		{"boolean", "log_checkpoints", "on", 17, ""},
+		{"boolean prefix", "log_checkpoints", "of", 17, ""},
+		{"invalid boolean", "log_checkpoints", "maybe", 17, "boolean expected"},
+		{"integer", "max_connections", "100", 17, ""},
+		{"integer out of range", "max_connections", "0", 17, "out of range [1, 262143]"},
+		{"memory unit", "shared_buffers", "128MB", 17, ""},
+		{"memory below minimum", "shared_buffers", "64kB", 17, "out of range"},
+		{"fractional memory unit", "work_mem", "1.5GB", 17, ""},
+		{"invalid memory unit", "work_mem", "4mb", 17, "valid units are B, kB, MB, GB and TB"},
+		{"time unit", "checkpoint_timeout", "15min", 17, ""},
+		{"time out of range", "checkpoint_timeout", "2d", 17, "out of range [30 s, 86400 s]"},
+		{"unit for unitless parameter", "max_connections", "100MB", 17, "parameter has no unit"},
+		{"real", "checkpoint_completion_target", "0.9", 17, ""},
+		{"real with unit", "vacuum_cost_delay", "2ms", 17, ""},
+		{"enum", "wal_level", "logical", 17, ""},
+		{"enum case insensitive", "log_statement", "DDL", 17, ""},
+		{"invalid enum", "wal_level", "full", 17, "expected one of minimal, replica, logical"},
+		{"string", "shared_preload_libraries", "bg_mon,pg_stat_statements", 17, ""},
+		{"case insensitive name", "DateStyle", "ISO, MDY", 17, ""},
+		{"custom parameter", "pg_stat_statements.max", "anything", 17, ""},
+		{"typo", "shared_bufers", "1GB", 17, `unknown parameter "shared_bufers", did you mean "shared_buffers"?`},
+		{"unknown", "foo", "bar", 17, `unknown parameter "foo"`},
+		{"added later", "sync_replication_slots", "on", 16, "not available in PostgreSQL 16"},
+		{"removed", "old_snapshot_threshold", "60", 17, "not available in PostgreSQL 17"},
+		{"any version", "old_snapshot_threshold", "60", 0, ""},
+		{"internal", "block_size", "16384", 17, "cannot be changed"},
+		{"definition by version", "wal_compression", "zstd", 14, "boolean expected"},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.name, tt.value, tt.majorVersion)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s [%s]: expected error containing %q, got %v", t.Name(), tt.subTest, tt.err, err)
+		}
+	}
+}
+
+func TestValidateSession(t *testing.T) {
+	tests := []struct {
+		subTest string
+		name    string
+		value   string
+		err     string
+	}{
+		{"user context", "statement_timeout", "30s", ""},
+		{"superuser context", "log_min_duration_statement", "1s", ""},
+		{"string", "search_path", `"$user", public`, ""},
+		{"custom parameter", "pg_stat_statements.track", "all", ""},
+		{"postmaster context", "shared_buffers", "1GB", "cannot be set per role or database"},
+		{"sighup context", "log_line_prefix", "%m", "cannot be set per role or database"},
+		{"empty value", "search_path", " ", "empty value"},
+		{"invalid value", "statement_timeout", "soon", "invalid value"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSession(tt.name, tt.value, 17)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s [%s]: unexpected error: %v", t.Name(), tt.subTest, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s [%s]: expected error containing %q, got %v", t.Name(), tt.subTest, tt.err, err)
+		}
+	}
+}
+
+func TestRequiresRestart(t *testing.T) {
+	tests := []struct {
+		name         string
+		majorVersion int
+		expected     bool
+	}{
+		{"shared_buffers", 17, true},
+		{"work_mem", 17, false},
+		{"restore_command", 13, true},
+		{"restore_command", 14, false},
+		{"autovacuum_max_workers", 17, true},
+		{"autovacuum_max_workers", 18, false},
+		{"pg_stat_statements.max", 17, false},
+	}
+
+	for _, tt := range tests {
+		if restart := RequiresRestart(tt.name, tt.majorVersion); restart != tt.expected {
+			t.Errorf("%s: expected restart of %s in version %d to be %v, got %v", t.Name(), tt.name, tt.majorVersion, tt.expected, restart)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package privileges
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Object types which privileges can be granted on
+const (
+	ObjectDatabase = "database"
+	ObjectSchema   = "schema"
+	ObjectTable    = "table"
+	ObjectSequence = "sequence"
+	ObjectFunction = "function"
+	ObjectType     = "type"
+)
+
+// objectPrivileges lists the privileges of every object type, as reported by aclexplode
+var objectPrivileges = map[string][]string{
+	ObjectDatabase: {"CONNECT", "CREATE", "TEMPORARY"},
+	ObjectSchema:   {"CREATE", "USAGE"},
+	ObjectTable:    {"DELETE", "INSERT", "MAINTAIN", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"},
+	ObjectSequence: {"SELECT", "UPDATE", "USAGE"},
+	ObjectFunction: {"EXECUTE"},
+	ObjectType:     {"USAGE"},
+}
+
+// privilegeVersions lists the major version of Postgres which introduced a privilege
+var privilegeVersions = map[string]int{
+	"MAINTAIN": 17,
+}
+
+// grantKeywords are the object types of GRANT and REVOKE
+var grantKeywords = map[string]string{
+	ObjectDatabase: "DATABASE",
+	ObjectSchema:   "SCHEMA",
+	ObjectTable:    "TABLE",
+	ObjectSequence: "SEQUENCE",
+	ObjectFunction: "ROUTINE",
+}
+
+// defaultKeywords are the object types of ALTER DEFAULT PRIVILEGES
+var defaultKeywords = map[string]string{
+	ObjectSchema:   "SCHEMAS",
+	ObjectTable:    "TABLES",
+	ObjectSequence: "SEQUENCES",
+	ObjectFunction: "FUNCTIONS",
+	ObjectType:     "TYPES",
+}
+
+// defaultACLTypes maps pg_default_acl.defaclobjtype to the object types
+var defaultACLTypes = map[string]string{
+	"n": ObjectSchema,
+	"r": ObjectTable,
+	"S": ObjectSequence,
+	"f": ObjectFunction,
+	"T": ObjectType,
+}
+
+// IsGrantable reports whether privileges can be granted on single objects of the type
+func IsGrantable(objectType string) bool {
+	_, ok := grantKeywords[objectType]
+	return ok
+}
+
+// IsDefaultable reports whether default privileges can be defined for the object type
+func IsDefaultable(objectType string) bool {
+	_, ok := defaultKeywords[objectType]
+	return ok
+}
+
+// GrantKeyword returns the keyword of the object type in GRANT and REVOKE statements
+func GrantKeyword(objectType string) string {
+	return grantKeywords[objectType]
+}
+
+// DefaultKeyword returns the keyword of the object type in ALTER DEFAULT PRIVILEGES statements
+func DefaultKeyword(objectType string) string {
+	return defaultKeywords[objectType]
+}
+
+// DefaultACLType returns the object type of a pg_default_acl entry
+func DefaultACLType(defaclobjtype string) string {
+	return defaultACLTypes[defaclobjtype]
+}
+
+// Normalize returns the sorted privileges in upper case with ALL expanded to every privilege of
+// the object type in the given major version of Postgres. It fails for privileges which do not
+// exist for the object type or the version. A version of 0 accepts the privileges of all versions.
+func Normalize(objectType string, privileges []string, version int) ([]string, error) {
+	valid, ok := objectPrivileges[objectType]
+	if !ok {
+		return nil, fmt.Errorf("unknown object type %q", objectType)
+	}
+	available := make([]string, 0, len(valid))
+	for _, privilege := range valid {
+		if version == 0 || privilegeVersions[privilege] <= version {
+			available = append(available, privilege)
+		}
+	}
+
+	normalized := make(map[string]bool)
+	for _, privilege := range privileges {
+		privilege = strings.ToUpper(strings.TrimSpace(privilege))
+		switch privilege {
+		case "ALL", "ALL PRIVILEGES":
+			for _, p := range available {
+				normalized[p] = true
+			}
+			continue
+		case "TEMP":
+			privilege = "TEMPORARY"
+		}
+		if !contains(valid, privilege) {
+			return nil, fmt.Errorf("privilege %q cannot be granted on %s, valid privileges: %s",
+				privilege, objectType, strings.Join(valid, ", "))
+		}
+		if !contains(available, privilege) {
+			return nil, fmt.Errorf("privilege %q requires Postgres %d", privilege, privilegeVersions[privilege])
+		}
+		normalized[privilege] = true
+	}
+
+	result := make([]string, 0, len(normalized))
+	for privilege := range normalized {
+		result = append(result, privilege)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
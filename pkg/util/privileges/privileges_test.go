@@ -0,0 +1,47 @@
+package privileges
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		objectType string
+		privileges []string
+		version    int
+		expected   []string
+		err        string
+	}{
+		{ObjectTable, []string{"select", "INSERT", "Select"}, 16, []string{"INSERT", "SELECT"}, ""},
+		{ObjectDatabase, []string{"connect", "temp"}, 16, []string{"CONNECT", "TEMPORARY"}, ""},
+		{ObjectSequence, []string{"ALL"}, 16, []string{"SELECT", "UPDATE", "USAGE"}, ""},
+		{ObjectFunction, []string{"all privileges"}, 16, []string{"EXECUTE"}, ""},
+		{ObjectSchema, []string{}, 16, []string{}, ""},
+		{ObjectSchema, []string{"SELECT"}, 16, nil, `privilege "SELECT" cannot be granted on schema`},
+		{"view", []string{"SELECT"}, 16, nil, `unknown object type "view"`},
+		{ObjectTable, []string{"ALL"}, 16, []string{"DELETE", "INSERT", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"}, ""},
+		{ObjectTable, []string{"ALL"}, 17, []string{"DELETE", "INSERT", "MAINTAIN", "REFERENCES", "SELECT", "TRIGGER", "TRUNCATE", "UPDATE"}, ""},
+		{ObjectTable, []string{"maintain"}, 17, []string{"MAINTAIN"}, ""},
+		{ObjectTable, []string{"maintain"}, 0, []string{"MAINTAIN"}, ""},
+		{ObjectTable, []string{"MAINTAIN"}, 16, nil, `privilege "MAINTAIN" requires Postgres 17`},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.objectType, tt.privileges, tt.version)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("TestNormalize %s %v (%d): expected error containing %q, got %v", tt.objectType, tt.privileges, tt.version, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestNormalize %s %v (%d): unexpected error: %v", tt.objectType, tt.privileges, tt.version, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("TestNormalize %s %v (%d): expected %v, got %v", tt.objectType, tt.privileges, tt.version, tt.expected, got)
+		}
+	}
+}
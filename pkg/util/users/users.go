@@ -7,6 +7,8 @@ import (
 
 	"reflect"
 
+	"github.com/lib/pq"
+
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/spec"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util"
 	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
@@ -20,11 +22,14 @@ const (
 	alterRoleSetSQL      = `ALTER ROLE "%s" SET %s TO %s`
 	dropUserSQL          = `SET LOCAL synchronous_commit = 'local'; DROP ROLE "%s";`
 	grantToUserSQL       = `GRANT %s TO "%s"`
+	grantAdminToUserSQL  = `GRANT %s TO "%s" WITH ADMIN OPTION`
 	revokeFromUserSQL    = `REVOKE "%s" FROM "%s"`
 	doBlockStmt          = `SET LOCAL synchronous_commit = 'local'; DO $$ BEGIN %s; END;$$;`
 	passwordTemplate     = "ENCRYPTED PASSWORD '%s'"
 	inRoleTemplate       = `IN ROLE %s`
 	adminTemplate        = `ADMIN %s`
+	connLimitTemplate    = `CONNECTION LIMIT %d`
+	validUntilTemplate   = `VALID UNTIL '%s'`
 )
 
 // DefaultUserSyncStrategy implements a user sync strategy that merges already existing database users
@@ -77,11 +82,25 @@ func (strategy DefaultUserSyncStrategy) ProduceSyncRequests(dbUsers spec.PgUserM
 				r.User.Flags = addNewFlags
 				r.Kind = spec.PGsyncUserAlter
 			}
+			// connection limit, expiry and administered roles are only managed when defined
+			if newUser.ConnLimit != nil && (dbUser.ConnLimit == nil || *dbUser.ConnLimit != *newUser.ConnLimit) {
+				r.User.ConnLimit = newUser.ConnLimit
+				r.Kind = spec.PGsyncUserAlter
+			}
+			if newUser.ValidUntil != "" && dbUser.ValidUntil != newUser.ValidUntil {
+				r.User.ValidUntil = newUser.ValidUntil
+				r.Kind = spec.PGsyncUserAlter
+			}
+			if addAdminOf, equal := util.SubstractStringSlices(newUser.AdminOf, dbUser.AdminOf); !equal {
+				r.User.AdminOf = addAdminOf
+				r.Kind = spec.PGsyncUserAlter
+			}
 			if r.Kind == spec.PGsyncUserAlter {
 				r.User.Name = newUser.Name
 				reqs = append(reqs, r)
 			}
-			if len(newUser.Parameters) > 0 &&
+			// an empty map resets all parameters of the role, while nil leaves them untouched
+			if newUser.Parameters != nil &&
 				!reflect.DeepEqual(dbUser.Parameters, newUser.Parameters) {
 				reqs = append(reqs, spec.PgSyncUserRequest{Kind: spec.PGSyncAlterSet, User: newUser})
 			}
@@ -107,6 +126,10 @@ func (strategy DefaultUserSyncStrategy) ProduceSyncRequests(dbUsers spec.PgUserM
 				// user found in database and not wanted in newUsers - replace LOGIN flag with NOLOGIN
 				dbUser.Flags = util.StringSliceReplaceElement(dbUser.Flags, constants.RoleFlagLogin, constants.RoleFlagNoLogin)
 			}
+			// only LOGIN is changed, keep the remaining attributes of the role as they are
+			dbUser.ConnLimit = nil
+			dbUser.ValidUntil = ""
+			dbUser.AdminOf = nil
 			// request ALTER ROLE to grant or revoke LOGIN
 			reqs = append(reqs, spec.PgSyncUserRequest{Kind: spec.PGsyncUserAlter, User: dbUser})
 			// request RENAME which will happen on behalf of the pgUser.Deleted field
@@ -226,6 +249,7 @@ func (strategy DefaultUserSyncStrategy) createPgUser(user spec.PgUser, db *sql.D
 	if user.AdminRole != "" {
 		userFlags = append(userFlags, fmt.Sprintf(adminTemplate, user.AdminRole))
 	}
+	userFlags = append(userFlags, produceAttributes(user)...)
 
 	if user.Password == "" {
 		userPassword = "PASSWORD NULL"
@@ -238,6 +262,12 @@ func (strategy DefaultUserSyncStrategy) createPgUser(user spec.PgUser, db *sql.D
 		return err
 	}
 
+	if len(user.AdminOf) > 0 {
+		if _, err := db.Exec(fmt.Sprintf(doBlockStmt, produceGrantAdminStmt(user))); err != nil {
+			return fmt.Errorf("incomplete setup for user %s: %v", user.Name, err)
+		}
+	}
+
 	if len(user.Parameters) > 0 {
 		if err := strategy.alterPgUserSet(user, db); err != nil {
 			return fmt.Errorf("incomplete setup for user %s: %v", user.Name, err)
@@ -250,7 +280,7 @@ func (strategy DefaultUserSyncStrategy) createPgUser(user spec.PgUser, db *sql.D
 func (strategy DefaultUserSyncStrategy) alterPgUser(user spec.PgUser, db *sql.DB) error {
 	var resultStmt []string
 
-	if user.Password != "" || len(user.Flags) > 0 {
+	if user.Password != "" || len(user.Flags) > 0 || user.ConnLimit != nil || user.ValidUntil != "" {
 		alterStmt := produceAlterStmt(user, strategy.PasswordEncryption)
 		resultStmt = append(resultStmt, alterStmt)
 	}
@@ -258,6 +288,9 @@ func (strategy DefaultUserSyncStrategy) alterPgUser(user spec.PgUser, db *sql.DB
 		grantStmt := produceGrantStmt(user)
 		resultStmt = append(resultStmt, grantStmt)
 	}
+	if len(user.AdminOf) > 0 {
+		resultStmt = append(resultStmt, produceGrantAdminStmt(user))
+	}
 
 	if len(resultStmt) > 0 {
 		query := fmt.Sprintf(doBlockStmt, strings.Join(resultStmt, ";"))
@@ -282,14 +315,27 @@ func produceAlterStmt(user spec.PgUser, encryption string) string {
 	if len(flags) != 0 {
 		result = append(result, strings.Join(flags, " "))
 	}
+	result = append(result, produceAttributes(user)...)
 	return fmt.Sprintf(alterUserSQL, user.Name, strings.Join(result, " "))
 }
 
+func produceAttributes(user spec.PgUser) []string {
+	// CONNECTION LIMIT 10 VALID UNTIL '2030-01-01T00:00:00Z'
+	result := make([]string, 0)
+	if user.ConnLimit != nil {
+		result = append(result, fmt.Sprintf(connLimitTemplate, *user.ConnLimit))
+	}
+	if user.ValidUntil != "" {
+		result = append(result, fmt.Sprintf(validUntilTemplate, user.ValidUntil))
+	}
+	return result
+}
+
 func produceAlterRoleSetStmts(user spec.PgUser) []string {
 	result := make([]string, 0)
 	result = append(result, fmt.Sprintf(alterRoleResetAllSQL, user.Name))
 	for name, value := range user.Parameters {
-		result = append(result, fmt.Sprintf(alterRoleSetSQL, user.Name, name, quoteParameterValue(name, value)))
+		result = append(result, fmt.Sprintf(alterRoleSetSQL, user.Name, name, QuoteParameterValue(name, value)))
 	}
 	return result
 }
@@ -299,12 +345,21 @@ func produceGrantStmt(user spec.PgUser) string {
 	return fmt.Sprintf(grantToUserSQL, quoteMemberList(user), user.Name)
 }
 
+func produceGrantAdminStmt(user spec.PgUser) string {
+	// GRANT ROLE "foo", "bar" TO baz WITH ADMIN OPTION
+	return fmt.Sprintf(grantAdminToUserSQL, quoteRoleList(user.AdminOf), user.Name)
+}
+
 func quoteMemberList(user spec.PgUser) string {
-	var memberof []string
-	for _, member := range user.MemberOf {
-		memberof = append(memberof, fmt.Sprintf(`"%s"`, member))
+	return quoteRoleList(user.MemberOf)
+}
+
+func quoteRoleList(roles []string) string {
+	var quoted []string
+	for _, role := range roles {
+		quoted = append(quoted, fmt.Sprintf(`"%s"`, role))
 	}
-	return strings.Join(memberof, ",")
+	return strings.Join(quoted, ",")
 }
 
 func revokeRole(groupRole, role string, db *sql.DB) error {
@@ -317,28 +372,30 @@ func revokeRole(groupRole, role string, db *sql.DB) error {
 	return nil
 }
 
-// quoteVal quotes values to be used at ALTER ROLE SET param = value if necessary
-func quoteParameterValue(name, val string) string {
-	start := val[0]
-	end := val[len(val)-1]
+// QuoteParameterValue quotes values to be used at ALTER ROLE/DATABASE SET param = value. Values
+// are always passed as escaped literals, an enclosing pair of quotes is stripped beforehand.
+func QuoteParameterValue(name, val string) string {
 	if name == "search_path" {
-		// strip single quotes from the search_path. Those are required in the YAML configuration
-		// to quote values containing commas, as otherwise NewFromMap would treat each comma-separated
-		// part of such string as a separate map entry. However, a search_path is interpreted as a list
-		// only if it is not quoted, otherwise it is treated as a single value. Therefore, we strip
-		// single quotes here. Note that you can still use double quotes in order to escape schemas
-		// containing spaces (but something more complex, like double quotes inside double quotes or spaces
-		// in the schema name would break the parsing code in the operator.)
-		if start == '\'' && end == '\'' {
-			return val[1 : len(val)-1]
+		// single quotes are required in the YAML configuration to quote values containing commas,
+		// as otherwise NewFromMap would treat each comma-separated part of such string as a
+		// separate map entry. However, a search_path is interpreted as a list only if it is not
+		// quoted as a whole, so each schema is passed as a separate literal. Double quotes can
+		// still be used around schemas containing spaces, but not commas.
+		schemas := strings.Split(unquoteParameterValue(val, '\''), ",")
+		for i, schema := range schemas {
+			schemas[i] = pq.QuoteLiteral(unquoteParameterValue(schema, '"'))
 		}
-
-		return val
+		return strings.Join(schemas, ", ")
 	}
-	if (start == '"' && end == '"') || (start == '\'' && end == '\'') {
-		return val
+	return pq.QuoteLiteral(unquoteParameterValue(unquoteParameterValue(val, '"'), '\''))
+}
+
+func unquoteParameterValue(val string, quote byte) string {
+	val = strings.TrimSpace(val)
+	if len(val) > 1 && val[0] == quote && val[len(val)-1] == quote {
+		return val[1 : len(val)-1]
 	}
-	return fmt.Sprintf(`'%s'`, strings.Trim(val, " "))
+	return val
 }
 
 // DropPgUser to remove user created by the operator e.g. for password rotation
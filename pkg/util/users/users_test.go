@@ -0,0 +1,31 @@
+package users
+
+import (
+	"testing"
+)
+
+func TestQuoteParameterValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"work_mem", "64MB", `'64MB'`},
+		{"work_mem", " 64MB ", `'64MB'`},
+		{"timezone", `'Europe/Vienna'`, `'Europe/Vienna'`},
+		{"timezone", `"Europe/Vienna"`, `'Europe/Vienna'`},
+		{"application_name", "", `''`},
+		{"application_name", "'", `''''`},
+		{"application_name", "x'; ALTER ROLE foo SUPERUSER; --", `'x''; ALTER ROLE foo SUPERUSER; --'`},
+		{"application_name", `back\slash`, ` E'back\\slash'`},
+		{"search_path", "'public, data'", `'public', 'data'`},
+		{"search_path", `'"my schema", public'`, `'my schema', 'public'`},
+		{"search_path", "public", `'public'`},
+		{"search_path", "public'; RESET ALL; --", `'public''; RESET ALL; --'`},
+	}
+	for _, tt := range tests {
+		if quoted := QuoteParameterValue(tt.name, tt.value); quoted != tt.expected {
+			t.Errorf("expected %s for %s = %q, got %s", tt.expected, tt.name, tt.value, quoted)
+		}
+	}
+}
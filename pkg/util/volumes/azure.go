@@ -0,0 +1,246 @@
+package volumes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/httpclient"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/retryutil"
+)
+
+var azureDiskIDRegexp = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/[^/]+/providers/Microsoft\.Compute/disks/[^/]+$`)
+
+// AzureDiskVolumeResizer implements volume resizing interface for Azure Managed Disks.
+// IOPS and throughput can only be changed for Premium SSD v2 and Ultra disks.
+type AzureDiskVolumeResizer struct {
+	// ClientID selects a user-assigned managed identity, the system-assigned one is used when empty.
+	ClientID string
+	// ResourceManagerEndpoint and MetadataEndpoint default to the Azure public cloud endpoints.
+	ResourceManagerEndpoint string
+	MetadataEndpoint        string
+	HTTPClient              httpclient.HTTPClient
+
+	connection *cloudAPIClient
+}
+
+type azureDiskSku struct {
+	Name string `json:"name,omitempty"`
+}
+
+type azureDiskProperties struct {
+	DiskSizeGB        *int64 `json:"diskSizeGB,omitempty"`
+	DiskIOPSReadWrite *int64 `json:"diskIOPSReadWrite,omitempty"`
+	DiskMBpsReadWrite *int64 `json:"diskMBpsReadWrite,omitempty"`
+}
+
+type azureDisk struct {
+	ID         string               `json:"id,omitempty"`
+	Sku        *azureDiskSku        `json:"sku,omitempty"`
+	Properties *azureDiskProperties `json:"properties,omitempty"`
+}
+
+type azureAsyncOperation struct {
+	Status string `json:"status"`
+	Error  *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (r *AzureDiskVolumeResizer) resourceManagerEndpoint() string {
+	if r.ResourceManagerEndpoint != "" {
+		return strings.TrimSuffix(r.ResourceManagerEndpoint, "/")
+	}
+	return constants.AzureResourceManagerEndpoint
+}
+
+func (r *AzureDiskVolumeResizer) metadataEndpoint() string {
+	if r.MetadataEndpoint != "" {
+		return strings.TrimSuffix(r.MetadataEndpoint, "/")
+	}
+	return constants.AzureInstanceMetadataEndpoint
+}
+
+func (r *AzureDiskVolumeResizer) diskURL(diskID string) string {
+	return r.resourceManagerEndpoint() + diskID + "?api-version=" + constants.AzureDiskAPIVersion
+}
+
+// ConnectToProvider prepares an authenticated client for the Azure Resource Manager,
+// using the managed identity of the node or of the workload.
+func (r *AzureDiskVolumeResizer) ConnectToProvider() error {
+	query := url.Values{}
+	query.Set("api-version", "2018-02-01")
+	query.Set("resource", constants.AzureResourceManagerEndpoint+"/")
+	if r.ClientID != "" {
+		query.Set("client_id", r.ClientID)
+	}
+	tokenURL := r.metadataEndpoint() + "/identity/oauth2/token?" + query.Encode()
+	r.connection = newCloudAPIClient(r.HTTPClient, func(client httpclient.HTTPClient) (string, time.Duration, error) {
+		return fetchMetadataToken(client, tokenURL, map[string]string{"Metadata": "true"})
+	})
+	if _, err := r.connection.bearerToken(); err != nil {
+		r.connection = nil
+		return fmt.Errorf("could not connect to the Azure Resource Manager: %v", err)
+	}
+	return nil
+}
+
+// IsConnectedToProvider checks if the Azure Resource Manager client is initialized.
+func (r *AzureDiskVolumeResizer) IsConnectedToProvider() bool {
+	return r.connection != nil
+}
+
+// VolumeBelongsToProvider checks if the given persistent volume is backed by an Azure Managed Disk.
+func (r *AzureDiskVolumeResizer) VolumeBelongsToProvider(pv *v1.PersistentVolume) bool {
+	if pv.Spec.CSI != nil {
+		return pv.Spec.CSI.Driver == constants.AzureDiskCSIDriver
+	}
+	return pv.Spec.AzureDisk != nil
+}
+
+// ExtractVolumeID validates a managed disk resource id of the form
+// "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/disks/d".
+func (r *AzureDiskVolumeResizer) ExtractVolumeID(volumeID string) (string, error) {
+	if !azureDiskIDRegexp.MatchString(volumeID) {
+		return "", fmt.Errorf("malformed Azure managed disk id %q", volumeID)
+	}
+	return volumeID, nil
+}
+
+// GetProviderVolumeID returns the managed disk resource id for CSI and in-tree volumes.
+func (r *AzureDiskVolumeResizer) GetProviderVolumeID(pv *v1.PersistentVolume) (string, error) {
+	if pv.Spec.CSI != nil {
+		return r.ExtractVolumeID(pv.Spec.CSI.VolumeHandle)
+	}
+	if pv.Spec.AzureDisk == nil || pv.Spec.AzureDisk.DataDiskURI == "" {
+		return "", fmt.Errorf("got empty disk id for volume %v", pv.Name)
+	}
+	return r.ExtractVolumeID(pv.Spec.AzureDisk.DataDiskURI)
+}
+
+func (r *AzureDiskVolumeResizer) getDisk(diskID string) (*azureDisk, error) {
+	var disk azureDisk
+	if _, err := r.connection.do(http.MethodGet, r.diskURL(diskID), nil, &disk); err != nil {
+		return nil, fmt.Errorf("could not get information about the disk %q: %v", diskID, err)
+	}
+	if disk.Properties == nil || disk.Properties.DiskSizeGB == nil {
+		return nil, fmt.Errorf("got incomplete information about the disk %q", diskID)
+	}
+	return &disk, nil
+}
+
+// DescribeVolumes returns size, sku and provisioned performance of the given disks.
+func (r *AzureDiskVolumeResizer) DescribeVolumes(volumeIds []string) ([]VolumeProperties, error) {
+	if !r.IsConnectedToProvider() {
+		if err := r.ConnectToProvider(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := []VolumeProperties{}
+	for _, diskID := range volumeIds {
+		disk, err := r.getDisk(diskID)
+		if err != nil {
+			return nil, err
+		}
+		props := VolumeProperties{VolumeID: diskID, Size: *disk.Properties.DiskSizeGB}
+		if disk.Sku != nil {
+			props.VolumeType = disk.Sku.Name
+		}
+		if disk.Properties.DiskIOPSReadWrite != nil {
+			props.Iops = *disk.Properties.DiskIOPSReadWrite
+		}
+		if disk.Properties.DiskMBpsReadWrite != nil {
+			props.Throughput = *disk.Properties.DiskMBpsReadWrite
+		}
+		p = append(p, props)
+	}
+	return p, nil
+}
+
+// ResizeVolume calls the Azure Resource Manager to grow the disk if necessary.
+func (r *AzureDiskVolumeResizer) ResizeVolume(diskID string, newSize int64) error {
+	return r.ModifyVolume(diskID, nil, &newSize, nil, nil)
+}
+
+// ModifyVolume grows the disk and changes its sku, IOPS and throughput (MBps) in one update.
+func (r *AzureDiskVolumeResizer) ModifyVolume(diskID string, newType *string, newSize *int64, iops *int64, throughput *int64) error {
+	if !r.IsConnectedToProvider() {
+		if err := r.ConnectToProvider(); err != nil {
+			return err
+		}
+	}
+	disk, err := r.getDisk(diskID)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	update := azureDisk{Properties: &azureDiskProperties{}}
+	if newType != nil && (disk.Sku == nil || !strings.EqualFold(disk.Sku.Name, *newType)) {
+		update.Sku = &azureDiskSku{Name: *newType}
+		changed = true
+	}
+	if newSize != nil && *newSize > *disk.Properties.DiskSizeGB {
+		update.Properties.DiskSizeGB = newSize
+		changed = true
+	}
+	if iops != nil && (disk.Properties.DiskIOPSReadWrite == nil || *disk.Properties.DiskIOPSReadWrite != *iops) {
+		update.Properties.DiskIOPSReadWrite = iops
+		changed = true
+	}
+	if throughput != nil && (disk.Properties.DiskMBpsReadWrite == nil || *disk.Properties.DiskMBpsReadWrite != *throughput) {
+		update.Properties.DiskMBpsReadWrite = throughput
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	header, err := r.connection.do(http.MethodPatch, r.diskURL(diskID), update, nil)
+	if err != nil {
+		return fmt.Errorf("could not modify disk %q: %v", diskID, err)
+	}
+	return r.waitForOperation(diskID, header.Get("Azure-AsyncOperation"))
+}
+
+// waitForOperation polls the asynchronous operation returned by the Resource Manager until it
+// reaches a terminal state. Synchronously completed requests carry no operation url.
+func (r *AzureDiskVolumeResizer) waitForOperation(diskID, operationURL string) error {
+	if operationURL == "" {
+		return nil
+	}
+	var op azureAsyncOperation
+	err := retryutil.Retry(constants.AzureDiskResizeWaitInterval, constants.AzureDiskResizeWaitTimeout,
+		func() (bool, error) {
+			if _, err := r.connection.do(http.MethodGet, operationURL, nil, &op); err != nil {
+				return false, fmt.Errorf("could not get status of the operation on disk %q: %v", diskID, err)
+			}
+			return op.Status == constants.AzureOperationStatusSucceeded ||
+				op.Status == constants.AzureOperationStatusFailed ||
+				op.Status == constants.AzureOperationStatusCanceled, nil
+		})
+	if err != nil {
+		return err
+	}
+	if op.Status != constants.AzureOperationStatusSucceeded {
+		if op.Error != nil {
+			return fmt.Errorf("operation on disk %q %s: %s: %s", diskID, strings.ToLower(op.Status), op.Error.Code, op.Error.Message)
+		}
+		return fmt.Errorf("operation on disk %q %s", diskID, strings.ToLower(op.Status))
+	}
+	return nil
+}
+
+// DisconnectFromProvider drops the Azure Resource Manager client.
+func (r *AzureDiskVolumeResizer) DisconnectFromProvider() error {
+	r.connection = nil
+	return nil
+}
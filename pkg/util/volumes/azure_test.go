@@ -0,0 +1,182 @@
+package volumes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const testAzureDiskID = "/subscriptions/0000-1111/resourceGroups/mc_rg_aks/providers/Microsoft.Compute/disks/pvc-1234"
+
+// fakeAzure is a minimal fake of the instance metadata service and the managed disk API.
+type fakeAzure struct {
+	mu          sync.Mutex
+	server      *httptest.Server
+	disk        azureDisk
+	opStatus    string
+	tokenCalls  int
+	patchBodies []azureDisk
+}
+
+func newFakeAzure(disk azureDisk, opStatus string) *fakeAzure {
+	f := &fakeAzure{disk: disk, opStatus: opStatus}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+func (f *fakeAzure) handle(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if req.URL.Path == "/metadata/identity/oauth2/token" {
+		if req.Header.Get("Metadata") != "true" || req.URL.Query().Get("resource") != "https://management.azure.com/" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.tokenCalls++
+		// IMDS encodes the lifetime as a string
+		w.Write([]byte(`{"access_token":"azure-token","expires_in":"86399","token_type":"Bearer"}`))
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer azure-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case req.URL.Path == "/arm"+testAzureDiskID && req.Method == http.MethodGet:
+		json.NewEncoder(w).Encode(f.disk)
+	case req.URL.Path == "/arm"+testAzureDiskID && req.Method == http.MethodPatch:
+		var body azureDisk
+		json.NewDecoder(req.Body).Decode(&body)
+		f.patchBodies = append(f.patchBodies, body)
+		if body.Sku != nil {
+			f.disk.Sku = body.Sku
+		}
+		if body.Properties.DiskSizeGB != nil {
+			f.disk.Properties.DiskSizeGB = body.Properties.DiskSizeGB
+		}
+		if body.Properties.DiskIOPSReadWrite != nil {
+			f.disk.Properties.DiskIOPSReadWrite = body.Properties.DiskIOPSReadWrite
+		}
+		if body.Properties.DiskMBpsReadWrite != nil {
+			f.disk.Properties.DiskMBpsReadWrite = body.Properties.DiskMBpsReadWrite
+		}
+		w.Header().Set("Azure-AsyncOperation", f.server.URL+"/arm/operations/op-1")
+		w.WriteHeader(http.StatusAccepted)
+	case req.URL.Path == "/arm/operations/op-1":
+		op := azureAsyncOperation{Status: f.opStatus}
+		if f.opStatus == "Failed" {
+			op.Error = &struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}{Code: "OperationNotAllowed", Message: "disk is attached"}
+		}
+		json.NewEncoder(w).Encode(op)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestAzureResizer(url string) *AzureDiskVolumeResizer {
+	return &AzureDiskVolumeResizer{
+		ResourceManagerEndpoint: url + "/arm",
+		MetadataEndpoint:        url + "/metadata",
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestAzureGetProviderVolumeID(t *testing.T) {
+	resizer := AzureDiskVolumeResizer{}
+
+	csiVolume := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+		CSI: &v1.CSIPersistentVolumeSource{Driver: "disk.csi.azure.com", VolumeHandle: testAzureDiskID},
+	}}}
+	inTreeVolume := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+		AzureDisk: &v1.AzureDiskVolumeSource{DiskName: "pvc-1234", DataDiskURI: testAzureDiskID},
+	}}}
+	for _, pv := range []*v1.PersistentVolume{csiVolume, inTreeVolume} {
+		if !resizer.VolumeBelongsToProvider(pv) {
+			t.Errorf("%s: expected volume %v to belong to Azure", t.Name(), pv.Spec.PersistentVolumeSource)
+		}
+		id, err := resizer.GetProviderVolumeID(pv)
+		if err != nil || id != testAzureDiskID {
+			t.Errorf("%s: expected %q, got %q (%v)", t.Name(), testAzureDiskID, id, err)
+		}
+	}
+
+	if _, err := resizer.ExtractVolumeID("/subscriptions/0000-1111/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa"); err == nil {
+		t.Errorf("%s: expected an error for a resource that is not a managed disk", t.Name())
+	}
+}
+
+func TestAzureDescribeAndModifyVolume(t *testing.T) {
+	fake := newFakeAzure(azureDisk{
+		ID:  testAzureDiskID,
+		Sku: &azureDiskSku{Name: "PremiumV2_LRS"},
+		Properties: &azureDiskProperties{
+			DiskSizeGB:        int64Ptr(100),
+			DiskIOPSReadWrite: int64Ptr(3000),
+			DiskMBpsReadWrite: int64Ptr(125),
+		},
+	}, "Succeeded")
+	defer fake.server.Close()
+	resizer := newTestAzureResizer(fake.server.URL)
+
+	props, err := resizer.DescribeVolumes([]string{testAzureDiskID})
+	if err != nil {
+		t.Fatalf("%s: could not describe volumes: %v", t.Name(), err)
+	}
+	expected := VolumeProperties{VolumeID: testAzureDiskID, VolumeType: "PremiumV2_LRS", Size: 100, Iops: 3000, Throughput: 125}
+	if len(props) != 1 || props[0] != expected {
+		t.Errorf("%s: expected %#v, got %#v", t.Name(), expected, props)
+	}
+
+	if err := resizer.ModifyVolume(testAzureDiskID, nil, int64Ptr(200), int64Ptr(5000), int64Ptr(125)); err != nil {
+		t.Fatalf("%s: could not modify volume: %v", t.Name(), err)
+	}
+	if len(fake.patchBodies) != 1 {
+		t.Fatalf("%s: expected one update, got %d", t.Name(), len(fake.patchBodies))
+	}
+	patch := fake.patchBodies[0]
+	if patch.Sku != nil || *patch.Properties.DiskSizeGB != 200 || *patch.Properties.DiskIOPSReadWrite != 5000 || patch.Properties.DiskMBpsReadWrite != nil {
+		t.Errorf("%s: unexpected update %#v", t.Name(), patch.Properties)
+	}
+
+	// nothing to change, no update is sent
+	if err := resizer.ResizeVolume(testAzureDiskID, 200); err != nil {
+		t.Errorf("%s: unexpected error: %v", t.Name(), err)
+	}
+	if len(fake.patchBodies) != 1 {
+		t.Errorf("%s: expected no further update, got %d", t.Name(), len(fake.patchBodies))
+	}
+	if fake.tokenCalls != 1 {
+		t.Errorf("%s: expected the token to be cached, got %d token requests", t.Name(), fake.tokenCalls)
+	}
+}
+
+func TestAzureModifyVolumeFailedOperation(t *testing.T) {
+	fake := newFakeAzure(azureDisk{
+		ID:         testAzureDiskID,
+		Sku:        &azureDiskSku{Name: "Premium_LRS"},
+		Properties: &azureDiskProperties{DiskSizeGB: int64Ptr(100)},
+	}, "Failed")
+	defer fake.server.Close()
+	resizer := newTestAzureResizer(fake.server.URL)
+
+	err := resizer.ModifyVolume(testAzureDiskID, nil, int64Ptr(200), nil, nil)
+	if err == nil {
+		t.Fatalf("%s: expected an error for a failed operation", t.Name())
+	}
+	expected := `operation on disk "` + testAzureDiskID + `" failed: OperationNotAllowed: disk is attached`
+	if err.Error() != expected {
+		t.Errorf("%s: expected error %q, got %q", t.Name(), expected, err.Error())
+	}
+}
@@ -0,0 +1,150 @@
+package volumes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/httpclient"
+)
+
+const cloudAPIRequestTimeout = 30 * time.Second
+
+// tokenFetcher obtains a new bearer token together with its lifetime.
+type tokenFetcher func(client httpclient.HTTPClient) (token string, expiresIn time.Duration, err error)
+
+// cloudAPIClient is a minimal JSON REST client for the cloud provider APIs used by the volume resizers.
+// It caches the bearer token returned by the fetcher until shortly before its expiry.
+type cloudAPIClient struct {
+	httpClient httpclient.HTTPClient
+	fetchToken tokenFetcher
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// cloudAPIError is returned for non-successful HTTP responses of the cloud API.
+type cloudAPIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *cloudAPIError) Error() string {
+	return fmt.Sprintf("cloud API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+func newCloudAPIClient(client httpclient.HTTPClient, fetchToken tokenFetcher) *cloudAPIClient {
+	if client == nil {
+		client = &http.Client{Timeout: cloudAPIRequestTimeout}
+	}
+	return &cloudAPIClient{httpClient: client, fetchToken: fetchToken}
+}
+
+func (c *cloudAPIClient) bearerToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// refresh a minute early so that the token does not expire in flight
+	if c.token != "" && time.Now().Add(time.Minute).Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+	token, expiresIn, err := c.fetchToken(c.httpClient)
+	if err != nil {
+		return "", fmt.Errorf("could not obtain access token: %v", err)
+	}
+	c.token = token
+	c.tokenExpiry = time.Now().Add(expiresIn)
+	return c.token, nil
+}
+
+// do sends a JSON request and decodes the JSON response into out, if given.
+// The response headers are returned so that callers can follow asynchronous operations.
+func (c *cloudAPIClient) do(method, url string, in, out interface{}) (http.Header, error) {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal request body: %v", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token, err := c.bearerToken()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %v", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &cloudAPIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return nil, fmt.Errorf("could not unmarshal response body: %v", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// fetchMetadataToken reads an OAuth token from an instance metadata service that responds
+// with the common {"access_token", "expires_in"} document.
+func fetchMetadataToken(client httpclient.HTTPClient, url string, headers map[string]string) (string, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("metadata service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var token struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", 0, fmt.Errorf("could not unmarshal token: %v", err)
+	}
+	if token.AccessToken == "" {
+		return "", 0, fmt.Errorf("metadata service returned an empty token")
+	}
+	expiresIn, err := token.ExpiresIn.Int64()
+	if err != nil {
+		return "", 0, fmt.Errorf("could not parse token lifetime %q: %v", token.ExpiresIn, err)
+	}
+	return token.AccessToken, time.Duration(expiresIn) * time.Second, nil
+}
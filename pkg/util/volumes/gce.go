@@ -0,0 +1,292 @@
+package volumes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/constants"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/httpclient"
+	"github.com/cybertec-postgresql/cybertec-pg-operator/pkg/util/retryutil"
+)
+
+var gceDiskIDRegexp = regexp.MustCompile(`^projects/([^/]+)/zones/([^/]+)/disks/([^/]+)$`)
+
+// GCEPDVolumeResizer implements volume resizing interface for Google Compute Engine Persistent Disks.
+// Performance settings (IOPS and throughput) can only be changed for Hyperdisk volumes.
+type GCEPDVolumeResizer struct {
+	// Project is used for in-tree volumes that do not carry the project in their id.
+	// When empty it is read from the metadata server.
+	Project string
+	// ComputeEndpoint and MetadataEndpoint default to the public Google endpoints.
+	ComputeEndpoint  string
+	MetadataEndpoint string
+	HTTPClient       httpclient.HTTPClient
+
+	connection *cloudAPIClient
+}
+
+type gceDisk struct {
+	Name                  string `json:"name"`
+	SizeGb                string `json:"sizeGb"`
+	Type                  string `json:"type"`
+	ProvisionedIops       string `json:"provisionedIops,omitempty"`
+	ProvisionedThroughput string `json:"provisionedThroughput,omitempty"`
+}
+
+type gceOperation struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  *struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"error,omitempty"`
+}
+
+func (r *GCEPDVolumeResizer) computeEndpoint() string {
+	if r.ComputeEndpoint != "" {
+		return strings.TrimSuffix(r.ComputeEndpoint, "/")
+	}
+	return constants.GCEComputeEndpoint
+}
+
+func (r *GCEPDVolumeResizer) metadataEndpoint() string {
+	if r.MetadataEndpoint != "" {
+		return strings.TrimSuffix(r.MetadataEndpoint, "/")
+	}
+	return constants.GCEMetadataEndpoint
+}
+
+// ConnectToProvider prepares an authenticated client for the Compute Engine API,
+// using the service account of the node or of the workload identity.
+func (r *GCEPDVolumeResizer) ConnectToProvider() error {
+	tokenURL := r.metadataEndpoint() + "/instance/service-accounts/default/token"
+	r.connection = newCloudAPIClient(r.HTTPClient, func(client httpclient.HTTPClient) (string, time.Duration, error) {
+		return fetchMetadataToken(client, tokenURL, map[string]string{"Metadata-Flavor": "Google"})
+	})
+	if _, err := r.connection.bearerToken(); err != nil {
+		r.connection = nil
+		return fmt.Errorf("could not connect to the Compute Engine API: %v", err)
+	}
+	return nil
+}
+
+// IsConnectedToProvider checks if the Compute Engine client is initialized.
+func (r *GCEPDVolumeResizer) IsConnectedToProvider() bool {
+	return r.connection != nil
+}
+
+// VolumeBelongsToProvider checks if the given persistent volume is backed by a GCE Persistent Disk.
+func (r *GCEPDVolumeResizer) VolumeBelongsToProvider(pv *v1.PersistentVolume) bool {
+	if pv.Spec.CSI != nil {
+		return pv.Spec.CSI.Driver == constants.GCEPDCSIDriver
+	}
+	return pv.Spec.GCEPersistentDisk != nil
+}
+
+// ExtractVolumeID normalizes a disk id of the form "projects/p/zones/z/disks/d",
+// also accepting a full Compute Engine API url of the disk.
+func (r *GCEPDVolumeResizer) ExtractVolumeID(volumeID string) (string, error) {
+	id := volumeID
+	if idx := strings.Index(id, "projects/"); idx > 0 {
+		id = id[idx:]
+	}
+	if !gceDiskIDRegexp.MatchString(id) {
+		return "", fmt.Errorf("malformed GCE persistent disk id %q", volumeID)
+	}
+	return id, nil
+}
+
+// GetProviderVolumeID returns the fully qualified disk id for CSI and in-tree volumes.
+func (r *GCEPDVolumeResizer) GetProviderVolumeID(pv *v1.PersistentVolume) (string, error) {
+	if pv.Spec.CSI != nil {
+		return r.ExtractVolumeID(pv.Spec.CSI.VolumeHandle)
+	}
+	if pv.Spec.GCEPersistentDisk == nil || pv.Spec.GCEPersistentDisk.PDName == "" {
+		return "", fmt.Errorf("got empty disk name for volume %v", pv.Name)
+	}
+	zone := pv.Labels[v1.LabelTopologyZone]
+	if zone == "" {
+		zone = pv.Labels[v1.LabelFailureDomainBetaZone]
+	}
+	if zone == "" {
+		return "", fmt.Errorf("could not determine the zone of volume %q", pv.Name)
+	}
+	project, err := r.project()
+	if err != nil {
+		return "", err
+	}
+	return r.ExtractVolumeID(path.Join("projects", project, "zones", zone, "disks", pv.Spec.GCEPersistentDisk.PDName))
+}
+
+func (r *GCEPDVolumeResizer) project() (string, error) {
+	if r.Project != "" {
+		return r.Project, nil
+	}
+	client := r.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: cloudAPIRequestTimeout}
+	}
+	req, err := http.NewRequest(http.MethodGet, r.metadataEndpoint()+"/project/project-id", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not read project id from the metadata server: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK || len(strings.TrimSpace(string(body))) == 0 {
+		return "", fmt.Errorf("could not read project id from the metadata server: status %d", resp.StatusCode)
+	}
+	r.Project = strings.TrimSpace(string(body))
+	return r.Project, nil
+}
+
+func (r *GCEPDVolumeResizer) getDisk(diskID string) (*gceDisk, error) {
+	var disk gceDisk
+	if _, err := r.connection.do(http.MethodGet, r.computeEndpoint()+"/"+diskID, nil, &disk); err != nil {
+		return nil, fmt.Errorf("could not get information about the disk %q: %v", diskID, err)
+	}
+	return &disk, nil
+}
+
+// DescribeVolumes returns size, type and provisioned performance of the given disks.
+func (r *GCEPDVolumeResizer) DescribeVolumes(volumeIds []string) ([]VolumeProperties, error) {
+	if !r.IsConnectedToProvider() {
+		if err := r.ConnectToProvider(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := []VolumeProperties{}
+	for _, diskID := range volumeIds {
+		disk, err := r.getDisk(diskID)
+		if err != nil {
+			return nil, err
+		}
+		props := VolumeProperties{VolumeID: diskID, VolumeType: path.Base(disk.Type)}
+		if props.Size, err = parseGCEInt(disk.SizeGb); err != nil {
+			return nil, fmt.Errorf("could not parse size of disk %q: %v", diskID, err)
+		}
+		if props.Iops, err = parseGCEInt(disk.ProvisionedIops); err != nil {
+			return nil, fmt.Errorf("could not parse provisioned IOPS of disk %q: %v", diskID, err)
+		}
+		if props.Throughput, err = parseGCEInt(disk.ProvisionedThroughput); err != nil {
+			return nil, fmt.Errorf("could not parse provisioned throughput of disk %q: %v", diskID, err)
+		}
+		p = append(p, props)
+	}
+	return p, nil
+}
+
+// ResizeVolume calls the Compute Engine API to grow the disk if necessary.
+func (r *GCEPDVolumeResizer) ResizeVolume(diskID string, newSize int64) error {
+	return r.ModifyVolume(diskID, nil, &newSize, nil, nil)
+}
+
+// ModifyVolume grows the disk and changes provisioned IOPS and throughput of Hyperdisk volumes.
+// The disk type of a Persistent Disk cannot be changed in place.
+func (r *GCEPDVolumeResizer) ModifyVolume(diskID string, newType *string, newSize *int64, iops *int64, throughput *int64) error {
+	if !r.IsConnectedToProvider() {
+		if err := r.ConnectToProvider(); err != nil {
+			return err
+		}
+	}
+	disk, err := r.getDisk(diskID)
+	if err != nil {
+		return err
+	}
+	if newType != nil && *newType != path.Base(disk.Type) {
+		return fmt.Errorf("could not change type of disk %q from %q to %q: not supported by Compute Engine", diskID, path.Base(disk.Type), *newType)
+	}
+
+	if newSize != nil {
+		currentSize, err := parseGCEInt(disk.SizeGb)
+		if err != nil {
+			return fmt.Errorf("could not parse size of disk %q: %v", diskID, err)
+		}
+		if *newSize > currentSize {
+			var op gceOperation
+			body := map[string]string{"sizeGb": strconv.FormatInt(*newSize, 10)}
+			if _, err := r.connection.do(http.MethodPost, r.computeEndpoint()+"/"+diskID+"/resize", body, &op); err != nil {
+				return fmt.Errorf("could not resize disk %q: %v", diskID, err)
+			}
+			if err := r.waitForOperation(diskID, &op); err != nil {
+				return err
+			}
+		}
+	}
+
+	update := gceDisk{}
+	mask := url.Values{}
+	if iops != nil && strconv.FormatInt(*iops, 10) != disk.ProvisionedIops {
+		update.ProvisionedIops = strconv.FormatInt(*iops, 10)
+		mask.Add("paths", "provisionedIops")
+	}
+	if throughput != nil && strconv.FormatInt(*throughput, 10) != disk.ProvisionedThroughput {
+		update.ProvisionedThroughput = strconv.FormatInt(*throughput, 10)
+		mask.Add("paths", "provisionedThroughput")
+	}
+	if len(mask) == 0 {
+		return nil
+	}
+	var op gceOperation
+	if _, err := r.connection.do(http.MethodPatch, r.computeEndpoint()+"/"+diskID+"?"+mask.Encode(), update, &op); err != nil {
+		return fmt.Errorf("could not update performance settings of disk %q: %v", diskID, err)
+	}
+	return r.waitForOperation(diskID, &op)
+}
+
+// waitForOperation polls the zonal operation until it is done and reports its errors.
+func (r *GCEPDVolumeResizer) waitForOperation(diskID string, op *gceOperation) error {
+	m := gceDiskIDRegexp.FindStringSubmatch(diskID)
+	if m == nil {
+		return fmt.Errorf("malformed GCE persistent disk id %q", diskID)
+	}
+	opURL := fmt.Sprintf("%s/projects/%s/zones/%s/operations/%s", r.computeEndpoint(), m[1], m[2], op.Name)
+
+	err := retryutil.Retry(constants.GCEDiskResizeWaitInterval, constants.GCEDiskResizeWaitTimeout,
+		func() (bool, error) {
+			if op.Status == constants.GCEOperationStatusDone {
+				return true, nil
+			}
+			if _, err := r.connection.do(http.MethodGet, opURL, nil, op); err != nil {
+				return false, fmt.Errorf("could not get status of operation %q: %v", op.Name, err)
+			}
+			return op.Status == constants.GCEOperationStatusDone, nil
+		})
+	if err != nil {
+		return err
+	}
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		return fmt.Errorf("operation %q on disk %q failed: %s: %s", op.Name, diskID, op.Error.Errors[0].Code, op.Error.Errors[0].Message)
+	}
+	return nil
+}
+
+// DisconnectFromProvider drops the Compute Engine client.
+func (r *GCEPDVolumeResizer) DisconnectFromProvider() error {
+	r.connection = nil
+	return nil
+}
+
+// parseGCEInt parses int64 values that the Compute Engine API encodes as strings.
+func parseGCEInt(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,226 @@
+package volumes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testGCEDiskID = "projects/test-project/zones/europe-west3-a/disks/pvc-1234"
+
+// fakeGCE is a minimal fake of the metadata server and the Compute Engine disk API.
+type fakeGCE struct {
+	mu         sync.Mutex
+	disk       gceDisk
+	operations map[string]int
+	requests   []string
+}
+
+func newFakeGCE(disk gceDisk) (*fakeGCE, *httptest.Server) {
+	f := &fakeGCE{disk: disk, operations: map[string]int{}}
+	return f, httptest.NewServer(http.HandlerFunc(f.handle))
+}
+
+func (f *fakeGCE) handle(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, req.Method+" "+req.URL.RequestURI())
+
+	switch {
+	case req.URL.Path == "/metadata/instance/service-accounts/default/token":
+		if req.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"access_token":"gce-token","expires_in":3599,"token_type":"Bearer"}`))
+		return
+	case req.URL.Path == "/metadata/project/project-id":
+		w.Write([]byte("test-project"))
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer gce-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/compute/"+testGCEDiskID:
+		json.NewEncoder(w).Encode(f.disk)
+	case req.Method == http.MethodPost && req.URL.Path == "/compute/"+testGCEDiskID+"/resize":
+		var body map[string]string
+		json.NewDecoder(req.Body).Decode(&body)
+		f.disk.SizeGb = body["sizeGb"]
+		f.operations["op-resize"] = 0
+		w.Write([]byte(`{"name":"op-resize","status":"RUNNING"}`))
+	case req.Method == http.MethodPatch && req.URL.Path == "/compute/"+testGCEDiskID:
+		var body gceDisk
+		json.NewDecoder(req.Body).Decode(&body)
+		for _, p := range req.URL.Query()["paths"] {
+			switch p {
+			case "provisionedIops":
+				f.disk.ProvisionedIops = body.ProvisionedIops
+			case "provisionedThroughput":
+				f.disk.ProvisionedThroughput = body.ProvisionedThroughput
+			}
+		}
+		w.Write([]byte(`{"name":"op-update","status":"DONE"}`))
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/compute/projects/test-project/zones/europe-west3-a/operations/"):
+		name := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+		f.operations[name]--
+		status := "RUNNING"
+		if f.operations[name] < 0 {
+			status = "DONE"
+		}
+		json.NewEncoder(w).Encode(gceOperation{Name: name, Status: status})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":{"code":404}}`)
+	}
+}
+
+func newTestGCEResizer(url string) *GCEPDVolumeResizer {
+	return &GCEPDVolumeResizer{
+		ComputeEndpoint:  url + "/compute",
+		MetadataEndpoint: url + "/metadata",
+	}
+}
+
+func TestGCEExtractVolumeID(t *testing.T) {
+	var tests = []struct {
+		input          string
+		expectedResult string
+		expectError    bool
+	}{
+		{
+			input:          testGCEDiskID,
+			expectedResult: testGCEDiskID,
+		},
+		{
+			input:          "https://www.googleapis.com/compute/v1/" + testGCEDiskID,
+			expectedResult: testGCEDiskID,
+		},
+		{
+			input:       "projects/test-project/regions/europe-west3/disks/pvc-1234",
+			expectError: true,
+		},
+		{
+			input:       "pvc-1234",
+			expectError: true,
+		},
+	}
+
+	resizer := GCEPDVolumeResizer{}
+	for _, tt := range tests {
+		volumeID, err := resizer.ExtractVolumeID(tt.input)
+		if volumeID != tt.expectedResult {
+			t.Errorf("%s expected: %s, got %s", t.Name(), tt.expectedResult, volumeID)
+		}
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s unexpected error for %q: %v", t.Name(), tt.input, err)
+		}
+	}
+}
+
+func TestGCEGetProviderVolumeID(t *testing.T) {
+	fake, server := newFakeGCE(gceDisk{})
+	defer server.Close()
+	resizer := newTestGCEResizer(server.URL)
+
+	csiVolume := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+		CSI: &v1.CSIPersistentVolumeSource{Driver: "pd.csi.storage.gke.io", VolumeHandle: testGCEDiskID},
+	}}}
+	inTreeVolume := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1.LabelTopologyZone: "europe-west3-a"}},
+		Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+			GCEPersistentDisk: &v1.GCEPersistentDiskVolumeSource{PDName: "pvc-1234"},
+		}},
+	}
+	ebsVolume := &v1.PersistentVolume{Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{
+		CSI: &v1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-1234"},
+	}}}
+
+	for _, pv := range []*v1.PersistentVolume{csiVolume, inTreeVolume} {
+		if !resizer.VolumeBelongsToProvider(pv) {
+			t.Errorf("%s: expected volume %v to belong to GCE", t.Name(), pv.Spec.PersistentVolumeSource)
+		}
+		id, err := resizer.GetProviderVolumeID(pv)
+		if err != nil || id != testGCEDiskID {
+			t.Errorf("%s: expected %q, got %q (%v)", t.Name(), testGCEDiskID, id, err)
+		}
+	}
+	if resizer.VolumeBelongsToProvider(ebsVolume) {
+		t.Errorf("%s: EBS volume must not belong to GCE", t.Name())
+	}
+	if resizer.Project != "test-project" || len(fake.requests) != 1 {
+		t.Errorf("%s: expected the project to be read once from the metadata server, got %q after %v", t.Name(), resizer.Project, fake.requests)
+	}
+}
+
+func TestGCEDescribeAndModifyVolume(t *testing.T) {
+	fake, server := newFakeGCE(gceDisk{
+		Name:                  "pvc-1234",
+		SizeGb:                "100",
+		Type:                  "https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west3-a/diskTypes/hyperdisk-balanced",
+		ProvisionedIops:       "3000",
+		ProvisionedThroughput: "140",
+	})
+	defer server.Close()
+	resizer := newTestGCEResizer(server.URL)
+
+	props, err := resizer.DescribeVolumes([]string{testGCEDiskID})
+	if err != nil {
+		t.Fatalf("%s: could not describe volumes: %v", t.Name(), err)
+	}
+	expected := VolumeProperties{VolumeID: testGCEDiskID, VolumeType: "hyperdisk-balanced", Size: 100, Iops: 3000, Throughput: 140}
+	if len(props) != 1 || props[0] != expected {
+		t.Errorf("%s: expected %#v, got %#v", t.Name(), expected, props)
+	}
+
+	size, iops, throughput := int64(200), int64(6000), int64(140)
+	if err := resizer.ModifyVolume(testGCEDiskID, nil, &size, &iops, &throughput); err != nil {
+		t.Fatalf("%s: could not modify volume: %v", t.Name(), err)
+	}
+	if fake.disk.SizeGb != "200" || fake.disk.ProvisionedIops != "6000" || fake.disk.ProvisionedThroughput != "140" {
+		t.Errorf("%s: unexpected disk after modification: %#v", t.Name(), fake.disk)
+	}
+	for _, r := range fake.requests {
+		if strings.HasPrefix(r, http.MethodPatch) && strings.Contains(r, "provisionedThroughput") {
+			t.Errorf("%s: unchanged throughput must not be updated: %s", t.Name(), r)
+		}
+	}
+
+	// shrinking is a no-op and changing the type is rejected
+	requests := len(fake.requests)
+	if err := resizer.ResizeVolume(testGCEDiskID, 50); err != nil {
+		t.Errorf("%s: unexpected error when shrinking: %v", t.Name(), err)
+	}
+	if len(fake.requests) != requests+1 {
+		t.Errorf("%s: expected only a lookup when shrinking, got %v", t.Name(), fake.requests[requests:])
+	}
+	newType := "pd-ssd"
+	if err := resizer.ModifyVolume(testGCEDiskID, &newType, nil, nil, nil); err == nil {
+		t.Errorf("%s: expected an error when changing the disk type", t.Name())
+	}
+}
+
+func TestGCEUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	resizer := newTestGCEResizer(server.URL)
+	if err := resizer.ConnectToProvider(); err == nil {
+		t.Errorf("%s: expected an error when no token is available", t.Name())
+	}
+	if resizer.IsConnectedToProvider() {
+		t.Errorf("%s: resizer must not be connected after a failed token request", t.Name())
+	}
+}
@@ -2,7 +2,11 @@ package volumes
 
 //go:generate mockgen -package mocks -destination=../../../mocks/$GOFILE -source=$GOFILE -build_flags=-mod=vendor
 
-import v1 "k8s.io/api/core/v1"
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
 
 // VolumeProperties ...
 type VolumeProperties struct {
@@ -25,3 +29,16 @@ type VolumeResizer interface {
 	DisconnectFromProvider() error
 	DescribeVolumes(providerVolumesID []string) ([]VolumeProperties, error)
 }
+
+// NewVolumeResizer returns the volume resizer of the given cloud provider, an empty provider stands for AWS.
+func NewVolumeResizer(provider, awsRegion string) (VolumeResizer, error) {
+	switch provider {
+	case "", "aws":
+		return &EBSVolumeResizer{AWSRegion: awsRegion}, nil
+	case "gcp":
+		return &GCEPDVolumeResizer{}, nil
+	case "azure":
+		return &AzureDiskVolumeResizer{}, nil
+	}
+	return nil, fmt.Errorf("unsupported storage resize provider %q, expected one of aws, gcp or azure", provider)
+}
@@ -47,3 +47,26 @@ func TestExtractVolumeID(t *testing.T) {
 		}
 	}
 }
+
+func TestNewVolumeResizer(t *testing.T) {
+	tests := []struct {
+		provider    string
+		expectError bool
+	}{
+		{"", false},
+		{"aws", false},
+		{"gcp", false},
+		{"azure", false},
+		{"openstack", true},
+	}
+
+	for _, tt := range tests {
+		resizer, err := NewVolumeResizer(tt.provider, "eu-central-1")
+		if (err != nil) != tt.expectError {
+			t.Errorf("%s: unexpected result for provider %q: %v", t.Name(), tt.provider, err)
+		}
+		if (resizer == nil) != tt.expectError {
+			t.Errorf("%s: unexpected resizer %#v for provider %q", t.Name(), resizer, tt.provider)
+		}
+	}
+}